@@ -0,0 +1,96 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// maxHighlights caps the changelog entries included in an announcement.
+const maxHighlights = 10
+
+// Announcement is a normalized description of a published release for
+// notification plugins (chat, forums, email).
+type Announcement struct {
+	Package      string            `json:"package"`
+	Version      string            `json:"version"`
+	Organization string            `json:"organization,omitempty"`
+	Summary      string            `json:"summary,omitempty"`
+	URLs         map[string]string `json:"urls"`
+	Highlights   []string          `json:"highlights"`
+	Breaking     []string          `json:"breaking,omitempty"`
+	// CI identifies the CI run that published the release.
+	CI *CIInfo `json:"ci,omitempty"`
+}
+
+var (
+	// markdownLinkPattern matches [text](url) links.
+	markdownLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	// commitRefPattern matches trailing commit references such as "(abc1234)".
+	commitRefPattern = regexp.MustCompile(`\s*\(\s*[0-9a-f]{7,40}\s*\)\s*$`)
+)
+
+// parseHighlights returns the bullet entries of a markdown changelog, with
+// links reduced to their text and commit references removed.
+func parseHighlights(changelog string) []string {
+	highlights := []string{}
+	for _, line := range strings.Split(changelog, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) < 3 || !strings.ContainsRune("-*+", rune(line[0])) || line[1] != ' ' {
+			continue
+		}
+		entry := markdownLinkPattern.ReplaceAllString(strings.TrimSpace(line[2:]), "$1")
+		entry = strings.TrimSpace(commitRefPattern.ReplaceAllString(entry, ""))
+		if entry == "" {
+			continue
+		}
+		highlights = append(highlights, entry)
+		if len(highlights) == maxHighlights {
+			break
+		}
+	}
+	return highlights
+}
+
+// buildAnnouncement assembles the announcement for a published package.
+func buildAnnouncement(cfg *Config, releaseCtx plugin.ReleaseContext, name, version, mixExs string) *Announcement {
+	if name == "" {
+		name = parseMixApp(mixExs)
+	}
+
+	announcement := &Announcement{
+		Package:      name,
+		Version:      version,
+		Organization: cfg.Organization,
+		Summary:      parseMixDescription(mixExs),
+		URLs:         map[string]string{},
+	}
+
+	if name != "" {
+		announcement.URLs["package"] = packageURL(cfg.Organization, name, version)
+		if cfg.DocsDestination != docsDestinationCustom && cfg.Organization == "" {
+			announcement.URLs["docs"] = hexdocsURL(name, version)
+		}
+	}
+	if releaseCtx.RepositoryURL != "" {
+		announcement.URLs["repository"] = releaseCtx.RepositoryURL
+	}
+
+	changelog := releaseCtx.Changelog
+	if changelog == "" {
+		changelog = releaseCtx.ReleaseNotes
+	}
+	announcement.Highlights = parseHighlights(changelog)
+
+	if releaseCtx.Changes != nil {
+		for _, c := range releaseCtx.Changes.Breaking {
+			desc := c.BreakingDescription
+			if desc == "" {
+				desc = c.Description
+			}
+			announcement.Breaking = append(announcement.Breaking, desc)
+		}
+	}
+	return announcement
+}
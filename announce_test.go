@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseHighlights(t *testing.T) {
+	changelog := `## [1.2.0] - 2024-05-01
+
+### Features
+- add streaming decoder ([#42](https://github.com/acme/my_lib/pull/42)) (abc1234)
+* **parser:** accept trailing commas
+
+### Bug Fixes
++ fix crash on empty input (0123456789abcdef)
+-not a bullet
+`
+	want := []string{
+		"add streaming decoder (#42)",
+		"**parser:** accept trailing commas",
+		"fix crash on empty input",
+	}
+	if got := parseHighlights(changelog); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHighlights() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildAnnouncement(t *testing.T) {
+	mixExs := `def project do
+    [app: :my_lib, version: "1.2.0", description: "A \"fast\" JSON library"]
+  end`
+	releaseCtx := plugin.ReleaseContext{
+		RepositoryURL: "https://github.com/acme/my_lib",
+		ReleaseNotes:  "- add streaming decoder",
+		Changes: &plugin.CategorizedChanges{
+			Breaking: []plugin.ConventionalCommit{{Description: "drop OTP 24", BreakingDescription: "OTP 25 is now required"}},
+		},
+	}
+
+	got := buildAnnouncement(&Config{}, releaseCtx, "", "1.2.0", mixExs)
+	want := &Announcement{
+		Package: "my_lib",
+		Version: "1.2.0",
+		Summary: `A "fast" JSON library`,
+		URLs: map[string]string{
+			"package":    "https://hex.pm/packages/my_lib/1.2.0",
+			"docs":       "https://hexdocs.pm/my_lib/1.2.0",
+			"repository": "https://github.com/acme/my_lib",
+		},
+		Highlights: []string{"add streaming decoder"},
+		Breaking:   []string{"OTP 25 is now required"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildAnnouncement() = %+v, want %+v", got, want)
+	}
+
+	t.Run("private package has no public docs", func(t *testing.T) {
+		got := buildAnnouncement(&Config{Organization: "acme"}, plugin.ReleaseContext{}, "my_lib", "1.2.0", "")
+		if _, ok := got.URLs["docs"]; ok {
+			t.Error("expected no hexdocs URL for an organization package")
+		}
+		if got.URLs["package"] != "https://hex.pm/packages/acme/my_lib/1.2.0" {
+			t.Errorf("unexpected package URL %q", got.URLs["package"])
+		}
+	})
+}
+
+func TestExecuteAnnouncement(t *testing.T) {
+	dir := chdirTemp(t)
+	if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte(`[app: :my_lib, description: "JSON library"]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("Building my_lib 1.0.0"), nil
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key"},
+		Context: plugin.ReleaseContext{Version: "1.0.0", Changelog: "- first release"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	announcement, ok := resp.Outputs["announcement"].(*Announcement)
+	if !ok {
+		t.Fatalf("expected announcement output, got %T", resp.Outputs["announcement"])
+	}
+	if announcement.Package != "my_lib" || announcement.Summary != "JSON library" || !reflect.DeepEqual(announcement.Highlights, []string{"first release"}) {
+		t.Errorf("unexpected announcement: %+v", announcement)
+	}
+}
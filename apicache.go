@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// apiCacheDirName is the cache subdirectory under the user cache directory.
+const apiCacheDirName = "relicta-hex"
+
+// apiCache stores Hex API GET responses on disk for ETag revalidation.
+type apiCache struct {
+	dir string
+}
+
+// cachedResponse is a cached API response body and its ETag.
+type cachedResponse struct {
+	ETag string          `json:"etag"`
+	Body json.RawMessage `json:"body"`
+}
+
+// defaultAPICacheDir returns the cache directory used when api_cache_dir is unset.
+func defaultAPICacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no user cache directory: set api_cache_dir: %w", err)
+	}
+	return filepath.Join(base, apiCacheDirName, "api"), nil
+}
+
+// newAPICache returns the cache configured by api_cache and api_cache_dir,
+// or nil when caching is disabled or unavailable.
+func newAPICache(cfg *Config) *apiCache {
+	if !cfg.APICache {
+		return nil
+	}
+	dir := cfg.APICacheDir
+	if dir == "" {
+		var err error
+		if dir, err = defaultAPICacheDir(); err != nil {
+			return nil
+		}
+	}
+	return &apiCache{dir: dir}
+}
+
+// key identifies a request. The API key is part of it because responses
+// differ by the permissions of the caller.
+func (c *apiCache) key(url, apiKey string) string {
+	sum := sha256.Sum256([]byte(url + "\x00" + apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the cached response for a request, if any.
+func (c *apiCache) get(url, apiKey string) (*cachedResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, c.key(url, apiKey)+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil || cached.ETag == "" {
+		return nil, false
+	}
+	return &cached, true
+}
+
+// put stores a response that carries an ETag. Cache write failures are
+// ignored: the cache only saves requests.
+func (c *apiCache) put(url, apiKey, etag string, body []byte) {
+	if c == nil || etag == "" || !json.Valid(body) {
+		return
+	}
+	data, err := json.Marshal(cachedResponse{ETag: etag, Body: body})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return
+	}
+	path := filepath.Join(c.dir, c.key(url, apiKey)+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestHexAPICacheRevalidation(t *testing.T) {
+	var full, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"name":"relicta-hex-1"}]`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	cfg := &Config{APIKey: "key", APIURL: server.URL, APICache: true, APICacheDir: cacheDir}
+	p := &HexPlugin{}
+
+	for i := 0; i < 3; i++ {
+		keys, err := p.hexAPI(cfg).listKeys(context.Background())
+		if err != nil {
+			t.Fatalf("listKeys() error = %v", err)
+		}
+		if len(keys) != 1 || keys[0].Name != "relicta-hex-1" {
+			t.Fatalf("unexpected keys on run %d: %+v", i, keys)
+		}
+	}
+	if full != 1 || notModified != 2 {
+		t.Errorf("expected 1 full response and 2 revalidations, got %d and %d", full, notModified)
+	}
+
+	t.Run("cache is scoped to the api key", func(t *testing.T) {
+		other := *cfg
+		other.APIKey = "other-key"
+		if _, err := p.hexAPI(&other).listKeys(context.Background()); err != nil {
+			t.Fatalf("listKeys() error = %v", err)
+		}
+		if full != 2 {
+			t.Errorf("expected a full response for a different key, got %d", full)
+		}
+	})
+
+	t.Run("cache files are private", func(t *testing.T) {
+		entries, err := os.ReadDir(cacheDir)
+		if err != nil || len(entries) == 0 {
+			t.Fatalf("expected cache entries, got %v (%v)", entries, err)
+		}
+		info, err := entries[0].Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("cache file mode = %v, want 0600", info.Mode().Perm())
+		}
+	})
+}
+
+func TestHexAPICacheDisabled(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") != "" {
+			t.Error("unexpected conditional request with caching disabled")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	cfg := &Config{APIKey: "key", APIURL: server.URL}
+	for i := 0; i < 2; i++ {
+		if _, err := (&HexPlugin{}).hexAPI(cfg).listKeys(context.Background()); err != nil {
+			t.Fatalf("listKeys() error = %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
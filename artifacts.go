@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Content types of the registered artifacts.
+const (
+	contentTypeTar  = "application/x-tar"
+	contentTypeGzip = "application/gzip"
+)
+
+// ReleaseArtifact describes a file registered with Relicta for attaching to
+// the forge release.
+type ReleaseArtifact struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// fileDigest returns the size and hex SHA-256 digest of a file.
+func fileDigest(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newReleaseArtifact digests a file written to artifacts_dir.
+func newReleaseArtifact(path, contentType string) (ReleaseArtifact, error) {
+	size, digest, err := fileDigest(path)
+	if err != nil {
+		return ReleaseArtifact{}, fmt.Errorf("failed to digest %s: %w", path, err)
+	}
+	return ReleaseArtifact{
+		Name:        filepath.Base(path),
+		Path:        path,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      digest,
+	}, nil
+}
+
+// sdkArtifact converts the artifact for the SDK's artifact list.
+func (a ReleaseArtifact) sdkArtifact() plugin.Artifact {
+	return plugin.Artifact{
+		Name:     a.Name,
+		Path:     a.Path,
+		Type:     "file",
+		Size:     a.Size,
+		Checksum: "sha256:" + a.SHA256,
+	}
+}
+
+// packageArtifactName returns the file name of the package tarball artifact.
+func packageArtifactName(cfg *Config, version string) string {
+	projectType, _ := cfg.resolveProjectType()
+	name, _ := declaredPackageName(cfg.WorkDir, projectType)
+	if name == "" {
+		name = "package"
+	}
+	return fmt.Sprintf("%s-%s.tar", name, version)
+}
+
+// buildPackageArtifact builds the package tarball into artifacts_dir before
+// publishing, so the registered tarball is the one that was published.
+func (p *HexPlugin) buildPackageArtifact(ctx context.Context, cfg *Config, version string, summary *RunSummary) (ReleaseArtifact, error) {
+	if err := os.MkdirAll(cfg.ArtifactsDir, 0o755); err != nil {
+		return ReleaseArtifact{}, fmt.Errorf("failed to create artifacts_dir: %w", err)
+	}
+	path := filepath.Join(cfg.ArtifactsDir, packageArtifactName(cfg, version))
+	if err := p.buildPackage(ctx, cfg, path, summary); err != nil {
+		return ReleaseArtifact{}, err
+	}
+	return newReleaseArtifact(path, contentTypeTar)
+}
+
+// buildDocsArtifact packs the docs generated during the publish into artifacts_dir.
+func buildDocsArtifact(cfg *Config, version string) (ReleaseArtifact, error) {
+	if err := os.MkdirAll(cfg.ArtifactsDir, 0o755); err != nil {
+		return ReleaseArtifact{}, fmt.Errorf("failed to create artifacts_dir: %w", err)
+	}
+	path := filepath.Join(cfg.ArtifactsDir, docsTarballName(version))
+	if _, err := buildDocsTarball(filepath.Join(cfg.WorkDir, "doc"), path); err != nil {
+		return ReleaseArtifact{}, err
+	}
+	return newReleaseArtifact(path, contentTypeGzip)
+}
+
+// registerArtifacts attaches the artifacts to the response and summary.
+func registerArtifacts(resp *plugin.ExecuteResponse, artifacts []ReleaseArtifact, summary *RunSummary) {
+	if len(artifacts) == 0 {
+		return
+	}
+	for _, a := range artifacts {
+		resp.Artifacts = append(resp.Artifacts, a.sdkArtifact())
+		summary.addArtifact(a.sdkArtifact())
+	}
+	if resp.Outputs == nil {
+		resp.Outputs = map[string]any{}
+	}
+	resp.Outputs["artifacts"] = artifacts
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestFileDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.tar")
+	if err := os.WriteFile(path, []byte("package"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("package"))
+
+	size, digest, err := fileDigest(path)
+	if err != nil {
+		t.Fatalf("fileDigest() error = %v", err)
+	}
+	if size != 7 || digest != hex.EncodeToString(sum[:]) {
+		t.Errorf("fileDigest() = %d, %s", size, digest)
+	}
+}
+
+func TestExecuteRegistersArtifacts(t *testing.T) {
+	dir := chdirTemp(t)
+	if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte(`[app: :my_lib]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			if args[0] == "hex.build" {
+				return nil, os.WriteFile(args[len(args)-1], []byte("tarball"), 0o644)
+			}
+			// hex.publish generates the docs
+			if err := os.MkdirAll("doc", 0o755); err != nil {
+				return nil, err
+			}
+			return []byte("Building my_lib 1.0.0"), os.WriteFile(filepath.Join("doc", "index.html"), []byte("<html></html>"), 0o644)
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "artifacts_dir": "dist"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+	if errMsg, ok := resp.Outputs["artifacts_error"]; ok {
+		t.Fatalf("unexpected artifacts_error: %v", errMsg)
+	}
+
+	if len(resp.Artifacts) != 2 {
+		t.Fatalf("expected 2 artifacts, got %+v", resp.Artifacts)
+	}
+	pkg, docs := resp.Artifacts[0], resp.Artifacts[1]
+	if pkg.Name != "my_lib-1.0.0.tar" || pkg.Path != filepath.Join("dist", "my_lib-1.0.0.tar") || pkg.Size != 7 {
+		t.Errorf("unexpected package artifact: %+v", pkg)
+	}
+	if docs.Name != "docs-1.0.0.tar.gz" || docs.Checksum == "" {
+		t.Errorf("unexpected docs artifact: %+v", docs)
+	}
+
+	artifacts, _ := resp.Outputs["artifacts"].([]ReleaseArtifact)
+	if len(artifacts) != 2 || artifacts[0].ContentType != contentTypeTar || artifacts[1].ContentType != contentTypeGzip {
+		t.Errorf("unexpected artifacts output: %+v", resp.Outputs["artifacts"])
+	}
+}
+
+func TestPackageArtifactName(t *testing.T) {
+	tests := []struct {
+		name   string
+		mixExs string
+		want   string
+	}{
+		{name: "app name", mixExs: `[app: :my_lib]`, want: "my_lib-1.0.0.tar"},
+		{name: "declared package name", mixExs: `[app: :my_app, package: [name: "my_lib"]]`, want: "my_lib-1.0.0.tar"},
+		{name: "unknown", want: "package-1.0.0.tar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			if tt.mixExs != "" {
+				writeFile(t, mixExsFile, tt.mixExs)
+			}
+			cfg := &Config{WorkDir: ".", ProjectType: projectTypeAuto}
+			if got := packageArtifactName(cfg, "1.0.0"); got != tt.want {
+				t.Errorf("packageArtifactName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
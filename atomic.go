@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// publishedPackage is a package an atomic run has published and may have
+// to retire.
+type publishedPackage struct {
+	cfg     *Config
+	label   string
+	name    string
+	version string
+}
+
+// RolledBackPackage reports the retirement of one package during an atomic
+// rollback.
+type RolledBackPackage struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	Retired bool   `json:"retired"`
+	Error   string `json:"error,omitempty"`
+}
+
+// newPublishedPackage records a successful package publish, taking the name
+// and version the publish reported. The name falls back to the one the
+// project declares, and is empty when neither is known.
+func newPublishedPackage(cfg *Config, pkg PackageConfig, releaseCtx plugin.ReleaseContext, resp *plugin.ExecuteResponse) publishedPackage {
+	published := publishedPackage{cfg: cfg, label: pkg.label(), version: strings.TrimPrefix(releaseCtx.Version, "v")}
+	if name, ok := resp.Outputs["package"].(string); ok && name != "" {
+		published.name = name
+	} else {
+		projectType, _ := cfg.resolveProjectType()
+		published.name, _ = declaredPackageName(cfg.WorkDir, projectType)
+	}
+	if version, ok := resp.Outputs["version"].(string); ok && version != "" {
+		published.version = version
+	}
+	return published
+}
+
+// publishedBeforeFailure reports whether a failed publish had already
+// uploaded its package, which a canary may have retired itself.
+func publishedBeforeFailure(resp *plugin.ExecuteResponse) bool {
+	_, uploaded := resp.Outputs["package_url"]
+	return uploaded && resp.Outputs["canary"] != "retired"
+}
+
+// rollback retires the packages published before failed, newest first, so
+// the registry is never left with part of a release. Every package is
+// attempted even when an earlier retirement fails.
+func (p *HexPlugin) rollback(ctx context.Context, published []publishedPackage, failed string, summary *RunSummary) []RolledBackPackage {
+	// The hook may have been cancelled; the rollback must still run
+	ctx = context.WithoutCancel(ctx)
+	message := rollbackMessage(failed)
+
+	results := make([]RolledBackPackage, 0, len(published))
+	for i := len(published) - 1; i >= 0; i-- {
+		pkg := published[i]
+		if pkg.name == "" {
+			// Retiring a guessed name could retire someone else's package
+			results = append(results, RolledBackPackage{
+				Package: pkg.label,
+				Version: pkg.version,
+				Error:   "could not determine the package name to retire",
+			})
+			continue
+		}
+		result := RolledBackPackage{Package: pkg.name, Version: pkg.version}
+		summary.debugf("atomic: retiring %s %s", pkg.name, pkg.version)
+		args := invalidRetireArgs(pkg.cfg.Organization, pkg.name, pkg.version, message)
+		if output, err := p.runMix(ctx, pkg.cfg, summary, args, pkg.cfg.hexEnv()); err != nil {
+			result.Error = fmt.Sprintf("mix hex.retire failed: %v\nOutput: %s", err, string(output))
+		} else {
+			result.Retired = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// rollbackMessage is the retirement message of a package rolled back after
+// failed did not publish.
+func rollbackMessage(failed string) string {
+	return fmt.Sprintf("rolled back: %s failed to publish in the same release", failed)
+}
+
+// describeRollback summarizes a rollback for the error of the failed run.
+func describeRollback(results []RolledBackPackage) string {
+	var retired, kept []string
+	for _, r := range results {
+		if r.Retired {
+			retired = append(retired, r.Package)
+		} else {
+			kept = append(kept, r.Package)
+		}
+	}
+	var parts []string
+	if len(retired) > 0 {
+		parts = append(parts, "retired "+strings.Join(retired, ", "))
+	}
+	if len(kept) > 0 {
+		parts = append(parts, "could not retire "+strings.Join(kept, ", "))
+	}
+	return "atomic rollback " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteAtomic(t *testing.T) {
+	packages := []any{
+		map[string]any{"name": "core", "work_dir": "apps/core"},
+		map[string]any{"name": "web", "work_dir": "apps/web", "organization": "acme"},
+		map[string]any{"name": "ext", "work_dir": "apps/ext"},
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]any
+		failDocs    bool
+		retireFails string
+		wantRetired [][]string
+		wantPrefix  string
+		wantResult  []RolledBackPackage
+	}{
+		{
+			name:       "off",
+			config:     map[string]any{},
+			wantPrefix: "package ext: mix hex.publish failed",
+		},
+		{
+			name:   "retires published packages newest first",
+			config: map[string]any{"atomic": true},
+			wantRetired: [][]string{
+				{"hex.retire", "my_web", "1.0.0", "invalid", "--message", "rolled back: ext failed to publish in the same release", "--organization", "acme"},
+				{"hex.retire", "my_core", "1.0.0", "invalid", "--message", "rolled back: ext failed to publish in the same release"},
+			},
+			wantPrefix: "atomic rollback retired my_web, my_core after package ext failed: mix hex.publish failed",
+			wantResult: []RolledBackPackage{{Package: "my_web", Version: "1.0.0", Retired: true}, {Package: "my_core", Version: "1.0.0", Retired: true}},
+		},
+		{
+			name:        "retirement failure",
+			config:      map[string]any{"atomic": true},
+			retireFails: "my_core",
+			wantRetired: [][]string{
+				{"hex.retire", "my_web", "1.0.0", "invalid", "--message", "rolled back: ext failed to publish in the same release", "--organization", "acme"},
+				{"hex.retire", "my_core", "1.0.0", "invalid", "--message", "rolled back: ext failed to publish in the same release"},
+			},
+			wantPrefix: "atomic rollback retired my_web; could not retire my_core after package ext failed",
+		},
+		{
+			name:     "failed package already uploaded",
+			config:   map[string]any{"atomic": true, "split_phases": true},
+			failDocs: true,
+			wantRetired: [][]string{
+				{"hex.retire", "my_ext", "1.0.0", "invalid", "--message", "rolled back: ext failed to publish in the same release"},
+				{"hex.retire", "my_web", "1.0.0", "invalid", "--message", "rolled back: ext failed to publish in the same release", "--organization", "acme"},
+				{"hex.retire", "my_core", "1.0.0", "invalid", "--message", "rolled back: ext failed to publish in the same release"},
+			},
+			wantPrefix: "atomic rollback retired my_ext, my_web, my_core after package ext failed: package v1.0.0 published but docs publish failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					pkg := "my_" + strings.TrimPrefix(dir, "apps/")
+					switch {
+					case args[0] == "hex.retire" && args[1] == tt.retireFails:
+						return []byte("** (Mix) Request failed (status 403)"), errors.New("exit status 1")
+					case args[0] == "hex.retire":
+						return nil, nil
+					case pkg == "my_ext" && (!tt.failDocs || args[1] == phaseDocs):
+						return []byte("** (Mix) Request failed (status 422)"), errors.New("exit status 1")
+					}
+					return []byte("Building " + pkg + " 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "packages": packages}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.HasPrefix(resp.Error, tt.wantPrefix) {
+				t.Errorf("error = %q, want prefix %q", resp.Error, tt.wantPrefix)
+			}
+
+			var retired [][]string
+			for _, c := range mock.Calls {
+				if c.Args[0] == "hex.retire" {
+					retired = append(retired, c.Args)
+				}
+			}
+			if !reflect.DeepEqual(retired, tt.wantRetired) {
+				t.Errorf("retired = %v, want %v", retired, tt.wantRetired)
+			}
+			if tt.wantResult != nil && !reflect.DeepEqual(resp.Outputs["rollback"], tt.wantResult) {
+				t.Errorf("rollback = %+v, want %+v", resp.Outputs["rollback"], tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestNewPublishedPackage(t *testing.T) {
+	tests := []struct {
+		name    string
+		mixExs  string
+		outputs map[string]any
+		want    string
+	}{
+		{
+			name:    "reported by the publish",
+			mixExs:  `[app: :my_app]`,
+			outputs: map[string]any{"package": "my_lib"},
+			want:    "my_lib",
+		},
+		{
+			name:   "declared package name",
+			mixExs: `[app: :my_app, package: [name: "my_lib"]]`,
+			want:   "my_lib",
+		},
+		{
+			name:   "app name",
+			mixExs: `[app: :my_app]`,
+			want:   "my_app",
+		},
+		{
+			name: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			if tt.mixExs != "" {
+				writeFile(t, mixExsFile, tt.mixExs)
+			}
+			outputs := tt.outputs
+			if outputs == nil {
+				outputs = map[string]any{}
+			}
+			cfg := &Config{WorkDir: ".", ProjectType: projectTypeAuto}
+			got := newPublishedPackage(cfg, PackageConfig{Name: "core"}, plugin.ReleaseContext{Version: "1.0.0"}, &plugin.ExecuteResponse{Outputs: outputs})
+			if got.name != tt.want {
+				t.Errorf("name = %q, want %q", got.name, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollbackUnknownPackageName(t *testing.T) {
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+	published := []publishedPackage{{cfg: &Config{WorkDir: "."}, label: "core", version: "1.0.0"}}
+
+	results := p.rollback(context.Background(), published, "web", &RunSummary{})
+	if len(mock.Calls) > 0 {
+		t.Errorf("expected no retirement, got %+v", mock.Calls)
+	}
+	want := []RolledBackPackage{{Package: "core", Version: "1.0.0", Error: "could not determine the package name to retire"}}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("rollback = %+v, want %+v", results, want)
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// errAttemptBudgetExhausted stops a publish whose version has used all of
+// max_attempts_per_version. It is never retried.
+var errAttemptBudgetExhausted = errors.New("publish attempt budget exhausted")
+
+// PublishState is the state file shared by the runs publishing a package,
+// so limits hold across retries and re-runs.
+type PublishState struct {
+	Versions map[string]*VersionAttempts `json:"versions"`
+}
+
+// VersionAttempts counts the publish attempts of one package version.
+type VersionAttempts struct {
+	Attempts      int       `json:"attempts"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	Published     bool      `json:"published"`
+}
+
+// AttemptBudget reports the attempts of the version being published.
+type AttemptBudget struct {
+	Key         string `json:"key"`
+	Attempts    int    `json:"attempts"`
+	MaxAttempts int    `json:"max_attempts"`
+	Remaining   int    `json:"remaining"`
+}
+
+// validateAttemptBudget checks that max_attempts_per_version is not
+// negative and has a state_file to count in.
+func validateAttemptBudget(maxAttempts int, stateFile string) *fieldError {
+	switch {
+	case maxAttempts < 0:
+		return &fieldError{Field: "max_attempts_per_version", Err: fmt.Errorf("must not be negative")}
+	case maxAttempts > 0 && stateFile == "":
+		return &fieldError{Field: "max_attempts_per_version", Err: fmt.Errorf("requires state_file, where attempts are counted across runs")}
+	case stateFile != "":
+		if err := validatePath(stateFile); err != nil {
+			return &fieldError{Field: "state_file", Err: err}
+		}
+	}
+	return nil
+}
+
+// readPublishState reads the state file; a missing file is an empty state.
+func readPublishState(path string) (*PublishState, error) {
+	state := &PublishState{Versions: map[string]*VersionAttempts{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state_file: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state_file %s: %w", path, err)
+	}
+	if state.Versions == nil {
+		state.Versions = map[string]*VersionAttempts{}
+	}
+	return state, nil
+}
+
+// writePublishState replaces the state file atomically, so an interrupted
+// run never leaves it truncated.
+func writePublishState(path string, state *PublishState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state_file: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create state_file directory: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write state_file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write state_file: %w", err)
+	}
+	return nil
+}
+
+// attemptKey identifies a package version in the state file, scoped to its
+// organization repository.
+func attemptKey(cfg *Config, version string) string {
+	projectType, _ := cfg.resolveProjectType()
+	name, _ := declaredPackageName(cfg.WorkDir, projectType)
+	if name == "" {
+		name = filepath.Base(filepath.Clean(cfg.WorkDir))
+	}
+	if cfg.Organization != "" {
+		name = cfg.Organization + "/" + name
+	}
+	return name + "@" + version
+}
+
+// attemptTracker counts the publish attempts of one version in the state
+// file, persisting each attempt before it runs so a killed run still counts.
+type attemptTracker struct {
+	mu    sync.Mutex
+	path  string
+	key   string
+	max   int
+	state *PublishState
+}
+
+// newAttemptTracker loads the state of the version being published.
+func newAttemptTracker(cfg *Config, version string) (*attemptTracker, error) {
+	state, err := readPublishState(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	key := attemptKey(cfg, version)
+	if state.Versions[key] == nil {
+		state.Versions[key] = &VersionAttempts{}
+	}
+	return &attemptTracker{path: cfg.StateFile, key: key, max: cfg.MaxAttemptsPerVersion, state: state}, nil
+}
+
+func (t *attemptTracker) entry() *VersionAttempts {
+	return t.state.Versions[t.key]
+}
+
+// budget reports the attempts used and left.
+func (t *attemptTracker) budget() AttemptBudget {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return AttemptBudget{Key: t.key, Attempts: t.entry().Attempts, MaxAttempts: t.max, Remaining: max(t.max-t.entry().Attempts, 0)}
+}
+
+// exhaustedError explains that no attempts are left.
+func (t *attemptTracker) exhaustedError() error {
+	msg := fmt.Sprintf("%d of max_attempts_per_version %d attempts used for %s", t.entry().Attempts, t.max, t.key)
+	if last := t.entry().LastError; last != "" {
+		msg += fmt.Sprintf(" (last error: %s)", last)
+	}
+	return fmt.Errorf("%w: %s; fix the package, then remove %s from %s to publish it again", errAttemptBudgetExhausted, msg, t.key, t.path)
+}
+
+// take records an attempt, or fails once the budget is used up.
+func (t *attemptTracker) take() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry := t.entry()
+	if entry.Attempts >= t.max {
+		return t.exhaustedError()
+	}
+	entry.Attempts++
+	entry.LastAttemptAt = time.Now().UTC()
+	return writePublishState(t.path, t.state)
+}
+
+// finish records the outcome of the last attempt.
+func (t *attemptTracker) finish(err error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if errors.Is(err, errAttemptBudgetExhausted) {
+		return nil
+	}
+	entry := t.entry()
+	entry.Published = err == nil
+	entry.LastError = ""
+	if err != nil {
+		entry.LastError, _, _ = strings.Cut(err.Error(), "\n")
+	}
+	return writePublishState(t.path, t.state)
+}
+
+// attemptTrackerKey carries the tracker to the package publish phase.
+type attemptTrackerKey struct{}
+
+// withAttemptTracker returns a context whose publish attempts are counted.
+func withAttemptTracker(ctx context.Context, t *attemptTracker) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, attemptTrackerKey{}, t)
+}
+
+// attemptTrackerFrom returns the tracker carried by ctx, if any.
+func attemptTrackerFrom(ctx context.Context) *attemptTracker {
+	t, _ := ctx.Value(attemptTrackerKey{}).(*attemptTracker)
+	return t
+}
+
+// newPublishAttempts loads the attempt budget when max_attempts_per_version
+// is set and reports it in the attempt_budget output. It returns a failed
+// response when the state file cannot be read or no attempts are left.
+func newPublishAttempts(cfg *Config, version string, outputs map[string]any) (*attemptTracker, *plugin.ExecuteResponse) {
+	if cfg.MaxAttemptsPerVersion <= 0 {
+		return nil, nil
+	}
+	tracker, err := newAttemptTracker(cfg, version)
+	if err != nil {
+		return nil, &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+			Outputs: outputs,
+		}
+	}
+	budget := tracker.budget()
+	outputs["attempt_budget"] = budget
+	if budget.Remaining == 0 {
+		return nil, &plugin.ExecuteResponse{
+			Success: false,
+			Error:   tracker.exhaustedError().Error(),
+			Outputs: outputs,
+		}
+	}
+	return tracker, nil
+}
+
+// record stores the outcome of the publish and updates attempt_budget. A
+// state file that cannot be written is reported as state_file_error.
+func (t *attemptTracker) record(err error, outputs map[string]any) {
+	if t == nil {
+		return
+	}
+	if werr := t.finish(err); werr != nil {
+		outputs["state_file_error"] = werr.Error()
+	}
+	outputs["attempt_budget"] = t.budget()
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateAttemptBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		max       int
+		stateFile string
+		wantField string
+	}{
+		{name: "disabled", max: 0},
+		{name: "with state file", max: 3, stateFile: ".relicta/hex-state.json"},
+		{name: "negative", max: -1, wantField: "max_attempts_per_version"},
+		{name: "missing state file", max: 3, wantField: "max_attempts_per_version"},
+		{name: "traversing state file", max: 3, stateFile: "../state.json", wantField: "state_file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAttemptBudget(tt.max, tt.stateFile)
+			switch {
+			case tt.wantField == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantField != "" && (err == nil || err.Field != tt.wantField):
+				t.Errorf("validateAttemptBudget() = %v, want error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestExecuteAttemptBudget(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        map[string]any
+		runs          []bool // whether hex.publish succeeds, per attempt
+		wantSuccess   []bool // per Execute run
+		wantPublishes int
+		wantAttempts  int
+		wantRemaining int
+		wantError     string
+	}{
+		{
+			name:          "retries count against the budget",
+			config:        map[string]any{"retries": 3},
+			runs:          []bool{false, false, false, false},
+			wantSuccess:   []bool{false},
+			wantPublishes: 2,
+			wantAttempts:  2,
+			wantError:     "publish attempt budget exhausted",
+		},
+		{
+			name:          "re-runs count against the budget",
+			runs:          []bool{false, false, false},
+			wantSuccess:   []bool{false, false, false},
+			wantPublishes: 2,
+			wantAttempts:  2,
+			wantError:     "last error",
+		},
+		{
+			name:          "a successful attempt leaves the rest",
+			runs:          []bool{false, true},
+			wantSuccess:   []bool{false, true},
+			wantPublishes: 2,
+			wantAttempts:  2,
+		},
+		{
+			name:          "disabled without a cap",
+			config:        map[string]any{"max_attempts_per_version": 0},
+			runs:          []bool{false, false, false},
+			wantSuccess:   []bool{false, false, false},
+			wantPublishes: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFile(t, "mix.exs", `def project, do: [app: :my_lib, version: "1.0.0"]`)
+			publishes := 0
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if !contains(args, "hex.publish") {
+						return nil, nil
+					}
+					ok := tt.runs[publishes]
+					publishes++
+					if !ok {
+						return []byte("** (Mix) Request failed (:econnrefused)"), errors.New("exit status 1")
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "k", "retry_delay": "1ms", "max_attempts_per_version": 2, "state_file": ".relicta/hex-state.json"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			var resp *plugin.ExecuteResponse
+			for i, want := range tt.wantSuccess {
+				var err error
+				resp, err = p.Execute(context.Background(), plugin.ExecuteRequest{
+					Hook:    plugin.HookPostPublish,
+					Config:  config,
+					Context: plugin.ReleaseContext{Version: "1.0.0"},
+				})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if resp.Success != want {
+					t.Fatalf("run %d: success = %v, want %v (error: %s)", i, resp.Success, want, resp.Error)
+				}
+			}
+			if publishes != tt.wantPublishes {
+				t.Errorf("hex.publish ran %d times, want %d", publishes, tt.wantPublishes)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("error = %q, want it to contain %q", resp.Error, tt.wantError)
+			}
+			if tt.wantAttempts == 0 {
+				if _, ok := resp.Outputs["attempt_budget"]; ok {
+					t.Errorf("unexpected attempt_budget %v", resp.Outputs["attempt_budget"])
+				}
+				return
+			}
+			budget, ok := resp.Outputs["attempt_budget"].(AttemptBudget)
+			if !ok {
+				t.Fatalf("attempt_budget = %v", resp.Outputs["attempt_budget"])
+			}
+			want := AttemptBudget{Key: "my_lib@1.0.0", Attempts: tt.wantAttempts, MaxAttempts: 2, Remaining: tt.wantRemaining}
+			if budget != want {
+				t.Errorf("attempt_budget = %+v, want %+v", budget, want)
+			}
+			state, err := readPublishState(".relicta/hex-state.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := state.Versions["my_lib@1.0.0"]; got == nil || got.Attempts != tt.wantAttempts {
+				t.Errorf("state = %+v", got)
+			}
+		})
+	}
+}
+
+func TestExecuteAttemptBudgetDryRun(t *testing.T) {
+	chdirTemp(t)
+	writeFile(t, "mix.exs", `def project, do: [app: :my_lib, version: "1.0.0"]`)
+	writeFile(t, "state.json", `{"versions": {"my_lib@1.0.0": {"attempts": 1, "last_error": "exit status 1"}}}`)
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		DryRun:  true,
+		Config:  map[string]any{"api_key": "k", "max_attempts_per_version": 3, "state_file": "state.json"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+	want := AttemptBudget{Key: "my_lib@1.0.0", Attempts: 1, MaxAttempts: 3, Remaining: 2}
+	if got := resp.Outputs["attempt_budget"]; got != want {
+		t.Errorf("attempt_budget = %+v, want %+v", got, want)
+	}
+}
+
+func TestAttemptKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		mixExs       string
+		organization string
+		want         string
+	}{
+		{name: "app name", mixExs: `[app: :my_lib]`, want: "my_lib@1.0.0"},
+		{name: "declared package name", mixExs: `[app: :my_app, package: [name: "my_lib"]]`, want: "my_lib@1.0.0"},
+		{name: "organization", mixExs: `[app: :my_lib]`, organization: "acme", want: "acme/my_lib@1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFile(t, mixExsFile, tt.mixExs)
+			cfg := &Config{WorkDir: ".", ProjectType: projectTypeAuto, Organization: tt.organization}
+			if got := attemptKey(cfg, "1.0.0"); got != tt.want {
+				t.Errorf("attemptKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
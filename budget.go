@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Publish phases that share the deadline budget, in the order they run.
+const (
+	budgetGates        = "gates"
+	budgetBuild        = "build"
+	budgetUpload       = "upload"
+	budgetVerification = "verification"
+)
+
+// budgetWeights are the relative shares of the remaining deadline each phase
+// receives. Uploads and gates dominate most publishes.
+var budgetWeights = map[string]float64{
+	budgetGates:        3,
+	budgetBuild:        1,
+	budgetUpload:       4,
+	budgetVerification: 2,
+}
+
+// PhaseTiming reports the budget and elapsed time of one publish phase.
+type PhaseTiming struct {
+	Phase    string `json:"phase"`
+	Budget   string `json:"budget"`
+	Elapsed  string `json:"elapsed"`
+	Exceeded bool   `json:"exceeded,omitempty"`
+}
+
+// deadlineBudget splits the time left before the context deadline across
+// the publish phases. Each phase gets its weighted share of what remains
+// when it starts, so time a phase does not use rolls over to later ones.
+// A nil budget leaves every phase bounded by the hook context alone.
+type deadlineBudget struct {
+	parent   context.Context
+	deadline time.Time
+	phases   []string
+
+	current string
+	started time.Time
+	share   time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timings []PhaseTiming
+}
+
+// budgetPhases lists the phases a publish with cfg runs.
+func budgetPhases(cfg *Config) []string {
+	var phases []string
+	if len(cfg.Gates) > 0 || len(cfg.VerifyMatrix) > 0 {
+		phases = append(phases, budgetGates)
+	}
+	phases = append(phases, budgetBuild, budgetUpload)
+	if cfg.Canary || len(cfg.PostPublishTasks) > 0 {
+		phases = append(phases, budgetVerification)
+	}
+	return phases
+}
+
+// newDeadlineBudget returns the budget of a publish, or nil when
+// deadline_budget is off or the host supplied no deadline.
+func newDeadlineBudget(ctx context.Context, cfg *Config) *deadlineBudget {
+	if !cfg.DeadlineBudget {
+		return nil
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	return &deadlineBudget{parent: ctx, deadline: deadline, phases: budgetPhases(cfg)}
+}
+
+// enter ends the running phase and starts the named one, returning the
+// context bounded by its share. Phases the publish does not plan run
+// unbounded within the hook context. A nil budget returns ctx unchanged.
+func (b *deadlineBudget) enter(ctx context.Context, phase string) context.Context {
+	if b == nil {
+		return ctx
+	}
+	b.end()
+	i := slices.Index(b.phases, phase)
+	if i < 0 {
+		return b.parent
+	}
+
+	var total float64
+	for _, p := range b.phases[i:] {
+		total += budgetWeights[p]
+	}
+	b.current = phase
+	b.started = time.Now()
+	b.share = time.Duration(float64(time.Until(b.deadline)) * budgetWeights[phase] / total)
+	b.ctx, b.cancel = context.WithTimeout(b.parent, b.share)
+	return b.ctx
+}
+
+// exceeded reports whether the running phase used up its own share while
+// the hook deadline itself has not passed.
+func (b *deadlineBudget) exceeded() bool {
+	return b.ctx != nil && errors.Is(b.ctx.Err(), context.DeadlineExceeded) && b.parent.Err() == nil
+}
+
+// end records the timing of the running phase and releases its context.
+func (b *deadlineBudget) end() {
+	if b.ctx == nil {
+		return
+	}
+	b.timings = append(b.timings, PhaseTiming{
+		Phase:    b.current,
+		Budget:   b.share.Round(time.Millisecond).String(),
+		Elapsed:  time.Since(b.started).Round(time.Millisecond).String(),
+		Exceeded: b.exceeded(),
+	})
+	b.cancel()
+	b.ctx, b.cancel = nil, nil
+}
+
+// report ends the running phase and adds the timing report to resp. A
+// failed publish whose phase overran its share names that phase.
+func (b *deadlineBudget) report(resp *plugin.ExecuteResponse) {
+	if b == nil {
+		return
+	}
+	b.end()
+	if resp == nil || len(b.timings) == 0 {
+		return
+	}
+	if resp.Outputs == nil {
+		resp.Outputs = map[string]any{}
+	}
+	resp.Outputs["phase_budget"] = b.timings
+
+	last := b.timings[len(b.timings)-1]
+	if !resp.Success && last.Exceeded {
+		resp.Error = fmt.Sprintf("%s phase exceeded its deadline budget of %s (elapsed %s): %s", last.Phase, last.Budget, last.Elapsed, resp.Error)
+		resp.Outputs["error_class"] = errorClassTimeout
+	}
+}
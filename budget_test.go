@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestBudgetPhases(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{name: "publish only", want: []string{budgetBuild, budgetUpload}},
+		{name: "gates", cfg: Config{Gates: []GateConfig{{Name: "test"}}}, want: []string{budgetGates, budgetBuild, budgetUpload}},
+		{name: "verify matrix", cfg: Config{VerifyMatrix: []MatrixEntry{{}}}, want: []string{budgetGates, budgetBuild, budgetUpload}},
+		{name: "canary", cfg: Config{Canary: true}, want: []string{budgetBuild, budgetUpload, budgetVerification}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := budgetPhases(&tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("budgetPhases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDeadlineBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if b := newDeadlineBudget(ctx, &Config{}); b != nil {
+		t.Error("expected no budget when deadline_budget is off")
+	}
+	if b := newDeadlineBudget(context.Background(), &Config{DeadlineBudget: true}); b != nil {
+		t.Error("expected no budget without a deadline")
+	}
+	if b := newDeadlineBudget(ctx, &Config{DeadlineBudget: true}); b == nil {
+		t.Error("expected a budget")
+	}
+}
+
+func TestDeadlineBudgetShares(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	b := newDeadlineBudget(ctx, &Config{DeadlineBudget: true, Gates: []GateConfig{{Name: "test"}}, Canary: true})
+
+	near := func(got, want time.Duration) bool {
+		return got > want-200*time.Millisecond && got <= want
+	}
+
+	// Gates get 3 of the 10 weights
+	b.enter(ctx, budgetGates)
+	if !near(b.share, 3*time.Second) {
+		t.Errorf("gates share = %s, want about 3s", b.share)
+	}
+	// Unused gates time rolls over: build gets 1 of the remaining 7 weights
+	b.enter(ctx, budgetBuild)
+	if !near(b.share, 10*time.Second/7) {
+		t.Errorf("build share = %s, want about 1.43s", b.share)
+	}
+
+	resp := &plugin.ExecuteResponse{Success: true}
+	b.report(resp)
+	timings := resp.Outputs["phase_budget"].([]PhaseTiming)
+	if len(timings) != 2 || timings[0].Phase != budgetGates || timings[1].Phase != budgetBuild || timings[1].Exceeded {
+		t.Errorf("timings = %+v", timings)
+	}
+}
+
+func TestExecuteDeadlineBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		budget     bool
+		wantPrefix string
+	}{
+		{name: "phase overruns its share", budget: true, wantPrefix: "gates phase exceeded its deadline budget of "},
+		{name: "budget off", budget: false, wantPrefix: "pre-publish gates failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if args[0] == "test" && tt.budget {
+						<-ctx.Done()
+						return nil, ctx.Err()
+					}
+					if args[0] == "test" {
+						return []byte("1 failure"), context.Canceled
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			start := time.Now()
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"api_key":         "test-api-key",
+					"timeout":         "700ms",
+					"deadline_budget": tt.budget,
+					"gates":           []any{"test"},
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.HasPrefix(resp.Error, tt.wantPrefix) {
+				t.Errorf("error = %q, want prefix %q", resp.Error, tt.wantPrefix)
+			}
+
+			timings, ok := resp.Outputs["phase_budget"].([]PhaseTiming)
+			if !tt.budget {
+				if ok {
+					t.Errorf("unexpected phase_budget %+v", timings)
+				}
+				return
+			}
+			// The gates share is 3 of 8 weights, well before the hook timeout
+			if elapsed := time.Since(start); elapsed > 600*time.Millisecond {
+				t.Errorf("gates ran %s, past their share", elapsed)
+			}
+			if len(timings) != 1 || timings[0].Phase != budgetGates || !timings[0].Exceeded {
+				t.Errorf("phase_budget = %+v", timings)
+			}
+			if resp.Outputs["error_class"] != errorClassTimeout {
+				t.Errorf("error_class = %v, want %s", resp.Outputs["error_class"], errorClassTimeout)
+			}
+		})
+	}
+}
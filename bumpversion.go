@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+var (
+	// mixProjectBlockPattern matches the opening of the keyword list
+	// returned by the project function of mix.exs.
+	mixProjectBlockPattern = regexp.MustCompile(`\bdef\s+project(?:\(\))?\s*(?:,\s*do:|do)\s*\[`)
+	// mixVersionKeyPattern matches a version key, with its value when the
+	// value is a string literal.
+	mixVersionKeyPattern = regexp.MustCompile(`\bversion:\s*(?:"([^"]*)")?`)
+	// mixVersionAttrPattern matches a @version module attribute set to a
+	// string literal.
+	mixVersionAttrPattern = regexp.MustCompile(`(?m)^[ \t]*@version[ \t]+"([^"]*)"`)
+)
+
+// VersionBump reports the versions bump_version found in one mix.exs.
+type VersionBump struct {
+	File     string   `json:"file"`
+	Previous []string `json:"previous,omitempty"`
+	Changed  bool     `json:"changed"`
+}
+
+// rewriteMixVersion sets the version: field of the project and every
+// @version attribute in content to version. Values that are not string
+// literals, such as version: @version, are left for the attribute they
+// read. It returns the rewritten content and the literals it replaced.
+func rewriteMixVersion(content, version string) (string, []string) {
+	// Submatch index pairs of the literals to replace, in order
+	var spans [][2]int
+	for _, m := range mixVersionAttrPattern.FindAllStringSubmatchIndex(content, -1) {
+		spans = append(spans, [2]int{m[2], m[3]})
+	}
+	if loc := mixProjectBlockPattern.FindStringIndex(content); loc != nil {
+		start := loc[1]
+		block := content[start : start+closingBracket(content[start:])]
+		for _, m := range mixVersionKeyPattern.FindAllStringSubmatchIndex(block, -1) {
+			// Nested lists, such as releases, may set versions of their own
+			if nestingDepth(block[:m[0]]) > 0 {
+				continue
+			}
+			if m[2] >= 0 {
+				spans = append(spans, [2]int{start + m[2], start + m[3]})
+			}
+			break
+		}
+	}
+	if len(spans) == 0 {
+		return content, nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var b strings.Builder
+	var previous []string
+	last := 0
+	for _, span := range spans {
+		literal := content[span[0]:span[1]]
+		// Interpolated strings compute the version; leave them alone
+		if strings.Contains(literal, "#{") {
+			continue
+		}
+		previous = append(previous, literal)
+		b.WriteString(content[last:span[0]])
+		b.WriteString(version)
+		last = span[1]
+	}
+	b.WriteString(content[last:])
+	return b.String(), previous
+}
+
+// nestingDepth counts the brackets, braces and parentheses left open in s.
+func nestingDepth(s string) int {
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+		}
+	}
+	return depth
+}
+
+// versionBumpDirs lists the directories whose mix.exs bump_version rewrites,
+// and whether each must declare a version. Umbrella roots usually leave the
+// version to their apps; rebar3 and Gleam projects have no mix.exs.
+func versionBumpDirs(cfg *Config) ([]string, map[string]bool) {
+	cfgs := []*Config{cfg}
+	if len(cfg.Packages) > 0 {
+		cfgs = nil
+		for _, pkg := range cfg.orderedPackages() {
+			cfgs = append(cfgs, cfg.forPackage(pkg))
+		}
+	}
+
+	var dirs []string
+	required := make(map[string]bool)
+	for _, c := range cfgs {
+		projectType, _ := c.resolveProjectType()
+		switch projectType {
+		case projectTypeMix:
+			dirs = append(dirs, c.WorkDir)
+			required[c.WorkDir] = true
+		case projectTypeUmbrella:
+			dirs = append(dirs, c.WorkDir)
+			for _, app := range umbrellaApps(c.WorkDir) {
+				if dir, err := resolveAppDir(c.WorkDir, app); err == nil {
+					dirs = append(dirs, dir)
+					required[dir] = true
+				}
+			}
+		}
+	}
+	return dirs, required
+}
+
+// bumpVersion rewrites the version in mix.exs to the release version on the
+// version hooks, so the project publishes the version that was tagged.
+// Every directory and file is checked before any is written, and dry runs
+// write nothing.
+func (p *HexPlugin) bumpVersion(cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool, summary *RunSummary) *plugin.ExecuteResponse {
+	if !cfg.BumpVersion {
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "bump_version is not set; mix.exs left unchanged",
+		}
+	}
+
+	version := strings.TrimPrefix(releaseCtx.Version, "v")
+	if version == "" {
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "No release version yet; mix.exs left unchanged",
+		}
+	}
+	if err := validateHexVersion(version); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid version for Hex: %v", err),
+		}
+	}
+
+	dirs, required := versionBumpDirs(cfg)
+	if len(dirs) == 0 {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("bump_version found no %s to rewrite", mixExsFile),
+		}
+	}
+
+	var bumps []VersionBump
+	rewritten := make(map[string]string)
+	for _, dir := range dirs {
+		file := filepath.Join(dir, mixExsFile)
+		if err := validatePath(dir); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("invalid work_dir %s: %v", dir, err),
+			}
+		}
+		content, err := readMixExs(dir)
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+		updated, previous := rewriteMixVersion(content, version)
+		if len(previous) == 0 {
+			if required[dir] {
+				return &plugin.ExecuteResponse{
+					Success: false,
+					Error:   fmt.Sprintf("%s declares no version: string or @version attribute to bump", file),
+				}
+			}
+			continue
+		}
+		bump := VersionBump{File: file, Previous: previous, Changed: updated != content}
+		if bump.Changed {
+			rewritten[file] = updated
+		}
+		summary.debugf("%s: version %s -> %s", file, strings.Join(previous, ", "), version)
+		bumps = append(bumps, bump)
+	}
+
+	var changed []string
+	for _, bump := range bumps {
+		if !bump.Changed {
+			continue
+		}
+		changed = append(changed, bump.File)
+		if dryRun {
+			continue
+		}
+		mode := os.FileMode(0o644)
+		if info, err := os.Stat(bump.File); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.WriteFile(bump.File, []byte(rewritten[bump.File]), mode); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("failed to write %s: %v", bump.File, err),
+				Outputs: map[string]any{"version": version, "version_files": bumps},
+			}
+		}
+	}
+
+	var message string
+	switch {
+	case len(changed) == 0:
+		message = fmt.Sprintf("%s already at version %s", mixExsFile, version)
+	case dryRun:
+		message = fmt.Sprintf("Would bump version to %s in %s", version, strings.Join(changed, ", "))
+	default:
+		message = fmt.Sprintf("Bumped version to %s in %s", version, strings.Join(changed, ", "))
+	}
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: message,
+		Outputs: map[string]any{"version": version, "version_files": bumps},
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestRewriteMixVersion(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		want         string
+		wantPrevious []string
+	}{
+		{
+			name:         "inline project",
+			content:      `def project, do: [app: :my_lib, version: "1.0.0"]`,
+			want:         `def project, do: [app: :my_lib, version: "1.2.0"]`,
+			wantPrevious: []string{"1.0.0"},
+		},
+		{
+			name: "version attribute",
+			content: `  @version "1.0.0"
+
+  def project do
+    [app: :my_lib, version: @version, docs: [source_ref: "v#{@version}"]]
+  end`,
+			want: `  @version "1.2.0"
+
+  def project do
+    [app: :my_lib, version: @version, docs: [source_ref: "v#{@version}"]]
+  end`,
+			wantPrevious: []string{"1.0.0"},
+		},
+		{
+			name: "release versions are left alone",
+			content: `def project do
+    [
+      app: :my_app,
+      releases: [my_app: [version: "0.0.1"]],
+      version: "1.0.0"
+    ]
+  end`,
+			want: `def project do
+    [
+      app: :my_app,
+      releases: [my_app: [version: "0.0.1"]],
+      version: "1.2.0"
+    ]
+  end`,
+			wantPrevious: []string{"1.0.0"},
+		},
+		{
+			name:    "dependency versions are left alone",
+			content: "def project, do: [app: :my_lib, version: version()]\n\ndefp deps, do: [{:dep, version: \"1.0.0\"}]",
+			want:    "def project, do: [app: :my_lib, version: version()]\n\ndefp deps, do: [{:dep, version: \"1.0.0\"}]",
+		},
+		{
+			name:    "no version",
+			content: `def project, do: [apps_path: "apps"]`,
+			want:    `def project, do: [apps_path: "apps"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, previous := rewriteMixVersion(tt.content, "1.2.0")
+			if got != tt.want {
+				t.Errorf("rewriteMixVersion() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(previous, tt.wantPrevious) {
+				t.Errorf("previous = %v, want %v", previous, tt.wantPrevious)
+			}
+		})
+	}
+}
+
+func TestExecuteBumpVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		hook        plugin.Hook
+		files       map[string]string
+		config      map[string]any
+		version     string
+		dryRun      bool
+		wantSuccess bool
+		wantError   string
+		wantMessage string
+		wantFiles   map[string]string
+	}{
+		{
+			name:        "rewrites mix.exs",
+			hook:        plugin.HookPostVersion,
+			files:       map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			config:      map[string]any{"bump_version": true},
+			version:     "v1.2.0",
+			wantSuccess: true,
+			wantMessage: "Bumped version to 1.2.0 in mix.exs",
+			wantFiles:   map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.2.0"]`},
+		},
+		{
+			name:        "disabled",
+			hook:        plugin.HookPostVersion,
+			files:       map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			version:     "1.2.0",
+			wantSuccess: true,
+			wantMessage: "bump_version is not set",
+			wantFiles:   map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+		},
+		{
+			name:        "no version yet",
+			hook:        plugin.HookPreVersion,
+			files:       map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			config:      map[string]any{"bump_version": true},
+			wantSuccess: true,
+			wantMessage: "No release version yet",
+		},
+		{
+			name:        "dry run",
+			hook:        plugin.HookPreVersion,
+			files:       map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			config:      map[string]any{"bump_version": true},
+			version:     "1.2.0",
+			dryRun:      true,
+			wantSuccess: true,
+			wantMessage: "Would bump version to 1.2.0 in mix.exs",
+			wantFiles:   map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+		},
+		{
+			name:        "already bumped",
+			hook:        plugin.HookPostVersion,
+			files:       map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.2.0"]`},
+			config:      map[string]any{"bump_version": true},
+			version:     "1.2.0",
+			wantSuccess: true,
+			wantMessage: "mix.exs already at version 1.2.0",
+		},
+		{
+			name:      "invalid version",
+			hook:      plugin.HookPostVersion,
+			files:     map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			config:    map[string]any{"bump_version": true},
+			version:   "1.2",
+			wantError: "invalid version for Hex",
+			wantFiles: map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+		},
+		{
+			name:      "no literal version",
+			hook:      plugin.HookPostVersion,
+			files:     map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: File.read!("VERSION")]`},
+			config:    map[string]any{"bump_version": true},
+			version:   "1.2.0",
+			wantError: "mix.exs declares no version: string or @version attribute to bump",
+		},
+		{
+			name: "umbrella apps",
+			hook: plugin.HookPostVersion,
+			files: map[string]string{
+				"mix.exs":             `def project, do: [apps_path: "apps"]`,
+				"apps/core/mix.exs":   "@version \"1.0.0\"\ndef project, do: [app: :core, version: @version]",
+				"apps/client/mix.exs": `def project, do: [app: :client, version: "1.0.0"]`,
+			},
+			config:      map[string]any{"bump_version": true},
+			version:     "1.2.0",
+			wantSuccess: true,
+			wantFiles: map[string]string{
+				"mix.exs":             `def project, do: [apps_path: "apps"]`,
+				"apps/core/mix.exs":   "@version \"1.2.0\"\ndef project, do: [app: :core, version: @version]",
+				"apps/client/mix.exs": `def project, do: [app: :client, version: "1.2.0"]`,
+			},
+		},
+		{
+			name: "packages are checked before any is written",
+			hook: plugin.HookPostVersion,
+			files: map[string]string{
+				"core/mix.exs":   `def project, do: [app: :core, version: "1.0.0"]`,
+				"client/mix.exs": `def project, do: [app: :client]`,
+			},
+			config: map[string]any{"bump_version": true, "packages": []any{
+				map[string]any{"work_dir": "core"},
+				map[string]any{"work_dir": "client"},
+			}},
+			version:   "1.2.0",
+			wantError: "client/mix.exs declares no version",
+			wantFiles: map[string]string{"core/mix.exs": `def project, do: [app: :core, version: "1.0.0"]`},
+		},
+		{
+			name:      "work_dir outside the repository",
+			hook:      plugin.HookPostVersion,
+			files:     map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			config:    map[string]any{"bump_version": true, "work_dir": "../outside"},
+			version:   "1.2.0",
+			wantError: "invalid work_dir ../outside",
+			wantFiles: map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+		},
+		{
+			name: "gleam packages are skipped",
+			hook: plugin.HookPostVersion,
+			files: map[string]string{
+				"core/mix.exs":      `def project, do: [app: :core, version: "1.0.0"]`,
+				"client/gleam.toml": "name = \"client\"\nversion = \"1.0.0\"\n",
+			},
+			config: map[string]any{"bump_version": true, "packages": []any{
+				map[string]any{"work_dir": "core"},
+				map[string]any{"work_dir": "client"},
+			}},
+			version:     "1.2.0",
+			wantSuccess: true,
+			wantFiles: map[string]string{
+				"core/mix.exs":      `def project, do: [app: :core, version: "1.2.0"]`,
+				"client/gleam.toml": "name = \"client\"\nversion = \"1.0.0\"\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			for path, content := range tt.files {
+				writeFile(t, path, content)
+			}
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    tt.hook,
+				DryRun:  tt.dryRun,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: tt.version},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+			}
+			if tt.wantMessage != "" && !strings.Contains(resp.Message, tt.wantMessage) {
+				t.Errorf("expected message containing %q, got %q", tt.wantMessage, resp.Message)
+			}
+			for path, want := range tt.wantFiles {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != want {
+					t.Errorf("%s = %q, want %q", path, data, want)
+				}
+			}
+			if len(mock.Calls) > 0 {
+				t.Errorf("expected no commands, got %+v", mock.Calls)
+			}
+		})
+	}
+}
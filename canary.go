@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// defaultCanaryWindow is how long a canary release has to prove itself.
+const defaultCanaryWindow = 15 * time.Minute
+
+// canaryPollInterval is how often canary_confirm_file is checked.
+var canaryPollInterval = 10 * time.Second
+
+// retireReasonInvalid is the hex.retire reason recorded for a release the
+// plugin retires itself, after a failed canary or an atomic rollback.
+const retireReasonInvalid = "invalid"
+
+// maxRetireMessageLength is the longest retirement message Hex accepts.
+const maxRetireMessageLength = 140
+
+// retireMessage fits message on one line within Hex's retirement message
+// limit, eliding the end of longer messages.
+func retireMessage(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	runes := []rune(message)
+	if len(runes) <= maxRetireMessageLength {
+		return message
+	}
+	return string(runes[:maxRetireMessageLength-3]) + "..."
+}
+
+// invalidRetireArgs returns the mix arguments retiring a release as invalid.
+func invalidRetireArgs(organization, name, version, message string) []string {
+	args := []string{"hex.retire", name, version, retireReasonInvalid, "--message", retireMessage(message)}
+	if organization != "" {
+		args = append(args, "--organization", organization)
+	}
+	return args
+}
+
+// canaryRetireMessage is the retirement message of a canary that failed
+// validation.
+func canaryRetireMessage(reason string) string {
+	return "canary validation failed: " + reason
+}
+
+// validateCanaryConfig checks that canary mode targets a real registry and
+// that canary_confirm_file stays inside work_dir.
+func validateCanaryConfig(canary, testRegistry bool, confirmFile string) *fieldError {
+	if canary && testRegistry {
+		return &fieldError{Field: "canary", Err: fmt.Errorf("cannot be combined with test_registry")}
+	}
+	if confirmFile != "" {
+		if err := validatePath(confirmFile); err != nil {
+			return &fieldError{Field: "canary_confirm_file", Err: err}
+		}
+	}
+	return nil
+}
+
+// waitForConfirmation polls until canary_confirm_file exists or ctx ends.
+func waitForConfirmation(ctx context.Context, path string) error {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no confirmation at %s within the canary window", path)
+		case <-time.After(canaryPollInterval):
+		}
+	}
+}
+
+// validateCanary installs the release from the registry and waits for the
+// confirmation file, both within canary_window.
+func (p *HexPlugin) validateCanary(ctx context.Context, cfg *Config, name, version string, summary *RunSummary) error {
+	windowCtx, cancel := context.WithTimeout(ctx, cfg.CanaryWindow)
+	defer cancel()
+
+	window := *cfg
+	window.SmokeTestTimeout = cfg.CanaryWindow
+	if _, err := p.installRelease(ctx, windowCtx, &window, name, version, summary); err != nil {
+		return fmt.Errorf("smoke test: %w", err)
+	}
+	if cfg.CanaryConfirmFile != "" {
+		return waitForConfirmation(windowCtx, filepath.Join(cfg.WorkDir, cfg.CanaryConfirmFile))
+	}
+	return nil
+}
+
+// runCanary validates a freshly published release and retires it when
+// validation fails, so a bad release stops resolving for new consumers.
+func (p *HexPlugin) runCanary(ctx context.Context, cfg *Config, name, version string, resp *plugin.ExecuteResponse, summary *RunSummary) *plugin.ExecuteResponse {
+	if !resp.Success {
+		return resp
+	}
+	if name == "" {
+		resp.Success = false
+		resp.Error = fmt.Sprintf("package v%s published but the canary could not determine the package name", version)
+		return resp
+	}
+
+	err := p.validateCanary(ctx, cfg, name, version, summary)
+	if err == nil {
+		summary.debugf("canary %s %s validated", name, version)
+		resp.Outputs["canary"] = "validated"
+		return resp
+	}
+
+	summary.debugf("canary %s %s failed validation, retiring: %v", name, version, err)
+	resp.Success = false
+	resp.Outputs["canary_error"] = err.Error()
+	// The retirement must run even when cancellation caused the failure
+	message := canaryRetireMessage(err.Error())
+	if output, retireErr := p.runMix(context.WithoutCancel(ctx), cfg, summary, invalidRetireArgs(cfg.Organization, name, version, message), cfg.hexEnv()); retireErr != nil {
+		resp.Outputs["canary"] = "failed"
+		resp.Error = fmt.Sprintf("canary %s %s failed validation (%v) and could not be retired: %v\nOutput: %s", name, version, err, retireErr, string(output))
+		return resp
+	}
+	resp.Outputs["canary"] = "retired"
+	resp.Error = fmt.Sprintf("canary %s %s failed validation and was retired: %v", name, version, err)
+	return resp
+}
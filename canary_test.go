@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestInvalidRetireArgs(t *testing.T) {
+	got := strings.Join(invalidRetireArgs("acme", "my_lib", "1.0.0", "broken"), " ")
+	if got != "hex.retire my_lib 1.0.0 invalid --message broken --organization acme" {
+		t.Errorf("invalidRetireArgs() = %q", got)
+	}
+}
+
+func TestRetireMessage(t *testing.T) {
+	long := canaryRetireMessage("mix compile failed: exit status 1\nOutput: " + strings.Repeat("== Compilation error in file lib/my_lib.ex ==\n", 10))
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "short", message: "broken", want: "broken"},
+		{name: "multi-line", message: "broken:\n  exit status 1", want: "broken: exit status 1"},
+		{name: "at the limit", message: strings.Repeat("é", maxRetireMessageLength), want: strings.Repeat("é", maxRetireMessageLength)},
+		{name: "long", message: long, want: "canary validation failed: mix compile failed: exit status 1 Output: == Compilation error in file lib/my_lib.ex == == Compilation error in..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := retireMessage(tt.message)
+			if got != tt.want {
+				t.Errorf("retireMessage() = %q, want %q", got, tt.want)
+			}
+			if n := len([]rune(got)); n > maxRetireMessageLength {
+				t.Errorf("message has %d characters, more than %d", n, maxRetireMessageLength)
+			}
+		})
+	}
+}
+
+func TestValidateCanaryConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		canary       bool
+		testRegistry bool
+		confirmFile  string
+		wantField    string
+	}{
+		{name: "disabled"},
+		{name: "enabled", canary: true, confirmFile: ".canary-ok"},
+		{name: "test registry", canary: true, testRegistry: true, wantField: "canary"},
+		{name: "escaping confirm file", canary: true, confirmFile: "../ok", wantField: "canary_confirm_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCanaryConfig(tt.canary, tt.testRegistry, tt.confirmFile)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Field != tt.wantField {
+				t.Errorf("expected error on %s, got %v", tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestExecuteCanary(t *testing.T) {
+	prevSmoke, prevCanary := smokeTestPollInterval, canaryPollInterval
+	smokeTestPollInterval, canaryPollInterval = 10*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { smokeTestPollInterval, canaryPollInterval = prevSmoke, prevCanary })
+
+	tests := []struct {
+		name            string
+		mixExs          string
+		config          map[string]any
+		confirm         bool
+		compileErr      error
+		retireErr       error
+		expectedSuccess bool
+		expectedCanary  string
+		expectedError   string
+	}{
+		{
+			name:            "validated by smoke test",
+			config:          map[string]any{},
+			expectedSuccess: true,
+			expectedCanary:  "validated",
+		},
+		{
+			name:           "smoke test failure retires",
+			config:         map[string]any{},
+			compileErr:     errors.New("exit status 1"),
+			expectedCanary: "retired",
+			expectedError:  "canary my_lib 1.0.0 failed validation and was retired",
+		},
+		{
+			name:           "declared package name is retired",
+			mixExs:         `[app: :my_app, package: [name: "my_lib"]]`,
+			config:         map[string]any{},
+			compileErr:     errors.New("exit status 1"),
+			expectedCanary: "retired",
+			expectedError:  "canary my_lib 1.0.0 failed validation and was retired",
+		},
+		{
+			name:            "confirmed",
+			config:          map[string]any{"canary_confirm_file": ".canary-ok"},
+			confirm:         true,
+			expectedSuccess: true,
+			expectedCanary:  "validated",
+		},
+		{
+			name:           "confirmation missing retires",
+			config:         map[string]any{"canary_confirm_file": ".canary-ok", "canary_window": "100ms"},
+			expectedCanary: "retired",
+			expectedError:  "no confirmation",
+		},
+		{
+			name:           "retire failure",
+			config:         map[string]any{},
+			compileErr:     errors.New("exit status 1"),
+			retireErr:      errors.New("exit status 1"),
+			expectedCanary: "failed",
+			expectedError:  "could not be retired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t)
+			mixExs := tt.mixExs
+			if mixExs == "" {
+				mixExs = `[app: :my_lib]`
+			}
+			if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte(mixExs), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.confirm {
+				if err := os.WriteFile(filepath.Join(dir, ".canary-ok"), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"version":"1.0.0"}`))
+			}))
+			defer server.Close()
+
+			var retired []string
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					switch args[0] {
+					case "compile":
+						return []byte("== Compilation error"), tt.compileErr
+					case "hex.retire":
+						retired = args
+						return nil, tt.retireErr
+					}
+					return []byte("ok"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "api_url": server.URL, "canary": true}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if got := resp.Outputs["canary"]; got != tt.expectedCanary {
+				t.Errorf("expected canary %q, got %v", tt.expectedCanary, got)
+			}
+			if tt.expectedError != "" && !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, resp.Error)
+			}
+			if wantRetire := tt.expectedCanary != "validated"; wantRetire != (retired != nil) {
+				t.Errorf("expected retire=%v, got args %v", wantRetire, retired)
+			}
+			if retired != nil && retired[1] != "my_lib" {
+				t.Errorf("retired package %q, want my_lib", retired[1])
+			}
+		})
+	}
+}
+
+func TestExecuteCanaryDryRun(t *testing.T) {
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"canary": true, "canary_window": "30m"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success || resp.Outputs["canary_window"] != "30m0s" {
+		t.Errorf("unexpected dry run response: %+v", resp)
+	}
+	if len(mock.Calls) != 0 {
+		t.Errorf("expected no commands in dry run, got %d", len(mock.Calls))
+	}
+}
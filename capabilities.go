@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Capabilities negotiated with the Relicta host.
+const (
+	capabilityStreamingLogs = "streaming_logs"
+	capabilityArtifacts     = "artifacts"
+	capabilityHealthChecks  = "health_checks"
+	capabilityAPIPublish    = "api_publish"
+)
+
+// hostCapabilitiesEnv lists the features of the host, comma-separated.
+// Hosts that predate negotiation do not set it.
+const hostCapabilitiesEnv = "RELICTA_HOST_CAPABILITIES"
+
+// Capability is a feature the plugin declares in GetInfo.
+type Capability struct {
+	Name        string `json:"-"`
+	Supported   bool   `json:"supported"`
+	Description string `json:"description"`
+}
+
+// pluginCapabilities lists the features the plugin can use when the host
+// supports them.
+var pluginCapabilities = []Capability{
+	{capabilityStreamingLogs, true, "Command output is streamed line by line to stderr while commands run when stream_logs is set"},
+	{capabilityArtifacts, true, "Built package tarballs are returned as ExecuteResponse artifacts when artifacts_dir is set"},
+	{capabilityHealthChecks, true, "Validate checks the API key against the registry when key_expiry_check is set"},
+	{capabilityStructuredLogging, true, "Debug decisions are logged as JSON records on stderr instead of returned in the decisions output"},
+	{capabilityEvents, true, "Publish lifecycle events (gates, build, upload, verification) are written as JSON lines on stderr as they happen instead of returned in the events output"},
+	{capabilityAPIPublish, false, "Publishing through the Hex HTTP API without mix"},
+}
+
+// legacyHostCapabilities are the features every host of plugin protocol 1
+// has, assumed when the host declares none.
+var legacyHostCapabilities = []string{capabilityArtifacts, capabilityHealthChecks}
+
+// withCapabilities embeds the plugin capabilities into a config schema under
+// the x-capabilities extension keyword, next to x-outputs.
+func withCapabilities(configSchema string) string {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(configSchema), &schema); err != nil {
+		return configSchema
+	}
+	capabilities := make(map[string]Capability, len(pluginCapabilities))
+	for _, c := range pluginCapabilities {
+		capabilities[c.Name] = c
+	}
+	schema["x-capabilities"] = capabilities
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return configSchema
+	}
+	return string(data)
+}
+
+// hostCapabilities returns the features the host declares, or those of a
+// legacy host when it declares none.
+func hostCapabilities() map[string]bool {
+	declared := os.Getenv(hostCapabilitiesEnv)
+	names := legacyHostCapabilities
+	if strings.TrimSpace(declared) != "" {
+		names = strings.Split(declared, ",")
+	}
+	caps := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			caps[name] = true
+		}
+	}
+	return caps
+}
+
+// negotiateCapabilities turns off the requested features the host lacks,
+// so an older host gets a working run without them rather than a failure.
+// It returns why each feature was turned off or moved to outputs.
+func (c *Config) negotiateCapabilities(host map[string]bool) []string {
+	var degraded []string
+	if c.StreamLogs && !host[capabilityStreamingLogs] {
+		c.StreamLogs = false
+		degraded = append(degraded, fmt.Sprintf("%s: the host does not support it; command output is returned when each command finishes", capabilityStreamingLogs))
+	}
+	if c.ArtifactsDir != "" && !host[capabilityArtifacts] {
+		degraded = append(degraded, fmt.Sprintf("%s: the host does not support them; they are listed in the artifacts output", capabilityArtifacts))
+	}
+	return degraded
+}
+
+// logStreamKey carries the log stream to the executor.
+type logStreamKey struct{}
+
+// withLogStream returns a context carrying the stream command output is
+// copied to while commands run.
+func withLogStream(ctx context.Context, stream *logStream) context.Context {
+	return context.WithValue(ctx, logStreamKey{}, stream)
+}
+
+// logStreamFrom returns the log stream carried by ctx, if any.
+func logStreamFrom(ctx context.Context) *logStream {
+	stream, _ := ctx.Value(logStreamKey{}).(*logStream)
+	return stream
+}
+
+// logStream writes command output line by line, masked and sanitized like
+// the captured output, so the host can show it while a command runs.
+type logStream struct {
+	mu     sync.Mutex
+	w      io.Writer
+	masker *secretMasker
+	buf    []byte
+}
+
+func newLogStream(w io.Writer, masker *secretMasker) *logStream {
+	return &logStream{w: w, masker: masker}
+}
+
+func (s *logStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf = append(s.buf, p...)
+	for {
+		i := bytes.IndexByte(s.buf, '\n')
+		if i < 0 {
+			break
+		}
+		s.writeLine(s.buf[:i+1])
+		s.buf = s.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush writes a trailing partial line, at the end of a command.
+func (s *logStream) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buf) > 0 {
+		s.writeLine(append(s.buf, '\n'))
+		s.buf = nil
+	}
+}
+
+// writeLine writes one line. Stream errors never fail the command.
+func (s *logStream) writeLine(line []byte) {
+	_, _ = io.WriteString(s.w, s.masker.mask(sanitizeText(string(line))))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestCapabilitiesInInfo(t *testing.T) {
+	var schema struct {
+		Properties   map[string]any        `json:"properties"`
+		Outputs      map[string]any        `json:"x-outputs"`
+		Capabilities map[string]Capability `json:"x-capabilities"`
+	}
+	if err := json.Unmarshal([]byte((&HexPlugin{}).GetInfo().ConfigSchema), &schema); err != nil {
+		t.Fatalf("config schema is not valid JSON: %v", err)
+	}
+	if _, ok := schema.Properties["stream_logs"]; !ok {
+		t.Error("config properties missing stream_logs")
+	}
+	if schema.Outputs == nil {
+		t.Error("x-outputs missing next to x-capabilities")
+	}
+
+	tests := []struct {
+		name          string
+		wantSupported bool
+	}{
+		{name: capabilityStreamingLogs, wantSupported: true},
+		{name: capabilityArtifacts, wantSupported: true},
+		{name: capabilityHealthChecks, wantSupported: true},
+		{name: capabilityAPIPublish, wantSupported: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := schema.Capabilities[tt.name]
+			if !ok {
+				t.Fatalf("x-capabilities missing %s", tt.name)
+			}
+			if c.Supported != tt.wantSupported {
+				t.Errorf("supported = %v, want %v", c.Supported, tt.wantSupported)
+			}
+			if c.Description == "" {
+				t.Error("description is empty")
+			}
+		})
+	}
+}
+
+func TestHostCapabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		declared string
+		want     map[string]bool
+	}{
+		{
+			name:     "legacy host",
+			declared: "",
+			want:     map[string]bool{capabilityArtifacts: true, capabilityHealthChecks: true},
+		},
+		{
+			name:     "declared",
+			declared: " streaming_logs, artifacts ,,",
+			want:     map[string]bool{capabilityStreamingLogs: true, capabilityArtifacts: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(hostCapabilitiesEnv, tt.declared)
+			if got := hostCapabilities(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hostCapabilities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteDegradesOnOlderHosts(t *testing.T) {
+	tests := []struct {
+		name         string
+		declared     string
+		streamLogs   bool
+		wantDegraded bool
+	}{
+		{name: "legacy host", declared: "", streamLogs: true, wantDegraded: true},
+		{name: "host streams logs", declared: "streaming_logs,artifacts", streamLogs: true, wantDegraded: false},
+		{name: "streaming not requested", declared: "", streamLogs: false, wantDegraded: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(hostCapabilitiesEnv, tt.declared)
+			var streamed bool
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					streamed = logStreamFrom(ctx) != nil
+					return []byte("Package published"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "stream_logs": tt.streamLogs},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			degraded, _ := resp.Outputs["degraded"].([]string)
+			if got := len(degraded) > 0; got != tt.wantDegraded {
+				t.Errorf("degraded = %v, want degraded %v", degraded, tt.wantDegraded)
+			}
+			if tt.wantDegraded && !strings.HasPrefix(degraded[0], capabilityStreamingLogs+":") {
+				t.Errorf("degraded = %v, want a streaming_logs note", degraded)
+			}
+			if want := tt.streamLogs && !tt.wantDegraded; streamed != want {
+				t.Errorf("streamed = %v, want %v", streamed, want)
+			}
+		})
+	}
+}
+
+func TestLogStream(t *testing.T) {
+	var buf bytes.Buffer
+	stream := newLogStream(&buf, &secretMasker{values: []string{"secret-key"}})
+	for _, chunk := range []string{"Building ", "my_lib\nusing secret-key\x07\n", "trailing"} {
+		if _, err := stream.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := buf.String(), "Building my_lib\nusing ***\n"; got != want {
+		t.Errorf("before flush = %q, want %q", got, want)
+	}
+	stream.flush()
+	if got, want := buf.String(), "Building my_lib\nusing ***\ntrailing\n"; got != want {
+		t.Errorf("after flush = %q, want %q", got, want)
+	}
+}
+
+func TestRealCommandExecutorStreamsLogs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	var buf bytes.Buffer
+	ctx := withLogStream(context.Background(), newLogStream(&buf, &secretMasker{}))
+	output, err := (&RealCommandExecutor{}).Run(ctx, "sh", []string{"-c", "echo one; printf two"}, nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(output); got != "one\ntwo" {
+		t.Errorf("output = %q, want %q", got, "one\ntwo")
+	}
+	if got := buf.String(); got != "one\ntwo\n" {
+		t.Errorf("streamed = %q, want %q", got, "one\ntwo\n")
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// chaosFailures maps each failure chaos can inject to the mix output that
+// reproduces it, so classification and hints react as they would for real.
+var chaosFailures = map[string]string{
+	"network":        "** (Mix) Request failed (:econnrefused)",
+	"timeout":        "** (Mix) Request failed (:timeout)",
+	"server_error":   "** (Mix) Request failed (status 503) Service Unavailable",
+	"auth":           "** (Mix) Invalid API key (status 401)",
+	"version_exists": "** (Mix) Stopping package build due to errors.\nVersion already published: you must include the --replace flag to update an existing package",
+}
+
+// chaosWriteTasks are the mix tasks that change the registry. Chaos mode
+// never runs them, so a rehearsal publishes nothing real.
+var chaosWriteTasks = []string{"hex.publish", "hex.retire", "hex.owner"}
+
+// ChaosConfig injects a simulated failure into a publish phase, so rollback
+// and retire automation can be rehearsed.
+type ChaosConfig struct {
+	Phase   string `json:"phase"`
+	Failure string `json:"failure"`
+}
+
+// parseChaos decodes the chaos object.
+func parseChaos(raw map[string]any) (*ChaosConfig, *fieldError) {
+	val, ok := raw["chaos"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, &fieldError{Field: "chaos", Err: fmt.Errorf("must be an object")}
+	}
+	var chaos ChaosConfig
+	if err := decodeStrict(obj, &chaos); err != nil {
+		return nil, &fieldError{Field: "chaos", Err: err}
+	}
+	return &chaos, nil
+}
+
+// chaosPhases lists the phases chaos may fail, in the order they run.
+var chaosPhases = []string{budgetGates, budgetBuild, budgetUpload, phaseDocs, budgetVerification}
+
+// validateChaos checks the chaos phase and failure. Custom docs targets are
+// uploaded over HTTP rather than through mix, so chaos cannot keep them from
+// publishing and the two are exclusive.
+func validateChaos(raw map[string]any) []*fieldError {
+	chaos, ferr := parseChaos(raw)
+	if ferr != nil {
+		return []*fieldError{ferr}
+	}
+	if chaos == nil {
+		return nil
+	}
+
+	var errs []*fieldError
+	if !slices.Contains(chaosPhases, chaos.Phase) {
+		errs = append(errs, &fieldError{Field: "chaos.phase", Err: fmt.Errorf("must be one of %s", strings.Join(chaosPhases, ", "))})
+	}
+	if _, ok := chaosFailures[chaos.Failure]; !ok {
+		names := make([]string, 0, len(chaosFailures))
+		for name := range chaosFailures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		errs = append(errs, &fieldError{Field: "chaos.failure", Err: fmt.Errorf("must be one of %s", strings.Join(names, ", "))})
+	}
+	if dest, _ := raw["docs_destination"].(string); dest == docsDestinationCustom || dest == docsDestinationBoth {
+		errs = append(errs, &fieldError{Field: "chaos", Err: fmt.Errorf("cannot be combined with docs_destination %s, whose uploads would still publish", dest)})
+	}
+	return errs
+}
+
+// checkChaosPhase reports a chaos phase the publish never reaches, which
+// would make the rehearsal silently succeed. Unknown phases are left to
+// validateChaos.
+func (c *Config) checkChaosPhase() error {
+	if c.Chaos == nil || !slices.Contains(chaosPhases, c.Chaos.Phase) {
+		return nil
+	}
+	phases := budgetPhases(c)
+	if c.splitsDocs() {
+		phases = append(phases, phaseDocs)
+	}
+	if !slices.Contains(phases, c.Chaos.Phase) {
+		return fmt.Errorf("chaos phase %s never runs with this config: the publish runs %s", c.Chaos.Phase, strings.Join(phases, ", "))
+	}
+	return nil
+}
+
+// inject fails the named phase when chaos targets it, returning nil
+// otherwise. The failure looks like a mix error whose retries ran out.
+func (c *ChaosConfig) inject(phase string, outputs map[string]any, summary *RunSummary) *plugin.ExecuteResponse {
+	if c == nil || c.Phase != phase {
+		return nil
+	}
+	output := chaosFailures[c.Failure]
+	summary.debugf("chaos: injecting a %s failure into the %s phase", c.Failure, phase)
+
+	if outputs == nil {
+		outputs = map[string]any{}
+	}
+	outputs["chaos"] = map[string]string{"phase": c.Phase, "failure": c.Failure}
+	outputs["output"] = output
+	outputs["exit_code"] = 1
+	outputs["error_class"] = classifyError(context.Background(), []byte(output), errors.New("exit status 1"))
+	return &plugin.ExecuteResponse{
+		Success: false,
+		Error:   fmt.Sprintf("chaos: simulated %s failure in the %s phase\nOutput: %s", c.Failure, phase, output),
+		Outputs: outputs,
+	}
+}
+
+// simulates reports whether chaos replaces the mix task in args, which is
+// every registry write, including the configured publish task, except a
+// --dry-run publish.
+func (c *ChaosConfig) simulates(publishTask string, args []string) bool {
+	if c == nil || len(args) == 0 || slices.Contains(args, "--dry-run") {
+		return false
+	}
+	return args[0] == publishTask || slices.Contains(chaosWriteTasks, args[0])
+}
+
+// simulateChaosWrite stands in for a registry write skipped by chaos mode.
+func simulateChaosWrite(cfg *Config, summary *RunSummary, args []string) []byte {
+	summary.debugf("chaos: not running %s", cfg.mixDisplay(args...))
+	return []byte(fmt.Sprintf("chaos: simulated %s, nothing was sent to the registry", cfg.mixDisplay(args...)))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateChaos(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        map[string]any
+		wantFields []string
+	}{
+		{name: "unset", raw: map[string]any{}},
+		{name: "valid", raw: map[string]any{"chaos": map[string]any{"phase": "upload", "failure": "network"}}},
+		{name: "not an object", raw: map[string]any{"chaos": "upload"}, wantFields: []string{"chaos"}},
+		{name: "unknown field", raw: map[string]any{"chaos": map[string]any{"phase": "upload", "failure": "network", "rate": 1}}, wantFields: []string{"chaos"}},
+		{name: "unknown phase and failure", raw: map[string]any{"chaos": map[string]any{"phase": "tag", "failure": "disk_full"}}, wantFields: []string{"chaos.phase", "chaos.failure"}},
+		{
+			name:       "custom docs",
+			raw:        map[string]any{"docs_destination": "both", "chaos": map[string]any{"phase": "upload", "failure": "auth"}},
+			wantFields: []string{"chaos"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range validateChaos(tt.raw) {
+				fields = append(fields, err.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestChaosSimulates(t *testing.T) {
+	tests := []struct {
+		name  string
+		chaos *ChaosConfig
+		task  string
+		args  []string
+		want  bool
+	}{
+		{name: "off", args: []string{"hex.publish", "--yes"}},
+		{name: "publish", chaos: &ChaosConfig{}, args: []string{"hex.publish", "--yes"}, want: true},
+		{name: "docs", chaos: &ChaosConfig{}, args: []string{"hex.publish", "docs", "--yes"}, want: true},
+		{name: "retire", chaos: &ChaosConfig{}, args: []string{"hex.retire", "my_lib", "1.0.0", "security"}, want: true},
+		{name: "dry run publish", chaos: &ChaosConfig{}, args: []string{"hex.publish", "--dry-run"}},
+		{name: "build", chaos: &ChaosConfig{}, args: []string{"hex.build"}},
+		{name: "other task", chaos: &ChaosConfig{}, args: []string{"release", "--yes"}},
+		{name: "publish alias", chaos: &ChaosConfig{}, task: "release", args: []string{"release", "--yes"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := tt.task
+			if task == "" {
+				task = defaultPublishTask
+			}
+			if got := tt.chaos.simulates(task, tt.args); got != tt.want {
+				t.Errorf("simulates(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteChaos(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]any
+		wantError string
+		wantClass string
+		wantHint  bool
+		wantCalls []string
+	}{
+		{
+			name:      "version exists at upload",
+			config:    map[string]any{"chaos": map[string]any{"phase": "upload", "failure": "version_exists"}},
+			wantError: "chaos: simulated version_exists failure in the upload phase",
+			wantClass: errorClassValidation,
+			wantHint:  true,
+		},
+		{
+			name:      "network at gates",
+			config:    map[string]any{"gates": []any{"test"}, "chaos": map[string]any{"phase": "gates", "failure": "network"}},
+			wantError: "chaos: simulated network failure in the gates phase",
+			wantClass: errorClassNetwork,
+		},
+		{
+			name:      "docs after a simulated package publish",
+			config:    map[string]any{"split_phases": true, "chaos": map[string]any{"phase": "docs", "failure": "server_error"}},
+			wantError: "chaos: simulated server_error failure in the docs phase",
+			wantClass: errorClass5xx,
+		},
+		{
+			name:      "verification runs real tasks",
+			config:    map[string]any{"post_publish_tasks": []any{"my_app.announce"}, "chaos": map[string]any{"phase": "verification", "failure": "timeout"}},
+			wantError: "chaos: simulated timeout failure in the verification phase",
+			wantClass: errorClassTimeout,
+		},
+		{
+			name:      "phase never reached",
+			config:    map[string]any{"chaos": map[string]any{"phase": "docs", "failure": "network"}},
+			wantError: "invalid chaos.phase: chaos phase docs never runs with this config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.HasPrefix(resp.Error, tt.wantError) {
+				t.Errorf("error = %q, want prefix %q", resp.Error, tt.wantError)
+			}
+			for _, c := range mock.Calls {
+				if c.Args[0] == "hex.publish" {
+					t.Errorf("chaos ran a real publish: %v", c.Args)
+				}
+			}
+			if tt.wantClass == "" {
+				return
+			}
+			if resp.Outputs["error_class"] != tt.wantClass {
+				t.Errorf("error_class = %v, want %s", resp.Outputs["error_class"], tt.wantClass)
+			}
+			if (resp.Outputs["hint"] != nil) != tt.wantHint {
+				t.Errorf("hint = %v, want present %v", resp.Outputs["hint"], tt.wantHint)
+			}
+			if resp.Outputs["chaos"] == nil {
+				t.Error("expected the chaos output")
+			}
+		})
+	}
+}
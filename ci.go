@@ -0,0 +1,70 @@
+package main
+
+import "strings"
+
+// CI providers detected from the environment.
+const (
+	ciGitHubActions = "github_actions"
+	ciGitLab        = "gitlab_ci"
+	ciBuildkite     = "buildkite"
+)
+
+// CIInfo identifies the CI run that executed the plugin, tying a Hex release
+// to the build that produced it.
+type CIInfo struct {
+	Provider string `json:"provider"`
+	BuildURL string `json:"build_url,omitempty"`
+	Runner   string `json:"runner,omitempty"`
+	Workflow string `json:"workflow,omitempty"`
+	RunID    string `json:"run_id,omitempty"`
+}
+
+// detectCI reads the CI provider metadata from the environment. It returns
+// nil outside GitHub Actions, GitLab CI and Buildkite.
+func detectCI(getenv func(string) string) *CIInfo {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		info := &CIInfo{
+			Provider: ciGitHubActions,
+			Runner:   getenv("RUNNER_NAME"),
+			Workflow: getenv("GITHUB_WORKFLOW"),
+			RunID:    getenv("GITHUB_RUN_ID"),
+		}
+		server, repo := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY")
+		if server != "" && repo != "" && info.RunID != "" {
+			info.BuildURL = strings.TrimSuffix(server, "/") + "/" + repo + "/actions/runs/" + info.RunID
+			if attempt := getenv("GITHUB_RUN_ATTEMPT"); attempt != "" {
+				info.BuildURL += "/attempts/" + attempt
+			}
+		}
+		return info
+	case getenv("GITLAB_CI") == "true":
+		info := &CIInfo{
+			Provider: ciGitLab,
+			BuildURL: getenv("CI_JOB_URL"),
+			Runner:   getenv("CI_RUNNER_DESCRIPTION"),
+			Workflow: getenv("CI_PIPELINE_NAME"),
+			RunID:    getenv("CI_PIPELINE_ID"),
+		}
+		if info.BuildURL == "" {
+			info.BuildURL = getenv("CI_PIPELINE_URL")
+		}
+		if info.Workflow == "" {
+			info.Workflow = getenv("CI_JOB_NAME")
+		}
+		return info
+	case getenv("BUILDKITE") == "true":
+		info := &CIInfo{
+			Provider: ciBuildkite,
+			BuildURL: getenv("BUILDKITE_BUILD_URL"),
+			Runner:   getenv("BUILDKITE_AGENT_NAME"),
+			Workflow: getenv("BUILDKITE_PIPELINE_SLUG"),
+			RunID:    getenv("BUILDKITE_BUILD_NUMBER"),
+		}
+		if job := getenv("BUILDKITE_JOB_ID"); info.BuildURL != "" && job != "" {
+			info.BuildURL += "#" + job
+		}
+		return info
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *CIInfo
+	}{
+		{
+			name: "no ci",
+			env:  map[string]string{"CI": "true"},
+		},
+		{
+			name: "github actions",
+			env: map[string]string{
+				"GITHUB_ACTIONS":     "true",
+				"GITHUB_SERVER_URL":  "https://github.com",
+				"GITHUB_REPOSITORY":  "acme/my_lib",
+				"GITHUB_RUN_ID":      "42",
+				"GITHUB_RUN_ATTEMPT": "2",
+				"GITHUB_WORKFLOW":    "release",
+				"RUNNER_NAME":        "runner-1",
+			},
+			want: &CIInfo{
+				Provider: ciGitHubActions,
+				BuildURL: "https://github.com/acme/my_lib/actions/runs/42/attempts/2",
+				Runner:   "runner-1",
+				Workflow: "release",
+				RunID:    "42",
+			},
+		},
+		{
+			name: "gitlab ci",
+			env: map[string]string{
+				"GITLAB_CI":             "true",
+				"CI_JOB_URL":            "https://gitlab.com/acme/my_lib/-/jobs/7",
+				"CI_RUNNER_DESCRIPTION": "shared-runner",
+				"CI_JOB_NAME":           "publish",
+				"CI_PIPELINE_ID":        "99",
+			},
+			want: &CIInfo{
+				Provider: ciGitLab,
+				BuildURL: "https://gitlab.com/acme/my_lib/-/jobs/7",
+				Runner:   "shared-runner",
+				Workflow: "publish",
+				RunID:    "99",
+			},
+		},
+		{
+			name: "buildkite",
+			env: map[string]string{
+				"BUILDKITE":               "true",
+				"BUILDKITE_BUILD_URL":     "https://buildkite.com/acme/my-lib/builds/5",
+				"BUILDKITE_JOB_ID":        "abc",
+				"BUILDKITE_AGENT_NAME":    "agent-3",
+				"BUILDKITE_PIPELINE_SLUG": "my-lib",
+				"BUILDKITE_BUILD_NUMBER":  "5",
+			},
+			want: &CIInfo{
+				Provider: ciBuildkite,
+				BuildURL: "https://buildkite.com/acme/my-lib/builds/5#abc",
+				Runner:   "agent-3",
+				Workflow: "my-lib",
+				RunID:    "5",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectCI(func(key string) string { return tt.env[key] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectCI() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteCIMetadata(t *testing.T) {
+	chdirTemp(t)
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "acme/my_lib")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_RUN_ATTEMPT", "")
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("Building my_lib 1.0.0"), nil
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	summaryPath := "summary.json"
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "summary_path": summaryPath},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("unexpected failure: %s", resp.Error)
+	}
+
+	const buildURL = "https://github.com/acme/my_lib/actions/runs/42"
+	announcement := resp.Outputs["announcement"].(*Announcement)
+	if announcement.CI == nil || announcement.CI.BuildURL != buildURL {
+		t.Errorf("announcement ci = %+v", announcement.CI)
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary RunSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.CI == nil || summary.CI.Provider != ciGitHubActions || summary.CI.BuildURL != buildURL {
+		t.Errorf("summary ci = %+v", summary.CI)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Error classes assigned to failed commands.
+const (
+	errorClassAuth       = "auth"
+	errorClassValidation = "validation"
+	errorClassTimeout    = "timeout"
+	errorClass5xx        = "5xx"
+	errorClassNetwork    = "network"
+	errorClassIncident   = "registry_incident"
+	errorClassUnknown    = "unknown"
+)
+
+// retryableClasses are the error classes that retry_on may select. Auth and
+// validation failures fail identically on every attempt and are never retried.
+var retryableClasses = []string{errorClassNetwork, errorClass5xx, errorClassTimeout, errorClassIncident}
+
+// errorClassMarkers maps each class to lowercase substrings of mix output or
+// errors that identify it, checked in order.
+var errorClassMarkers = []struct {
+	class   string
+	markers []string
+}{
+	{errorClassIncident, []string{"registry incident"}},
+	{errorClassAuth, []string{"invalid api key", "unauthorized", "forbidden", "missing write permission", "authentication failed"}},
+	{errorClassValidation, []string{"validation failed", "unprocessable entity", "must include the --replace flag"}},
+	{errorClassTimeout, []string{"timeout", "timed out", "deadline exceeded"}},
+	{errorClass5xx, []string{"internal server error", "bad gateway", "service unavailable"}},
+	{errorClassNetwork, []string{"econnrefused", "econnreset", "nxdomain", "ehostunreach", "connection refused", "connection reset", "no such host", "failed to connect", "network is unreachable", ":closed"}},
+}
+
+// httpStatusPattern matches HTTP status codes reported by Hex, e.g. "(status 503)" or "HTTP 401".
+var httpStatusPattern = regexp.MustCompile(`(?:status|http|code)[:\s]*([45]\d\d)\b`)
+
+// classifyError assigns an error class to a failed command from its error
+// and captured output.
+func classifyError(ctx context.Context, output []byte, err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return errorClassTimeout
+	}
+
+	text := strings.ToLower(err.Error() + "\n" + string(output))
+
+	if m := httpStatusPattern.FindStringSubmatch(text); m != nil {
+		switch {
+		case m[1] == "401" || m[1] == "403":
+			return errorClassAuth
+		case m[1] == "422":
+			return errorClassValidation
+		case m[1][0] == '5':
+			return errorClass5xx
+		}
+	}
+
+	for _, c := range errorClassMarkers {
+		for _, marker := range c.markers {
+			if strings.Contains(text, marker) {
+				return c.class
+			}
+		}
+	}
+	return errorClassUnknown
+}
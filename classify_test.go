@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	exit := errors.New("exit status 1")
+
+	tests := []struct {
+		name     string
+		output   string
+		err      error
+		expected string
+	}{
+		{name: "success", err: nil, expected: ""},
+		{name: "invalid key", output: "** (Mix) Invalid API key", err: exit, expected: errorClassAuth},
+		{name: "auth status", output: "Publishing failed (status 401)", err: exit, expected: errorClassAuth},
+		{name: "replace required", output: "must include the --replace flag to update an existing package", err: exit, expected: errorClassValidation},
+		{name: "validation status", output: "Publishing failed (status 422)\nversion: invalid", err: exit, expected: errorClassValidation},
+		{name: "server error", output: "Publishing failed (status 503)", err: exit, expected: errorClass5xx},
+		{name: "bad gateway", output: "502 Bad Gateway", err: exit, expected: errorClass5xx},
+		{name: "registry incident", err: errors.New("registry incident: hex api returned 503; nothing was published"), expected: errorClassIncident},
+		{name: "timeout", output: "Request timed out", err: exit, expected: errorClassTimeout},
+		{name: "connection refused", output: "Failed to connect: {:failed_connect, [{:to_address, {'hex.pm', 443}}, {:inet, [:inet], :econnrefused}]}", err: exit, expected: errorClassNetwork},
+		{name: "unknown", output: "something else", err: exit, expected: errorClassUnknown},
+		{name: "context deadline", err: context.DeadlineExceeded, expected: errorClassTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(context.Background(), []byte(tt.output), tt.err); got != tt.expected {
+				t.Errorf("got %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// capabilityStructuredLogging lets the plugin log JSON records to stderr,
+// which hosts that declare it parse and attach to the release log.
+const capabilityStructuredLogging = "structured_logging"
+
+// Modes of the compatibility matrix: a feature either uses the host's own
+// API or falls back to its equivalent in the response outputs.
+const (
+	compatNative  = "native"
+	compatOutputs = "outputs"
+)
+
+// compatMatrix records how each host-dependent feature is delivered during a
+// run, so fleets mixing host versions can tell why responses differ.
+type compatMatrix struct {
+	// Artifacts are returned as ExecuteResponse artifacts, or only listed in
+	// the artifacts output.
+	Artifacts string `json:"artifacts"`
+	// Logging emits decisions as structured log records, or returns them in
+	// the decisions output.
+	Logging string `json:"logging"`
+	// StreamingLogs streams command output while commands run, or returns it
+	// in outputs when each command finishes.
+	StreamingLogs string `json:"streaming_logs"`
+	// Events writes lifecycle events to stderr as they happen, or returns
+	// them in the events output.
+	Events string `json:"events"`
+}
+
+// newCompatMatrix picks the mode of each feature from the host capabilities.
+// cfg has already been negotiated, so StreamLogs holds only when the host
+// streams logs.
+func newCompatMatrix(host map[string]bool, cfg *Config) compatMatrix {
+	mode := func(native bool) string {
+		if native {
+			return compatNative
+		}
+		return compatOutputs
+	}
+	return compatMatrix{
+		Artifacts:     mode(host[capabilityArtifacts]),
+		Logging:       mode(host[capabilityStructuredLogging]),
+		StreamingLogs: mode(cfg.StreamLogs),
+		Events:        mode(host[capabilityEvents]),
+	}
+}
+
+// structuredLogger returns the logger decisions are emitted to when logging
+// is native, or nil when decisions are returned in outputs.
+func (m compatMatrix) structuredLogger(w io.Writer) *slog.Logger {
+	if m.Logging != compatNative {
+		return nil
+	}
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})).With("plugin", "hex")
+}
+
+// apply moves the features the host lacks into their outputs equivalents
+// and reports the matrix in the compat_modes output. Artifacts are already
+// listed in the artifacts output, which older hosts pass through, and
+// decisions logged or events emitted natively are not repeated in outputs.
+func (m compatMatrix) apply(resp *plugin.ExecuteResponse) {
+	if m.Artifacts == compatOutputs {
+		resp.Artifacts = nil
+	}
+	if m.Logging == compatNative {
+		delete(resp.Outputs, "decisions")
+	}
+	if m.Events == compatNative {
+		delete(resp.Outputs, "events")
+	}
+	resp.Outputs["compat_modes"] = m
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestNewCompatMatrix(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       map[string]bool
+		streamLogs bool
+		want       compatMatrix
+	}{
+		{
+			name: "legacy host",
+			host: map[string]bool{capabilityArtifacts: true, capabilityHealthChecks: true},
+			want: compatMatrix{Artifacts: compatNative, Logging: compatOutputs, StreamingLogs: compatOutputs, Events: compatOutputs},
+		},
+		{
+			name:       "current host",
+			host:       map[string]bool{capabilityArtifacts: true, capabilityStructuredLogging: true, capabilityStreamingLogs: true, capabilityEvents: true},
+			streamLogs: true,
+			want:       compatMatrix{Artifacts: compatNative, Logging: compatNative, StreamingLogs: compatNative, Events: compatNative},
+		},
+		{
+			name: "host without artifacts",
+			host: map[string]bool{capabilityStructuredLogging: true},
+			want: compatMatrix{Artifacts: compatOutputs, Logging: compatNative, StreamingLogs: compatOutputs, Events: compatOutputs},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newCompatMatrix(tt.host, &Config{StreamLogs: tt.streamLogs}); got != tt.want {
+				t.Errorf("newCompatMatrix() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompatMatrixApply(t *testing.T) {
+	tests := []struct {
+		name          string
+		matrix        compatMatrix
+		wantArtifacts int
+		wantDecisions bool
+	}{
+		{
+			name:          "native",
+			matrix:        compatMatrix{Artifacts: compatNative, Logging: compatNative, StreamingLogs: compatNative},
+			wantArtifacts: 1,
+			wantDecisions: false,
+		},
+		{
+			name:          "outputs",
+			matrix:        compatMatrix{Artifacts: compatOutputs, Logging: compatOutputs, StreamingLogs: compatOutputs},
+			wantArtifacts: 0,
+			wantDecisions: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &plugin.ExecuteResponse{
+				Success:   true,
+				Artifacts: []plugin.Artifact{{Name: "my_lib-1.0.0.tar", Path: "dist/my_lib-1.0.0.tar"}},
+				Outputs: map[string]any{
+					"artifacts": []string{"dist/my_lib-1.0.0.tar"},
+					"decisions": []string{"ran 1 gate"},
+				},
+			}
+			tt.matrix.apply(resp)
+			if len(resp.Artifacts) != tt.wantArtifacts {
+				t.Errorf("artifacts = %v, want %d", resp.Artifacts, tt.wantArtifacts)
+			}
+			if _, ok := resp.Outputs["artifacts"]; !ok {
+				t.Error("artifacts output removed")
+			}
+			if _, ok := resp.Outputs["decisions"]; ok != tt.wantDecisions {
+				t.Errorf("decisions output present = %v, want %v", ok, tt.wantDecisions)
+			}
+			if resp.Outputs["compat_modes"] != tt.matrix {
+				t.Errorf("compat_modes = %v, want %+v", resp.Outputs["compat_modes"], tt.matrix)
+			}
+		})
+	}
+}
+
+func TestStructuredLogging(t *testing.T) {
+	var buf bytes.Buffer
+	summary := &RunSummary{
+		verbosity: verbosityDebug,
+		masker:    &secretMasker{values: []string{"test-api-key"}},
+		logger:    compatMatrix{Logging: compatNative}.structuredLogger(&buf),
+	}
+	summary.debugf("using key %s", "test-api-key")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("log record is not JSON: %v (%q)", err, buf.String())
+	}
+	if record["level"] != "DEBUG" || record["plugin"] != "hex" || record["msg"] != "using key ***" {
+		t.Errorf("record = %v", record)
+	}
+	if len(summary.Decisions) != 1 {
+		t.Errorf("decisions = %v, want the decision kept for the summary", summary.Decisions)
+	}
+
+	if logger := (compatMatrix{Logging: compatOutputs}).structuredLogger(&buf); logger != nil {
+		t.Error("expected no logger when logging falls back to outputs")
+	}
+}
+
+func TestExecuteReportsCompatModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		declared      string
+		wantLogging   string
+		wantDecisions bool
+	}{
+		{name: "legacy host", declared: "", wantLogging: compatOutputs, wantDecisions: true},
+		{name: "structured logging", declared: "artifacts,structured_logging", wantLogging: compatNative, wantDecisions: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(hostCapabilitiesEnv, tt.declared)
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte("Package published"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "verbosity": "debug"},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+				DryRun:  true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			modes, ok := resp.Outputs["compat_modes"].(compatMatrix)
+			if !ok {
+				t.Fatalf("compat_modes = %#v", resp.Outputs["compat_modes"])
+			}
+			if modes.Logging != tt.wantLogging {
+				t.Errorf("logging = %s, want %s", modes.Logging, tt.wantLogging)
+			}
+			if _, ok := resp.Outputs["decisions"]; ok != tt.wantDecisions {
+				t.Errorf("decisions output present = %v, want %v", ok, tt.wantDecisions)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationOptions lists every config key holding a duration. Each accepts a
+// Go duration string ("90s", "5m") or a number of seconds.
+var durationOptions = []string{
+	"timeout",
+	"retry_delay",
+	"registry_incident_max_wait",
+	"shutdown_grace",
+	"smoke_test_timeout",
+	"canary_window",
+	"key_expiry_horizon",
+	"key_max_age",
+	"package_timeout",
+	"docs_timeout",
+}
+
+// parseDurationValue converts a raw config value into a duration.
+func parseDurationValue(val any) (time.Duration, error) {
+	switch v := val.(type) {
+	case nil:
+		return 0, nil
+	case time.Duration:
+		return v, nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, fmt.Errorf("must be a finite number of seconds")
+		}
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, nil
+		}
+		if secs, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.Duration(secs * float64(time.Second)), nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use a Go duration such as \"90s\" or \"5m\"", v)
+		}
+		return d, nil
+	default:
+		return 0, fmt.Errorf("must be a duration string or a number of seconds, got %T", val)
+	}
+}
+
+// DocsTarget is a single self-hosted docs destination.
+type DocsTarget struct {
+	URL      string `json:"url"`
+	TokenEnv string `json:"token_env,omitempty"`
+}
+
+// GateConfig configures a single pre-publish gate.
+type GateConfig struct {
+	Name string `json:"name"`
+	// Args overrides the mix arguments of a built-in gate, or defines a custom gate.
+	Args []string `json:"args,omitempty"`
+	// AllowFailure records the gate result without blocking the publish.
+	AllowFailure bool `json:"allow_failure,omitempty"`
+}
+
+// PackageConfig overrides top-level settings for one package of a multi-package release.
+type PackageConfig struct {
+	Name         string `json:"name,omitempty"`
+	WorkDir      string `json:"work_dir"`
+	Organization string `json:"organization,omitempty"`
+	Replace      *bool  `json:"replace,omitempty"`
+	APIKey       string `json:"api_key,omitempty"`
+	// APIKeyEnv and APIKeyFile read the package's key like org_keys does,
+	// for packages owned by another Hex account or organization.
+	APIKeyEnv  string `json:"api_key_env,omitempty"`
+	APIKeyFile string `json:"api_key_file,omitempty"`
+	// DependsOn names packages, by label, that must publish first.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// ProjectType selects the tool publishing the package, overriding the
+	// top-level project_type in repos mixing Mix, rebar3 and Gleam packages.
+	ProjectType string `json:"project_type,omitempty"`
+	// PackageName is the name the package must declare, like package_name.
+	PackageName string `json:"package_name,omitempty"`
+}
+
+// decodeObjectList decodes a list option whose entries are objects, or
+// strings when fromString is provided. Unknown object fields are rejected so
+// typos surface as validation errors instead of being silently ignored.
+func decodeObjectList[T any](raw map[string]any, key string, fromString func(string) T) ([]T, *fieldError) {
+	val, ok := raw[key]
+	if !ok || val == nil {
+		return nil, nil
+	}
+
+	var items []any
+	switch v := val.(type) {
+	case []any:
+		items = v
+	case []string:
+		for _, s := range v {
+			items = append(items, s)
+		}
+	case []map[string]any:
+		for _, m := range v {
+			items = append(items, m)
+		}
+	default:
+		return nil, &fieldError{Field: key, Err: fmt.Errorf("must be a list")}
+	}
+
+	result := make([]T, 0, len(items))
+	for i, item := range items {
+		field := fmt.Sprintf("%s[%d]", key, i)
+
+		switch v := item.(type) {
+		case string:
+			if fromString == nil {
+				return nil, &fieldError{Field: field, Err: fmt.Errorf("must be an object")}
+			}
+			result = append(result, fromString(v))
+		case map[string]any:
+			var decoded T
+			if err := decodeStrict(v, &decoded); err != nil {
+				return nil, &fieldError{Field: field, Err: err}
+			}
+			result = append(result, decoded)
+		default:
+			return nil, &fieldError{Field: field, Err: fmt.Errorf("must be a string or an object")}
+		}
+	}
+
+	return result, nil
+}
+
+// decodeStrict decodes a config object into v, rejecting unknown fields.
+func decodeStrict(obj map[string]any, v any) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid object: %v", strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+// parseDocsTargets decodes docs_targets, accepting plain URLs or objects.
+func parseDocsTargets(raw map[string]any) ([]DocsTarget, *fieldError) {
+	return decodeObjectList(raw, "docs_targets", func(s string) DocsTarget { return DocsTarget{URL: s} })
+}
+
+// parseGates decodes gates, accepting gate names or objects.
+func parseGates(raw map[string]any) ([]GateConfig, *fieldError) {
+	return decodeObjectList(raw, "gates", func(s string) GateConfig { return GateConfig{Name: s} })
+}
+
+// parsePackages decodes the packages list of a multi-package release.
+func parsePackages(raw map[string]any) ([]PackageConfig, *fieldError) {
+	return decodeObjectList[PackageConfig](raw, "packages", nil)
+}
+
+// validateNestedConfig decodes every nested option and reports the first error of each.
+func validateNestedConfig(raw map[string]any) []*fieldError {
+	var errs []*fieldError
+	if _, err := parseDocsTargets(raw); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := parseGates(raw); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, validateVerifyMatrix(raw)...)
+	errs = append(errs, validatePublishWindows(raw)...)
+	packages, err := parsePackages(raw)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for i, pkg := range packages {
+		field := fmt.Sprintf("packages[%d]", i)
+		if pkg.WorkDir == "" {
+			errs = append(errs, &fieldError{Field: field + ".work_dir", Err: fmt.Errorf("is required")})
+		} else if err := validatePath(pkg.WorkDir); err != nil {
+			errs = append(errs, &fieldError{Field: field + ".work_dir", Err: err})
+		}
+		if err := validateOrganization(pkg.Organization); err != nil {
+			errs = append(errs, &fieldError{Field: field + ".organization", Err: err})
+		}
+		if err := validatePackageKey(pkg); err != nil {
+			errs = append(errs, &fieldError{Field: field + err.Field, Err: err.Err})
+		}
+		if err := validateProjectType(pkg.ProjectType); err != nil {
+			errs = append(errs, &fieldError{Field: field + "." + err.Field, Err: err.Err})
+		}
+		if err := validatePackageName(pkg.PackageName); err != nil {
+			errs = append(errs, &fieldError{Field: field + "." + err.Field, Err: err.Err})
+		}
+	}
+	if _, err := orderPackages(packages); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// getDuration returns the duration stored under key, or defaultVal when the
+// key is missing or invalid. Invalid values are reported by validateDurations.
+func getDuration(raw map[string]any, key string, defaultVal time.Duration) time.Duration {
+	val, ok := raw[key]
+	if !ok {
+		return defaultVal
+	}
+	d, err := parseDurationValue(val)
+	if err != nil || d == 0 {
+		return defaultVal
+	}
+	return d
+}
+
+// fieldError is a validation error naming the offending config field.
+type fieldError struct {
+	Field string
+	Err   error
+}
+
+// Error implements error.
+func (e *fieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// validateRawConfig runs the structural checks that typed parsing cannot
+// report: duration syntax, nested objects, profiles, org_keys, hex_config,
+// chaos, dependency_policy, output patterns and key rotation.
+func validateRawConfig(raw map[string]any) []*fieldError {
+	errs := append(validateDurations(raw), validateNestedConfig(raw)...)
+	errs = append(errs, validateProfiles(raw)...)
+	errs = append(errs, validateOrgKeys(raw)...)
+	errs = append(errs, validateHexConfig(raw)...)
+	errs = append(errs, validateChaos(raw)...)
+	errs = append(errs, validateDependencyPolicy(raw)...)
+	errs = append(errs, validateOutputPatterns(raw)...)
+	errs = append(errs, validateOutputCriteria(raw)...)
+	return append(errs, validateRotation(raw)...)
+}
+
+// validate runs the checks of the typed config shared by Validate and the
+// publish hook, so an option rejected by one is rejected by the other.
+func (c *Config) validate() []*fieldError {
+	var errs []*fieldError
+	check := func(field string, err error) {
+		if err != nil {
+			errs = append(errs, &fieldError{Field: field, Err: err})
+		}
+	}
+	checkField := func(err *fieldError) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	checkPath := func(field, path string) {
+		if path != "" {
+			check(field, validatePath(path))
+		}
+	}
+
+	check("work_dir", validatePath(c.WorkDir))
+	check("organization", validateOrganization(c.Organization))
+	// A package entry may supply the organization of a provisioned key
+	if c.ProvisionOrgKey && c.Organization == "" && len(c.Packages) == 0 {
+		check("provision_org_key", fmt.Errorf("requires organization"))
+	}
+	check("chaos.phase", c.checkChaosPhase())
+	check("secret_source_policy", validateSecretPolicy(c.SecretSourcePolicy))
+	check("replace_policy", validateReplacePolicy(c.ReplacePolicy))
+	check("dirty_worktree", validateDirtyWorktree(c.DirtyWorktree))
+	check("git_state", validateGitState(c.GitState))
+	check("publish_window_action", validateWindowAction(c.PublishWindowAction))
+	check("verbosity", validateVerbosity(c.Verbosity))
+	check("docs_destination", validateDocsConfig(c.DocsDestination, c.DocsTargets))
+	check("gates", validateGates(c.Gates, c.AllowedLicenses))
+	checkField(validateToolchain(c.ElixirPath, c.PathPrepend))
+	checkField(validateHomeDirs(c.MixHome, c.HexHome))
+	check("retry_on", validateRetryOn(c.RetryOn))
+	checkField(validateSecretScanAllow(c.SecretScanAllow))
+	checkField(validatePublishTask(c.Task))
+	checkField(validateTasks("pre_publish_tasks", c.PrePublishTasks))
+	checkField(validateTasks("post_publish_tasks", c.PostPublishTasks))
+	checkPath("test_registry_dir", c.TestRegistryDir)
+	checkField(validateCanaryConfig(c.Canary, c.TestRegistry, c.CanaryConfirmFile))
+	checkField(validateRehearse(c.Rehearse, c.TestRegistry))
+	checkField(validateFreezeConfig(c.FreezeFile, c.FreezeURL, c.FreezeAction))
+	checkPath("junit_path", c.JUnitPath)
+	checkPath("artifacts_dir", c.ArtifactsDir)
+	checkField(validateProjectType(c.ProjectType))
+	checkField(validateAttemptBudget(c.MaxAttemptsPerVersion, c.StateFile))
+	checkField(validatePackageName(c.PackageName))
+	checkField(validateRegistryHealthConfig(c.RegistryStatusURL, c.RegistryIncidentAction))
+	return errs
+}
+
+// validateDurations checks every duration option present in the config.
+func validateDurations(raw map[string]any) []*fieldError {
+	var errs []*fieldError
+	for _, key := range durationOptions {
+		val, ok := raw[key]
+		if !ok {
+			continue
+		}
+		d, err := parseDurationValue(val)
+		if err == nil && d < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+		if err != nil {
+			errs = append(errs, &fieldError{Field: key, Err: err})
+		}
+	}
+	return errs
+}
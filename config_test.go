@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseDurationValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       any
+		expected    time.Duration
+		expectError bool
+	}{
+		{name: "go duration string", value: "90s", expected: 90 * time.Second},
+		{name: "minutes", value: "5m", expected: 5 * time.Minute},
+		{name: "compound duration", value: "1h30m", expected: 90 * time.Minute},
+		{name: "numeric string is seconds", value: "30", expected: 30 * time.Second},
+		{name: "float seconds", value: float64(1.5), expected: 1500 * time.Millisecond},
+		{name: "int seconds", value: 10, expected: 10 * time.Second},
+		{name: "empty string", value: "", expected: 0},
+		{name: "nil", value: nil, expected: 0},
+		{name: "garbage string", value: "soon", expectError: true},
+		{name: "unsupported type", value: true, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDurationValue(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("got %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateDurations(t *testing.T) {
+	errs := validateDurations(map[string]any{"timeout": "5 minutes"})
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errs))
+	}
+	if errs[0].Field != "timeout" || !strings.HasPrefix(errs[0].Error(), "timeout: ") {
+		t.Errorf("error should name the field, got %q", errs[0].Error())
+	}
+
+	if errs := validateDurations(map[string]any{"timeout": "-1s"}); len(errs) != 1 {
+		t.Error("negative durations should be rejected")
+	}
+	if errs := validateDurations(map[string]any{"timeout": "2m"}); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestDurationConfig(t *testing.T) {
+	p := &HexPlugin{}
+
+	t.Run("parse config reads timeout", func(t *testing.T) {
+		cfg := p.parseConfig(map[string]any{"timeout": "2m"})
+		if cfg.Timeout != 2*time.Minute {
+			t.Errorf("timeout: got %v", cfg.Timeout)
+		}
+	})
+
+	t.Run("validate names the offending field", func(t *testing.T) {
+		resp, err := p.Validate(context.Background(), map[string]any{"timeout": "later"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Valid || len(resp.Errors) != 1 || resp.Errors[0].Field != "timeout" {
+			t.Errorf("expected timeout error, got %+v", resp.Errors)
+		}
+	})
+
+	t.Run("execute rejects invalid durations", func(t *testing.T) {
+		mock := &MockCommandExecutor{}
+		p := &HexPlugin{executor: mock}
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "k", "timeout": "later"},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Success || !strings.Contains(resp.Error, "invalid timeout") {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+		if len(mock.Calls) != 0 {
+			t.Error("no command should run with invalid config")
+		}
+	})
+
+	t.Run("execute applies the timeout to commands", func(t *testing.T) {
+		var hasDeadline bool
+		mock := &MockCommandExecutor{
+			RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+				_, hasDeadline = ctx.Deadline()
+				return nil, nil
+			},
+		}
+		p := &HexPlugin{executor: mock}
+		_, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "k", "timeout": 30},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !hasDeadline {
+			t.Error("expected command context to carry the timeout")
+		}
+	})
+}
+
+func TestParseNestedConfig(t *testing.T) {
+	p := &HexPlugin{}
+	cfg := p.parseConfig(map[string]any{
+		"docs_targets": []any{
+			"https://docs.internal/{version}/",
+			map[string]any{"url": "s3://docs/my_lib/", "token_env": "DOCS_TOKEN"},
+		},
+		"gates": []any{
+			"test",
+			map[string]any{"name": "credo", "args": []any{"credo"}, "allow_failure": true},
+		},
+		"packages": []any{
+			map[string]any{"name": "core", "work_dir": "apps/core", "replace": true},
+		},
+	})
+
+	if len(cfg.DocsTargets) != 2 || cfg.DocsTargets[0].URL != "https://docs.internal/{version}/" || cfg.DocsTargets[1].TokenEnv != "DOCS_TOKEN" {
+		t.Errorf("unexpected docs targets: %+v", cfg.DocsTargets)
+	}
+	if len(cfg.Gates) != 2 || cfg.Gates[0].Name != "test" || !cfg.Gates[1].AllowFailure || len(cfg.Gates[1].Args) != 1 {
+		t.Errorf("unexpected gates: %+v", cfg.Gates)
+	}
+	if len(cfg.Packages) != 1 || cfg.Packages[0].WorkDir != "apps/core" || cfg.Packages[0].Replace == nil || !*cfg.Packages[0].Replace {
+		t.Errorf("unexpected packages: %+v", cfg.Packages)
+	}
+}
+
+func TestValidateNestedConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		expectField string
+	}{
+		{
+			name:   "valid nested config",
+			config: map[string]any{"gates": []any{map[string]any{"name": "test"}}, "packages": []any{map[string]any{"work_dir": "apps/a"}}},
+		},
+		{
+			name:        "unknown field is rejected",
+			config:      map[string]any{"docs_targets": []any{map[string]any{"url": "https://x", "tokn_env": "T"}}},
+			expectField: "docs_targets[0]",
+		},
+		{
+			name:        "non-list is rejected",
+			config:      map[string]any{"gates": "test"},
+			expectField: "gates",
+		},
+		{
+			name:        "package entries must be objects",
+			config:      map[string]any{"packages": []any{"apps/a"}},
+			expectField: "packages[0]",
+		},
+		{
+			name:        "package work_dir is required",
+			config:      map[string]any{"packages": []any{map[string]any{"name": "a"}}},
+			expectField: "packages[0].work_dir",
+		},
+		{
+			name:        "package work_dir must stay in the repository",
+			config:      map[string]any{"packages": []any{map[string]any{"work_dir": "../other"}}},
+			expectField: "packages[0].work_dir",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateNestedConfig(tt.config)
+			if tt.expectField == "" {
+				if len(errs) != 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tt.expectField {
+				t.Errorf("expected error for %q, got %v", tt.expectField, errs)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		expectField string
+	}{
+		{name: "valid", config: map[string]any{"organization": "acme", "replace_policy": "prerelease"}},
+		{name: "escaping work_dir", config: map[string]any{"work_dir": "../other"}, expectField: "work_dir"},
+		{name: "provisioned key without organization", config: map[string]any{"provision_org_key": true}, expectField: "provision_org_key"},
+		{name: "unknown replace policy", config: map[string]any{"replace_policy": "sometimes"}, expectField: "replace_policy"},
+		{name: "unknown dirty worktree mode", config: map[string]any{"dirty_worktree": "maybe"}, expectField: "dirty_worktree"},
+		{name: "escaping test registry dir", config: map[string]any{"test_registry_dir": "../registry"}, expectField: "test_registry_dir"},
+		{name: "canary on the test registry", config: map[string]any{"canary": true, "test_registry": true}, expectField: "canary"},
+		{name: "unreachable chaos phase", config: map[string]any{"chaos": map[string]any{"phase": "docs", "failure": "network"}}, expectField: "chaos.phase"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &HexPlugin{executor: &MockCommandExecutor{}}
+			errs := p.parseConfig(tt.config).validate()
+			if tt.expectField == "" {
+				if len(errs) != 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || errs[0].Field != tt.expectField {
+				t.Fatalf("expected an error on %s, got %v", tt.expectField, errs)
+			}
+
+			// Validate and the publish hook reject the same options
+			vresp, err := p.Validate(context.Background(), tt.config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			found := false
+			for _, e := range vresp.Errors {
+				found = found || e.Field == tt.expectField
+			}
+			if vresp.Valid || !found {
+				t.Errorf("Validate did not report %s: %+v", tt.expectField, vresp.Errors)
+			}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+				DryRun:  true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success || !strings.HasPrefix(resp.Error, "invalid "+tt.expectField+":") {
+				t.Errorf("expected publish to fail on %s, got success=%v error=%q", tt.expectField, resp.Success, resp.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+// errNonInteractive explains why a publish without --yes cannot proceed.
+const errNonInteractive = "confirmation required but running non-interactively: set yes: true or run the release from a terminal"
+
+// InteractiveExecutor is implemented by executors that can attach the
+// terminal to a command so mix can prompt for confirmation.
+type InteractiveExecutor interface {
+	RunInteractive(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error)
+}
+
+// RunInteractive executes the command with stdin forwarded from the terminal.
+// Output is echoed to stderr, masked with the masker carried by ctx, since
+// stdout carries the plugin protocol, and also captured for the response,
+// spooling like Run. The command runs in its
+// own process group, the terminal's foreground group while it prompts, and
+// is stopped like Run when ctx ends.
+func (e *RealCommandExecutor) RunInteractive(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+	cmd := newCommand(ctx, name, args, env, dir)
+
+	output := &spoolWriter{threshold: spoolThresholdFrom(ctx)}
+	echo := &echoWriter{w: os.Stderr, masker: maskerFrom(ctx)}
+	defer echo.flush()
+	out := io.MultiWriter(echo, output)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if stdinIsTerminal() {
+		setForegroundProcessGroup(cmd, os.Stdin)
+		defer restoreForeground(os.Stdin)
+	} else {
+		setProcessGroup(cmd)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	err := waitGracefully(ctx, cmd, shutdownGraceFrom(ctx))
+	return output.Bytes(), err
+}
+
+// maskerKey carries the secret masker of the run to the executor.
+type maskerKey struct{}
+
+// withMasker returns a context carrying the masker that output echoed to
+// the terminal passes through.
+func withMasker(ctx context.Context, m *secretMasker) context.Context {
+	return context.WithValue(ctx, maskerKey{}, m)
+}
+
+// maskerFrom returns the masker carried by ctx, if any.
+func maskerFrom(ctx context.Context) *secretMasker {
+	m, _ := ctx.Value(maskerKey{}).(*secretMasker)
+	return m
+}
+
+// echoWriter echoes the output of a prompting command to the terminal,
+// masked. Unlike the log stream it does not wait for whole lines, so a
+// prompt shows before its answer is read; only a tail that could start a
+// secret is held back until the next write.
+type echoWriter struct {
+	w      io.Writer
+	masker *secretMasker
+	buf    string
+}
+
+func (e *echoWriter) Write(p []byte) (int, error) {
+	masked := e.masker.mask(e.buf + string(p))
+	keep := e.masker.secretPrefixLen(masked)
+	_, _ = io.WriteString(e.w, masked[:len(masked)-keep])
+	e.buf = masked[len(masked)-keep:]
+	return len(p), nil
+}
+
+// flush writes the held back tail, at the end of the command.
+func (e *echoWriter) flush() {
+	if e.buf != "" {
+		_, _ = io.WriteString(e.w, e.buf)
+		e.buf = ""
+	}
+}
+
+// secretPrefixLen returns the length of the longest tail of s that begins
+// a known secret, which the rest of the secret may follow.
+func (m *secretMasker) secretPrefixLen(s string) int {
+	if m == nil {
+		return 0
+	}
+	longest := 0
+	for _, v := range m.values {
+		for n := min(len(v)-1, len(s)); n > longest; n-- {
+			if strings.HasSuffix(s, v[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// stdinIsTerminal reports whether stdin is attached to a terminal.
+func stdinIsTerminal() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// isInteractive reports whether a confirmation prompt can reach a user.
+func (p *HexPlugin) isInteractive() bool {
+	if p.terminal != nil {
+		return p.terminal()
+	}
+	return stdinIsTerminal()
+}
+
+// runInteractiveCommand runs a command with the terminal attached when the
+// executor supports it, recording it like runCommand.
+func (p *HexPlugin) runInteractiveCommand(ctx context.Context, summary *RunSummary, name string, args []string, env []string, dir string) ([]byte, error) {
+	executor, ok := p.getExecutor().(InteractiveExecutor)
+	if !ok {
+		return p.runCommand(ctx, summary, name, args, env, dir)
+	}
+
+	start := time.Now()
+	var masker *secretMasker
+	if summary != nil {
+		masker = summary.masker
+	}
+	output, err := executor.RunInteractive(withMasker(ctx, masker), name, args, env, dir)
+	output, err = summary.maskResult(sanitizeOutput(output), err)
+	summary.recordCommand(name, args, env, dir, start, err)
+	summary.recordTranscript(output, stdinTerminal, start)
+	return output, err
+}
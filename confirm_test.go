@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// interactiveMockExecutor records which execution path was used.
+type interactiveMockExecutor struct {
+	MockCommandExecutor
+	interactiveCalls int
+	masker           *secretMasker
+}
+
+// RunInteractive implements InteractiveExecutor.
+func (m *interactiveMockExecutor) RunInteractive(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+	m.interactiveCalls++
+	m.masker = maskerFrom(ctx)
+	return []byte("Building my_lib 1.0.0"), nil
+}
+
+func TestInteractiveConfirmation(t *testing.T) {
+	run := func(yes bool) (*interactiveMockExecutor, *plugin.ExecuteResponse) {
+		mock := &interactiveMockExecutor{}
+		p := &HexPlugin{executor: mock, terminal: func() bool { return true }}
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "k-secret", "yes": yes},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return mock, resp
+	}
+
+	t.Run("prompt is forwarded to the terminal", func(t *testing.T) {
+		mock, resp := run(false)
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		if mock.interactiveCalls != 1 || len(mock.Calls) != 0 {
+			t.Errorf("expected interactive run, got %d interactive and %d plain calls", mock.interactiveCalls, len(mock.Calls))
+		}
+		if mock.masker == nil || mock.masker.mask("k-secret") != "***" {
+			t.Errorf("expected the run's masker for the terminal echo, got %v", mock.masker)
+		}
+		if resp.Outputs["package"] != "my_lib" {
+			t.Errorf("expected output to be parsed, got %v", resp.Outputs["package"])
+		}
+	})
+
+	t.Run("yes skips the terminal", func(t *testing.T) {
+		mock, _ := run(true)
+		if mock.interactiveCalls != 0 || len(mock.Calls) != 1 {
+			t.Errorf("expected plain run, got %d interactive and %d plain calls", mock.interactiveCalls, len(mock.Calls))
+		}
+	})
+}
+
+func TestEchoWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   []string
+	}{
+		{
+			name:   "prompt without newline",
+			writes: []string{"Proceed? [Yn] "},
+			want:   []string{"Proceed? [Yn] "},
+		},
+		{
+			name:   "secret in one write",
+			writes: []string{"key: hex-secret-key\n"},
+			want:   []string{"key: ***\n"},
+		},
+		{
+			name:   "secret split across writes",
+			writes: []string{"key: hex-sec", "ret-key\n"},
+			want:   []string{"key: ", "key: ***\n"},
+		},
+		{
+			name:   "held back tail is flushed",
+			writes: []string{"done hex-"},
+			want:   []string{"done ", "done hex-"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &secretMasker{}
+			m.add("hex-secret-key")
+			var b strings.Builder
+			echo := &echoWriter{w: &b, masker: m}
+			for i, w := range tt.writes {
+				if _, err := echo.Write([]byte(w)); err != nil {
+					t.Fatal(err)
+				}
+				if got := b.String(); got != tt.want[i] {
+					t.Errorf("after write %d: %q, want %q", i, got, tt.want[i])
+				}
+			}
+			echo.flush()
+			if got, want := b.String(), tt.want[len(tt.want)-1]; got != want {
+				t.Errorf("after flush: %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// OutputCriteriaError reports a command that exited 0 while its output
+// shows the publish did not succeed, as wrapped mix aliases sometimes do.
+type OutputCriteriaError struct {
+	Reason string
+}
+
+func (e *OutputCriteriaError) Error() string {
+	return e.Reason
+}
+
+// ExitCode reports the exit status of the command, which was success.
+func (e *OutputCriteriaError) ExitCode() int {
+	return 0
+}
+
+// compileCriterion compiles the success_pattern or failure_pattern option.
+// An unset option yields a nil pattern.
+func compileCriterion(raw map[string]any, key string) (*regexp.Regexp, *fieldError) {
+	val, ok := raw[key]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	expr, ok := val.(string)
+	if !ok {
+		return nil, &fieldError{Field: key, Err: fmt.Errorf("must be a regular expression")}
+	}
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, &fieldError{Field: key, Err: err}
+	}
+	return re, nil
+}
+
+// validateOutputCriteria checks that success_pattern and failure_pattern
+// compile.
+func validateOutputCriteria(raw map[string]any) []*fieldError {
+	var errs []*fieldError
+	for _, key := range []string{"success_pattern", "failure_pattern"} {
+		if _, ferr := compileCriterion(raw, key); ferr != nil {
+			errs = append(errs, ferr)
+		}
+	}
+	return errs
+}
+
+// checkOutputCriteria applies failure_pattern and success_pattern to the
+// output of a command that exited 0. A failure_pattern match wins over a
+// success_pattern match.
+func (c *Config) checkOutputCriteria(output []byte) error {
+	text := ansiPattern.ReplaceAll(output, nil)
+	if c.FailurePattern != nil {
+		if m := c.FailurePattern.Find(text); m != nil {
+			return &OutputCriteriaError{Reason: fmt.Sprintf("output matched failure_pattern %q: %s", c.FailurePattern, m)}
+		}
+	}
+	if c.SuccessPattern != nil && !c.SuccessPattern.Match(text) {
+		return &OutputCriteriaError{Reason: fmt.Sprintf("output did not match success_pattern %q", c.SuccessPattern)}
+	}
+	return nil
+}
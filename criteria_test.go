@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateOutputCriteria(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        map[string]any
+		wantFields []string
+	}{
+		{name: "unset", raw: map[string]any{}},
+		{name: "valid", raw: map[string]any{"success_pattern": `Package published`, "failure_pattern": `(?i)error`}},
+		{name: "not a string", raw: map[string]any{"success_pattern": true}, wantFields: []string{"success_pattern"}},
+		{name: "bad expressions", raw: map[string]any{"success_pattern": `(`, "failure_pattern": `[`}, wantFields: []string{"success_pattern", "failure_pattern"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range validateOutputCriteria(tt.raw) {
+				fields = append(fields, err.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestExecuteOutputCriteria(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		output      string
+		wantSuccess bool
+		wantError   string
+	}{
+		{name: "no criteria", config: map[string]any{}, output: "Building my_lib 1.0.0\n** (Mix) Publish failed", wantSuccess: true},
+		{name: "success pattern matches", config: map[string]any{"success_pattern": `Package published to`}, output: "Building my_lib 1.0.0\nPackage published to https://hex.pm/packages/my_lib/1.0.0", wantSuccess: true},
+		{
+			name:      "success pattern missing",
+			config:    map[string]any{"success_pattern": `Package published to`},
+			output:    "Building my_lib 1.0.0\nAborted",
+			wantError: `mix hex.publish failed: output did not match success_pattern "Package published to"`,
+		},
+		{
+			name:      "failure pattern wins",
+			config:    map[string]any{"success_pattern": `Building`, "failure_pattern": `\*\* \(Mix\) .*`},
+			output:    "Building my_lib 1.0.0\n** (Mix) Publish failed",
+			wantError: `mix hex.publish failed: output matched failure_pattern "\\*\\* \\(Mix\\) .*": ** (Mix) Publish failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte(tt.output), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "retries": 2}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error %q)", resp.Success, tt.wantSuccess, resp.Error)
+			}
+			if tt.wantSuccess {
+				return
+			}
+			if !strings.HasPrefix(resp.Error, tt.wantError) {
+				t.Errorf("error = %q, want prefix %q", resp.Error, tt.wantError)
+			}
+			if resp.Outputs["exit_code"] != 0 {
+				t.Errorf("exit_code = %v, want 0", resp.Outputs["exit_code"])
+			}
+			if len(mock.Calls) != 1 {
+				t.Errorf("output criteria must not be retried, got %d calls", len(mock.Calls))
+			}
+		})
+	}
+}
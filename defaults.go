@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Config keys that structure the config rather than set options.
+const (
+	defaultsKey = "defaults"
+	hooksKey    = "hooks"
+)
+
+// knownHooks are the hook names accepted as keys of the hooks block.
+var knownHooks = []plugin.Hook{
+	plugin.HookPreInit, plugin.HookPostInit,
+	plugin.HookPrePlan, plugin.HookPostPlan,
+	plugin.HookPreVersion, plugin.HookPostVersion,
+	plugin.HookPreNotes, plugin.HookPostNotes,
+	plugin.HookPreApprove, plugin.HookPostApprove,
+	plugin.HookPrePublish, plugin.HookPostPublish,
+	plugin.HookOnSuccess, plugin.HookOnError,
+}
+
+// configObject returns the object stored under key, or nil when it is unset.
+func configObject(raw map[string]any, key string) (map[string]any, *fieldError) {
+	val, ok := raw[key]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, &fieldError{Field: key, Err: fmt.Errorf("must be an object")}
+	}
+	for _, nested := range []string{defaultsKey, hooksKey} {
+		if _, ok := obj[nested]; ok {
+			return nil, &fieldError{Field: key + "." + nested, Err: fmt.Errorf("cannot be nested")}
+		}
+	}
+	return obj, nil
+}
+
+// validateHookConfig checks the defaults and hooks blocks.
+func validateHookConfig(raw map[string]any) []*fieldError {
+	var errs []*fieldError
+	if _, err := configObject(raw, defaultsKey); err != nil {
+		errs = append(errs, err)
+	}
+
+	hooks, err := configObject(raw, hooksKey)
+	if err != nil {
+		return append(errs, err)
+	}
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !isKnownHook(name) {
+			errs = append(errs, &fieldError{Field: hooksKey + "." + name, Err: fmt.Errorf("unknown hook")})
+			continue
+		}
+		if _, err := configObject(hooks, name); err != nil {
+			err.Field = hooksKey + "." + err.Field
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// isKnownHook reports whether name is a Relicta hook.
+func isKnownHook(name string) bool {
+	for _, h := range knownHooks {
+		if string(h) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeConfig returns the effective config for a hook: the project config
+// file, overridden by the defaults block, overridden by top-level options,
+// overridden by the hooks entry for the hook. Options set nowhere still fall
+// back to their environment variables. An umbrella app named by app is
+// resolved to work_dir before the project config file is read from it.
+func mergeConfig(raw map[string]any, hook plugin.Hook) (map[string]any, []*fieldError) {
+	if errs := validateHookConfig(raw); len(errs) > 0 {
+		return nil, errs
+	}
+
+	defaults, _ := configObject(raw, defaultsKey)
+	hooks, _ := configObject(raw, hooksKey)
+	hookConfig, _ := configObject(hooks, string(hook))
+
+	merged := make(map[string]any, len(defaults)+len(raw)+len(hookConfig))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range raw {
+		if k != defaultsKey && k != hooksKey {
+			merged[k] = v
+		}
+	}
+	for k, v := range hookConfig {
+		merged[k] = v
+	}
+
+	merged, err := withUmbrellaApp(merged)
+	if err != nil {
+		return nil, []*fieldError{err}
+	}
+
+	merged, err = withProjectConfig(merged)
+	if err != nil {
+		return nil, []*fieldError{err}
+	}
+	return merged, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestMergeConfig(t *testing.T) {
+	raw := map[string]any{
+		"defaults": map[string]any{
+			"api_key":      "default-key",
+			"organization": "acme",
+			"work_dir":     "apps/core",
+		},
+		"organization": "top-level-org",
+		"hooks": map[string]any{
+			"post-publish": map[string]any{"work_dir": "apps/web"},
+			"pre-version":  map[string]any{"work_dir": "apps/other"},
+		},
+	}
+
+	merged, errs := mergeConfig(raw, plugin.HookPostPublish)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	want := map[string]any{
+		"api_key":      "default-key",
+		"organization": "top-level-org",
+		"work_dir":     "apps/web",
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("mergeConfig() = %v, want %v", merged, want)
+	}
+}
+
+func TestMergeConfigErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   map[string]any
+		field string
+	}{
+		{name: "defaults not an object", raw: map[string]any{"defaults": "x"}, field: "defaults"},
+		{name: "hooks not an object", raw: map[string]any{"hooks": []any{}}, field: "hooks"},
+		{name: "unknown hook", raw: map[string]any{"hooks": map[string]any{"post-release": map[string]any{}}}, field: "hooks.post-release"},
+		{name: "hook entry not an object", raw: map[string]any{"hooks": map[string]any{"post-publish": true}}, field: "hooks.post-publish"},
+		{name: "nested defaults", raw: map[string]any{"defaults": map[string]any{"hooks": map[string]any{}}}, field: "defaults.hooks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := mergeConfig(tt.raw, plugin.HookPostPublish)
+			if len(errs) == 0 || errs[0].Field != tt.field {
+				t.Errorf("expected error on %q, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestExecuteDefaultsPrecedence(t *testing.T) {
+	t.Setenv("HEX_ORGANIZATION", "env-org")
+
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"defaults": map[string]any{"api_key": "test-api-key", "organization": "default-org"},
+			"hooks":    map[string]any{"post-publish": map[string]any{"organization": "hook-org"}},
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+	if got := strings.Join(mock.Calls[0].Args, " "); !strings.Contains(got, "--organization hook-org") {
+		t.Errorf("expected hook organization to win, got %q", got)
+	}
+}
+
+func TestValidateDefaults(t *testing.T) {
+	p := &HexPlugin{}
+
+	resp, err := p.Validate(context.Background(), map[string]any{
+		"defaults": map[string]any{"organization": "Invalid Org"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Valid {
+		t.Error("expected organization from defaults to be validated")
+	}
+
+	resp, _ = p.Validate(context.Background(), map[string]any{
+		"hooks": map[string]any{"post-release": map[string]any{}},
+	})
+	if resp.Valid {
+		t.Error("expected unknown hook to be rejected")
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Levels of a dependency policy rule.
+const (
+	policyOff  = "off"
+	policyWarn = "warn"
+	policyFail = "fail"
+)
+
+// Dependency policy rules.
+const (
+	depRuleGit        = "git"
+	depRulePath       = "path"
+	depRuleExact      = "exact"
+	depRuleUpperBound = "upper_bound"
+)
+
+// DependencyPolicy sets the level of each rule applied to the dependencies
+// declared in mix.exs. Unset rules are off.
+type DependencyPolicy struct {
+	// Git forbids git and github dependencies.
+	Git string `json:"git,omitempty"`
+	// Path forbids path and in_umbrella dependencies.
+	Path string `json:"path,omitempty"`
+	// Exact forbids requirements pinned with == or a bare version.
+	Exact string `json:"exact,omitempty"`
+	// UpperBound requires requirements to bound the versions they accept.
+	UpperBound string `json:"upper_bound,omitempty"`
+}
+
+// level returns the level of a rule.
+func (d *DependencyPolicy) level(rule string) string {
+	var level string
+	switch rule {
+	case depRuleGit:
+		level = d.Git
+	case depRulePath:
+		level = d.Path
+	case depRuleExact:
+		level = d.Exact
+	case depRuleUpperBound:
+		level = d.UpperBound
+	}
+	if level == "" {
+		return policyOff
+	}
+	return level
+}
+
+// DependencyViolation is a declared dependency breaking a policy rule.
+type DependencyViolation struct {
+	Package     string `json:"package"`
+	Requirement string `json:"requirement,omitempty"`
+	Rule        string `json:"rule"`
+	Level       string `json:"level"`
+	Message     string `json:"message"`
+}
+
+// MixDependency is a dependency declared in the deps of mix.exs.
+type MixDependency struct {
+	Name        string
+	Requirement string
+	Options     string
+}
+
+// parseDependencyPolicy decodes the dependency_policy object.
+func parseDependencyPolicy(raw map[string]any) (*DependencyPolicy, *fieldError) {
+	val, ok := raw["dependency_policy"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, &fieldError{Field: "dependency_policy", Err: fmt.Errorf("must be an object")}
+	}
+	var policy DependencyPolicy
+	if err := decodeStrict(obj, &policy); err != nil {
+		return nil, &fieldError{Field: "dependency_policy", Err: err}
+	}
+	return &policy, nil
+}
+
+// validateDependencyPolicy checks the level of every dependency_policy rule.
+func validateDependencyPolicy(raw map[string]any) []*fieldError {
+	policy, ferr := parseDependencyPolicy(raw)
+	if ferr != nil {
+		return []*fieldError{ferr}
+	}
+	if policy == nil {
+		return nil
+	}
+	var errs []*fieldError
+	for _, rule := range []string{depRuleGit, depRulePath, depRuleExact, depRuleUpperBound} {
+		switch policy.level(rule) {
+		case policyOff, policyWarn, policyFail:
+		default:
+			errs = append(errs, &fieldError{Field: "dependency_policy." + rule, Err: fmt.Errorf("must be %s, %s or %s", policyOff, policyWarn, policyFail)})
+		}
+	}
+	return errs
+}
+
+var (
+	// mixDepsPattern matches the body of the deps function of mix.exs.
+	mixDepsPattern = regexp.MustCompile(`(?s)defp?\s+deps(?:\(\))?\s+do\b(.*?)\n\s*end\b`)
+	// mixDepPattern matches a {:name, ...} dependency tuple.
+	mixDepPattern = regexp.MustCompile(`\{\s*:([a-z_][a-zA-Z0-9_]*)\s*(?:,\s*([^{}]*))?\}`)
+	// mixOnlyPattern matches the only: option of a dependency.
+	mixOnlyPattern = regexp.MustCompile(`only:\s*(\[[^\]]*\]|:\w+)`)
+	// mixOptionPattern matches the keys of the keyword options of a dependency.
+	mixOptionPattern = regexp.MustCompile(`(?:^|[\s,])([a-z_]+):`)
+)
+
+// parseMixDeps extracts the dependencies declared in the deps function of
+// mix.exs.
+func parseMixDeps(content string) []MixDependency {
+	m := mixDepsPattern.FindStringSubmatch(content)
+	if m == nil {
+		return nil
+	}
+	var deps []MixDependency
+	for _, d := range mixDepPattern.FindAllStringSubmatch(m[1], -1) {
+		dep := MixDependency{Name: d[1], Options: strings.TrimSpace(d[2])}
+		if s := mixStringPattern.FindStringSubmatchIndex(dep.Options); s != nil && strings.TrimSpace(dep.Options[:s[0]]) == "" {
+			dep.Requirement = dep.Options[s[2]:s[3]]
+			dep.Options = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(dep.Options[s[1]:]), ","))
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// packaged reports whether Hex includes the dependency in the package:
+// dependencies restricted to other environments than prod are left out.
+func (d MixDependency) packaged() bool {
+	m := mixOnlyPattern.FindStringSubmatch(d.Options)
+	return m == nil || strings.Contains(m[1], ":prod")
+}
+
+// hasOption reports whether the dependency sets one of the keyword options.
+func (d MixDependency) hasOption(names ...string) bool {
+	for _, m := range mixOptionPattern.FindAllStringSubmatch(d.Options, -1) {
+		if slices.Contains(names, m[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// requirementClauses splits a requirement into its "or" alternatives.
+func requirementClauses(requirement string) []string {
+	return strings.Split(requirement, " or ")
+}
+
+// isExactRequirement reports whether a requirement pins a single version,
+// with == or a bare version, which Elixir treats the same.
+func isExactRequirement(requirement string) bool {
+	for _, clause := range requirementClauses(requirement) {
+		for _, part := range strings.Split(clause, " and ") {
+			part = strings.TrimSpace(part)
+			if strings.HasPrefix(part, "==") || (part != "" && part[0] >= '0' && part[0] <= '9') {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isUpperBounded reports whether every alternative of a requirement bounds
+// the versions it accepts, with ~>, <, <= or an exact version.
+func isUpperBounded(requirement string) bool {
+	for _, clause := range requirementClauses(requirement) {
+		bounded := false
+		for _, part := range strings.Split(clause, " and ") {
+			part = strings.TrimSpace(part)
+			if strings.HasPrefix(part, "~>") || strings.HasPrefix(part, "<") || strings.HasPrefix(part, "==") || (part != "" && part[0] >= '0' && part[0] <= '9') {
+				bounded = true
+			}
+		}
+		if !bounded {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDependencies applies the policy to the packaged dependencies.
+func (d *DependencyPolicy) checkDependencies(deps []MixDependency) []DependencyViolation {
+	violations := []DependencyViolation{}
+	add := func(dep MixDependency, rule, message string) {
+		if level := d.level(rule); level != policyOff {
+			violations = append(violations, DependencyViolation{Package: dep.Name, Requirement: dep.Requirement, Rule: rule, Level: level, Message: message})
+		}
+	}
+	for _, dep := range deps {
+		if !dep.packaged() {
+			continue
+		}
+		switch {
+		case dep.hasOption("git", "github"):
+			add(dep, depRuleGit, "git dependencies cannot be published to Hex")
+		case dep.hasOption("path", "in_umbrella"):
+			add(dep, depRulePath, "path dependencies cannot be published to Hex")
+		case dep.Requirement == "":
+		default:
+			if isExactRequirement(dep.Requirement) {
+				add(dep, depRuleExact, fmt.Sprintf("requirement %q pins an exact version", dep.Requirement))
+			}
+			if !isUpperBounded(dep.Requirement) {
+				add(dep, depRuleUpperBound, fmt.Sprintf("requirement %q has no upper bound", dep.Requirement))
+			}
+		}
+	}
+	return violations
+}
+
+// checkDependencyPolicy reads the dependencies from mix.exs and applies the
+// dependency_policy. It returns the violations and an error listing those at
+// the fail level.
+func checkDependencyPolicy(cfg *Config) ([]DependencyViolation, error) {
+	content, err := readMixExs(cfg.WorkDir)
+	if err != nil {
+		return nil, fmt.Errorf("dependency_policy: %w", err)
+	}
+	violations := cfg.DependencyPolicy.checkDependencies(parseMixDeps(content))
+
+	var failed []string
+	for _, v := range violations {
+		if v.Level == policyFail {
+			failed = append(failed, fmt.Sprintf("%s (%s)", v.Package, v.Message))
+		}
+	}
+	if len(failed) > 0 {
+		return violations, fmt.Errorf("dependency policy violations: %s", strings.Join(failed, "; "))
+	}
+	return violations, nil
+}
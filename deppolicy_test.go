@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const testDepsMixExs = `defmodule MyLib.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :my_lib, version: "1.0.0", deps: deps()]
+  end
+
+  defp deps do
+    [
+      {:jason, "~> 1.4"},
+      {:plug, ">= 1.15.0"},
+      {:decimal, "== 2.1.1"},
+      {:telemetry, "1.2.1", optional: true},
+      {:my_fork, git: "https://github.com/acme/my_fork.git", tag: "v1.0.0"},
+      {:sibling, path: "../sibling"},
+      {:ex_doc, ">= 0.0.0", only: :dev, runtime: false},
+      {:credo, github: "rrrene/credo", only: [:dev, :test]}
+    ]
+  end
+end
+`
+
+func TestParseMixDeps(t *testing.T) {
+	got := parseMixDeps(testDepsMixExs)
+	want := []MixDependency{
+		{Name: "jason", Requirement: "~> 1.4"},
+		{Name: "plug", Requirement: ">= 1.15.0"},
+		{Name: "decimal", Requirement: "== 2.1.1"},
+		{Name: "telemetry", Requirement: "1.2.1", Options: "optional: true"},
+		{Name: "my_fork", Options: `git: "https://github.com/acme/my_fork.git", tag: "v1.0.0"`},
+		{Name: "sibling", Options: `path: "../sibling"`},
+		{Name: "ex_doc", Requirement: ">= 0.0.0", Options: "only: :dev, runtime: false"},
+		{Name: "credo", Options: `github: "rrrene/credo", only: [:dev, :test]`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMixDeps() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRequirementBounds(t *testing.T) {
+	tests := []struct {
+		requirement string
+		exact       bool
+		bounded     bool
+	}{
+		{requirement: "~> 1.4", bounded: true},
+		{requirement: ">= 1.0.0", bounded: false},
+		{requirement: ">= 1.0.0 and < 2.0.0", bounded: true},
+		{requirement: "~> 1.0 or >= 2.0.0", bounded: false},
+		{requirement: "== 1.0.0", exact: true, bounded: true},
+		{requirement: "1.0.0", exact: true, bounded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.requirement, func(t *testing.T) {
+			if got := isExactRequirement(tt.requirement); got != tt.exact {
+				t.Errorf("isExactRequirement(%q) = %v, want %v", tt.requirement, got, tt.exact)
+			}
+			if got := isUpperBounded(tt.requirement); got != tt.bounded {
+				t.Errorf("isUpperBounded(%q) = %v, want %v", tt.requirement, got, tt.bounded)
+			}
+		})
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	policy := &DependencyPolicy{Git: policyFail, Path: policyFail, Exact: policyWarn, UpperBound: policyWarn}
+	got := policy.checkDependencies(parseMixDeps(testDepsMixExs))
+	want := []DependencyViolation{
+		{Package: "plug", Requirement: ">= 1.15.0", Rule: depRuleUpperBound, Level: policyWarn, Message: `requirement ">= 1.15.0" has no upper bound`},
+		{Package: "decimal", Requirement: "== 2.1.1", Rule: depRuleExact, Level: policyWarn, Message: `requirement "== 2.1.1" pins an exact version`},
+		{Package: "telemetry", Requirement: "1.2.1", Rule: depRuleExact, Level: policyWarn, Message: `requirement "1.2.1" pins an exact version`},
+		{Package: "my_fork", Rule: depRuleGit, Level: policyFail, Message: "git dependencies cannot be published to Hex"},
+		{Package: "sibling", Rule: depRulePath, Level: policyFail, Message: "path dependencies cannot be published to Hex"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkDependencies() = %+v, want %+v", got, want)
+	}
+
+	if got := (&DependencyPolicy{UpperBound: policyFail}).checkDependencies(parseMixDeps(testDepsMixExs)); len(got) != 1 || got[0].Package != "plug" {
+		t.Errorf("unset rules should be off, got %+v", got)
+	}
+}
+
+func TestValidateDependencyPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  any
+		wantErr string
+	}{
+		{name: "valid", policy: map[string]any{"git": "fail", "exact": "warn", "upper_bound": "off"}},
+		{name: "invalid level", policy: map[string]any{"path": "error"}, wantErr: "dependency_policy.path: must be off, warn or fail"},
+		{name: "unknown rule", policy: map[string]any{"pins": "fail"}, wantErr: "dependency_policy"},
+		{name: "not an object", policy: "fail", wantErr: "dependency_policy: must be an object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateDependencyPolicy(map[string]any{"dependency_policy": tt.policy})
+			if tt.wantErr == "" {
+				if len(errs) != 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestExecuteDependencyPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         map[string]any
+		wantSuccess    bool
+		wantError      string
+		wantViolations int
+	}{
+		{name: "warnings do not block", policy: map[string]any{"exact": "warn", "upper_bound": "warn"}, wantSuccess: true, wantViolations: 3},
+		{name: "failures block the publish", policy: map[string]any{"git": "fail", "upper_bound": "warn"}, wantError: "dependency policy violations: my_fork (git dependencies cannot be published to Hex)", wantViolations: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFile(t, mixExsFile, testDepsMixExs)
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "dependency_policy": tt.policy},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+			if got, _ := resp.Outputs["dependency_policy"].([]DependencyViolation); len(got) != tt.wantViolations {
+				t.Errorf("dependency_policy = %+v, want %d violations", got, tt.wantViolations)
+			}
+			if !tt.wantSuccess && len(mock.Calls) != 0 {
+				t.Errorf("expected no mix commands, got %d", len(mock.Calls))
+			}
+		})
+	}
+}
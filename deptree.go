@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// dependencyTreeArgs prints the resolved production dependency tree, the
+// part of the tree downstream projects resolve.
+var dependencyTreeArgs = []string{"deps.tree", "--format", "plain", "--only", "prod"}
+
+// addDependencyTree records the resolved dependency tree in the outputs for
+// provenance. Failures never block the publish; they are reported as
+// dependency_tree_error.
+func (p *HexPlugin) addDependencyTree(ctx context.Context, cfg *Config, outputs map[string]any, summary *RunSummary) {
+	output, err := p.runMix(ctx, cfg, summary, dependencyTreeArgs, nil)
+	if err != nil {
+		outputs["dependency_tree_error"] = fmt.Sprintf("mix deps.tree failed: %v\nOutput: %s", err, string(output))
+		return
+	}
+	outputs["dependency_tree"] = strings.TrimRight(ansiPattern.ReplaceAllString(string(output), ""), "\n")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteDependencyTree(t *testing.T) {
+	const tree = "my_lib\n|-- jason ~> 1.4 (Hex package)\n`-- telemetry ~> 1.0 (Hex package)\n"
+
+	tests := []struct {
+		name          string
+		treeErr       error
+		expectedTree  string
+		expectedError string
+	}{
+		{name: "tree attached", expectedTree: strings.TrimRight(tree, "\n")},
+		{name: "failure does not block publish", treeErr: errors.New("exit status 1"), expectedError: "mix deps.tree failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if args[0] == "deps.tree" {
+						return []byte(tree), tt.treeErr
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "dependency_tree": true},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			if !reflect.DeepEqual(mock.Calls[0].Args, dependencyTreeArgs) {
+				t.Errorf("unexpected deps.tree args: %v", mock.Calls[0].Args)
+			}
+			if tt.expectedTree != "" && resp.Outputs["dependency_tree"] != tt.expectedTree {
+				t.Errorf("dependency_tree = %q, want %q", resp.Outputs["dependency_tree"], tt.expectedTree)
+			}
+			if tt.expectedError != "" {
+				msg, _ := resp.Outputs["dependency_tree_error"].(string)
+				if !strings.Contains(msg, tt.expectedError) {
+					t.Errorf("expected dependency_tree_error containing %q, got %q", tt.expectedError, msg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// defaultDiagnosticsLines is how much of the failed command's output the
+// diagnostics bundle keeps.
+const defaultDiagnosticsLines = 200
+
+// diagnosticsCommandTimeout bounds each command run to collect diagnostics;
+// the hook context may already have expired.
+const diagnosticsCommandTimeout = 10 * time.Second
+
+// diagnosticsFile is one file of the diagnostics bundle.
+type diagnosticsFile struct {
+	Name    string
+	Content string
+}
+
+// lastLines returns the last n lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// mixExsSummary lists the mix.exs fields that matter for publishing.
+func mixExsSummary(content string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "app: %s\n", parseMixApp(content))
+	fmt.Fprintf(&b, "description: %s\n", parseMixDescription(content))
+	fmt.Fprintf(&b, "licenses: %s\n", strings.Join(parseMixLicenses(content), ", "))
+	if appsPath, ok := parseMixAppsPath(content); ok {
+		fmt.Fprintf(&b, "apps_path: %s\n", appsPath)
+	}
+	return b.String()
+}
+
+// writeDiagnosticsBundle packs files into a gzipped tarball at dest.
+func writeDiagnosticsBundle(dest string, files []diagnosticsFile, modTime time.Time) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.Name, Mode: 0o600, Size: int64(len(f.Content)), ModTime: modTime}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to pack %s: %w", f.Name, err)
+		}
+		if _, err := tw.Write([]byte(f.Content)); err != nil {
+			return fmt.Errorf("failed to pack %s: %w", f.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize diagnostics bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finalize diagnostics bundle: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("failed to create diagnostics directory: %w", err)
+	}
+	if err := os.WriteFile(dest, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write diagnostics bundle: %w", err)
+	}
+	return nil
+}
+
+// diagnosticsCommand runs a mix task for the bundle, keeping its output
+// even when it fails.
+func (p *HexPlugin) diagnosticsCommand(ctx context.Context, cfg *Config, summary *RunSummary, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), diagnosticsCommandTimeout)
+	defer cancel()
+	output, err := p.runMix(ctx, cfg, summary, args, cfg.hexEnv())
+	if err != nil {
+		return fmt.Sprintf("%s\n(%s failed: %v)\n", output, cfg.mixDisplay(args...), err)
+	}
+	return string(output)
+}
+
+// collectDiagnostics gathers the files of a bundle describing a failed
+// publish. Everything in it passes through the secret masker, so it can be
+// attached to a bug report.
+func (p *HexPlugin) collectDiagnostics(ctx context.Context, cfg *Config, resp *plugin.ExecuteResponse, summary *RunSummary) ([]diagnosticsFile, error) {
+	if err := validatePath(cfg.DiagnosticsDir); err != nil {
+		return nil, fmt.Errorf("invalid diagnostics_dir: %w", err)
+	}
+
+	output := resp.Error
+	if out, ok := resp.Outputs["output"].(string); ok && out != "" {
+		output = out
+	}
+	mixExs, err := readMixExs(cfg.WorkDir)
+	if err != nil {
+		mixExs = ""
+	}
+	_, _, env := cfg.mixCommand(nil, cfg.hexEnv())
+
+	versions := ""
+	if v, err := p.detectToolchain(ctx, cfg, summary); err != nil {
+		versions = err.Error() + "\n"
+	} else {
+		versions = v.String()
+	}
+
+	files := []diagnosticsFile{
+		{Name: "error.txt", Content: resp.Error + "\n"},
+		{Name: "output.txt", Content: lastLines(output, cfg.DiagnosticsLines)},
+		{Name: "versions.txt", Content: versions},
+		{Name: "hex_config.txt", Content: p.diagnosticsCommand(ctx, cfg, summary, "hex.config")},
+		{Name: "mix_exs.txt", Content: mixExsSummary(mixExs)},
+		{Name: "env.txt", Content: strings.Join(redactEnv(env), "\n") + "\n"},
+	}
+	for i := range files {
+		files[i].Content = summary.masker.mask(files[i].Content)
+	}
+	return files, nil
+}
+
+// writeDiagnostics writes the diagnostics bundle into diagnostics_dir and
+// returns its path.
+func writeDiagnostics(cfg *Config, files []diagnosticsFile) (string, error) {
+	now := time.Now().UTC()
+	path := filepath.Join(cfg.DiagnosticsDir, fmt.Sprintf("hex-diagnostics-%s.tar.gz", now.Format("20060102T150405Z")))
+	if err := writeDiagnosticsBundle(path, files, now); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// readBundle returns the files of a gzipped tarball by name.
+func readBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(data)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{in: "a\nb\nc\n", n: 2, want: "b\nc\n"},
+		{in: "a\nb", n: 5, want: "a\nb\n"},
+		{in: "a\nb\nc", n: 1, want: "c\n"},
+	}
+	for _, tt := range tests {
+		if got := lastLines(tt.in, tt.n); got != tt.want {
+			t.Errorf("lastLines(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteDiagnosticsBundle(t *testing.T) {
+	tests := []struct {
+		name      string
+		failure   bool
+		dryRun    bool
+		wantPath  bool
+		wantCalls int
+	}{
+		{name: "failure", failure: true, wantPath: true, wantCalls: 3},
+		{name: "success", wantCalls: 1},
+		{name: "dry run", failure: true, dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t)
+			if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte(`[app: :my_lib, description: "A lib", licenses: ["MIT"]]`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					switch args[0] {
+					case "hex.info":
+						return []byte("Hex: 2.1.1\nElixir: 1.17.2\nOTP: 27.0\n"), nil
+					case "hex.config":
+						return []byte("api_url: https://hex.pm/api\n"), nil
+					}
+					if tt.failure {
+						return []byte("line 1\nline 2\nsent key secret-api-key\n** (Mix) publish failed"), errors.New("exit status 1")
+					}
+					return []byte("mock output"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "secret-api-key", "diagnostics_dir": "diag", "diagnostics_lines": 2},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+				DryRun:  tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(mock.Calls) != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, len(mock.Calls))
+			}
+			path, ok := resp.Outputs["diagnostics_path"].(string)
+			if ok != tt.wantPath {
+				t.Fatalf("diagnostics_path = %v, want present %v (error %v)", resp.Outputs["diagnostics_path"], tt.wantPath, resp.Outputs["diagnostics_error"])
+			}
+			if !tt.wantPath {
+				return
+			}
+
+			files := readBundle(t, path)
+			if !strings.Contains(files["versions.txt"], "OTP: 27.0") {
+				t.Errorf("versions.txt = %q", files["versions.txt"])
+			}
+			if !strings.Contains(files["hex_config.txt"], "api_url") {
+				t.Errorf("hex_config.txt = %q", files["hex_config.txt"])
+			}
+			if !strings.Contains(files["mix_exs.txt"], "app: my_lib") || !strings.Contains(files["mix_exs.txt"], "licenses: MIT") {
+				t.Errorf("mix_exs.txt = %q", files["mix_exs.txt"])
+			}
+			if !strings.Contains(files["env.txt"], "HEX_API_KEY=<redacted>") {
+				t.Errorf("env.txt = %q", files["env.txt"])
+			}
+			if !strings.Contains(files["error.txt"], "mix hex.publish failed") {
+				t.Errorf("error.txt = %q", files["error.txt"])
+			}
+			for name, content := range files {
+				if strings.Contains(content, "secret-api-key") {
+					t.Errorf("%s leaks the api key: %q", name, content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Docs destinations supported by the docs_destination option.
+const (
+	docsDestinationHexdocs = "hexdocs"
+	docsDestinationCustom  = "custom"
+	docsDestinationBoth    = "both"
+)
+
+// defaultDocsTokenEnv is the environment variable holding the bearer token
+// sent to HTTP docs targets.
+const defaultDocsTokenEnv = "HEX_DOCS_UPLOAD_TOKEN"
+
+// HTTPClient abstracts HTTP requests for testability.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// getHTTPClient returns the HTTP client, defaulting to a client with a generous timeout.
+func (p *HexPlugin) getHTTPClient() HTTPClient {
+	if p.httpClient != nil {
+		return p.httpClient
+	}
+	return &http.Client{Timeout: 10 * time.Minute}
+}
+
+// usesCustomDocs reports whether docs must be uploaded to self-hosted targets.
+func (c *Config) usesCustomDocs() bool {
+	return c.DocsDestination == docsDestinationCustom || c.DocsDestination == docsDestinationBoth
+}
+
+// validateDocsConfig validates the docs destination and its targets.
+func validateDocsConfig(destination string, targets []DocsTarget) error {
+	switch destination {
+	case "", docsDestinationHexdocs:
+		return nil
+	case docsDestinationCustom, docsDestinationBoth:
+	default:
+		return fmt.Errorf("must be one of: %s, %s, %s", docsDestinationHexdocs, docsDestinationCustom, docsDestinationBoth)
+	}
+
+	if len(targets) == 0 {
+		return fmt.Errorf("docs_targets is required when docs_destination is %q", destination)
+	}
+
+	for _, target := range targets {
+		if err := validateDocsTarget(target.URL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateDocsTarget validates a single docs target URL.
+func validateDocsTarget(target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid docs target %q: %v", target, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("invalid docs target %q: missing host", target)
+		}
+		// The upload carries the docs bearer token
+		if u.Scheme == "http" && !isLoopbackHost(u.Hostname()) {
+			return fmt.Errorf("invalid docs target %q: must use https; http is only allowed for localhost", target)
+		}
+	case "s3":
+		if u.Host == "" {
+			return fmt.Errorf("invalid docs target %q: missing bucket", target)
+		}
+	default:
+		return fmt.Errorf("invalid docs target %q: scheme must be http, https or s3", target)
+	}
+
+	return nil
+}
+
+// isLoopbackHost reports whether host names the local machine.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// docsTarballName returns the file name used for the docs tarball of a version.
+func docsTarballName(version string) string {
+	return fmt.Sprintf("docs-%s.tar.gz", version)
+}
+
+// resolveDocsTarget expands the {version} placeholder and appends the tarball
+// name to targets ending with a slash.
+func resolveDocsTarget(target, version string) string {
+	resolved := strings.ReplaceAll(target, "{version}", version)
+	if strings.HasSuffix(resolved, "/") {
+		resolved += docsTarballName(version)
+	}
+	return resolved
+}
+
+// buildDocsTarball packs the generated docs directory into a gzipped tarball.
+func buildDocsTarball(docsDir, dest string) (int64, error) {
+	info, err := os.Stat(docsDir)
+	if err != nil {
+		return 0, fmt.Errorf("docs directory not found: %w", err)
+	}
+	if !info.IsDir() {
+		return 0, fmt.Errorf("docs path is not a directory: %s", docsDir)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create docs tarball: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.Walk(docsDir, func(path string, fi os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(docsDir, path)
+		if err != nil {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = src.Close() }()
+
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack docs: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finalize docs tarball: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finalize docs tarball: %w", err)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat docs tarball: %w", err)
+	}
+	return stat.Size(), nil
+}
+
+// uploadDocs uploads the docs tarball to a single resolved target URL.
+func (p *HexPlugin) uploadDocs(ctx context.Context, tarball, target, tokenEnv string, summary *RunSummary) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid docs target: %w", err)
+	}
+
+	if u.Scheme == "s3" {
+		output, err := p.runCommand(ctx, summary, "aws", []string{"s3", "cp", tarball, target}, nil, "")
+		if err != nil {
+			return fmt.Errorf("aws s3 cp failed: %v\nOutput: %s", err, string(output))
+		}
+		return nil
+	}
+
+	f, err := os.Open(tarball)
+	if err != nil {
+		return fmt.Errorf("failed to open docs tarball: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat docs tarball: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, f)
+	if err != nil {
+		return fmt.Errorf("failed to build upload request: %w", err)
+	}
+	// Object stores and presigned URLs reject chunked uploads
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/gzip")
+	if token := os.Getenv(tokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.getHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// publishCustomDocs builds the docs tarball and uploads it to every configured target.
+// It returns the resolved target URLs that received the docs.
+func (p *HexPlugin) publishCustomDocs(ctx context.Context, cfg *Config, version string, summary *RunSummary) ([]string, error) {
+	// hex.publish already generated the docs when hexdocs is also a destination
+	if cfg.DocsDestination == docsDestinationCustom {
+		output, err := p.runMix(ctx, cfg, summary, []string{"docs"}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("mix docs failed: %v\nOutput: %s", err, string(output))
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "relicta-hex-docs-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	tarball := filepath.Join(tmpDir, docsTarballName(version))
+	size, err := buildDocsTarball(filepath.Join(cfg.WorkDir, "doc"), tarball)
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded := make([]string, 0, len(cfg.DocsTargets))
+	for _, target := range cfg.DocsTargets {
+		resolved := resolveDocsTarget(target.URL, version)
+		tokenEnv := target.TokenEnv
+		if tokenEnv == "" {
+			tokenEnv = cfg.DocsTokenEnv
+		}
+		if err := p.uploadDocs(ctx, tarball, resolved, tokenEnv, summary); err != nil {
+			return uploaded, fmt.Errorf("docs upload to %s failed: %w", resolved, err)
+		}
+		uploaded = append(uploaded, resolved)
+		summary.addArtifact(plugin.Artifact{
+			Name: docsTarballName(version),
+			Path: resolved,
+			Type: "url",
+			Size: size,
+		})
+	}
+
+	return uploaded, nil
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// chdirTemp switches into a fresh temp directory for the duration of the test.
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(prev) })
+	return dir
+}
+
+// writeFile creates a file (and its parent directories) with the given content.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestValidateDocsConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		targets     []DocsTarget
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "default destination is valid",
+			destination: "",
+		},
+		{
+			name:        "hexdocs needs no targets",
+			destination: "hexdocs",
+		},
+		{
+			name:        "custom with https target is valid",
+			destination: "custom",
+			targets:     []DocsTarget{{URL: "https://docs.internal/my_lib/"}},
+		},
+		{
+			name:        "both with s3 target is valid",
+			destination: "both",
+			targets:     []DocsTarget{{URL: "s3://docs-bucket/my_lib/"}},
+		},
+		{
+			name:        "unknown destination is invalid",
+			destination: "ftp",
+			expectError: true,
+			errorMsg:    "must be one of",
+		},
+		{
+			name:        "custom without targets is invalid",
+			destination: "custom",
+			expectError: true,
+			errorMsg:    "docs_targets is required",
+		},
+		{
+			name:        "unsupported scheme is invalid",
+			destination: "custom",
+			targets:     []DocsTarget{{URL: "ftp://docs.internal/"}},
+			expectError: true,
+			errorMsg:    "scheme must be",
+		},
+		{
+			name:        "http target is invalid",
+			destination: "custom",
+			targets:     []DocsTarget{{URL: "http://docs.internal/my_lib/"}},
+			expectError: true,
+			errorMsg:    "must use https",
+		},
+		{
+			name:        "http loopback targets are valid",
+			destination: "custom",
+			targets:     []DocsTarget{{URL: "http://localhost:8080/docs/"}, {URL: "http://127.0.0.1:9000/"}, {URL: "http://[::1]/docs/"}},
+		},
+		{
+			name:        "s3 target without bucket is invalid",
+			destination: "custom",
+			targets:     []DocsTarget{{URL: "s3:///prefix"}},
+			expectError: true,
+			errorMsg:    "missing bucket",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDocsConfig(tt.destination, tt.targets)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("error: expected to contain %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestResolveDocsTarget(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{"https://docs.internal/my_lib/", "https://docs.internal/my_lib/docs-1.2.0.tar.gz"},
+		{"https://docs.internal/my_lib/{version}.tgz", "https://docs.internal/my_lib/1.2.0.tgz"},
+		{"s3://bucket/my_lib/{version}/", "s3://bucket/my_lib/1.2.0/docs-1.2.0.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		if got := resolveDocsTarget(tt.target, "1.2.0"); got != tt.expected {
+			t.Errorf("resolveDocsTarget(%q): got %q, expected %q", tt.target, got, tt.expected)
+		}
+	}
+}
+
+func TestBuildDocsTarball(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "doc", "index.html"), "<html></html>")
+	writeFile(t, filepath.Join(dir, "doc", "dist", "app.js"), "console.log(1)")
+
+	dest := filepath.Join(dir, "docs.tar.gz")
+	size, err := buildDocsTarball(filepath.Join(dir, "doc"), dest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size == 0 {
+		t.Error("expected non-empty tarball")
+	}
+
+	f, err := os.Open(dest)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		names = append(names, hdr.Name)
+	}
+
+	if !contains(names, "index.html") || !contains(names, "dist/app.js") {
+		t.Errorf("unexpected tarball entries: %v", names)
+	}
+
+	t.Run("missing docs directory fails", func(t *testing.T) {
+		_, err := buildDocsTarball(filepath.Join(dir, "missing"), filepath.Join(dir, "x.tar.gz"))
+		if err == nil || !strings.Contains(err.Error(), "docs directory not found") {
+			t.Errorf("expected docs directory error, got %v", err)
+		}
+	})
+}
+
+func TestExecuteCustomDocs(t *testing.T) {
+	dir := chdirTemp(t)
+	writeFile(t, filepath.Join(dir, "doc", "index.html"), "<html></html>")
+
+	var uploads []string
+	var authHeader string
+	var transferEncoding []string
+	var contentLength, bodyLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads = append(uploads, r.Method+" "+r.URL.Path)
+		authHeader = r.Header.Get("Authorization")
+		transferEncoding, contentLength = r.TransferEncoding, r.ContentLength
+		bodyLength, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	t.Setenv("HEX_DOCS_UPLOAD_TOKEN", "docs-token")
+
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock, httpClient: server.Client()}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"api_key":          "test-key",
+			"docs_destination": "custom",
+			"docs_targets":     []any{server.URL + "/my_lib/", "s3://docs-bucket/my_lib/{version}.tar.gz"},
+		},
+		Context: plugin.ReleaseContext{Version: "1.2.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+
+	if len(mock.Calls) != 3 {
+		t.Fatalf("expected 3 calls (publish, docs, s3), got %d", len(mock.Calls))
+	}
+	if strings.Join(mock.Calls[0].Args, " ") != "hex.publish package --yes" {
+		t.Errorf("unexpected publish args: %v", mock.Calls[0].Args)
+	}
+	if strings.Join(mock.Calls[1].Args, " ") != "docs" {
+		t.Errorf("expected mix docs, got %v", mock.Calls[1].Args)
+	}
+	if mock.Calls[2].Name != "aws" || mock.Calls[2].Args[len(mock.Calls[2].Args)-1] != "s3://docs-bucket/my_lib/1.2.0.tar.gz" {
+		t.Errorf("unexpected s3 upload call: %v %v", mock.Calls[2].Name, mock.Calls[2].Args)
+	}
+
+	if len(uploads) != 1 || uploads[0] != "PUT /my_lib/docs-1.2.0.tar.gz" {
+		t.Errorf("unexpected uploads: %v", uploads)
+	}
+	if authHeader != "Bearer docs-token" {
+		t.Errorf("expected bearer token, got %q", authHeader)
+	}
+	if len(transferEncoding) != 0 || contentLength <= 0 || contentLength != bodyLength {
+		t.Errorf("expected a Content-Length upload, got transfer encoding %v, length %d for %d bytes", transferEncoding, contentLength, bodyLength)
+	}
+
+	t.Run("upload failure is reported after publish", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer failing.Close()
+
+		p := &HexPlugin{executor: &MockCommandExecutor{}, httpClient: failing.Client()}
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPostPublish,
+			Config: map[string]any{
+				"api_key":          "test-key",
+				"docs_destination": "both",
+				"docs_targets":     []any{failing.URL + "/"},
+			},
+			Context: plugin.ReleaseContext{Version: "1.2.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Success {
+			t.Fatal("expected failure")
+		}
+		if !strings.Contains(resp.Error, "published but docs upload failed") || !strings.Contains(resp.Error, "status 403") {
+			t.Errorf("unexpected error: %s", resp.Error)
+		}
+	})
+}
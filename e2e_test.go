@@ -0,0 +1,91 @@
+//go:build e2e
+
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestE2ETestRegistry publishes a generated Mix project into a local registry
+// and resolves it from a consumer project. It requires an Elixir toolchain with
+// Hex installed and runs with: go test -tags e2e -run E2E ./...
+func TestE2ETestRegistry(t *testing.T) {
+	if _, err := exec.LookPath("mix"); err != nil {
+		t.Skip("mix not found in PATH")
+	}
+
+	root := chdirTemp(t)
+	writeFile(t, filepath.Join(root, "lib_src", "mix.exs"), `defmodule E2eLib.MixProject do
+  use Mix.Project
+
+  def project do
+    [
+      app: :e2e_lib,
+      version: "0.1.0",
+      elixir: "~> 1.12",
+      description: "Relicta hex plugin e2e fixture",
+      package: [licenses: ["MIT"], links: %{}]
+    ]
+  end
+end
+`)
+	writeFile(t, filepath.Join(root, "lib_src", "lib", "e2e_lib.ex"), "defmodule E2eLib do\n  def hello, do: :world\nend\n")
+
+	p := &HexPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"work_dir":          "lib_src",
+			"test_registry":     true,
+			"test_registry_dir": "registry",
+		},
+		Context: plugin.ReleaseContext{Version: "0.1.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("publish to test registry failed: %s", resp.Error)
+	}
+
+	// Serve the registry and resolve the package from a consumer project
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &http.Server{Handler: http.FileServer(http.Dir(filepath.Join(root, "registry", "public")))}
+	go func() { _ = server.Serve(listener) }()
+	defer func() { _ = server.Close() }()
+
+	consumer := filepath.Join(root, "consumer")
+	writeFile(t, filepath.Join(consumer, "mix.exs"), `defmodule Consumer.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :consumer, version: "0.0.1", deps: [{:e2e_lib, "0.1.0", repo: "test_registry"}]]
+  end
+end
+`)
+
+	env := append(os.Environ(), "HEX_HOME="+filepath.Join(root, "hex_home"))
+	steps := [][]string{
+		{"hex.repo", "add", testRegistryName, "http://" + listener.Addr().String(), "--public-key", filepath.Join(root, "registry", "public_key.pem")},
+		{"deps.get"},
+	}
+	for _, args := range steps {
+		cmd := exec.Command("mix", args...)
+		cmd.Dir = consumer
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("mix %v failed: %v\n%s", args, err, out)
+		}
+	}
+}
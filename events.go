@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// capabilityEvents lets the plugin emit publish lifecycle events as JSON
+// lines on stderr, which hosts that declare it show as a live timeline.
+const capabilityEvents = "events"
+
+// Publish lifecycle events.
+const (
+	eventGateStarted    = "gate_started"
+	eventGatePassed     = "gate_passed"
+	eventGateFailed     = "gate_failed"
+	eventBuildFinished  = "build_finished"
+	eventUploadStarted  = "upload_started"
+	eventUploadFinished = "upload_finished"
+	eventVerified       = "verified"
+)
+
+// PublishEvent is one step of the publish timeline.
+type PublishEvent struct {
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	WorkDir string    `json:"work_dir"`
+	Gate    string    `json:"gate,omitempty"`
+	// Success is set on events that end a step.
+	Success    *bool  `json:"success,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Attempts   int    `json:"attempts,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// eventRecord is the line written for a live event, tagged so hosts can
+// tell it from other stderr output.
+type eventRecord struct {
+	Type   string `json:"type"`
+	Plugin string `json:"plugin"`
+	PublishEvent
+}
+
+// eventWriter returns where events are written as they happen when the
+// host supports them, or nil when they are returned in the events output.
+func (m compatMatrix) eventWriter(w io.Writer) io.Writer {
+	if m.Events != compatNative {
+		return nil
+	}
+	return w
+}
+
+// emit records a lifecycle event and, on hosts with events, writes it as
+// a JSON line. Errors are masked; write failures never fail the run.
+func (s *RunSummary) emit(event PublishEvent) {
+	if s == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	event.Error = s.masker.mask(sanitizeText(event.Error))
+	s.Events = append(s.Events, event)
+	if s.events == nil {
+		return
+	}
+	if data, err := json.Marshal(eventRecord{Type: "event", Plugin: "hex", PublishEvent: event}); err == nil {
+		_, _ = s.events.Write(append(data, '\n'))
+	}
+}
+
+// finished returns the end of a step: its success, duration and error.
+func finished(event, workDir string, start time.Time, err error) PublishEvent {
+	success := err == nil
+	e := PublishEvent{Event: event, WorkDir: workDir, Success: &success, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
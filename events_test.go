@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		failOn      string
+		host        string
+		wantSuccess bool
+		wantEvents  []string
+		wantFailed  string
+	}{
+		{
+			name:        "successful publish",
+			config:      map[string]any{"gates": []any{"format"}},
+			wantSuccess: true,
+			wantEvents:  []string{eventGateStarted, eventGatePassed, eventBuildFinished, eventUploadStarted, eventUploadFinished, eventVerified},
+		},
+		{
+			name:       "failed gate",
+			config:     map[string]any{"gates": []any{"format", "test"}},
+			failOn:     "format",
+			wantEvents: []string{eventGateStarted, eventGateFailed, eventGateStarted, eventGatePassed},
+			wantFailed: eventGateFailed,
+		},
+		{
+			name:       "failed warnings check",
+			config:     map[string]any{"warnings_as_errors_publish": true},
+			failOn:     "--dry-run",
+			wantEvents: []string{eventBuildFinished},
+			wantFailed: eventBuildFinished,
+		},
+		{
+			name:       "failed upload",
+			failOn:     "hex.publish",
+			wantEvents: []string{eventBuildFinished, eventUploadStarted, eventUploadFinished},
+			wantFailed: eventUploadFinished,
+		},
+		{
+			name:       "failed post-publish task",
+			config:     map[string]any{"post_publish_tasks": []any{"notify"}},
+			failOn:     "notify",
+			wantEvents: []string{eventBuildFinished, eventUploadStarted, eventUploadFinished, eventVerified},
+			wantFailed: eventVerified,
+		},
+		{
+			name:        "host with events",
+			host:        capabilityEvents,
+			wantSuccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(hostCapabilitiesEnv, tt.host)
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if tt.failOn != "" && contains(args, tt.failOn) {
+						return []byte("** (Mix) failed"), errors.New("exit status 1")
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+
+			events, _ := resp.Outputs["events"].([]PublishEvent)
+			var names []string
+			for _, e := range events {
+				names = append(names, e.Event)
+				if e.WorkDir != "." || e.Time.IsZero() {
+					t.Errorf("event %+v lacks work_dir or time", e)
+				}
+				if e.Success != nil && !*e.Success && e.Event != tt.wantFailed {
+					t.Errorf("unexpected failed event %+v", e)
+				}
+				if e.Success != nil && !*e.Success && e.Error == "" {
+					t.Errorf("failed event %+v has no error", e)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", names, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestEmitWritesEvents(t *testing.T) {
+	var buf bytes.Buffer
+	summary := &RunSummary{masker: &secretMasker{}, events: &buf}
+	summary.masker.add("s3cr3t-key")
+
+	summary.emit(PublishEvent{Event: eventUploadStarted, WorkDir: "."})
+	summary.emit(finished(eventUploadFinished, ".", summary.Events[0].Time, errors.New("401 for key s3cr3t-key")))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 event lines, got %q", buf.String())
+	}
+	var record eventRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Type != "event" || record.Plugin != "hex" || record.Event != eventUploadFinished || *record.Success {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if strings.Contains(buf.String(), "s3cr3t-key") || record.Error != "401 for key ***" {
+		t.Errorf("event error not masked: %q", buf.String())
+	}
+}
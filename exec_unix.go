@@ -0,0 +1,44 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// setProcessGroup starts the command in its own process group so signals
+// reach mix and the BEAM processes it spawns.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// setForegroundProcessGroup starts the command in its own process group and
+// makes it the foreground group of tty, so it can read the user's answers
+// without being stopped by SIGTTIN.
+func setForegroundProcessGroup(cmd *exec.Cmd, tty *os.File) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Foreground: true, Ctty: int(tty.Fd())}
+}
+
+// restoreForeground hands tty back to the plugin's process group once a
+// foreground command exits. SIGTTOU is ignored meanwhile, as the plugin is
+// then a background group changing the terminal.
+func restoreForeground(tty *os.File) {
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	_ = unix.IoctlSetPointerInt(int(tty.Fd()), unix.TIOCSPGRP, syscall.Getpgrp())
+}
+
+// interruptProcess sends SIGINT to the command's process group.
+func interruptProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
+
+// killProcess sends SIGKILL to the command's process group.
+func killProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
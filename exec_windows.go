@@ -0,0 +1,28 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// setForegroundProcessGroup is a no-op on Windows.
+func setForegroundProcessGroup(cmd *exec.Cmd, tty *os.File) {}
+
+// restoreForeground is a no-op on Windows.
+func restoreForeground(tty *os.File) {}
+
+// interruptProcess is unsupported on Windows, so shutdown goes straight to killing.
+func interruptProcess(cmd *exec.Cmd) error {
+	return errors.New("interrupt is not supported on windows")
+}
+
+// killProcess terminates the command.
+func killProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// FailureMessageData is the data available to failure_message_template.
+type FailureMessageData struct {
+	Hook         string
+	ErrorClass   string
+	ExitCode     int
+	Package      string
+	Version      string
+	Organization string
+	// Error is the failure message the template replaces, including any hint.
+	Error string
+	Hint  string
+}
+
+// parseFailureTemplate parses a failure_message_template. Referencing a field
+// FailureMessageData does not have fails here, not at render time.
+func parseFailureTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("failure_message_template").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(&strings.Builder{}, FailureMessageData{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// newFailureMessageData collects the template data of a failed response. The
+// package name comes from the publish output, falling back to the mix.exs app.
+func newFailureMessageData(cfg *Config, req plugin.ExecuteRequest, resp *plugin.ExecuteResponse, summary *RunSummary) FailureMessageData {
+	data := FailureMessageData{
+		Hook:         string(req.Hook),
+		Version:      strings.TrimPrefix(req.Context.Version, "v"),
+		Organization: cfg.Organization,
+		Error:        resp.Error,
+		Package:      summary.Package,
+	}
+	data.ErrorClass, _ = resp.Outputs["error_class"].(string)
+	data.ExitCode, _ = resp.Outputs["exit_code"].(int)
+	data.Hint, _ = resp.Outputs["hint"].(string)
+	if name, ok := resp.Outputs["package"].(string); ok && name != "" {
+		data.Package = name
+	}
+	if data.Package == "" {
+		if content, err := readMixExs(cfg.WorkDir); err == nil {
+			data.Package = parseMixApp(content)
+		}
+	}
+	return data
+}
+
+// applyFailureTemplate replaces the error of a failed response with the
+// rendered failure_message_template. A template that fails to render leaves
+// the error untouched and reports why as failure_message_error.
+func applyFailureTemplate(cfg *Config, req plugin.ExecuteRequest, resp *plugin.ExecuteResponse, summary *RunSummary) {
+	if resp.Success || cfg.FailureMessageTemplate == "" {
+		return
+	}
+	tmpl, err := parseFailureTemplate(cfg.FailureMessageTemplate)
+	if err != nil {
+		resp.Outputs["failure_message_error"] = fmt.Sprintf("invalid failure_message_template: %v", err)
+		return
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, newFailureMessageData(cfg, req, resp, summary)); err != nil {
+		resp.Outputs["failure_message_error"] = err.Error()
+		return
+	}
+	resp.Error = summary.masker.mask(b.String())
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseFailureTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "fields", text: "{{.Package}} {{.Version}} failed ({{.ErrorClass}})"},
+		{name: "plain text", text: "publish failed, see the runbook"},
+		{name: "syntax error", text: "{{.Package", wantErr: true},
+		{name: "unknown field", text: "{{.Release}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFailureTemplate(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseFailureTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteFailureMessageTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		success   bool
+		wantError string
+		wantOut   bool
+	}{
+		{
+			name:      "renders class, package and version",
+			template:  "{{.Package}} v{{.Version}} failed ({{.ErrorClass}}): https://runbooks.example.com/hex#{{.ErrorClass}}",
+			wantError: "my_pkg v1.2.0 failed (auth): https://runbooks.example.com/hex#auth",
+		},
+		{
+			name:      "original error and hint",
+			template:  "{{.Hint}}",
+			wantError: "check that HEX_API_KEY holds a current key with api:write permission",
+		},
+		{
+			name:     "success is untouched",
+			template: "{{.Package}} failed",
+			success:  true,
+		},
+		{
+			name:     "invalid template keeps the error",
+			template: "{{.Release}}",
+			wantOut:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			if err := os.WriteFile("mix.exs", []byte("def project do\n  [app: :my_pkg, version: \"1.2.0\"]\nend\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if tt.success {
+						return []byte("Building my_pkg 1.2.0"), nil
+					}
+					return []byte("** (Mix) Invalid API key"), errors.New("exit status 1")
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "failure_message_template": tt.template},
+				Context: plugin.ReleaseContext{Version: "v1.2.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.success {
+				t.Fatalf("success = %v, want %v (error %q)", resp.Success, tt.success, resp.Error)
+			}
+			if tt.wantError != "" && resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+			if tt.wantOut {
+				if resp.Outputs["failure_message_error"] == nil {
+					t.Error("expected failure_message_error output")
+				}
+				if !strings.Contains(resp.Error, "Invalid API key") {
+					t.Errorf("expected the original error, got %q", resp.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateFailureMessageTemplate(t *testing.T) {
+	p := &HexPlugin{}
+	resp, err := p.Validate(context.Background(), map[string]any{
+		"api_key":                  "test-api-key",
+		"failure_message_template": "{{.Package",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Valid {
+		t.Fatal("expected invalid config")
+	}
+	found := false
+	for _, e := range resp.Errors {
+		if e.Field == "failure_message_template" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a failure_message_template error, got %+v", resp.Errors)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// mixFilesPattern matches the files: list of the package metadata, written
+// as a list of strings or a ~w sigil.
+var mixFilesPattern = regexp.MustCompile(`files:\s*(?:~w[(\[{]([^)\]}]*)[)\]}]|\[([^\]]*)\])`)
+
+// parseMixFiles extracts the files: list from the package metadata of
+// mix.exs. It reports false when the list is not declared.
+func parseMixFiles(content string) ([]string, bool) {
+	m := mixFilesPattern.FindStringSubmatch(content)
+	if m == nil {
+		return nil, false
+	}
+	if m[2] == "" && m[1] != "" {
+		return strings.Fields(m[1]), true
+	}
+	files := []string{}
+	for _, s := range mixStringPattern.FindAllStringSubmatch(m[2], -1) {
+		files = append(files, s[1])
+	}
+	return files, true
+}
+
+// broadFilesEntry explains why a files: entry may ship more than intended,
+// or returns "" for a focused entry.
+func broadFilesEntry(entry string) string {
+	entry = strings.TrimPrefix(strings.TrimSpace(entry), "./")
+	first, _, _ := strings.Cut(entry, "/")
+	switch {
+	case entry == "" || entry == "." || entry == "*" || strings.HasPrefix(entry, "**"):
+		return "matches the whole project"
+	case first == "test" || first == "tests":
+		return "ships test files and fixtures"
+	case first == "config":
+		return "ships local configuration"
+	case strings.HasPrefix(first, ".env"):
+		return "ships environment files"
+	case first == "_build" || first == "deps":
+		return "ships build output or dependencies"
+	}
+	return ""
+}
+
+// checkFilesHygiene reports the problems of the files: list in mix.exs: a
+// missing list, which falls back to the Hex defaults, and overly broad
+// entries.
+func checkFilesHygiene(content string) []string {
+	files, ok := parseMixFiles(content)
+	if !ok {
+		return []string{"package metadata does not declare files:, so Hex packages its default file list"}
+	}
+	if len(files) == 0 {
+		return []string{"package metadata declares an empty files: list"}
+	}
+	var problems []string
+	for _, entry := range files {
+		if why := broadFilesEntry(entry); why != "" {
+			problems = append(problems, fmt.Sprintf("files: entry %q %s", entry, why))
+		}
+	}
+	return problems
+}
+
+// requireExplicitFiles enforces require_explicit_files on the mix.exs in
+// the working directory. Problems are reported in the files_hygiene output.
+func requireExplicitFiles(cfg *Config, outputs map[string]any) error {
+	content, err := readMixExs(cfg.WorkDir)
+	if err != nil {
+		return fmt.Errorf("require_explicit_files: %w", err)
+	}
+	problems := checkFilesHygiene(content)
+	if len(problems) == 0 {
+		return nil
+	}
+	outputs["files_hygiene"] = problems
+	return fmt.Errorf("require_explicit_files: %s; list only what the package needs in package/0, e.g. files: ~w(lib mix.exs README.md LICENSE CHANGELOG.md), and check the result with package_manifest", strings.Join(problems, "; "))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseMixFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantOK  bool
+	}{
+		{name: "sigil", content: `package: [licenses: ["MIT"], files: ~w(lib mix.exs README.md)]`, want: []string{"lib", "mix.exs", "README.md"}, wantOK: true},
+		{name: "list", content: `files: ["lib", "priv/templates", "mix.exs"],`, want: []string{"lib", "priv/templates", "mix.exs"}, wantOK: true},
+		{name: "missing", content: `package: [licenses: ["MIT"]]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseMixFiles(tt.content)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMixFiles() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckFilesHygiene(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "focused", content: `files: ~w(lib priv/static mix.exs README.md LICENSE)`},
+		{name: "missing", content: `package: [licenses: ["MIT"]]`, want: []string{"package metadata does not declare files:, so Hex packages its default file list"}},
+		{name: "empty", content: `files: []`, want: []string{"package metadata declares an empty files: list"}},
+		{
+			name:    "broad entries",
+			content: `files: ["lib", "./**/*", "test/fixtures", "config", ".env"]`,
+			want: []string{
+				`files: entry "./**/*" matches the whole project`,
+				`files: entry "test/fixtures" ships test files and fixtures`,
+				`files: entry "config" ships local configuration`,
+				`files: entry ".env" ships environment files`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkFilesHygiene(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkFilesHygiene() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRequireExplicitFiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		mixExs      string
+		wantSuccess bool
+		wantError   string
+	}{
+		{name: "explicit files", mixExs: `package: [files: ~w(lib mix.exs)]`, wantSuccess: true},
+		{name: "default files", mixExs: `package: [licenses: ["MIT"]]`, wantError: "require_explicit_files: package metadata does not declare files:"},
+		{name: "broad files", mixExs: `package: [files: ~w(.)]`, wantError: `require_explicit_files: files: entry "." matches the whole project; list only what the package needs`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFile(t, mixExsFile, tt.mixExs)
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "require_explicit_files": true},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if !tt.wantSuccess {
+				if !strings.Contains(resp.Error, tt.wantError) {
+					t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+				}
+				if _, ok := resp.Outputs["files_hygiene"].([]string); !ok {
+					t.Errorf("expected files_hygiene output, got %v", resp.Outputs["files_hygiene"])
+				}
+				if len(mock.Calls) != 0 {
+					t.Errorf("expected no mix commands, got %d", len(mock.Calls))
+				}
+			}
+		})
+	}
+}
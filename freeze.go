@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Actions taken while a release freeze is active, selected by freeze_action.
+const (
+	freezeActionFail = "fail"
+	freezeActionSkip = "skip"
+)
+
+// freezeCheckTimeout bounds the freeze_url request.
+const freezeCheckTimeout = 30 * time.Second
+
+// maxFreezeResponse limits how much of a freeze status is read.
+const maxFreezeResponse = 64 * 1024
+
+// FreezeStatus is the freeze state published by freeze_file or freeze_url.
+type FreezeStatus struct {
+	Frozen bool   `json:"frozen"`
+	Reason string `json:"reason,omitempty"`
+	Until  string `json:"until,omitempty"`
+}
+
+// validateFreezeConfig checks freeze_file, freeze_url and freeze_action.
+func validateFreezeConfig(file, url, action string) *fieldError {
+	if file != "" {
+		if err := validatePath(file); err != nil {
+			return &fieldError{Field: "freeze_file", Err: err}
+		}
+	}
+	if url != "" {
+		if err := validateHTTPURL(url); err != nil {
+			return &fieldError{Field: "freeze_url", Err: err}
+		}
+	}
+	switch action {
+	case "", freezeActionFail, freezeActionSkip:
+		return nil
+	default:
+		return &fieldError{Field: "freeze_action", Err: fmt.Errorf("must be %s or %s", freezeActionFail, freezeActionSkip)}
+	}
+}
+
+// parseFreezeStatus reads a freeze status document. A JSON object is read as
+// a FreezeStatus; any other content declares a freeze with that reason.
+func parseFreezeStatus(data []byte) FreezeStatus {
+	var status FreezeStatus
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "{") && json.Unmarshal([]byte(text), &status) == nil {
+		return status
+	}
+	return FreezeStatus{Frozen: true, Reason: text}
+}
+
+// describe returns the reason of an active freeze for messages.
+func (s FreezeStatus) describe() string {
+	reason := s.Reason
+	if reason == "" {
+		reason = "no reason given"
+	}
+	if s.Until != "" {
+		reason += " (until " + s.Until + ")"
+	}
+	return reason
+}
+
+// readFreezeFile returns the freeze declared by freeze_file. A missing file
+// means there is no freeze.
+func readFreezeFile(workDir, file string) (FreezeStatus, error) {
+	data, err := os.ReadFile(filepath.Join(workDir, file))
+	if errors.Is(err, os.ErrNotExist) {
+		return FreezeStatus{}, nil
+	}
+	if err != nil {
+		return FreezeStatus{}, fmt.Errorf("failed to read freeze_file: %w", err)
+	}
+	return parseFreezeStatus(data), nil
+}
+
+// fetchFreezeStatus returns the freeze published at freeze_url. A 404 means
+// there is no freeze; other failures are errors so an unreachable freeze
+// service never lets a release through silently.
+func (p *HexPlugin) fetchFreezeStatus(ctx context.Context, url string) (FreezeStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, freezeCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return FreezeStatus{}, fmt.Errorf("failed to create freeze request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.getHTTPClient().Do(req)
+	if err != nil {
+		return FreezeStatus{}, fmt.Errorf("freeze check failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return FreezeStatus{}, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return FreezeStatus{}, fmt.Errorf("freeze check failed: %s returned %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFreezeResponse))
+	if err != nil {
+		return FreezeStatus{}, fmt.Errorf("failed to read freeze status: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return FreezeStatus{}, nil
+	}
+	return parseFreezeStatus(data), nil
+}
+
+// checkFreeze consults freeze_file, then freeze_url, and returns the first
+// active freeze.
+func (p *HexPlugin) checkFreeze(ctx context.Context, cfg *Config) (FreezeStatus, error) {
+	if cfg.FreezeFile != "" {
+		status, err := readFreezeFile(cfg.WorkDir, cfg.FreezeFile)
+		if err != nil || status.Frozen {
+			return status, err
+		}
+	}
+	if cfg.FreezeURL != "" {
+		return p.fetchFreezeStatus(ctx, cfg.FreezeURL)
+	}
+	return FreezeStatus{}, nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseFreezeStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want FreezeStatus
+	}{
+		{"plain reason", "End of quarter freeze\n", FreezeStatus{Frozen: true, Reason: "End of quarter freeze"}},
+		{"empty file", "", FreezeStatus{Frozen: true}},
+		{"json active", `{"frozen": true, "reason": "incident", "until": "2026-10-20"}`, FreezeStatus{Frozen: true, Reason: "incident", Until: "2026-10-20"}},
+		{"json lifted", `{"frozen": false}`, FreezeStatus{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseFreezeStatus([]byte(tt.data)); got != tt.want {
+				t.Errorf("parseFreezeStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreezeStatusDescribe(t *testing.T) {
+	if got := (FreezeStatus{Frozen: true}).describe(); got != "no reason given" {
+		t.Errorf("describe() = %q", got)
+	}
+	if got := (FreezeStatus{Frozen: true, Reason: "incident", Until: "monday"}).describe(); got != "incident (until monday)" {
+		t.Errorf("describe() = %q", got)
+	}
+}
+
+func TestValidateFreezeConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		url       string
+		action    string
+		wantField string
+	}{
+		{name: "unset"},
+		{name: "valid", file: ".freeze", url: "https://freeze.example.com/status", action: "skip"},
+		{name: "escaping file", file: "../freeze", wantField: "freeze_file"},
+		{name: "bad url", url: "freeze.example.com", wantField: "freeze_url"},
+		{name: "bad action", action: "wait", wantField: "freeze_action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFreezeConfig(tt.file, tt.url, tt.action)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Field != tt.wantField {
+				t.Errorf("expected error on %s, got %v", tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestExecuteFreeze(t *testing.T) {
+	tests := []struct {
+		name            string
+		freezeFile      string
+		status          int
+		body            string
+		action          string
+		expectedSuccess bool
+		expectedError   string
+		expectedCalls   int
+		expectedSkipped bool
+	}{
+		{
+			name:            "no freeze",
+			status:          http.StatusNotFound,
+			expectedSuccess: true,
+			expectedCalls:   1,
+		},
+		{
+			name:            "lifted freeze",
+			status:          http.StatusOK,
+			body:            `{"frozen": false}`,
+			expectedSuccess: true,
+			expectedCalls:   1,
+		},
+		{
+			name:          "freeze url active",
+			status:        http.StatusOK,
+			body:          `{"frozen": true, "reason": "incident INC-42"}`,
+			expectedError: "release freeze active: incident INC-42",
+		},
+		{
+			name:          "freeze file active",
+			freezeFile:    "Holiday freeze",
+			status:        http.StatusNotFound,
+			expectedError: "release freeze active: Holiday freeze",
+		},
+		{
+			name:            "skip while frozen",
+			status:          http.StatusOK,
+			body:            `{"frozen": true, "reason": "incident"}`,
+			action:          "skip",
+			expectedSuccess: true,
+			expectedSkipped: true,
+		},
+		{
+			name:          "freeze service down",
+			status:        http.StatusInternalServerError,
+			expectedError: "freeze check failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t)
+			if tt.freezeFile != "" {
+				if err := os.WriteFile(filepath.Join(dir, ".freeze"), []byte(tt.freezeFile), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{
+				"api_key":     "test-api-key",
+				"freeze_file": ".freeze",
+				"freeze_url":  server.URL,
+			}
+			if tt.action != "" {
+				config["freeze_action"] = tt.action
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if tt.expectedError != "" && !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, resp.Error)
+			}
+			if len(mock.Calls) != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, len(mock.Calls))
+			}
+			if skipped, _ := resp.Outputs["skipped"].(bool); skipped != tt.expectedSkipped {
+				t.Errorf("expected skipped=%v, got %v", tt.expectedSkipped, resp.Outputs["skipped"])
+			}
+		})
+	}
+}
+
+func TestExecuteFreezeDryRun(t *testing.T) {
+	dir := chdirTemp(t)
+	if err := os.WriteFile(filepath.Join(dir, ".freeze"), []byte("Holiday freeze"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := (&HexPlugin{executor: &MockCommandExecutor{}}).Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"freeze_file": ".freeze"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success || resp.Outputs["freeze_reason"] != "Holiday freeze" {
+		t.Errorf("expected the dry run to report the freeze, got %+v", resp)
+	}
+}
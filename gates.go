@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// licenseGate is the name of the built-in license policy gate.
+const licenseGate = "license"
+
+// docsGate is the name of the built-in ExDoc warnings gate enabled by strict_docs.
+const docsGate = "docs"
+
+// builtinGates maps gate names to the mix arguments they run.
+var builtinGates = map[string][]string{
+	"test":   {"test"},
+	"format": {"format", "--check-formatted"},
+	"credo":  {"credo", "--strict"},
+	"audit":  {"hex.audit"},
+}
+
+// GateResult records the outcome of a single pre-publish gate.
+type GateResult struct {
+	Name       string `json:"name"`
+	Command    string `json:"command,omitempty"`
+	Success    bool   `json:"success"`
+	DurationMs int64  `json:"duration_ms"`
+	Message    string `json:"message,omitempty"`
+	Output     string `json:"output,omitempty"`
+	// Details lists individual findings, such as docs warnings.
+	Details []string `json:"details,omitempty"`
+	// AllowFailure marks gates whose failure does not block the publish.
+	AllowFailure bool `json:"allow_failure,omitempty"`
+}
+
+// knownGates returns the names of all supported gates, sorted.
+func knownGates() []string {
+	names := []string{licenseGate, docsGate}
+	for name := range builtinGates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateGates validates the configured gates. Custom gates must declare their mix args.
+func validateGates(gates []GateConfig, allowedLicenses []string) error {
+	for _, gate := range gates {
+		if gate.Name == "" {
+			return fmt.Errorf("gate name is required")
+		}
+		if _, ok := builtinGates[gate.Name]; !ok && gate.Name != licenseGate && gate.Name != docsGate && len(gate.Args) == 0 {
+			return fmt.Errorf("unknown gate %q: must be one of %s, or define args for a custom gate", gate.Name, strings.Join(knownGates(), ", "))
+		}
+		if gate.Name == licenseGate && len(allowedLicenses) == 0 {
+			return fmt.Errorf("the %s gate requires allowed_licenses", licenseGate)
+		}
+	}
+	return nil
+}
+
+// withDocsGate appends the docs gate when strict_docs is enabled and the
+// gate is not configured explicitly.
+func withDocsGate(gates []GateConfig, strictDocs bool) []GateConfig {
+	if !strictDocs {
+		return gates
+	}
+	for _, g := range gates {
+		if g.Name == docsGate {
+			return gates
+		}
+	}
+	return append(gates, GateConfig{Name: docsGate})
+}
+
+// gateArgs returns the mix arguments a gate runs.
+func (g GateConfig) gateArgs() []string {
+	if len(g.Args) > 0 {
+		return g.Args
+	}
+	return builtinGates[g.Name]
+}
+
+// gateNames returns the names of the configured gates.
+func gateNames(gates []GateConfig) []string {
+	names := make([]string, 0, len(gates))
+	for _, g := range gates {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+// runGates runs every configured gate and returns all results, including failures.
+func (p *HexPlugin) runGates(ctx context.Context, cfg *Config, summary *RunSummary) []GateResult {
+	results := make([]GateResult, 0, len(cfg.Gates))
+
+	for _, gate := range cfg.Gates {
+		start := time.Now()
+		var result GateResult
+		summary.emit(PublishEvent{Event: eventGateStarted, WorkDir: cfg.WorkDir, Gate: gate.Name})
+
+		switch {
+		case gate.Name == licenseGate && len(gate.Args) == 0:
+			result = checkLicenseGate(cfg)
+		case gate.Name == docsGate && len(gate.Args) == 0:
+			result = p.checkDocsGate(ctx, cfg, summary)
+		default:
+			args := gate.gateArgs()
+			output, err := p.runMix(ctx, cfg, summary, args, []string{"MIX_ENV=test"})
+			result = GateResult{
+				Name:    gate.Name,
+				Command: cfg.mixDisplay(args...),
+				Success: err == nil,
+				Output:  string(output),
+			}
+			if err != nil {
+				result.Message = err.Error()
+			}
+		}
+
+		result.DurationMs = time.Since(start).Milliseconds()
+		result.AllowFailure = gate.AllowFailure
+		results = append(results, result)
+
+		event := PublishEvent{Event: eventGatePassed, WorkDir: cfg.WorkDir, Gate: gate.Name, Success: &result.Success, DurationMs: result.DurationMs}
+		if !result.Success {
+			event.Event, event.Error = eventGateFailed, result.Message
+		}
+		summary.emit(event)
+	}
+
+	if summary != nil {
+		summary.Gates = append(summary.Gates, results...)
+	}
+
+	return results
+}
+
+// checkLicenseGate verifies the licenses declared in mix.exs against the allowed list.
+func checkLicenseGate(cfg *Config) GateResult {
+	result := GateResult{Name: licenseGate}
+
+	content, err := readMixExs(cfg.WorkDir)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	licenses := parseMixLicenses(content)
+	if len(licenses) == 0 {
+		result.Message = "no licenses declared in package metadata"
+		return result
+	}
+
+	allowed := make(map[string]bool, len(cfg.AllowedLicenses))
+	for _, l := range cfg.AllowedLicenses {
+		allowed[l] = true
+	}
+
+	var denied []string
+	for _, l := range licenses {
+		if !allowed[l] {
+			denied = append(denied, l)
+		}
+	}
+
+	if len(denied) > 0 {
+		result.Message = fmt.Sprintf("licenses not allowed by policy: %s", strings.Join(denied, ", "))
+		return result
+	}
+
+	result.Success = true
+	result.Message = fmt.Sprintf("licenses allowed: %s", strings.Join(licenses, ", "))
+	return result
+}
+
+// docsWarningPattern matches the first line of a warning printed by mix docs.
+var docsWarningPattern = regexp.MustCompile(`^\s*warning:\s*(.+)$`)
+
+// docsLocationPattern matches the source location printed below a warning.
+var docsLocationPattern = regexp.MustCompile(`([\w./-]+\.(?:ex|exs|md|cheatmd)(?::\d+)?)`)
+
+// parseDocsWarnings extracts ExDoc warnings, such as undefined references
+// and missing docs, with their source location when ExDoc prints one.
+func parseDocsWarnings(output string) []string {
+	lines := strings.Split(output, "\n")
+	var warnings []string
+	for i, line := range lines {
+		m := docsWarningPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		warning := strings.TrimSpace(m[1])
+		// The location follows on one of the next lines, after ExDoc's box drawing
+		for j := i + 1; j < len(lines) && j <= i+6; j++ {
+			if strings.TrimSpace(lines[j]) == "" || docsWarningPattern.MatchString(lines[j]) {
+				break
+			}
+			if loc := docsLocationPattern.FindString(lines[j]); loc != "" {
+				warning = fmt.Sprintf("%s (%s)", warning, loc)
+				break
+			}
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// checkDocsGate builds the docs and fails on any ExDoc warning.
+func (p *HexPlugin) checkDocsGate(ctx context.Context, cfg *Config, summary *RunSummary) GateResult {
+	result := GateResult{Name: docsGate, Command: cfg.mixDisplay("docs")}
+
+	output, err := p.runMix(ctx, cfg, summary, []string{"docs"}, nil)
+	result.Output = string(output)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	result.Details = parseDocsWarnings(string(output))
+	if len(result.Details) > 0 {
+		result.Message = fmt.Sprintf("%d docs warnings: %s", len(result.Details), result.Details[0])
+		return result
+	}
+
+	result.Success = true
+	result.Message = "no docs warnings"
+	return result
+}
+
+// failedGates returns the names of blocking gates that did not pass.
+func failedGates(results []GateResult) []string {
+	var failed []string
+	for _, r := range results {
+		if !r.Success && !r.AllowFailure {
+			failed = append(failed, r.Name)
+		}
+	}
+	return failed
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the gate results of one run.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single gate in the JUnit report.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure describes why a gate failed.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// writeJUnitReport writes the gate results as a JUnit XML file.
+func writeJUnitReport(path, suiteName string, results []GateResult) error {
+	suite := junitTestSuite{Name: suiteName, Tests: len(results)}
+
+	var total int64
+	for _, r := range results {
+		total += r.DurationMs
+		tc := junitTestCase{
+			Name:      r.Name,
+			ClassName: "hex.gates",
+			Time:      formatSeconds(r.DurationMs),
+			SystemOut: r.Output,
+		}
+		if !r.Success {
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: r.Message, Content: r.Output}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = formatSeconds(total)
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode junit report: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create junit directory: %w", err)
+		}
+	}
+
+	data = append([]byte(xml.Header), data...)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write junit report: %w", err)
+	}
+	return nil
+}
+
+// formatSeconds formats a millisecond duration as JUnit seconds.
+func formatSeconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateGates(t *testing.T) {
+	tests := []struct {
+		name        string
+		gates       []GateConfig
+		licenses    []string
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:  "no gates is valid",
+			gates: nil,
+		},
+		{
+			name:  "builtin gates are valid",
+			gates: []GateConfig{{Name: "test"}, {Name: "format"}, {Name: "credo"}, {Name: "audit"}},
+		},
+		{
+			name:     "license gate with allowed licenses is valid",
+			gates:    []GateConfig{{Name: "license"}},
+			licenses: []string{"MIT"},
+		},
+		{
+			name:        "license gate without allowed licenses is invalid",
+			gates:       []GateConfig{{Name: "license"}},
+			expectError: true,
+			errorMsg:    "requires allowed_licenses",
+		},
+		{
+			name:        "unknown gate is invalid",
+			gates:       []GateConfig{{Name: "dialyzer"}},
+			expectError: true,
+			errorMsg:    "unknown gate",
+		},
+		{
+			name:  "custom gate with args is valid",
+			gates: []GateConfig{{Name: "dialyzer", Args: []string{"dialyzer", "--halt-exit-status"}}},
+		},
+		{
+			name:        "gate without name is invalid",
+			gates:       []GateConfig{{Args: []string{"test"}}},
+			expectError: true,
+			errorMsg:    "gate name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGates(tt.gates, tt.licenses)
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckLicenseGate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "mix.exs"), `
+  defp package do
+    [licenses: ["MIT", "GPL-3.0"], links: %{}]
+  end
+`)
+
+	tests := []struct {
+		name          string
+		allowed       []string
+		expectSuccess bool
+		messagePart   string
+	}{
+		{
+			name:          "all licenses allowed",
+			allowed:       []string{"MIT", "GPL-3.0"},
+			expectSuccess: true,
+		},
+		{
+			name:          "denied license fails",
+			allowed:       []string{"MIT", "Apache-2.0"},
+			expectSuccess: false,
+			messagePart:   "GPL-3.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checkLicenseGate(&Config{WorkDir: dir, AllowedLicenses: tt.allowed})
+			if result.Success != tt.expectSuccess {
+				t.Errorf("success: got %v, expected %v (%s)", result.Success, tt.expectSuccess, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.messagePart) {
+				t.Errorf("message: expected to contain %q, got %q", tt.messagePart, result.Message)
+			}
+		})
+	}
+
+	t.Run("missing mix.exs fails", func(t *testing.T) {
+		result := checkLicenseGate(&Config{WorkDir: t.TempDir(), AllowedLicenses: []string{"MIT"}})
+		if result.Success {
+			t.Error("expected failure without mix.exs")
+		}
+	})
+}
+
+func TestExecuteGates(t *testing.T) {
+	chdirTemp(t)
+
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			if args[0] == "credo" {
+				return []byte("1 issue found"), errors.New("exit status 1")
+			}
+			return []byte("ok"), nil
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"api_key":    "test-key",
+			"gates":      []any{"test", "credo", "format"},
+			"junit_path": "reports/gates.xml",
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Success {
+		t.Fatal("expected failure when a gate fails")
+	}
+	if !strings.Contains(resp.Error, "pre-publish gates failed: credo") {
+		t.Errorf("unexpected error: %s", resp.Error)
+	}
+
+	// All gates run, but the publish itself never happens
+	if len(mock.Calls) != 3 {
+		t.Fatalf("expected 3 gate calls, got %d", len(mock.Calls))
+	}
+	for _, call := range mock.Calls {
+		if call.Args[0] == "hex.publish" {
+			t.Error("publish must not run when gates fail")
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join("reports", "gates.xml"))
+	if err != nil {
+		t.Fatalf("read junit: %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("decode junit: %v", err)
+	}
+	if len(report.Suites) != 1 {
+		t.Fatalf("expected 1 suite, got %d", len(report.Suites))
+	}
+	suite := report.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 1 {
+		t.Errorf("expected 3 tests with 1 failure, got %d/%d", suite.Tests, suite.Failures)
+	}
+	for _, tc := range suite.Cases {
+		if (tc.Name == "credo") != (tc.Failure != nil) {
+			t.Errorf("unexpected failure state for %s", tc.Name)
+		}
+	}
+
+	t.Run("passing gates allow publish", func(t *testing.T) {
+		mock := &MockCommandExecutor{}
+		p := &HexPlugin{executor: mock}
+
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "test-key", "gates": []any{"test"}},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		if len(mock.Calls) != 2 || mock.Calls[1].Args[0] != "hex.publish" {
+			t.Errorf("expected gate then publish, got %+v", mock.Calls)
+		}
+		if _, ok := resp.Outputs["gates"].([]GateResult); !ok {
+			t.Error("expected gate results in outputs")
+		}
+	})
+}
+
+func TestParseDocsWarnings(t *testing.T) {
+	output := `Generating docs...
+    warning: documentation references function "MyLib.missing/1" but it is undefined or private
+    │
+ 12 │   See MyLib.missing/1.
+    │
+    └─ lib/my_lib.ex:12: MyLib.run/0
+
+warning: documentation references module Other but it is hidden
+  lib/my_lib/other.ex:3: MyLib.Other (module)
+
+warning: MyLib.helper/0 is public but has no documentation
+View "html" docs at "doc/index.html"
+`
+
+	warnings := parseDocsWarnings(output)
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, got %d: %v", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], "MyLib.missing/1") || !strings.HasSuffix(warnings[0], "(lib/my_lib.ex:12)") {
+		t.Errorf("unexpected first warning: %q", warnings[0])
+	}
+	if !strings.HasSuffix(warnings[1], "(lib/my_lib/other.ex:3)") {
+		t.Errorf("unexpected second warning: %q", warnings[1])
+	}
+	if strings.Contains(warnings[2], "(") {
+		t.Errorf("warning without location should not get one: %q", warnings[2])
+	}
+
+	if len(parseDocsWarnings("Generating docs...\nView \"html\" docs")) != 0 {
+		t.Error("expected no warnings for clean output")
+	}
+}
+
+func TestStrictDocs(t *testing.T) {
+	run := func(docsOutput string) (*MockCommandExecutor, *plugin.ExecuteResponse) {
+		mock := &MockCommandExecutor{
+			RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+				if args[0] == "docs" {
+					return []byte(docsOutput), nil
+				}
+				return []byte("Building my_lib 1.0.0"), nil
+			},
+		}
+		p := &HexPlugin{executor: mock}
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "k", "strict_docs": true},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return mock, resp
+	}
+
+	t.Run("warnings block the publish", func(t *testing.T) {
+		mock, resp := run("warning: documentation references type t() but it is undefined\n  lib/a.ex:1: A")
+		if resp.Success || !strings.Contains(resp.Error, "docs") {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+		if len(mock.Calls) != 1 {
+			t.Errorf("expected only mix docs to run, got %d calls", len(mock.Calls))
+		}
+		results := resp.Outputs["gates"].([]GateResult)
+		if len(results[0].Details) != 1 {
+			t.Errorf("expected warning details, got %+v", results[0])
+		}
+	})
+
+	t.Run("clean docs publish", func(t *testing.T) {
+		mock, resp := run("Generating docs...")
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		if len(mock.Calls) != 2 || mock.Calls[0].Args[0] != "docs" {
+			t.Errorf("unexpected calls: %+v", mock.Calls)
+		}
+	})
+
+	t.Run("docs gate is not duplicated", func(t *testing.T) {
+		gates := withDocsGate([]GateConfig{{Name: docsGate, AllowFailure: true}}, true)
+		if len(gates) != 1 || !gates[0].AllowFailure {
+			t.Errorf("unexpected gates: %+v", gates)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Git state modes selected by git_state.
+const (
+	gitStateIgnore = "ignore"
+	gitStateWarn   = "warn"
+	gitStateFail   = "fail"
+)
+
+// Results of comparing a release context field with git.
+const (
+	gitFieldMatch    = "match"
+	gitFieldDiverged = "diverged"
+	// gitFieldUnknown is a field git cannot answer, such as the branch of a
+	// detached HEAD.
+	gitFieldUnknown = "unknown"
+	// gitFieldSkipped is a field the release context leaves empty.
+	gitFieldSkipped = "skipped"
+)
+
+// validateGitState checks that git_state names a known mode.
+func validateGitState(mode string) error {
+	switch mode {
+	case "", gitStateIgnore, gitStateWarn, gitStateFail:
+		return nil
+	default:
+		return fmt.Errorf("must be %s, %s or %s", gitStateIgnore, gitStateWarn, gitStateFail)
+	}
+}
+
+// GitStateField compares one release context field with git.
+type GitStateField struct {
+	Field   string `json:"field"`
+	Context string `json:"context"`
+	Git     string `json:"git"`
+	Status  string `json:"status"`
+}
+
+// GitState compares the release context with the git state of work_dir.
+type GitState struct {
+	Fields []GitStateField `json:"fields"`
+	// Diverged names the fields whose context and git values differ.
+	Diverged []string `json:"diverged"`
+}
+
+// add records a field, comparing the values with match when both are known.
+func (s *GitState) add(field, contextValue, gitValue string, match func(string, string) bool) {
+	f := GitStateField{Field: field, Context: contextValue, Git: gitValue}
+	switch {
+	case contextValue == "":
+		f.Status = gitFieldSkipped
+	case gitValue == "":
+		f.Status = gitFieldUnknown
+	case match(contextValue, gitValue):
+		f.Status = gitFieldMatch
+	default:
+		f.Status = gitFieldDiverged
+		s.Diverged = append(s.Diverged, field)
+	}
+	s.Fields = append(s.Fields, f)
+}
+
+// err explains the divergence, or returns nil when there is none.
+func (s *GitState) err() error {
+	if len(s.Diverged) == 0 {
+		return nil
+	}
+	var diffs []string
+	for _, f := range s.Fields {
+		if f.Status == gitFieldDiverged {
+			diffs = append(diffs, fmt.Sprintf("%s is %s in the release but %s in git", f.Field, f.Context, f.Git))
+		}
+	}
+	return fmt.Errorf("release context does not match the git state of work_dir: %s", strings.Join(diffs, "; "))
+}
+
+// tagVersion returns the version a release tag names.
+func tagVersion(tag string) string {
+	return strings.TrimPrefix(tag, "v")
+}
+
+// compareGitState reads HEAD, its branch and the tags pointing at it in
+// work_dir and compares them with the release context, catching pipelines
+// whose context describes another repository or checkout.
+func (p *HexPlugin) compareGitState(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, summary *RunSummary) (*GitState, error) {
+	git := func(args ...string) (string, error) {
+		output, err := p.runCommand(ctx, summary, "git", args, nil, cfg.WorkDir)
+		if err != nil {
+			return "", fmt.Errorf("git %s failed: %v\nOutput: %s", strings.Join(args, " "), err, string(output))
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	head, err := git("rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	branch, err := git("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if branch == "HEAD" {
+		// CI checkouts often detach HEAD, leaving no branch to compare
+		branch = ""
+	}
+	output, err := git("tag", "--points-at", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	tags := strings.Fields(output)
+
+	state := &GitState{}
+	state.add("version", strings.TrimPrefix(releaseCtx.Version, "v"), strings.Join(tags, ","), func(version, _ string) bool {
+		return slices.ContainsFunc(tags, func(tag string) bool { return tagVersion(tag) == version })
+	})
+	state.add("tag", releaseCtx.TagName, strings.Join(tags, ","), func(tag, _ string) bool {
+		return slices.Contains(tags, tag)
+	})
+	state.add("commit", releaseCtx.CommitSHA, head, shaMatches)
+	state.add("branch", releaseCtx.Branch, branch, func(a, b string) bool { return a == b })
+	return state, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteGitState(t *testing.T) {
+	const head = "0123456789abcdef0123456789abcdef01234567"
+	release := plugin.ReleaseContext{Version: "1.0.0", TagName: "v1.0.0", CommitSHA: "0123456", Branch: "main"}
+
+	tests := []struct {
+		name         string
+		mode         string
+		release      plugin.ReleaseContext
+		branch       string
+		tags         string
+		wantSuccess  bool
+		wantStatuses map[string]string
+		wantDiverged []string
+		wantError    string
+		wantState    bool
+	}{
+		{
+			name:         "matching state",
+			mode:         "fail",
+			release:      release,
+			branch:       "main",
+			tags:         "v1.0.0\n",
+			wantSuccess:  true,
+			wantStatuses: map[string]string{"version": gitFieldMatch, "tag": gitFieldMatch, "commit": gitFieldMatch, "branch": gitFieldMatch},
+			wantState:    true,
+		},
+		{
+			name:         "detached HEAD without tags",
+			mode:         "fail",
+			release:      release,
+			branch:       "HEAD",
+			wantSuccess:  true,
+			wantStatuses: map[string]string{"version": gitFieldUnknown, "tag": gitFieldUnknown, "commit": gitFieldMatch, "branch": gitFieldUnknown},
+			wantState:    true,
+		},
+		{
+			name:         "empty context fields are skipped",
+			mode:         "fail",
+			release:      plugin.ReleaseContext{Version: "1.0.0"},
+			branch:       "main",
+			tags:         "v1.0.0\n",
+			wantSuccess:  true,
+			wantStatuses: map[string]string{"version": gitFieldMatch, "tag": gitFieldSkipped, "commit": gitFieldSkipped, "branch": gitFieldSkipped},
+			wantState:    true,
+		},
+		{
+			name:         "divergence warns",
+			mode:         "warn",
+			release:      release,
+			branch:       "develop",
+			tags:         "v0.9.0\n",
+			wantSuccess:  true,
+			wantDiverged: []string{"version", "tag", "branch"},
+			wantState:    true,
+		},
+		{
+			name:         "divergence fails",
+			mode:         "fail",
+			release:      plugin.ReleaseContext{Version: "1.0.0", CommitSHA: "fedcba9"},
+			branch:       "main",
+			tags:         "v1.0.0\n",
+			wantDiverged: []string{"commit"},
+			wantError:    "commit is fedcba9 in the release but " + head + " in git",
+			wantState:    true,
+		},
+		{
+			name:        "ignored by default",
+			release:     release,
+			branch:      "develop",
+			wantSuccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if name != "git" {
+						return []byte("Building my_lib 1.0.0"), nil
+					}
+					switch strings.Join(args, " ") {
+					case "rev-parse HEAD":
+						return []byte(head + "\n"), nil
+					case "rev-parse --abbrev-ref HEAD":
+						return []byte(tt.branch + "\n"), nil
+					case "tag --points-at HEAD":
+						return []byte(tt.tags), nil
+					}
+					t.Fatalf("unexpected git command: %v", args)
+					return nil, nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			if tt.mode != "" {
+				config["git_state"] = tt.mode
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: tt.release,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+			}
+
+			state, ok := resp.Outputs["git_state"].(*GitState)
+			if ok != tt.wantState {
+				t.Fatalf("git_state = %v, want present %v", resp.Outputs["git_state"], tt.wantState)
+			}
+			if !ok {
+				for _, call := range mock.Calls {
+					if call.Name == "git" {
+						t.Errorf("unexpected git command: %v", call.Args)
+					}
+				}
+				return
+			}
+			for _, f := range state.Fields {
+				if want, ok := tt.wantStatuses[f.Field]; ok && f.Status != want {
+					t.Errorf("%s = %s, want %s (%+v)", f.Field, f.Status, want, f)
+				}
+			}
+			if !reflect.DeepEqual(state.Diverged, tt.wantDiverged) {
+				t.Errorf("diverged = %v, want %v", state.Diverged, tt.wantDiverged)
+			}
+		})
+	}
+}
+
+func TestValidateGitState(t *testing.T) {
+	for _, mode := range []string{"", "ignore", "warn", "fail"} {
+		if err := validateGitState(mode); err != nil {
+			t.Errorf("validateGitState(%q) = %v", mode, err)
+		}
+	}
+	if err := validateGitState("strict"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
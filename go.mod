@@ -2,7 +2,12 @@ module github.com/relicta-tech/plugin-hex
 
 go 1.22.7
 
-require github.com/relicta-tech/relicta-plugin-sdk v1.0.0
+require (
+	github.com/mattn/go-isatty v0.0.17
+	github.com/relicta-tech/relicta-plugin-sdk v1.0.0
+	golang.org/x/sys v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/fatih/color v1.7.0 // indirect
@@ -11,10 +16,8 @@ require (
 	github.com/hashicorp/go-plugin v1.6.2 // indirect
 	github.com/hashicorp/yamux v0.1.1 // indirect
 	github.com/mattn/go-colorable v0.1.4 // indirect
-	github.com/mattn/go-isatty v0.0.17 // indirect
 	github.com/oklog/run v1.0.0 // indirect
 	golang.org/x/net v0.29.0 // indirect
-	golang.org/x/sys v0.25.0 // indirect
 	golang.org/x/text v0.18.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240903143218-8af14fe29dc1 // indirect
 	google.golang.org/grpc v1.68.0 // indirect
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHexAPIURL is the public Hex.pm API.
+const defaultHexAPIURL = "https://hex.pm/api"
+
+// hexAPI is a minimal client for the Hex.pm HTTP API.
+type hexAPI struct {
+	client  HTTPClient
+	baseURL string
+	apiKey  string
+	cache   *apiCache
+}
+
+// hexAPI returns an API client for the configured registry and key.
+func (p *HexPlugin) hexAPI(cfg *Config) *hexAPI {
+	base := cfg.APIURL
+	if base == "" {
+		base = defaultHexAPIURL
+	}
+	return &hexAPI{
+		client:  p.getHTTPClient(),
+		baseURL: strings.TrimSuffix(base, "/"),
+		apiKey:  cfg.APIKey,
+		cache:   newAPICache(cfg),
+	}
+}
+
+// HexKeyPermission is a single permission granted to an API key.
+type HexKeyPermission struct {
+	Domain   string `json:"domain"`
+	Resource string `json:"resource,omitempty"`
+}
+
+// HexKey is an API key as returned by the Hex.pm API.
+type HexKey struct {
+	Name        string             `json:"name"`
+	Permissions []HexKeyPermission `json:"permissions,omitempty"`
+	Secret      string             `json:"secret,omitempty"`
+	InsertedAt  time.Time          `json:"inserted_at"`
+	RevokedAt   *time.Time         `json:"revoked_at,omitempty"`
+	// ExpiresAt is set by registries that issue expiring keys.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// AuthingKey marks the key that authenticated the request.
+	AuthingKey bool `json:"authing_key,omitempty"`
+}
+
+// HexUser is the user owning the authenticating key.
+type HexUser struct {
+	Username string `json:"username"`
+	// TFAEnabled is nil when the registry does not report two-factor status.
+	TFAEnabled *bool `json:"tfa_enabled,omitempty"`
+}
+
+// hexAPIError is a non-success response from the Hex.pm API.
+type hexAPIError struct {
+	Method  string
+	Path    string
+	Status  int
+	Message string
+}
+
+// Error implements error.
+func (e *hexAPIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("hex api %s %s returned %d: %s", e.Method, e.Path, e.Status, e.Message)
+	}
+	return fmt.Sprintf("hex api %s %s returned %d", e.Method, e.Path, e.Status)
+}
+
+// Rate limit handling of the Hex API.
+const (
+	rateLimitRetries = 3
+	maxRateLimitWait = time.Minute
+)
+
+// hexAPIPageSize is the page size of paginated Hex API listings.
+const hexAPIPageSize = 100
+
+// maxHexAPIPages bounds paginated listings.
+const maxHexAPIPages = 1000
+
+// rateLimitDelay returns how long to wait before retrying a rate limited
+// request, from Retry-After or X-RateLimit-Reset, capped at maxRateLimitWait.
+func rateLimitDelay(header http.Header, now time.Time) time.Duration {
+	wait := time.Second
+	if secs, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		wait = time.Duration(secs) * time.Second
+	} else if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		wait = time.Unix(reset, 0).Sub(now)
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return min(wait, maxRateLimitWait)
+}
+
+// do sends a request and decodes a JSON response into out when it is non-nil.
+func (a *hexAPI) do(ctx context.Context, method, path string, body, out any) error {
+	data, _, err := a.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode hex api response: %w", err)
+		}
+	}
+	return nil
+}
+
+// send sends a request, waiting out rate limits, and returns the body and
+// headers of a successful response.
+func (a *hexAPI) send(ctx context.Context, method, path string, body any) ([]byte, http.Header, error) {
+	for attempt := 0; ; attempt++ {
+		data, header, err := a.sendOnce(ctx, method, path, body)
+		var apiErr *hexAPIError
+		if !errors.As(err, &apiErr) || apiErr.Status != http.StatusTooManyRequests || attempt >= rateLimitRetries {
+			return data, header, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, err
+		case <-time.After(rateLimitDelay(header, time.Now())):
+		}
+	}
+}
+
+// sendOnce sends a single request. GET responses are revalidated against
+// the cache with If-None-Match.
+func (a *hexAPI) sendOnce(ctx context.Context, method, path string, body any) ([]byte, http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", a.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Revalidate cached GET responses instead of downloading them again
+	var cached *cachedResponse
+	if method == http.MethodGet {
+		if c, ok := a.cache.get(req.URL.String(), a.apiKey); ok {
+			cached = c
+			req.Header.Set("If-None-Match", c.ETag)
+		}
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("hex api %s %s failed: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read hex api response: %w", err)
+	}
+
+	status := resp.StatusCode
+	if status == http.StatusNotModified && cached != nil {
+		data, status = cached.Body, http.StatusOK
+	} else if method == http.MethodGet && status >= 200 && status < 300 {
+		a.cache.put(req.URL.String(), a.apiKey, resp.Header.Get("ETag"), data)
+	}
+
+	if status < 200 || status >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		return nil, resp.Header, &hexAPIError{Method: method, Path: path, Status: status, Message: apiErr.Message}
+	}
+	return data, resp.Header, nil
+}
+
+// linkNextPattern matches the rel="next" entry of a Link header.
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// nextPagePath returns the API path of the next page from a Link header,
+// and whether the header was present at all.
+func (a *hexAPI) nextPagePath(header http.Header) (string, bool) {
+	link := header.Get("Link")
+	if link == "" {
+		return "", false
+	}
+	m := linkNextPattern.FindStringSubmatch(link)
+	if m == nil {
+		return "", true
+	}
+	return strings.TrimPrefix(m[1], a.baseURL), true
+}
+
+// pagePath adds the page parameter to path.
+func pagePath(path string, page int) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%spage=%d", path, sep, page)
+}
+
+// getAllPages fetches every page of a listing. It follows Link rel="next"
+// headers when the registry sends them, and otherwise requests numbered
+// pages until one holds fewer than hexAPIPageSize items.
+func getAllPages[T any](ctx context.Context, a *hexAPI, path string) ([]T, error) {
+	var all []T
+	next := pagePath(path, 1)
+	for page := 1; page <= maxHexAPIPages; page++ {
+		data, header, err := a.send(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		var items []T
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &items); err != nil {
+				return nil, fmt.Errorf("failed to decode hex api response: %w", err)
+			}
+		}
+		all = append(all, items...)
+
+		if link, ok := a.nextPagePath(header); ok {
+			if link == "" {
+				return all, nil
+			}
+			next = link
+			continue
+		}
+		if len(items) < hexAPIPageSize {
+			return all, nil
+		}
+		next = pagePath(path, page+1)
+	}
+	return nil, fmt.Errorf("hex api listing %s exceeded %d pages", path, maxHexAPIPages)
+}
+
+// listKeys returns the API keys of the authenticated user.
+func (a *hexAPI) listKeys(ctx context.Context) ([]HexKey, error) {
+	var keys []HexKey
+	if err := a.do(ctx, http.MethodGet, "/keys", nil, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// currentUser returns the user owning the API key.
+func (a *hexAPI) currentUser(ctx context.Context) (*HexUser, error) {
+	var user HexUser
+	if err := a.do(ctx, http.MethodGet, "/users/me", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// userExists reports whether the registry has a user with the given
+// username or email.
+func (a *hexAPI) userExists(ctx context.Context, name string) (bool, error) {
+	err := a.do(ctx, http.MethodGet, "/users/"+url.PathEscape(name), nil, nil)
+	var apiErr *hexAPIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// getOrganization fetches an organization, which only its members may do.
+func (a *hexAPI) getOrganization(ctx context.Context, organization string) error {
+	return a.do(ctx, http.MethodGet, "/orgs/"+url.PathEscape(organization), nil, nil)
+}
+
+// createKey generates a new API key with the given permissions.
+func (a *hexAPI) createKey(ctx context.Context, name string, permissions []HexKeyPermission) (*HexKey, error) {
+	body := map[string]any{"name": name, "permissions": permissions}
+	var key HexKey
+	if err := a.do(ctx, http.MethodPost, "/keys", body, &key); err != nil {
+		return nil, err
+	}
+	if key.Secret == "" {
+		return nil, fmt.Errorf("hex api did not return the secret of key %q", name)
+	}
+	return &key, nil
+}
+
+// revokeKey revokes the named API key.
+func (a *hexAPI) revokeKey(ctx context.Context, name string) error {
+	return a.do(ctx, http.MethodDelete, "/keys/"+url.PathEscape(name), nil, nil)
+}
+
+// repoPath returns the API path prefix of a repository: empty for hex.pm,
+// the organization repository for private packages.
+func repoPath(organization string) string {
+	if organization != "" {
+		return "/repos/" + url.PathEscape(organization)
+	}
+	return ""
+}
+
+// packagePath returns the API path of a package.
+func packagePath(organization, name string) string {
+	return repoPath(organization) + "/packages/" + url.PathEscape(name)
+}
+
+// releasePath returns the API path of a package release, scoped to the
+// organization repository for private packages.
+func releasePath(organization, name, version string) string {
+	return packagePath(organization, name) + "/releases/" + url.PathEscape(version)
+}
+
+// HexRetirement is the retirement status of a release.
+type HexRetirement struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+}
+
+// HexRelease is a package release as listed by the Hex.pm API.
+type HexRelease struct {
+	Version    string         `json:"version"`
+	InsertedAt time.Time      `json:"inserted_at"`
+	Retirement *HexRetirement `json:"retirement,omitempty"`
+}
+
+// HexPackage is a package as returned by the Hex.pm API.
+type HexPackage struct {
+	Name     string       `json:"name"`
+	Releases []HexRelease `json:"releases"`
+}
+
+// HexOwner is an owner of a package.
+type HexOwner struct {
+	Username string `json:"username"`
+	Level    string `json:"level,omitempty"`
+}
+
+// listPackages returns every package of the repository, across all pages.
+func (a *hexAPI) listPackages(ctx context.Context, organization string) ([]HexPackage, error) {
+	return getAllPages[HexPackage](ctx, a, repoPath(organization)+"/packages")
+}
+
+// listReleases returns every release of a package. The package endpoint
+// embeds the full release list, however many versions there are.
+func (a *hexAPI) listReleases(ctx context.Context, organization, name string) ([]HexRelease, error) {
+	var pkg HexPackage
+	if err := a.do(ctx, http.MethodGet, packagePath(organization, name), nil, &pkg); err != nil {
+		return nil, err
+	}
+	return pkg.Releases, nil
+}
+
+// listOwners returns every owner of a package, across all pages.
+func (a *hexAPI) listOwners(ctx context.Context, organization, name string) ([]HexOwner, error) {
+	return getAllPages[HexOwner](ctx, a, packagePath(organization, name)+"/owners")
+}
+
+// releaseExists reports whether the registry serves the package release.
+func (a *hexAPI) releaseExists(ctx context.Context, organization, name, version string) (bool, error) {
+	err := a.do(ctx, http.MethodGet, releasePath(organization, name, version), nil, nil)
+	var apiErr *hexAPIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitDelay(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"default", http.Header{}, time.Second},
+		{"retry after", http.Header{"Retry-After": {"5"}}, 5 * time.Second},
+		{"reset", http.Header{"X-Ratelimit-Reset": {"1800000010"}}, 10 * time.Second},
+		{"reset passed", http.Header{"X-Ratelimit-Reset": {"1799999990"}}, 0},
+		{"capped", http.Header{"Retry-After": {"3600"}}, maxRateLimitWait},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rateLimitDelay(tt.header, now); got != tt.want {
+				t.Errorf("rateLimitDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHexAPIPaths(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{packagePath("", "my_lib"), "/packages/my_lib"},
+		{packagePath("acme", "my_lib"), "/repos/acme/packages/my_lib"},
+		{pagePath("/packages", 2), "/packages?page=2"},
+		{pagePath("/packages?sort=name", 3), "/packages?sort=name&page=3"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestHexAPIListPackagesPagination(t *testing.T) {
+	const total = 250
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		var names []string
+		for i := (page - 1) * hexAPIPageSize; i < min(page*hexAPIPageSize, total); i++ {
+			names = append(names, fmt.Sprintf(`{"name":"pkg_%d"}`, i))
+		}
+		_, _ = w.Write([]byte("[" + strings.Join(names, ",") + "]"))
+	}))
+	defer server.Close()
+
+	api := (&HexPlugin{}).hexAPI(&Config{APIKey: "key", APIURL: server.URL})
+	packages, err := api.listPackages(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("listPackages() error = %v", err)
+	}
+	if len(packages) != total || packages[total-1].Name != "pkg_249" {
+		t.Errorf("expected %d packages, got %d", total, len(packages))
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 page requests, got %d", requests)
+	}
+}
+
+func TestHexAPIListOwnersFollowsLinkHeader(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/packages/my_lib/owners" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/packages/my_lib/owners?page=2>; rel="next"`, server.URL))
+			_, _ = w.Write([]byte(`[{"username":"jane","level":"full"}]`))
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/packages/my_lib/owners?page=1>; rel="first"`, server.URL))
+			_, _ = w.Write([]byte(`[{"username":"joe","level":"maintainer"}]`))
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	}))
+	defer server.Close()
+
+	api := (&HexPlugin{}).hexAPI(&Config{APIKey: "key", APIURL: server.URL})
+	owners, err := api.listOwners(context.Background(), "", "my_lib")
+	if err != nil {
+		t.Fatalf("listOwners() error = %v", err)
+	}
+	if len(owners) != 2 || owners[1].Username != "joe" {
+		t.Errorf("unexpected owners: %+v", owners)
+	}
+}
+
+func TestHexAPIListReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/packages/my_lib" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"my_lib","releases":[{"version":"1.1.0"},{"version":"1.0.0","retirement":{"reason":"security","message":"CVE"}}]}`))
+	}))
+	defer server.Close()
+
+	api := (&HexPlugin{}).hexAPI(&Config{APIKey: "key", APIURL: server.URL})
+	releases, err := api.listReleases(context.Background(), "acme", "my_lib")
+	if err != nil {
+		t.Fatalf("listReleases() error = %v", err)
+	}
+	if len(releases) != 2 || releases[1].Retirement == nil || releases[1].Retirement.Reason != "security" {
+		t.Errorf("unexpected releases: %+v", releases)
+	}
+}
+
+func TestHexAPIRetriesRateLimit(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	api := (&HexPlugin{}).hexAPI(&Config{APIKey: "key", APIURL: server.URL})
+	if _, err := api.listKeys(context.Background()); err != nil {
+		t.Fatalf("listKeys() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected a retry after the rate limit, got %d requests", requests)
+	}
+}
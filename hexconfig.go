@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// hexConfigKeys are the mix hex.config settings hex_config may set. The API
+// key is deliberately absent: it comes from api_key and never touches disk.
+var hexConfigKeys = []string{
+	"api_url",
+	"cacerts_path",
+	"http_concurrency",
+	"http_proxy",
+	"http_timeout",
+	"https_proxy",
+	"mirror_url",
+	"no_verify_repo_origin",
+	"offline",
+	"unsafe_https",
+	"unsafe_registry",
+}
+
+// HexConfigEntry is one mix hex.config setting.
+type HexConfigEntry struct {
+	Key   string
+	Value string
+}
+
+// parseHexConfig decodes the hex_config map into entries sorted by key.
+func parseHexConfig(raw map[string]any) ([]HexConfigEntry, *fieldError) {
+	val, ok := raw["hex_config"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, &fieldError{Field: "hex_config", Err: fmt.Errorf("must be an object")}
+	}
+
+	entries := make([]HexConfigEntry, 0, len(obj))
+	for key, v := range obj {
+		switch v.(type) {
+		case string, bool, int, int64, float64:
+		default:
+			return nil, &fieldError{Field: "hex_config." + key, Err: fmt.Errorf("must be a string, number or boolean")}
+		}
+		entries = append(entries, HexConfigEntry{Key: key, Value: fmt.Sprint(v)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// validateHexConfig checks the keys and values of hex_config.
+func validateHexConfig(raw map[string]any) []*fieldError {
+	entries, ferr := parseHexConfig(raw)
+	if ferr != nil {
+		return []*fieldError{ferr}
+	}
+	var errs []*fieldError
+	for _, e := range entries {
+		field := "hex_config." + e.Key
+		if !slices.Contains(hexConfigKeys, e.Key) {
+			errs = append(errs, &fieldError{Field: field, Err: fmt.Errorf("unsupported key: must be one of %s", strings.Join(hexConfigKeys, ", "))})
+			continue
+		}
+		if e.Value == "" || strings.ContainsAny(e.Value, "\x00\r\n") {
+			errs = append(errs, &fieldError{Field: field, Err: fmt.Errorf("must be a non-empty single-line value")})
+		}
+	}
+	return errs
+}
+
+// args returns the mix hex.config arguments that apply the entry.
+func (e HexConfigEntry) args() []string {
+	return []string{"hex.config", e.Key, e.Value}
+}
+
+// applyHexConfig writes hex_config into hex_home, or else into a fresh
+// HEX_HOME used for the rest of the run, so the settings never leak into the
+// user's own Hex configuration. The returned cleanup removes a fresh HEX_HOME.
+func (p *HexPlugin) applyHexConfig(ctx context.Context, cfg *Config, env []string, summary *RunSummary) (func(), error) {
+	home := cfg.HexHome
+	cleanup := func() {}
+	if home == "" {
+		var err error
+		if home, err = os.MkdirTemp("", "relicta-hex-home-"); err != nil {
+			return nil, fmt.Errorf("failed to create HEX_HOME: %w", err)
+		}
+		cleanup = func() { _ = os.RemoveAll(home) }
+		cfg.buildEnv = append(slices.Clip(cfg.buildEnv), "HEX_HOME="+home)
+	}
+
+	for _, e := range cfg.HexConfig {
+		if output, err := p.runMix(ctx, cfg, summary, e.args(), env); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("mix hex.config %s failed: %v\nOutput: %s", e.Key, err, string(output))
+		}
+	}
+	summary.debugf("applied %d hex_config setting(s) in %s", len(cfg.HexConfig), home)
+	return cleanup, nil
+}
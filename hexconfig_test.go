@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateHexConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{name: "valid", value: map[string]any{"offline": false, "http_proxy": "http://proxy:3128", "http_timeout": 30}},
+		{name: "not an object", value: "offline", wantErr: "hex_config: must be an object"},
+		{name: "unsupported key", value: map[string]any{"api_key": "secret"}, wantErr: "hex_config.api_key: unsupported key"},
+		{name: "nested value", value: map[string]any{"offline": []any{true}}, wantErr: "hex_config.offline: must be a string, number or boolean"},
+		{name: "multi-line value", value: map[string]any{"http_proxy": "a\nb"}, wantErr: "must be a non-empty single-line value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateHexConfig(map[string]any{"hex_config": tt.value})
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestExecuteHexConfig(t *testing.T) {
+	var homes []string
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			for _, kv := range env {
+				if home, ok := strings.CutPrefix(kv, "HEX_HOME="); ok {
+					homes = append(homes, home)
+				}
+			}
+			return []byte("mock output"), nil
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	config := map[string]any{
+		"api_key":    "test-api-key",
+		"hex_config": map[string]any{"unsafe_https": true, "http_proxy": "http://proxy:3128"},
+	}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  config,
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+
+	var calls [][]string
+	for _, c := range mock.Calls {
+		calls = append(calls, c.Args)
+	}
+	want := [][]string{
+		{"hex.config", "http_proxy", "http://proxy:3128"},
+		{"hex.config", "unsafe_https", "true"},
+		{"hex.publish", "--yes"},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	if len(homes) != 3 || homes[0] != homes[1] || homes[1] != homes[2] {
+		t.Fatalf("expected every command to share one HEX_HOME, got %v", homes)
+	}
+	if _, err := os.Stat(homes[0]); !os.IsNotExist(err) {
+		t.Errorf("expected HEX_HOME %s to be removed, got %v", homes[0], err)
+	}
+
+	resp, _ = p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  config,
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+		DryRun:  true,
+	})
+	wantDry := []string{"mix hex.config http_proxy http://proxy:3128", "mix hex.config unsafe_https true"}
+	if !reflect.DeepEqual(resp.Outputs["hex_config"], wantDry) {
+		t.Errorf("hex_config = %v, want %v", resp.Outputs["hex_config"], wantDry)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// remediationHints maps lowercase markers of common hex and mix failures to
+// what fixes them, checked in order.
+var remediationHints = []struct {
+	markers []string
+	hint    string
+}{
+	{
+		markers: []string{"no package metadata", "missing metadata fields", "missing: licenses", "missing: description"},
+		hint:    "add a package/0 function to mix.exs with description, licenses and links, and reference it as package: package() in project/0",
+	},
+	{
+		markers: []string{"key revoked", "api key revoked", "key has been revoked"},
+		hint:    "the API key was revoked: generate a new one with mix hex.user key generate and update HEX_API_KEY",
+	},
+	{
+		markers: []string{"already published", "must include the --replace flag"},
+		hint:    "this version is already on Hex: bump the version, or set replace: true if Hex still allows replacing it",
+	},
+	{
+		markers: []string{"tarball too large", "package exceeds", "too big"},
+		hint:    "the package is over the Hex size limit: narrow the files list in package/0 to exclude build output and fixtures",
+	},
+	{
+		markers: []string{"invalid api key", "authentication failed"},
+		hint:    "check that HEX_API_KEY holds a current key with api:write permission",
+	},
+	{
+		markers: []string{"missing write permission", "not authorized to publish"},
+		hint:    "the key's user must own the package: add them with mix hex.owner add, or use a key with api:write permission",
+	},
+}
+
+// remediationHint returns the hint for the first known failure in text.
+func remediationHint(text string) string {
+	text = strings.ToLower(text)
+	for _, h := range remediationHints {
+		for _, marker := range h.markers {
+			if strings.Contains(text, marker) {
+				return h.hint
+			}
+		}
+	}
+	return ""
+}
+
+// addRemediationHint attaches the hint for a failed response to its error,
+// ahead of any command output so quiet verbosity keeps it.
+func addRemediationHint(resp *plugin.ExecuteResponse) {
+	if resp.Success {
+		return
+	}
+	hint := remediationHint(resp.Error)
+	if hint == "" {
+		return
+	}
+	line := "\nHint: " + hint
+	if i := strings.Index(resp.Error, "\nOutput: "); i >= 0 {
+		resp.Error = resp.Error[:i] + line + resp.Error[i:]
+	} else {
+		resp.Error += line
+	}
+	resp.Outputs["hint"] = hint
+}
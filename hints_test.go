@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestRemediationHint(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "** (Mix) No package metadata found", want: "add a package/0 function"},
+		{text: "Stopping package build due to errors.\nMissing metadata fields: licenses", want: "add a package/0 function"},
+		{text: "API key revoked", want: "generate a new one"},
+		{text: "must include the --replace flag to update an existing package", want: "already on Hex"},
+		{text: "Package exceeds the maximum size", want: "over the Hex size limit"},
+		{text: "Invalid API key", want: "current key"},
+		{text: "exit status 1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := remediationHint(tt.text)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("expected no hint, got %q", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("remediationHint() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRemediationHint(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity string
+	}{
+		{name: "normal", verbosity: verbosityNormal},
+		{name: "quiet keeps the hint", verbosity: verbosityQuiet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte("** (Mix) No package metadata found"), errors.New("exit status 1")
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "verbosity": tt.verbosity},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.Contains(resp.Error, "\nHint: add a package/0 function") {
+				t.Errorf("expected a hint in the error, got %q", resp.Error)
+			}
+			if resp.Outputs["hint"] == nil {
+				t.Error("expected a hint output")
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// fallbackHomeName is the directory under the temp dir used as HOME when
+// the environment has none, e.g. in stripped containers.
+const fallbackHomeName = "relicta-hex-home"
+
+// homeFallbackEnv returns HOME, MIX_HOME and HEX_HOME pointing at a writable
+// temp directory when HOME is unset, since mix crashes on startup without
+// it. MIX_HOME and HEX_HOME are only set when they are unset too. It
+// returns nil when HOME is set and on Windows, which does not use HOME.
+func homeFallbackEnv() ([]string, error) {
+	if runtime.GOOS == "windows" || os.Getenv("HOME") != "" {
+		return nil, nil
+	}
+
+	home := filepath.Join(os.TempDir(), fallbackHomeName)
+	if err := os.MkdirAll(home, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create fallback HOME: %w", err)
+	}
+
+	env := []string{"HOME=" + home}
+	if os.Getenv("MIX_HOME") == "" {
+		env = append(env, "MIX_HOME="+filepath.Join(home, ".mix"))
+	}
+	if os.Getenv("HEX_HOME") == "" {
+		env = append(env, "HEX_HOME="+filepath.Join(home, ".hex"))
+	}
+	return env, nil
+}
+
+// validateHomeDirs checks that mix_home and hex_home are absolute, since a
+// relative path would resolve against work_dir.
+func validateHomeDirs(mixHome, hexHome string) *fieldError {
+	for _, dir := range []struct{ field, path string }{{"mix_home", mixHome}, {"hex_home", hexHome}} {
+		if dir.path != "" && !filepath.IsAbs(dir.path) {
+			return &fieldError{Field: dir.field, Err: fmt.Errorf("must be an absolute path")}
+		}
+	}
+	return nil
+}
+
+// homeEnv returns MIX_HOME and HEX_HOME for mix commands when mix_home or
+// hex_home is configured.
+func (c *Config) homeEnv() []string {
+	var env []string
+	if c.MixHome != "" {
+		env = append(env, "MIX_HOME="+c.MixHome)
+	}
+	if c.HexHome != "" {
+		env = append(env, "HEX_HOME="+c.HexHome)
+	}
+	return env
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestHomeFallbackEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("HOME is not used on Windows")
+	}
+	tmp := t.TempDir()
+	home := filepath.Join(tmp, fallbackHomeName)
+
+	tests := []struct {
+		name    string
+		home    string
+		mixHome string
+		want    []string
+	}{
+		{name: "home set", home: "/home/runner"},
+		{
+			name: "home unset",
+			want: []string{"HOME=" + home, "MIX_HOME=" + filepath.Join(home, ".mix"), "HEX_HOME=" + filepath.Join(home, ".hex")},
+		},
+		{
+			name:    "mix home kept",
+			mixHome: "/cache/mix",
+			want:    []string{"HOME=" + home, "HEX_HOME=" + filepath.Join(home, ".hex")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TMPDIR", tmp)
+			t.Setenv("HOME", tt.home)
+			t.Setenv("MIX_HOME", tt.mixHome)
+			t.Setenv("HEX_HOME", "")
+
+			got, err := homeFallbackEnv()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("homeFallbackEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteHomeFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("HOME is not used on Windows")
+	}
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("HOME", "")
+	t.Setenv("MIX_HOME", "")
+	t.Setenv("HEX_HOME", "")
+
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "verbosity": verbosityDebug},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+
+	var home string
+	for _, kv := range mock.Calls[0].Env {
+		if v, ok := strings.CutPrefix(kv, "HOME="); ok {
+			home = v
+		}
+	}
+	if filepath.Base(home) != fallbackHomeName {
+		t.Errorf("expected a fallback HOME, got env %v", mock.Calls[0].Env)
+	}
+	decisions, _ := resp.Outputs["decisions"].([]string)
+	if !contains(decisions, "HOME is unset, using HOME="+home+" MIX_HOME="+filepath.Join(home, ".mix")+" HEX_HOME="+filepath.Join(home, ".hex")) {
+		t.Errorf("expected the fallback in the debug decisions, got %v", decisions)
+	}
+}
+
+func TestValidateHomeDirs(t *testing.T) {
+	tests := []struct {
+		name      string
+		mixHome   string
+		hexHome   string
+		wantField string
+	}{
+		{name: "unset"},
+		{name: "absolute", mixHome: "/cache/mix", hexHome: "/cache/hex"},
+		{name: "relative mix_home", mixHome: "cache/mix", wantField: "mix_home"},
+		{name: "relative hex_home", hexHome: "cache/hex", wantField: "hex_home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHomeDirs(tt.mixHome, tt.hexHome)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Field != tt.wantField {
+				t.Errorf("expected an error for %s, got %v", tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestExecuteHomeDirs(t *testing.T) {
+	mixHome := filepath.Join(t.TempDir(), "mix")
+	hexHome := filepath.Join(t.TempDir(), "hex")
+
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"api_key":    "test-api-key",
+			"mix_home":   mixHome,
+			"hex_home":   hexHome,
+			"hex_config": map[string]any{"offline": false},
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+	if len(mock.Calls) != 2 {
+		t.Fatalf("expected hex.config and hex.publish, got %+v", mock.Calls)
+	}
+	for _, call := range mock.Calls {
+		// Later entries win when the command's environment is built
+		var gotMix, gotHex string
+		for _, kv := range call.Env {
+			if v, ok := strings.CutPrefix(kv, "MIX_HOME="); ok {
+				gotMix = v
+			}
+			if v, ok := strings.CutPrefix(kv, "HEX_HOME="); ok {
+				gotHex = v
+			}
+		}
+		if gotMix != mixHome || gotHex != hexHome {
+			t.Errorf("mix %s ran with MIX_HOME=%s HEX_HOME=%s, want %s and %s", call.Args[0], gotMix, gotHex, mixHome, hexHome)
+		}
+	}
+
+	resp, _ = p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "mix_home": "relative/mix"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if resp.Success || resp.Error != "invalid mix_home: must be an absolute path" {
+		t.Errorf("expected a relative mix_home to fail, got %+v", resp)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultKeyExpiryHorizon is how far ahead key_expiry_check warns.
+const defaultKeyExpiryHorizon = 14 * 24 * time.Hour
+
+// keyWarningCode marks Validate entries that warn without invalidating the config.
+const keyWarningCode = "warning"
+
+// keyExpiry returns when the key lapses: the registry's expiry when it
+// reports one, otherwise inserted_at plus key_max_age. It reports false when
+// neither applies.
+func keyExpiry(key HexKey, maxAge time.Duration) (time.Time, bool) {
+	if key.ExpiresAt != nil {
+		return *key.ExpiresAt, true
+	}
+	if maxAge > 0 && !key.InsertedAt.IsZero() {
+		return key.InsertedAt.Add(maxAge), true
+	}
+	return time.Time{}, false
+}
+
+// keyExpiryWarning describes a key that lapses within horizon of now.
+func keyExpiryWarning(key HexKey, maxAge, horizon time.Duration, now time.Time) string {
+	expires, ok := keyExpiry(key, maxAge)
+	if !ok {
+		return ""
+	}
+	if !now.Before(expires) {
+		return fmt.Sprintf("api key %s expired at %s", key.Name, expires.UTC().Format(time.RFC3339))
+	}
+	if left := expires.Sub(now); left <= horizon {
+		return fmt.Sprintf("api key %s expires at %s (in %s)", key.Name, expires.UTC().Format(time.RFC3339), left.Round(time.Hour))
+	}
+	return ""
+}
+
+// checkKeyHealth fetches the metadata of the authenticating key and returns
+// warnings when it lapses within key_expiry_horizon or its owner has no
+// two-factor authentication. Organization keys have no owner to check.
+func (p *HexPlugin) checkKeyHealth(ctx context.Context, cfg *Config, now time.Time) ([]string, error) {
+	api := p.hexAPI(cfg)
+	keys, err := api.listKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api key metadata: %w", err)
+	}
+
+	var warnings []string
+	for _, key := range keys {
+		if !key.AuthingKey {
+			continue
+		}
+		if w := keyExpiryWarning(key, cfg.KeyMaxAge, cfg.KeyExpiryHorizon, now); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+
+	user, err := api.currentUser(ctx)
+	var apiErr *hexAPIError
+	switch {
+	case errors.As(err, &apiErr) && (apiErr.Status == http.StatusForbidden || apiErr.Status == http.StatusNotFound):
+		// Organization keys are not owned by a user
+	case err != nil:
+		return warnings, fmt.Errorf("failed to fetch api key owner: %w", err)
+	case user.TFAEnabled != nil && !*user.TFAEnabled:
+		warnings = append(warnings, fmt.Sprintf("api key owner %s does not have two-factor authentication enabled", user.Username))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyExpiryWarning(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	soon := now.Add(3 * 24 * time.Hour)
+	later := now.Add(60 * 24 * time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		key    HexKey
+		maxAge time.Duration
+		want   string
+	}{
+		{name: "no expiry", key: HexKey{Name: "ci", InsertedAt: now.Add(-400 * 24 * time.Hour)}},
+		{name: "expires soon", key: HexKey{Name: "ci", ExpiresAt: &soon}, want: "api key ci expires at 2026-10-20T12:00:00Z (in 72h0m0s)"},
+		{name: "expires later", key: HexKey{Name: "ci", ExpiresAt: &later}},
+		{name: "expired", key: HexKey{Name: "ci", ExpiresAt: &past}, want: "api key ci expired at"},
+		{name: "max age reached soon", key: HexKey{Name: "ci", InsertedAt: now.Add(-85 * 24 * time.Hour)}, maxAge: 90 * 24 * time.Hour, want: "expires at 2026-10-22T12:00:00Z"},
+		{name: "max age far", key: HexKey{Name: "ci", InsertedAt: now}, maxAge: 90 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := keyExpiryWarning(tt.key, tt.maxAge, defaultKeyExpiryHorizon, now)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("unexpected warning %q", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("expected warning containing %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateKeyExpiryCheck(t *testing.T) {
+	soon := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name         string
+		keys         string
+		userStatus   int
+		user         string
+		wantWarnings []string
+	}{
+		{
+			name:       "healthy key",
+			keys:       `[{"name":"ci","authing_key":true,"inserted_at":"2026-10-01T00:00:00Z"}]`,
+			userStatus: http.StatusOK,
+			user:       `{"username":"jane","tfa_enabled":true}`,
+		},
+		{
+			name:         "expiring key",
+			keys:         `[{"name":"other","expires_at":"` + soon + `"},{"name":"ci","authing_key":true,"expires_at":"` + soon + `"}]`,
+			userStatus:   http.StatusOK,
+			user:         `{"username":"jane"}`,
+			wantWarnings: []string{"api key ci expires at"},
+		},
+		{
+			name:         "owner without 2fa",
+			keys:         `[]`,
+			userStatus:   http.StatusOK,
+			user:         `{"username":"jane","tfa_enabled":false}`,
+			wantWarnings: []string{"jane does not have two-factor authentication enabled"},
+		},
+		{
+			name:       "organization key",
+			keys:       `[]`,
+			userStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/keys":
+					_, _ = w.Write([]byte(tt.keys))
+				case "/users/me":
+					w.WriteHeader(tt.userStatus)
+					_, _ = w.Write([]byte(tt.user))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			resp, err := (&HexPlugin{}).Validate(context.Background(), map[string]any{
+				"api_key":          "test-api-key",
+				"api_url":          server.URL,
+				"key_expiry_check": true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Valid {
+				t.Errorf("warnings must not invalidate the config: %+v", resp.Errors)
+			}
+			if len(resp.Errors) != len(tt.wantWarnings) {
+				t.Fatalf("expected %d warnings, got %+v", len(tt.wantWarnings), resp.Errors)
+			}
+			for i, want := range tt.wantWarnings {
+				if resp.Errors[i].Code != keyWarningCode || !strings.Contains(resp.Errors[i].Message, want) {
+					t.Errorf("expected warning containing %q, got %+v", want, resp.Errors[i])
+				}
+			}
+		})
+	}
+}
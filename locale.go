@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+const (
+	// defaultLocale is the locale spawned commands run with, so Elixir
+	// starts with UTF-8 encoding even in containers without a locale.
+	defaultLocale = "en_US.UTF-8"
+	// localeInherit leaves LANG and LC_ALL as the environment sets them.
+	localeInherit = "inherit"
+)
+
+// localePattern matches locale names such as C.UTF-8 or de_DE.UTF-8@euro.
+var localePattern = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+
+// validateLocale checks that locale names a locale or is inherit.
+func validateLocale(locale string) *fieldError {
+	if locale == "" || localePattern.MatchString(locale) {
+		return nil
+	}
+	return &fieldError{Field: "locale", Err: fmt.Errorf("%q is not a locale name such as %s, or %s", locale, defaultLocale, localeInherit)}
+}
+
+// localeEnv returns LANG and LC_ALL for the configured locale, or nil when
+// the environment's own locale is inherited.
+func (c *Config) localeEnv() []string {
+	if c.Locale == "" || c.Locale == localeInherit {
+		return nil
+	}
+	return []string{"LANG=" + c.Locale, "LC_ALL=" + c.Locale}
+}
+
+// localeEnvKey carries the locale environment to the executor.
+type localeEnvKey struct{}
+
+// withLocaleEnv returns a context whose commands run with env set first.
+func withLocaleEnv(ctx context.Context, env []string) context.Context {
+	if len(env) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, localeEnvKey{}, env)
+}
+
+// commandEnv returns the environment added to a command: the locale carried
+// by ctx, overridden by the command's own env. It returns nil when there is
+// nothing to add, so the command inherits the environment unchanged.
+func commandEnv(ctx context.Context, env []string) []string {
+	locale, _ := ctx.Value(localeEnvKey{}).([]string)
+	if len(locale) == 0 {
+		return env
+	}
+	return append(append([]string{}, locale...), env...)
+}
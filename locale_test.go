@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValidateLocale(t *testing.T) {
+	for _, locale := range []string{"", "en_US.UTF-8", "C.UTF-8", "de_DE.UTF-8@euro", "inherit"} {
+		if err := validateLocale(locale); err != nil {
+			t.Errorf("validateLocale(%q) = %v", locale, err)
+		}
+	}
+	for _, locale := range []string{"en US", "C.UTF-8; rm -rf /", "LANG=C"} {
+		if err := validateLocale(locale); err == nil {
+			t.Errorf("validateLocale(%q) accepted an invalid locale", locale)
+		}
+	}
+}
+
+func TestRealExecutorLocale(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		env    []string
+		want   string
+	}{
+		{name: "default", locale: defaultLocale, want: "en_US.UTF-8 en_US.UTF-8"},
+		{name: "configured", locale: "C.UTF-8", want: "C.UTF-8 C.UTF-8"},
+		{name: "command env wins", locale: defaultLocale, env: []string{"LC_ALL=C"}, want: "en_US.UTF-8 C"},
+		{name: "inherit", locale: localeInherit, want: "inherited inherited"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LANG", "inherited")
+			t.Setenv("LC_ALL", "inherited")
+			cfg := &Config{Locale: tt.locale}
+			ctx := withLocaleEnv(context.Background(), cfg.localeEnv())
+
+			output, err := (&RealCommandExecutor{}).Run(ctx, "sh", []string{"-c", `echo "$LANG $LC_ALL"`}, tt.env, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.TrimSpace(string(output)); got != tt.want {
+				t.Errorf("LANG LC_ALL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
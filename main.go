@@ -2,9 +2,19 @@
 package main
 
 import (
+	"context"
+	"os"
+
 	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
 )
 
 func main() {
-	plugin.Serve(&HexPlugin{})
+	p := &HexPlugin{}
+
+	// Debug mode: run Validate/Execute locally without a Relicta host
+	if len(os.Args) > 1 && os.Args[1] == standaloneFlag {
+		os.Exit(runStandalone(context.Background(), p, os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	plugin.Serve(p)
 }
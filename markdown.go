@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// githubStepSummaryEnv is the file GitHub Actions renders as the step summary.
+const githubStepSummaryEnv = "GITHUB_STEP_SUMMARY"
+
+// markdown renders the run summary as a markdown report suitable for CI step summaries.
+func (s *RunSummary) markdown() string {
+	var b strings.Builder
+
+	status := "✅ Published"
+	switch {
+	case !s.Success:
+		status = "❌ Failed"
+	case s.DryRun:
+		status = "🧪 Dry run"
+	}
+
+	name := s.Package
+	if name == "" {
+		name = "package"
+	}
+
+	fmt.Fprintf(&b, "## Hex publish: %s %s\n\n", name, s.ReleaseVersion)
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Status | %s |\n", status)
+	if s.Package != "" {
+		fmt.Fprintf(&b, "| Package | `%s` |\n", s.Package)
+	}
+	if s.ReleaseVersion != "" {
+		fmt.Fprintf(&b, "| Version | `%s` |\n", s.ReleaseVersion)
+	}
+	fmt.Fprintf(&b, "| Duration | %s s |\n", formatSeconds(s.DurationMs))
+
+	if len(s.URLs) > 0 {
+		b.WriteString("\n### Links\n\n")
+		names := make([]string, 0, len(s.URLs))
+		for name := range s.URLs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "- %s: %s\n", name, s.URLs[name])
+		}
+	}
+
+	if len(s.Gates) > 0 {
+		b.WriteString("\n### Gates\n\n| Gate | Result | Duration |\n|---|---|---|\n")
+		for _, g := range s.Gates {
+			result := "✅ passed"
+			if !g.Success {
+				result = "❌ failed"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s s |\n", g.Name, result, formatSeconds(g.DurationMs))
+		}
+	}
+
+	if len(s.Errors) > 0 {
+		b.WriteString("\n### Errors\n\n")
+		for _, e := range s.Errors {
+			fmt.Fprintf(&b, "```\n%s\n```\n", strings.TrimSpace(e))
+		}
+	}
+
+	return b.String()
+}
+
+// appendStepSummary appends markdown to the GitHub Actions step summary file.
+func appendStepSummary(path, markdown string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.WriteString(markdown + "\n"); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	return nil
+}
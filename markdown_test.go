@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestMain keeps test runs under GitHub Actions from writing to the real step summary.
+func TestMain(m *testing.M) {
+	_ = os.Unsetenv(githubStepSummaryEnv)
+	os.Exit(m.Run())
+}
+
+func TestRunSummaryMarkdown(t *testing.T) {
+	s := &RunSummary{
+		Package:        "my_lib",
+		ReleaseVersion: "1.2.0",
+		Success:        false,
+		URLs:           map[string]string{"package": "https://hex.pm/packages/my_lib/1.2.0"},
+		Gates: []GateResult{
+			{Name: "test", Success: true},
+			{Name: "credo", Success: false},
+		},
+		Errors: []string{"pre-publish gates failed: credo"},
+	}
+
+	md := s.markdown()
+
+	for _, want := range []string{
+		"## Hex publish: my_lib 1.2.0",
+		"| Status | ❌ Failed |",
+		"- package: https://hex.pm/packages/my_lib/1.2.0",
+		"| test | ✅ passed |",
+		"| credo | ❌ failed |",
+		"pre-publish gates failed: credo",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestExecuteStepSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		expectWrite bool
+	}{
+		{
+			name:        "writes step summary by default",
+			config:      map[string]any{},
+			expectWrite: true,
+		},
+		{
+			name:        "step summary can be disabled",
+			config:      map[string]any{"step_summary": false},
+			expectWrite: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "step_summary.md")
+			t.Setenv(githubStepSummaryEnv, path)
+
+			p := &HexPlugin{}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				DryRun:  true,
+				Config:  tt.config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			markdown, ok := resp.Outputs["summary_markdown"].(string)
+			if !ok || !strings.Contains(markdown, "Dry run") {
+				t.Errorf("expected dry run markdown output, got %v", resp.Outputs["summary_markdown"])
+			}
+
+			data, err := os.ReadFile(path)
+			if tt.expectWrite {
+				if err != nil {
+					t.Fatalf("expected step summary file: %v", err)
+				}
+				if !strings.Contains(string(data), markdown) {
+					t.Error("step summary should contain the markdown output")
+				}
+			} else if err == nil {
+				t.Error("step summary should not be written when disabled")
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// maskReplacement replaces every masked secret.
+const maskReplacement = "***"
+
+// minMaskLength is the shortest value that is masked; shorter values would
+// scrub unrelated text.
+const minMaskLength = 4
+
+// secretMasker scrubs known secret values from captured text.
+type secretMasker struct {
+	values []string
+}
+
+// newSecretMasker collects the API key, the docs upload tokens, mask_values
+// and the values of the mask_env variables.
+func newSecretMasker(cfg *Config) *secretMasker {
+	candidates := append([]string{cfg.APIKey}, cfg.MaskValues...)
+
+	envNames := append([]string{cfg.DocsTokenEnv}, cfg.MaskEnv...)
+	for _, target := range cfg.DocsTargets {
+		envNames = append(envNames, target.TokenEnv)
+	}
+	for _, pkg := range cfg.Packages {
+		candidates = append(candidates, pkg.APIKey)
+	}
+	for _, name := range envNames {
+		if name != "" {
+			candidates = append(candidates, os.Getenv(name))
+		}
+	}
+
+	m := &secretMasker{}
+	m.add(candidates...)
+	return m
+}
+
+// add registers secrets that become known during the run.
+func (m *secretMasker) add(values ...string) {
+	if m == nil {
+		return
+	}
+	for _, v := range values {
+		if len(v) >= minMaskLength && !slices.Contains(m.values, v) {
+			m.values = append(m.values, v)
+		}
+	}
+
+	// Longest first so a secret containing another is fully replaced
+	sort.Slice(m.values, func(i, j int) bool { return len(m.values[i]) > len(m.values[j]) })
+}
+
+// mask replaces every known secret in s.
+func (m *secretMasker) mask(s string) string {
+	if m == nil {
+		return s
+	}
+	for _, v := range m.values {
+		s = strings.ReplaceAll(s, v, maskReplacement)
+	}
+	return s
+}
+
+// maskAll masks each string of the slice in place and returns it.
+func (m *secretMasker) maskAll(values []string) []string {
+	if m == nil {
+		return values
+	}
+	for i, v := range values {
+		values[i] = m.mask(v)
+	}
+	return values
+}
+
+// maskedError hides secrets in an error message while keeping the wrapped
+// error available to errors.As, so exit codes survive masking.
+type maskedError struct {
+	msg string
+	err error
+}
+
+// Error implements error.
+func (e *maskedError) Error() string { return e.msg }
+
+// Unwrap returns the original error.
+func (e *maskedError) Unwrap() error { return e.err }
+
+// maskError masks the message of err.
+func (m *secretMasker) maskError(err error) error {
+	if m == nil || err == nil {
+		return err
+	}
+	msg := m.mask(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &maskedError{msg: msg, err: err}
+}
+
+// maskResponse masks the message, error and string outputs of a response.
+func (m *secretMasker) maskResponse(resp *plugin.ExecuteResponse) {
+	if m == nil || resp == nil {
+		return
+	}
+	resp.Message = m.mask(resp.Message)
+	resp.Error = m.mask(resp.Error)
+	for key, val := range resp.Outputs {
+		switch v := val.(type) {
+		case string:
+			resp.Outputs[key] = m.mask(v)
+		case []string:
+			resp.Outputs[key] = m.maskAll(v)
+		}
+	}
+}
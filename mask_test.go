@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestSecretMasker(t *testing.T) {
+	t.Setenv("PRIVATE_REPO_AUTH", "ghp_private_token")
+
+	m := newSecretMasker(&Config{
+		APIKey:     "hex-key-123",
+		MaskValues: []string{"abc", "literal-secret"},
+		MaskEnv:    []string{"PRIVATE_REPO_AUTH", "UNSET_VARIABLE"},
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "api key", input: "key=hex-key-123", expected: "key=***"},
+		{name: "mask value", input: "token literal-secret leaked", expected: "token *** leaked"},
+		{name: "mask env value", input: "fetching https://ghp_private_token@github.com", expected: "fetching https://***@github.com"},
+		{name: "short values are ignored", input: "abc", expected: "abc"},
+		{name: "clean text is unchanged", input: "Building my_lib 1.0.0", expected: "Building my_lib 1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.mask(tt.input); got != tt.expected {
+				t.Errorf("got %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("masked errors keep the exit code", func(t *testing.T) {
+		err := m.maskError(&ReplayedError{Message: "failed with hex-key-123", Code: 3})
+		if strings.Contains(err.Error(), "hex-key-123") {
+			t.Errorf("error not masked: %v", err)
+		}
+		if exitCodeOf(err) != 3 {
+			t.Errorf("expected exit code 3, got %d", exitCodeOf(err))
+		}
+	})
+
+	t.Run("nil masker is a no-op", func(t *testing.T) {
+		var m *secretMasker
+		if m.mask("secret") != "secret" || m.maskError(errors.New("x")).Error() != "x" {
+			t.Error("nil masker should not change input")
+		}
+	})
+}
+
+func TestExecuteMasksSecrets(t *testing.T) {
+	t.Setenv("PRIVATE_REPO_AUTH", "ghp_private_token")
+	chdirTemp(t)
+	summaryPath := filepath.Join("out", "summary.json")
+
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("could not fetch https://ghp_private_token@github.com/acme/dep"), errors.New("exit status 1: ghp_private_token")
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"api_key":      "test-key",
+			"mask_env":     []any{"PRIVATE_REPO_AUTH"},
+			"summary_path": summaryPath,
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(resp.Error, "ghp_private_token") {
+		t.Errorf("error leaked the token: %s", resp.Error)
+	}
+	if md, _ := resp.Outputs["summary_markdown"].(string); strings.Contains(md, "ghp_private_token") {
+		t.Error("markdown summary leaked the token")
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	if strings.Contains(string(data), "ghp_private_token") {
+		t.Error("summary file leaked the token")
+	}
+
+	var s RunSummary
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("decode summary: %v", err)
+	}
+	if len(s.Commands) != 1 || !strings.Contains(s.Commands[0].Error, maskReplacement) {
+		t.Errorf("expected masked command error, got %+v", s.Commands)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MatrixEntry is an Elixir/OTP docker image the package must compile on.
+type MatrixEntry struct {
+	Image string `json:"image"`
+	// AllowFailure marks combinations that are not declared as supported.
+	AllowFailure bool `json:"allow_failure,omitempty"`
+}
+
+// dockerImagePattern matches docker image references such as
+// "hexpm/elixir:1.16.2-erlang-26.2.2-alpine-3.19.1".
+var dockerImagePattern = regexp.MustCompile(`^[a-z0-9]+([._/:@-][a-zA-Z0-9_.-]+)*$`)
+
+// matrixScript compiles the package as a dependency would, with build
+// output kept inside the container so host _build and deps stay untouched.
+const matrixScript = "mix local.hex --force && mix local.rebar --force && mix deps.get --only prod && mix compile"
+
+// parseVerifyMatrix decodes verify_matrix, accepting image names or objects.
+func parseVerifyMatrix(raw map[string]any) ([]MatrixEntry, *fieldError) {
+	return decodeObjectList(raw, "verify_matrix", func(s string) MatrixEntry { return MatrixEntry{Image: s} })
+}
+
+// validateVerifyMatrix checks every image reference.
+func validateVerifyMatrix(raw map[string]any) []*fieldError {
+	entries, err := parseVerifyMatrix(raw)
+	if err != nil {
+		return []*fieldError{err}
+	}
+	var errs []*fieldError
+	for i, entry := range entries {
+		if !dockerImagePattern.MatchString(entry.Image) {
+			errs = append(errs, &fieldError{Field: fmt.Sprintf("verify_matrix[%d].image", i), Err: fmt.Errorf("invalid docker image %q", entry.Image)})
+		}
+	}
+	return errs
+}
+
+// matrixImages returns the configured image names.
+func matrixImages(entries []MatrixEntry) []string {
+	images := make([]string, 0, len(entries))
+	for _, e := range entries {
+		images = append(images, e.Image)
+	}
+	return images
+}
+
+// matrixArgs returns the docker arguments compiling work_dir in image.
+func matrixArgs(workDir, image string) []string {
+	return []string{
+		"run", "--rm",
+		"-v", workDir + ":/src",
+		"-w", "/src",
+		"-e", "MIX_ENV=prod",
+		"-e", "MIX_DEPS_PATH=/tmp/deps",
+		"-e", "MIX_BUILD_ROOT=/tmp/_build",
+		image,
+		"sh", "-c", matrixScript,
+	}
+}
+
+// runVerifyMatrix compiles the package in each image and reports per-image results.
+func (p *HexPlugin) runVerifyMatrix(ctx context.Context, cfg *Config, summary *RunSummary) []GateResult {
+	results := make([]GateResult, 0, len(cfg.VerifyMatrix))
+
+	workDir, err := filepath.Abs(cfg.WorkDir)
+	if err != nil {
+		for _, entry := range cfg.VerifyMatrix {
+			results = append(results, GateResult{Name: entry.Image, Message: err.Error(), AllowFailure: entry.AllowFailure})
+		}
+		return results
+	}
+
+	for _, entry := range cfg.VerifyMatrix {
+		start := time.Now()
+		args := matrixArgs(workDir, entry.Image)
+		output, err := p.runCommand(ctx, summary, "docker", args, nil, cfg.WorkDir)
+		result := GateResult{
+			Name:         entry.Image,
+			Command:      "docker " + strings.Join(args, " "),
+			Success:      err == nil,
+			Output:       string(output),
+			DurationMs:   time.Since(start).Milliseconds(),
+			AllowFailure: entry.AllowFailure,
+		}
+		if err != nil {
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateVerifyMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  any
+		wantErr string
+	}{
+		{name: "image names", matrix: []any{"hexpm/elixir:1.16.2-erlang-26.2.2-alpine-3.19.1", "elixir:1.15"}},
+		{name: "objects", matrix: []any{map[string]any{"image": "elixir:1.17", "allow_failure": true}}},
+		{name: "invalid image", matrix: []any{"elixir:1.15; rm -rf /"}, wantErr: "verify_matrix[0].image"},
+		{name: "missing image", matrix: []any{map[string]any{"allow_failure": true}}, wantErr: "verify_matrix[0].image"},
+		{name: "unknown field", matrix: []any{map[string]any{"img": "elixir"}}, wantErr: "verify_matrix[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateVerifyMatrix(map[string]any{"verify_matrix": tt.matrix})
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.HasPrefix(errs[0].Field, tt.wantErr) {
+				t.Errorf("expected error on %q, got %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestExecuteVerifyMatrix(t *testing.T) {
+	tests := []struct {
+		name            string
+		matrix          []any
+		expectedSuccess bool
+		expectedPublish bool
+	}{
+		{
+			name:            "all images compile",
+			matrix:          []any{"elixir:1.15", "elixir:1.16"},
+			expectedSuccess: true,
+			expectedPublish: true,
+		},
+		{
+			name:            "supported image fails",
+			matrix:          []any{"elixir:1.15", "elixir:broken"},
+			expectedSuccess: false,
+		},
+		{
+			name:            "experimental image fails",
+			matrix:          []any{"elixir:1.15", map[string]any{"image": "elixir:broken", "allow_failure": true}},
+			expectedSuccess: true,
+			expectedPublish: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if name == "docker" && contains(args, "elixir:broken") {
+						return []byte("** (CompileError) undefined function"), errors.New("exit status 1")
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "verify_matrix": tt.matrix},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+
+			results, _ := resp.Outputs["verify_matrix"].([]GateResult)
+			if len(results) != len(tt.matrix) {
+				t.Fatalf("expected %d matrix results, got %+v", len(tt.matrix), resp.Outputs["verify_matrix"])
+			}
+			published := false
+			for _, call := range mock.Calls {
+				if call.Name == "mix" && contains(call.Args, "hex.publish") {
+					published = true
+				}
+			}
+			if published != tt.expectedPublish {
+				t.Errorf("published = %v, want %v", published, tt.expectedPublish)
+			}
+			if !tt.expectedSuccess && !strings.Contains(resp.Error, "elixir:broken") {
+				t.Errorf("expected error to name the failing image, got %q", resp.Error)
+			}
+		})
+	}
+}
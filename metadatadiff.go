@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PackageMetadata is the reviewable metadata of a package release.
+type PackageMetadata struct {
+	Description  string
+	Licenses     []string
+	Links        map[string]string
+	Files        []string
+	Requirements map[string]string
+}
+
+// HexPackageMeta is the package-level metadata returned by the Hex.pm API.
+type HexPackageMeta struct {
+	Description string            `json:"description"`
+	Licenses    []string          `json:"licenses"`
+	Links       map[string]string `json:"links"`
+}
+
+// HexRequirement is a dependency of a release as returned by the Hex.pm API.
+type HexRequirement struct {
+	App         string `json:"app"`
+	Optional    bool   `json:"optional"`
+	Requirement string `json:"requirement"`
+}
+
+// HexPackageInfo is a package with its metadata as returned by the Hex.pm API.
+type HexPackageInfo struct {
+	HexPackage
+	LatestVersion       string         `json:"latest_version"`
+	LatestStableVersion string         `json:"latest_stable_version"`
+	Meta                HexPackageMeta `json:"meta"`
+}
+
+// hexReleaseRequirements is the part of a release the metadata diff uses.
+type hexReleaseRequirements struct {
+	Requirements map[string]HexRequirement `json:"requirements"`
+}
+
+// MetadataChange is one difference between the published release and the
+// package that would be published. Added entries have no From, removed
+// entries no To.
+type MetadataChange struct {
+	Field string `json:"field"`
+	Key   string `json:"key,omitempty"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// MetadataDiff compares the metadata of the published release with the
+// package about to be published. Since is empty for a first release.
+type MetadataDiff struct {
+	Package string           `json:"package"`
+	Since   string           `json:"since,omitempty"`
+	Changes []MetadataChange `json:"changes"`
+}
+
+var (
+	// buildSectionPattern matches a "  Key: value" line of mix hex.build output.
+	buildSectionPattern = regexp.MustCompile(`^  (\S[^:]*):\s*(.*)$`)
+	// buildDependencyPattern matches "name requirement (app: ...)" dependency lines.
+	buildDependencyPattern = regexp.MustCompile(`^(\S+)\s+(.+?)(?:\s+\(.*\))?$`)
+)
+
+// parseBuildMetadata reads the metadata mix hex.build prints. Multi-line
+// sections such as Dependencies, Files and Links list their entries on the
+// more indented lines that follow.
+func parseBuildMetadata(output string) PackageMetadata {
+	meta := PackageMetadata{Links: map[string]string{}, Requirements: map[string]string{}}
+	var section string
+	for _, line := range strings.Split(ansiPattern.ReplaceAllString(output, ""), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "    ") && section != "" {
+			item := strings.TrimSpace(line)
+			switch section {
+			case "Dependencies":
+				if m := buildDependencyPattern.FindStringSubmatch(item); m != nil {
+					meta.Requirements[m[1]] = m[2]
+				}
+			case "Files":
+				meta.Files = append(meta.Files, item)
+			case "Links":
+				if name, url, ok := strings.Cut(item, ": "); ok {
+					meta.Links[name] = url
+				}
+			}
+			continue
+		}
+
+		section = ""
+		m := buildSectionPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "Description":
+			meta.Description = m[2]
+		case "Licenses":
+			for _, license := range strings.Split(m[2], ",") {
+				if license = strings.TrimSpace(license); license != "" {
+					meta.Licenses = append(meta.Licenses, license)
+				}
+			}
+		case "Dependencies", "Files", "Links":
+			section = m[1]
+		}
+	}
+	return meta
+}
+
+// diffMetadata compares the published metadata with the local one. Changes
+// are ordered by field, then key.
+func diffMetadata(published, local PackageMetadata) []MetadataChange {
+	changes := []MetadataChange{}
+	if published.Description != local.Description {
+		changes = append(changes, MetadataChange{Field: "description", From: published.Description, To: local.Description})
+	}
+	changes = append(changes, diffSet("licenses", published.Licenses, local.Licenses)...)
+	changes = append(changes, diffMap("links", published.Links, local.Links)...)
+	changes = append(changes, diffSet("files", published.Files, local.Files)...)
+	changes = append(changes, diffMap("requirements", published.Requirements, local.Requirements)...)
+	return changes
+}
+
+// diffSet reports the entries added to and removed from a list.
+func diffSet(field string, from, to []string) []MetadataChange {
+	fromSet := make(map[string]string, len(from))
+	for _, v := range from {
+		fromSet[v] = v
+	}
+	toSet := make(map[string]string, len(to))
+	for _, v := range to {
+		toSet[v] = v
+	}
+	return diffMap(field, fromSet, toSet)
+}
+
+// diffMap reports the keys added, removed or changed between two maps.
+func diffMap(field string, from, to map[string]string) []MetadataChange {
+	keys := make([]string, 0, len(from)+len(to))
+	for k := range from {
+		keys = append(keys, k)
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []MetadataChange
+	for _, k := range keys {
+		old, hadOld := from[k]
+		cur, hasCur := to[k]
+		switch {
+		case hadOld && hasCur && old == cur:
+		case !hadOld:
+			changes = append(changes, MetadataChange{Field: field, Key: k, To: cur})
+		case !hasCur:
+			changes = append(changes, MetadataChange{Field: field, Key: k, From: old})
+		default:
+			changes = append(changes, MetadataChange{Field: field, Key: k, From: old, To: cur})
+		}
+	}
+	return changes
+}
+
+// publishedVersion returns the release the metadata diff compares against:
+// the latest stable release, or the latest release when none is stable. An
+// empty version means the package was never published.
+func publishedVersion(pkg *HexPackageInfo) string {
+	if pkg.LatestStableVersion != "" {
+		return pkg.LatestStableVersion
+	}
+	if pkg.LatestVersion != "" {
+		return pkg.LatestVersion
+	}
+	if len(pkg.Releases) > 0 {
+		return pkg.Releases[0].Version
+	}
+	return ""
+}
+
+// packageFetchArgs returns the mix arguments downloading a release tarball.
+func packageFetchArgs(organization, name, version, dest string) []string {
+	args := []string{"hex.package", "fetch", name, version, "--output", dest}
+	if organization != "" {
+		args = append(args, "--repo", "hexpm:"+organization)
+	}
+	return args
+}
+
+// publishedMetadata fetches the metadata of a published release: the
+// package metadata of pkg, the requirements from the Hex API, and the file
+// list from the release tarball, which mix hex.package fetch downloads.
+func (p *HexPlugin) publishedMetadata(ctx context.Context, cfg *Config, pkg *HexPackageInfo, version, dir string, summary *RunSummary) (PackageMetadata, error) {
+	name := pkg.Name
+	var release hexReleaseRequirements
+	if err := p.hexAPI(cfg).do(ctx, http.MethodGet, releasePath(cfg.Organization, name, version), nil, &release); err != nil {
+		return PackageMetadata{}, fmt.Errorf("failed to fetch release %s %s: %w", name, version, err)
+	}
+
+	meta := PackageMetadata{
+		Description:  pkg.Meta.Description,
+		Licenses:     pkg.Meta.Licenses,
+		Links:        pkg.Meta.Links,
+		Requirements: map[string]string{},
+	}
+	for dep, req := range release.Requirements {
+		meta.Requirements[dep] = req.Requirement
+	}
+
+	tarball := filepath.Join(dir, "published.tar")
+	output, err := p.runMix(ctx, cfg, summary, packageFetchArgs(cfg.Organization, name, version, tarball), cfg.hexEnv())
+	if err != nil {
+		return PackageMetadata{}, fmt.Errorf("mix hex.package fetch failed: %v\nOutput: %s", err, string(output))
+	}
+	manifest, err := readTarballManifest(tarball)
+	if err != nil {
+		return PackageMetadata{}, err
+	}
+	for _, entry := range manifest {
+		meta.Files = append(meta.Files, entry.Path)
+	}
+	return meta, nil
+}
+
+// metadataDiff builds the package and compares its metadata with the latest
+// published release.
+func (p *HexPlugin) metadataDiff(ctx context.Context, cfg *Config, summary *RunSummary) (*MetadataDiff, error) {
+	dir, err := os.MkdirTemp("", "relicta-hex-metadata-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tarball := filepath.Join(dir, "package.tar")
+	output, err := p.runMix(ctx, cfg, summary, []string{"hex.build", "--output", tarball}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("mix hex.build failed: %v\nOutput: %s", err, string(output))
+	}
+	name := parsePublishedPackage(string(output))
+	if name == "" {
+		return nil, fmt.Errorf("could not determine package name from mix hex.build output")
+	}
+	local := parseBuildMetadata(string(output))
+	// The tarball lists files where the build output may list directories
+	manifest, err := readTarballManifest(tarball)
+	if err != nil {
+		return nil, err
+	}
+	local.Files = nil
+	for _, entry := range manifest {
+		local.Files = append(local.Files, entry.Path)
+	}
+
+	var pkg HexPackageInfo
+	err = p.hexAPI(cfg).do(ctx, http.MethodGet, packagePath(cfg.Organization, name), nil, &pkg)
+	var apiErr *hexAPIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		summary.debugf("package %s is not published yet; all metadata is new", name)
+		return &MetadataDiff{Package: name, Changes: diffMetadata(PackageMetadata{}, local)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch package %s: %w", name, err)
+	}
+	since := publishedVersion(&pkg)
+	if since == "" {
+		return &MetadataDiff{Package: name, Changes: diffMetadata(PackageMetadata{}, local)}, nil
+	}
+
+	pkg.Name = name
+	published, err := p.publishedMetadata(ctx, cfg, &pkg, since, dir, summary)
+	if err != nil {
+		return nil, err
+	}
+	return &MetadataDiff{Package: name, Since: since, Changes: diffMetadata(published, local)}, nil
+}
+
+// addMetadataDiff records the metadata diff in the outputs. Failures never
+// block the run; they are reported as metadata_diff_error.
+func (p *HexPlugin) addMetadataDiff(ctx context.Context, cfg *Config, outputs map[string]any, summary *RunSummary) {
+	diff, err := p.metadataDiff(ctx, cfg, summary)
+	if err != nil {
+		outputs["metadata_diff_error"] = err.Error()
+		return
+	}
+	outputs["metadata_diff"] = diff
+}
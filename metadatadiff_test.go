@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const testBuildOutput = `Building my_lib 1.1.0
+  Dependencies:
+    jason ~> 1.4 (app: jason)
+    plug ~> 1.15 (app: plug, optional)
+  App: my_lib
+  Name: my_lib
+  Files:
+    lib
+    lib/my_lib.ex
+    mix.exs
+  Version: 1.1.0
+  Build tools: mix
+  Description: A library
+  Licenses: Apache-2.0, MIT
+  Links:
+    GitHub: https://github.com/acme/my_lib
+  Elixir: ~> 1.15
+`
+
+func TestParseBuildMetadata(t *testing.T) {
+	got := parseBuildMetadata(testBuildOutput)
+	want := PackageMetadata{
+		Description:  "A library",
+		Licenses:     []string{"Apache-2.0", "MIT"},
+		Links:        map[string]string{"GitHub": "https://github.com/acme/my_lib"},
+		Files:        []string{"lib", "lib/my_lib.ex", "mix.exs"},
+		Requirements: map[string]string{"jason": "~> 1.4", "plug": "~> 1.15"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBuildMetadata() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffMetadata(t *testing.T) {
+	tests := []struct {
+		name      string
+		published PackageMetadata
+		local     PackageMetadata
+		want      []MetadataChange
+	}{
+		{name: "unchanged", published: PackageMetadata{Description: "A", Licenses: []string{"MIT"}}, local: PackageMetadata{Description: "A", Licenses: []string{"MIT"}}, want: []MetadataChange{}},
+		{
+			name: "changes",
+			published: PackageMetadata{
+				Description:  "Old",
+				Licenses:     []string{"MIT"},
+				Links:        map[string]string{"GitHub": "https://github.com/old/my_lib", "Docs": "https://example.com"},
+				Files:        []string{"lib/a.ex"},
+				Requirements: map[string]string{"jason": "~> 1.2"},
+			},
+			local: PackageMetadata{
+				Description:  "New",
+				Licenses:     []string{"Apache-2.0"},
+				Links:        map[string]string{"GitHub": "https://github.com/acme/my_lib"},
+				Files:        []string{"lib/a.ex", "lib/b.ex"},
+				Requirements: map[string]string{"jason": "~> 1.4", "plug": "~> 1.15"},
+			},
+			want: []MetadataChange{
+				{Field: "description", From: "Old", To: "New"},
+				{Field: "licenses", Key: "Apache-2.0", To: "Apache-2.0"},
+				{Field: "licenses", Key: "MIT", From: "MIT"},
+				{Field: "links", Key: "Docs", From: "https://example.com"},
+				{Field: "links", Key: "GitHub", From: "https://github.com/old/my_lib", To: "https://github.com/acme/my_lib"},
+				{Field: "files", Key: "lib/b.ex", To: "lib/b.ex"},
+				{Field: "requirements", Key: "jason", From: "~> 1.2", To: "~> 1.4"},
+				{Field: "requirements", Key: "plug", To: "~> 1.15"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffMetadata(tt.published, tt.local); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteMetadataDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		packageJSON string
+		status      int
+		wantSince   string
+		wantChanges []MetadataChange
+		wantError   string
+	}{
+		{
+			name:        "against the latest stable release",
+			packageJSON: `{"name":"my_lib","latest_version":"1.1.0-rc.0","latest_stable_version":"1.0.0","meta":{"description":"A library","licenses":["MIT"],"links":{"GitHub":"https://github.com/acme/my_lib"}}}`,
+			status:      http.StatusOK,
+			wantSince:   "1.0.0",
+			wantChanges: []MetadataChange{
+				{Field: "licenses", Key: "Apache-2.0", To: "Apache-2.0"},
+				{Field: "files", Key: "lib/my_lib.ex", From: "lib/my_lib.ex"},
+				{Field: "requirements", Key: "jason", From: "~> 1.2", To: "~> 1.4"},
+				{Field: "requirements", Key: "plug", To: "~> 1.15"},
+			},
+		},
+		{
+			name:   "first release",
+			status: http.StatusNotFound,
+			wantChanges: []MetadataChange{
+				{Field: "description", To: "A library"},
+				{Field: "licenses", Key: "Apache-2.0", To: "Apache-2.0"},
+				{Field: "licenses", Key: "MIT", To: "MIT"},
+				{Field: "links", Key: "GitHub", To: "https://github.com/acme/my_lib"},
+				{Field: "files", Key: "mix.exs", To: "mix.exs"},
+				{Field: "requirements", Key: "jason", To: "~> 1.4"},
+				{Field: "requirements", Key: "plug", To: "~> 1.15"},
+			},
+		},
+		{name: "registry unavailable", status: http.StatusServiceUnavailable, wantError: "failed to fetch package my_lib"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/packages/my_lib":
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(tt.packageJSON))
+				case "/packages/my_lib/releases/1.0.0":
+					_, _ = w.Write([]byte(`{"version":"1.0.0","requirements":{"jason":{"app":"jason","optional":false,"requirement":"~> 1.2"}}}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			chdirTemp(t)
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					switch {
+					case args[0] == "hex.build" && len(args) > 1:
+						writeTestTarball(t, args[len(args)-1], map[string]string{"mix.exs": ""})
+						return []byte(testBuildOutput), nil
+					case args[0] == "hex.package":
+						writeTestTarball(t, args[5], map[string]string{"lib/my_lib.ex": "", "mix.exs": ""})
+						return []byte("my_lib v1.0.0 downloaded"), nil
+					}
+					return []byte("Building my_lib 1.1.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				DryRun:  true,
+				Config:  map[string]any{"api_key": "test-api-key", "api_url": server.URL, "metadata_diff": true},
+				Context: plugin.ReleaseContext{Version: "1.1.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("a metadata diff must not fail the dry run: %s", resp.Error)
+			}
+			if tt.wantError != "" {
+				if msg, _ := resp.Outputs["metadata_diff_error"].(string); !strings.HasPrefix(msg, tt.wantError) {
+					t.Errorf("metadata_diff_error = %q, want prefix %q", msg, tt.wantError)
+				}
+				return
+			}
+
+			diff, ok := resp.Outputs["metadata_diff"].(*MetadataDiff)
+			if !ok {
+				t.Fatalf("expected metadata_diff, got error %v", resp.Outputs["metadata_diff_error"])
+			}
+			if diff.Package != "my_lib" || diff.Since != tt.wantSince {
+				t.Errorf("diff = %s since %q, want my_lib since %q", diff.Package, diff.Since, tt.wantSince)
+			}
+			if !reflect.DeepEqual(diff.Changes, tt.wantChanges) {
+				t.Errorf("changes = %+v, want %+v", diff.Changes, tt.wantChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// defaultMetricsPrefix names the exported metrics and the pushgateway job.
+const defaultMetricsPrefix = "relicta_hex"
+
+// PublishMetrics are the reliability metrics of one publish.
+type PublishMetrics struct {
+	Package         string
+	Success         bool
+	Attempts        int
+	ErrorClass      string
+	DurationSeconds float64
+	// PackageBytes is the package size when it was measured, otherwise 0.
+	PackageBytes int64
+}
+
+// validateMetricsEndpoint checks that metrics_endpoint is a statsd://host:port
+// address or an http(s) pushgateway URL.
+func validateMetricsEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err == nil && u.Scheme == "statsd" {
+		if _, port, err := net.SplitHostPort(u.Host); err != nil || port == "" {
+			return fmt.Errorf("statsd endpoints must be statsd://host:port")
+		}
+		return nil
+	}
+	if validateHTTPURL(endpoint) != nil {
+		return fmt.Errorf("must be a statsd://host:port address or an http or https pushgateway URL")
+	}
+	return nil
+}
+
+// validateMetricsPrefix checks that metrics_prefix is a valid metric name prefix.
+func validateMetricsPrefix(prefix string) error {
+	if !appNamePattern.MatchString(prefix) {
+		return fmt.Errorf("must start with a lowercase letter and contain only lowercase letters, digits and underscores")
+	}
+	return nil
+}
+
+// newPublishMetrics derives the metrics of a finished publish.
+func newPublishMetrics(summary *RunSummary, resp *plugin.ExecuteResponse) PublishMetrics {
+	m := PublishMetrics{
+		Package:         summary.Package,
+		Success:         resp.Success,
+		Attempts:        1,
+		DurationSeconds: float64(summary.DurationMs) / 1000,
+	}
+	if attempts, ok := resp.Outputs["attempts"].(int); ok {
+		m.Attempts = attempts
+	}
+	if !resp.Success {
+		m.ErrorClass = errorClassUnknown
+		if class, ok := resp.Outputs["error_class"].(string); ok && class != "" {
+			m.ErrorClass = class
+		}
+	}
+	if size, ok := resp.Outputs["manifest_size"].(int64); ok {
+		m.PackageBytes = size
+	}
+	if artifacts, ok := resp.Outputs["artifacts"].([]ReleaseArtifact); ok {
+		for _, a := range artifacts {
+			if a.ContentType == contentTypeTar {
+				m.PackageBytes = a.Size
+			}
+		}
+	}
+	return m
+}
+
+// samples returns the metric values by name suffix.
+func (m PublishMetrics) samples() map[string]float64 {
+	success := 0.0
+	if m.Success {
+		success = 1
+	}
+	s := map[string]float64{
+		"publish_success":          success,
+		"publish_attempts":         float64(m.Attempts),
+		"publish_duration_seconds": m.DurationSeconds,
+	}
+	if m.PackageBytes > 0 {
+		s["publish_package_bytes"] = float64(m.PackageBytes)
+	}
+	return s
+}
+
+// prometheusText renders the metrics in the Prometheus text format. A
+// failure is reported as publish_failures with its error class as label.
+func (m PublishMetrics) prometheusText(prefix string) string {
+	samples := m.samples()
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "# TYPE %s_%s gauge\n%s_%s %g\n", prefix, name, prefix, name, samples[name])
+	}
+	if m.ErrorClass != "" {
+		fmt.Fprintf(&b, "# TYPE %s_publish_failures gauge\n%s_publish_failures{class=%q} 1\n", prefix, prefix, m.ErrorClass)
+	}
+	return b.String()
+}
+
+// statsdLines renders the metrics as statsd gauges, timers and counters.
+func (m PublishMetrics) statsdLines(prefix string) string {
+	lines := []string{
+		fmt.Sprintf("%s.publish.attempts:%d|g", prefix, m.Attempts),
+		fmt.Sprintf("%s.publish.duration:%d|ms", prefix, int64(m.DurationSeconds*1000)),
+	}
+	if m.Success {
+		lines = append(lines, fmt.Sprintf("%s.publish.success:1|c", prefix))
+	} else {
+		lines = append(lines, fmt.Sprintf("%s.publish.failure.%s:1|c", prefix, m.ErrorClass))
+	}
+	if m.PackageBytes > 0 {
+		lines = append(lines, fmt.Sprintf("%s.publish.package_bytes:%d|g", prefix, m.PackageBytes))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pushgatewayURL returns the group URL of the metrics, grouped by job and,
+// when known, package.
+func pushgatewayURL(endpoint, job, pkg string) string {
+	u := strings.TrimRight(endpoint, "/") + "/metrics/job/" + url.PathEscape(job)
+	if pkg != "" {
+		u += "/package/" + url.PathEscape(pkg)
+	}
+	return u
+}
+
+// sendMetrics exports the publish metrics to metrics_endpoint. Like
+// telemetry it is bounded by telemetryTimeout and never fails the release.
+func (p *HexPlugin) sendMetrics(ctx context.Context, cfg *Config, m PublishMetrics) error {
+	ctx, cancel := context.WithTimeout(ctx, telemetryTimeout)
+	defer cancel()
+
+	u, err := url.Parse(cfg.MetricsEndpoint)
+	if err != nil {
+		return fmt.Errorf("invalid metrics_endpoint: %w", err)
+	}
+	if u.Scheme == "statsd" {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "udp", u.Host)
+		if err != nil {
+			return fmt.Errorf("statsd connection failed: %w", err)
+		}
+		defer func() { _ = conn.Close() }()
+		if _, err := conn.Write([]byte(m.statsdLines(cfg.MetricsPrefix))); err != nil {
+			return fmt.Errorf("statsd write failed: %w", err)
+		}
+		return nil
+	}
+
+	// PUT replaces the group, clearing the failure of an earlier run
+	body := m.prometheusText(cfg.MetricsPrefix)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushgatewayURL(cfg.MetricsEndpoint, cfg.MetricsPrefix, m.Package), bytes.NewReader([]byte(body)))
+	if err != nil {
+		return fmt.Errorf("failed to create metrics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := p.getHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("metrics push failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pushgateway returned %d", resp.StatusCode)
+	}
+	return nil
+}
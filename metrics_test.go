@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateMetricsEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  bool
+	}{
+		{endpoint: "statsd://127.0.0.1:8125"},
+		{endpoint: "https://pushgateway.example.com"},
+		{endpoint: "statsd://localhost", wantErr: true},
+		{endpoint: "udp://localhost:8125", wantErr: true},
+		{endpoint: "pushgateway:9091", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			if err := validateMetricsEndpoint(tt.endpoint); (err != nil) != tt.wantErr {
+				t.Errorf("validateMetricsEndpoint(%q) error = %v, wantErr %v", tt.endpoint, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublishMetricsFormats(t *testing.T) {
+	m := PublishMetrics{Package: "my_lib", Attempts: 3, ErrorClass: errorClassNetwork, DurationSeconds: 1.5, PackageBytes: 2048}
+
+	text := m.prometheusText("relicta_hex")
+	for _, want := range []string{
+		"relicta_hex_publish_attempts 3\n",
+		"relicta_hex_publish_success 0\n",
+		"relicta_hex_publish_duration_seconds 1.5\n",
+		"relicta_hex_publish_package_bytes 2048\n",
+		`relicta_hex_publish_failures{class="network"} 1` + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("prometheus text missing %q:\n%s", want, text)
+		}
+	}
+
+	lines := m.statsdLines("relicta_hex")
+	want := "relicta_hex.publish.attempts:3|g\nrelicta_hex.publish.duration:1500|ms\nrelicta_hex.publish.failure.network:1|c\nrelicta_hex.publish.package_bytes:2048|g"
+	if lines != want {
+		t.Errorf("statsdLines() = %q, want %q", lines, want)
+	}
+}
+
+func TestExecutePushesMetrics(t *testing.T) {
+	tests := []struct {
+		name      string
+		fail      bool
+		wantPath  string
+		wantLines []string
+	}{
+		{
+			name:      "success",
+			wantPath:  "/metrics/job/relicta_hex/package/my_lib",
+			wantLines: []string{"relicta_hex_publish_success 1"},
+		},
+		{
+			name:      "failure by class",
+			fail:      true,
+			wantPath:  "/metrics/job/relicta_hex",
+			wantLines: []string{"relicta_hex_publish_success 0", `relicta_hex_publish_failures{class="auth"} 1`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				method, path, body = r.Method, r.URL.Path, string(data)
+			}))
+			defer server.Close()
+
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if tt.fail {
+						return []byte("** (Mix) Invalid API key"), errors.New("exit status 1")
+					}
+					return []byte("Building my_lib 1.0.0\nPublished"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "metrics_endpoint": server.URL},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Outputs["metrics_error"] != nil {
+				t.Fatalf("unexpected metrics_error: %v", resp.Outputs["metrics_error"])
+			}
+			if method != http.MethodPut || path != tt.wantPath {
+				t.Errorf("pushed %s %s, want PUT %s", method, path, tt.wantPath)
+			}
+			for _, want := range tt.wantLines {
+				if !strings.Contains(body, want) {
+					t.Errorf("push body missing %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteSendsStatsdMetrics(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	p := &HexPlugin{executor: &MockCommandExecutor{}}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "metrics_endpoint": "statsd://" + conn.LocalAddr().String(), "metrics_prefix": "ci"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Outputs["metrics_error"] != nil {
+		t.Fatalf("unexpected metrics_error: %v", resp.Outputs["metrics_error"])
+	}
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no statsd packet: %v", err)
+	}
+	if packet := string(buf[:n]); !strings.Contains(packet, "ci.publish.attempts:1|g") || !strings.Contains(packet, "ci.publish.success:1|c") {
+		t.Errorf("unexpected statsd packet %q", packet)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// mixLockFile is the Mix dependency lock file name.
+const mixLockFile = "mix.lock"
+
+var (
+	// mixLockHexPattern matches `"name": {:hex, :name, "1.2.3", ...`.
+	mixLockHexPattern = regexp.MustCompile(`(?m)^\s*"([^"]+)":\s*\{:hex,\s*:[^,]+,\s*"([^"]+)"`)
+	// mixLockGitPattern matches `"name": {:git, "url", "ref", ...`.
+	mixLockGitPattern = regexp.MustCompile(`(?m)^\s*"([^"]+)":\s*\{:git,\s*"[^"]*",\s*"([^"]+)"`)
+)
+
+// parseMixLock returns the locked version of each dependency. Git
+// dependencies are identified by their abbreviated commit.
+func parseMixLock(content string) map[string]string {
+	deps := make(map[string]string)
+	for _, m := range mixLockHexPattern.FindAllStringSubmatch(content, -1) {
+		deps[m[1]] = m[2]
+	}
+	for _, m := range mixLockGitPattern.FindAllStringSubmatch(content, -1) {
+		ref := m[2]
+		if len(ref) > 7 {
+			ref = ref[:7]
+		}
+		deps[m[1]] = ref
+	}
+	return deps
+}
+
+// DependencyVersion is a dependency at a single version.
+type DependencyVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// DependencyUpdate is a dependency whose locked version changed.
+type DependencyUpdate struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DependencyChanges lists the mix.lock differences between two releases.
+type DependencyChanges struct {
+	Since      string              `json:"since"`
+	Added      []DependencyVersion `json:"added"`
+	Removed    []DependencyVersion `json:"removed"`
+	Upgraded   []DependencyUpdate  `json:"upgraded"`
+	Downgraded []DependencyUpdate  `json:"downgraded"`
+}
+
+// empty reports whether no dependency changed.
+func (c *DependencyChanges) empty() bool {
+	return len(c.Added)+len(c.Removed)+len(c.Upgraded)+len(c.Downgraded) == 0
+}
+
+// diffMixLock compares two parsed lock files, sorted by dependency name.
+func diffMixLock(previous, current map[string]string) *DependencyChanges {
+	changes := &DependencyChanges{
+		Added:      []DependencyVersion{},
+		Removed:    []DependencyVersion{},
+		Upgraded:   []DependencyUpdate{},
+		Downgraded: []DependencyUpdate{},
+	}
+
+	for name, version := range current {
+		old, ok := previous[name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, DependencyVersion{Name: name, Version: version})
+		case old == version:
+		case compareVersions(version, old) < 0:
+			changes.Downgraded = append(changes.Downgraded, DependencyUpdate{Name: name, From: old, To: version})
+		default:
+			changes.Upgraded = append(changes.Upgraded, DependencyUpdate{Name: name, From: old, To: version})
+		}
+	}
+	for name, version := range previous {
+		if _, ok := current[name]; !ok {
+			changes.Removed = append(changes.Removed, DependencyVersion{Name: name, Version: version})
+		}
+	}
+
+	sort.Slice(changes.Added, func(i, j int) bool { return changes.Added[i].Name < changes.Added[j].Name })
+	sort.Slice(changes.Removed, func(i, j int) bool { return changes.Removed[i].Name < changes.Removed[j].Name })
+	sort.Slice(changes.Upgraded, func(i, j int) bool { return changes.Upgraded[i].Name < changes.Upgraded[j].Name })
+	sort.Slice(changes.Downgraded, func(i, j int) bool { return changes.Downgraded[i].Name < changes.Downgraded[j].Name })
+	return changes
+}
+
+// compareVersions compares dotted versions numerically segment by segment,
+// falling back to string comparison for non-numeric segments.
+func compareVersions(a, b string) int {
+	as := strings.FieldsFunc(a, func(r rune) bool { return r == '.' || r == '-' || r == '+' })
+	bs := strings.FieldsFunc(b, func(r rune) bool { return r == '.' || r == '-' || r == '+' })
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		ai, aErr := strconv.Atoi(as[i])
+		bi, bErr := strconv.Atoi(bs[i])
+		if aErr == nil && bErr == nil {
+			if ai != bi {
+				if ai < bi {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// markdown renders the changes as a "Dependency changes" release notes section.
+func (c *DependencyChanges) markdown() string {
+	if c.empty() {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("### Dependency changes\n\n")
+	for _, d := range c.Added {
+		fmt.Fprintf(&b, "- Added `%s` %s\n", d.Name, d.Version)
+	}
+	for _, d := range c.Upgraded {
+		fmt.Fprintf(&b, "- Upgraded `%s` %s → %s\n", d.Name, d.From, d.To)
+	}
+	for _, d := range c.Downgraded {
+		fmt.Fprintf(&b, "- Downgraded `%s` %s → %s\n", d.Name, d.From, d.To)
+	}
+	for _, d := range c.Removed {
+		fmt.Fprintf(&b, "- Removed `%s` %s\n", d.Name, d.Version)
+	}
+	return b.String()
+}
+
+// previousTag returns the tag of the previous release, reusing the prefix of
+// the current tag (e.g. "v") when previous_tag is not configured.
+func previousTag(cfg *Config, releaseCtx plugin.ReleaseContext) string {
+	if cfg.PreviousTag != "" {
+		return cfg.PreviousTag
+	}
+	if releaseCtx.PreviousVersion == "" {
+		return ""
+	}
+	prefix := ""
+	if releaseCtx.TagName != "" && releaseCtx.Version != "" {
+		prefix = strings.TrimSuffix(releaseCtx.TagName, releaseCtx.Version)
+		if prefix == releaseCtx.TagName {
+			prefix = ""
+		}
+	}
+	return prefix + strings.TrimPrefix(releaseCtx.PreviousVersion, prefix)
+}
+
+// dependencyChanges compares mix.lock at the previous release tag with the working tree.
+func (p *HexPlugin) dependencyChanges(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, summary *RunSummary) (*DependencyChanges, error) {
+	tag := previousTag(cfg, releaseCtx)
+	if tag == "" {
+		return nil, fmt.Errorf("no previous release to compare against: set previous_tag")
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.WorkDir, mixLockFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", mixLockFile, err)
+	}
+
+	// ./ resolves the path relative to work_dir instead of the repository root
+	previous, err := p.runCommand(ctx, summary, "git", []string{"show", tag + ":./" + mixLockFile}, nil, cfg.WorkDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %v", mixLockFile, tag, err)
+	}
+
+	changes := diffMixLock(parseMixLock(string(previous)), parseMixLock(string(data)))
+	changes.Since = tag
+	return changes, nil
+}
+
+// addDependencyChanges records dependency changes in the outputs. Failures
+// never block the publish; they are reported as dependency_changes_error.
+func (p *HexPlugin) addDependencyChanges(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, outputs map[string]any, summary *RunSummary) {
+	changes, err := p.dependencyChanges(ctx, cfg, releaseCtx, summary)
+	if err != nil {
+		outputs["dependency_changes_error"] = err.Error()
+		return
+	}
+	outputs["dependency_changes"] = changes
+	outputs["dependency_changes_markdown"] = changes.markdown()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const previousMixLock = `%{
+  "decimal": {:hex, :decimal, "2.1.1", "5611dca5", [:mix], [], "hexpm", "53cfe5f4"},
+  "jason": {:hex, :jason, "1.4.1", "af1504e3", [:mix], [{:decimal, "~> 1.0 or ~> 2.0", [hex: :decimal, repo: "hexpm", optional: true]}], "hexpm", "fbb01ecd"},
+  "plug": {:hex, :plug, "1.15.0", "aa", [:mix], [], "hexpm", "bb"},
+  "telemetry": {:hex, :telemetry, "1.2.1", "68fdfe8d", [:rebar3], [], "hexpm", "dad9ce9d"},
+}
+`
+
+const currentMixLock = `%{
+  "decimal": {:hex, :decimal, "2.1.1", "5611dca5", [:mix], [], "hexpm", "53cfe5f4"},
+  "jason": {:hex, :jason, "1.4.4", "b9226785", [:mix], [], "hexpm", "c5eb0cab"},
+  "my_fork": {:git, "https://github.com/acme/my_fork.git", "0123456789abcdef", [branch: "main"]},
+  "plug": {:hex, :plug, "1.14.2", "cc", [:mix], [], "hexpm", "dd"},
+}
+`
+
+func TestParseMixLock(t *testing.T) {
+	deps := parseMixLock(currentMixLock)
+	expected := map[string]string{"decimal": "2.1.1", "jason": "1.4.4", "my_fork": "0123456", "plug": "1.14.2"}
+	if len(deps) != len(expected) {
+		t.Fatalf("got %v", deps)
+	}
+	for name, version := range expected {
+		if deps[name] != version {
+			t.Errorf("%s: got %q, expected %q", name, deps[name], version)
+		}
+	}
+}
+
+func TestDiffMixLock(t *testing.T) {
+	changes := diffMixLock(parseMixLock(previousMixLock), parseMixLock(currentMixLock))
+
+	if len(changes.Added) != 1 || changes.Added[0].Name != "my_fork" {
+		t.Errorf("added: %+v", changes.Added)
+	}
+	if len(changes.Removed) != 1 || changes.Removed[0].Name != "telemetry" {
+		t.Errorf("removed: %+v", changes.Removed)
+	}
+	if len(changes.Upgraded) != 1 || changes.Upgraded[0] != (DependencyUpdate{Name: "jason", From: "1.4.1", To: "1.4.4"}) {
+		t.Errorf("upgraded: %+v", changes.Upgraded)
+	}
+	if len(changes.Downgraded) != 1 || changes.Downgraded[0].Name != "plug" {
+		t.Errorf("downgraded: %+v", changes.Downgraded)
+	}
+
+	md := changes.markdown()
+	for _, want := range []string{"### Dependency changes", "Added `my_fork`", "Upgraded `jason` 1.4.1 → 1.4.4", "Removed `telemetry`"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	if diffMixLock(parseMixLock(currentMixLock), parseMixLock(currentMixLock)).markdown() != "" {
+		t.Error("expected no markdown without changes")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.10.0", "1.9.0", 1},
+		{"1.4.1", "1.4.4", -1},
+		{"2.0.0", "2.0.0", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.expected {
+			t.Errorf("compareVersions(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestPreviousTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		ctx      plugin.ReleaseContext
+		expected string
+	}{
+		{name: "configured tag", cfg: Config{PreviousTag: "release-1"}, ctx: plugin.ReleaseContext{PreviousVersion: "1.0.0"}, expected: "release-1"},
+		{name: "tag prefix is reused", ctx: plugin.ReleaseContext{Version: "1.2.0", TagName: "v1.2.0", PreviousVersion: "1.1.0"}, expected: "v1.1.0"},
+		{name: "no prefix", ctx: plugin.ReleaseContext{Version: "1.2.0", TagName: "1.2.0", PreviousVersion: "1.1.0"}, expected: "1.1.0"},
+		{name: "no previous version", ctx: plugin.ReleaseContext{Version: "1.0.0", TagName: "v1.0.0"}, expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := previousTag(&tt.cfg, tt.ctx); got != tt.expected {
+				t.Errorf("got %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteDependencyChanges(t *testing.T) {
+	dir := chdirTemp(t)
+	writeFile(t, filepath.Join(dir, mixLockFile), currentMixLock)
+
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			if name == "git" {
+				return []byte(previousMixLock), nil
+			}
+			return []byte("Building my_lib 1.2.0"), nil
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "k", "dependency_changes": true},
+		Context: plugin.ReleaseContext{Version: "1.2.0", TagName: "v1.2.0", PreviousVersion: "1.1.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got %s", resp.Error)
+	}
+
+	if mock.Calls[0].Name != "git" || strings.Join(mock.Calls[0].Args, " ") != "show v1.1.0:./mix.lock" {
+		t.Errorf("unexpected git call: %+v", mock.Calls[0])
+	}
+	changes, ok := resp.Outputs["dependency_changes"].(*DependencyChanges)
+	if !ok || changes.Since != "v1.1.0" || len(changes.Upgraded) != 1 {
+		t.Errorf("unexpected dependency changes: %+v", resp.Outputs["dependency_changes"])
+	}
+	if md, _ := resp.Outputs["dependency_changes_markdown"].(string); !strings.Contains(md, "Dependency changes") {
+		t.Errorf("unexpected markdown: %q", md)
+	}
+
+	t.Run("missing previous release does not block the publish", func(t *testing.T) {
+		resp, _ := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "k", "dependency_changes": true},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		if _, ok := resp.Outputs["dependency_changes_error"]; !ok {
+			t.Error("expected dependency_changes_error output")
+		}
+	})
+}
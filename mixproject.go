@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// mixExsFile is the Mix project definition file name.
+const mixExsFile = "mix.exs"
+
+// readMixExs reads the mix.exs file from the working directory.
+func readMixExs(workDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(workDir, mixExsFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", mixExsFile, err)
+	}
+	return string(data), nil
+}
+
+var (
+	mixLicensesPattern = regexp.MustCompile(`licenses:\s*\[([^\]]*)\]`)
+	mixStringPattern   = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// parseMixLicenses extracts the licenses declared in the package metadata of mix.exs.
+func parseMixLicenses(content string) []string {
+	m := mixLicensesPattern.FindStringSubmatch(content)
+	if m == nil {
+		return nil
+	}
+
+	var licenses []string
+	for _, s := range mixStringPattern.FindAllStringSubmatch(m[1], -1) {
+		licenses = append(licenses, s[1])
+	}
+	return licenses
+}
+
+var (
+	mixDescriptionPattern = regexp.MustCompile(`description:\s*"((?:[^"\\]|\\.)*)"`)
+	mixAppPattern         = regexp.MustCompile(`app:\s*:([a-z_][a-zA-Z0-9_]*)`)
+	mixAppsPathPattern    = regexp.MustCompile(`apps_path:\s*"([^"]*)"`)
+)
+
+// parseMixDescription extracts the package description from mix.exs.
+func parseMixDescription(content string) string {
+	if m := mixDescriptionPattern.FindStringSubmatch(content); m != nil {
+		return strings.ReplaceAll(m[1], `\"`, `"`)
+	}
+	return ""
+}
+
+// parseMixApp extracts the application name from mix.exs, which is the
+// package name unless package metadata overrides it.
+func parseMixApp(content string) string {
+	if m := mixAppPattern.FindStringSubmatch(content); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// parseMixAppsPath extracts apps_path from the mix.exs of an umbrella
+// project. It reports false for projects that are not umbrellas.
+func parseMixAppsPath(content string) (string, bool) {
+	if m := mixAppsPathPattern.FindStringSubmatch(content); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMixLicenses(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "single license",
+			content:  `[licenses: ["MIT"], links: %{}]`,
+			expected: []string{"MIT"},
+		},
+		{
+			name: "multiline licenses",
+			content: `licenses: [
+        "Apache-2.0",
+        "MIT"
+      ],`,
+			expected: []string{"Apache-2.0", "MIT"},
+		},
+		{
+			name:     "no licenses",
+			content:  `[links: %{}]`,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMixLicenses(tt.content); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("got %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Statuses of a first-publish check.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+var (
+	// hexPackageNamePattern is the format Hex accepts for package names.
+	hexPackageNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	// mixLinksPattern matches a non-empty links: map in the package metadata.
+	mixLinksPattern = regexp.MustCompile(`links:\s*%\{\s*[^}\s]`)
+)
+
+// OnboardingCheck is one item of the first-publish checklist.
+type OnboardingCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// OnboardingReport is the first-publish checklist of a package that has
+// never been published. Report renders it for reading in the release log.
+type OnboardingReport struct {
+	Package    string            `json:"package"`
+	Repository string            `json:"repository"`
+	Checks     []OnboardingCheck `json:"checks"`
+	Report     string            `json:"report"`
+}
+
+// add appends a check result.
+func (r *OnboardingReport) add(name, status, format string, args ...any) {
+	r.Checks = append(r.Checks, OnboardingCheck{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// failed lists the checks that block the first publish.
+func (r *OnboardingReport) failed() []string {
+	var failed []string
+	for _, c := range r.Checks {
+		if c.Status == checkFail {
+			failed = append(failed, fmt.Sprintf("%s (%s)", c.Name, c.Detail))
+		}
+	}
+	return failed
+}
+
+// render writes the checklist as text, one line per check.
+func (r *OnboardingReport) render() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "First publish of %s to %s\n", r.Package, r.Repository)
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "  [%s] %s: %s\n", c.Status, c.Name, c.Detail)
+	}
+	return b.String()
+}
+
+// checkMetadata checks the package metadata Hex requires or recommends.
+func checkMetadata(content string) (string, string) {
+	var missing, recommended []string
+	if parseMixDescription(content) == "" {
+		missing = append(missing, "description")
+	}
+	if len(parseMixLicenses(content)) == 0 {
+		recommended = append(recommended, "licenses")
+	}
+	if !mixLinksPattern.MatchString(content) {
+		recommended = append(recommended, "links")
+	}
+	switch {
+	case len(missing) > 0:
+		return checkFail, fmt.Sprintf("mix.exs does not declare %s, which Hex requires", strings.Join(missing, ", "))
+	case len(recommended) > 0:
+		return checkWarn, fmt.Sprintf("mix.exs does not declare %s, which hex.pm shows on the package page", strings.Join(recommended, ", "))
+	}
+	return checkPass, "description, licenses and links are declared"
+}
+
+// firstPublishReport looks the package up and, when it has never been
+// published, runs the first-publish checklist. It returns a nil report for
+// packages already on the registry.
+func (p *HexPlugin) firstPublishReport(ctx context.Context, cfg *Config, summary *RunSummary) (*OnboardingReport, error) {
+	content, err := readMixExs(cfg.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	name, file := declaredPackageName(cfg.WorkDir, projectTypeMix)
+	if name == "" {
+		return nil, fmt.Errorf("could not determine the package name from %s", file)
+	}
+
+	api := p.hexAPI(cfg)
+	err = api.do(ctx, http.MethodGet, packagePath(cfg.Organization, name), nil, nil)
+	var apiErr *hexAPIError
+	if err == nil {
+		summary.debugf("package %s is already published; skipping first-publish checks", name)
+		return nil, nil
+	}
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		return nil, fmt.Errorf("failed to look up package %s: %w", name, err)
+	}
+	summary.debugf("package %s has never been published; running first-publish checks", name)
+
+	report := &OnboardingReport{Package: name, Repository: "hex.pm"}
+	if cfg.Organization != "" {
+		report.Repository = "organization " + cfg.Organization
+	}
+
+	if hexPackageNamePattern.MatchString(name) && len(name) >= 2 {
+		report.add("name", checkPass, "%s is available", name)
+	} else {
+		report.add("name", checkFail, "%s is not a valid Hex package name: use lowercase letters, digits and underscores, starting with a letter", name)
+	}
+
+	status, detail := checkMetadata(content)
+	report.add("metadata", status, "%s", detail)
+
+	if cfg.Organization != "" {
+		report.add("owners", checkPass, "the package will belong to organization %s", cfg.Organization)
+	} else if user, err := api.currentUser(ctx); err != nil {
+		report.add("owners", checkWarn, "could not determine the key's user, who becomes the first owner: %v", err)
+	} else if len(cfg.FirstPublishOwners) == 0 {
+		report.add("owners", checkWarn, "%s will be the only owner; list co-owners in first_publish_owners so the package is not orphaned", user.Username)
+	} else {
+		report.add("owners", checkPass, "%s will be the first owner", user.Username)
+	}
+	for _, owner := range cfg.FirstPublishOwners {
+		exists, err := api.userExists(ctx, owner)
+		switch {
+		case err != nil:
+			report.add("owner "+owner, checkWarn, "could not look up the user: %v", err)
+		case !exists:
+			report.add("owner "+owner, checkFail, "no Hex user %s exists", owner)
+		default:
+			report.add("owner "+owner, checkPass, "add after publishing with mix hex.owner add %s %s", name, owner)
+		}
+	}
+
+	if cfg.Organization != "" {
+		err := api.getOrganization(ctx, cfg.Organization)
+		switch {
+		case err == nil:
+			report.add("organization", checkPass, "the API key has access to organization %s", cfg.Organization)
+		case errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound:
+			report.add("organization", checkFail, "organization %s does not exist", cfg.Organization)
+		case errors.As(err, &apiErr) && (apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden):
+			report.add("organization", checkFail, "the API key is not a member of organization %s", cfg.Organization)
+		default:
+			report.add("organization", checkWarn, "could not check membership of organization %s: %v", cfg.Organization, err)
+		}
+	}
+
+	report.Report = report.render()
+	return report, nil
+}
+
+// checkFirstPublish runs the first-publish checklist when first_publish_checks
+// is set, reporting it in the onboarding output. Lookup failures are reported
+// as onboarding_error without blocking; failed checks return an error.
+func (p *HexPlugin) checkFirstPublish(ctx context.Context, cfg *Config, outputs map[string]any, summary *RunSummary) error {
+	report, err := p.firstPublishReport(ctx, cfg, summary)
+	if err != nil {
+		outputs["onboarding_error"] = err.Error()
+		return nil
+	}
+	if report == nil {
+		return nil
+	}
+	outputs["onboarding"] = report
+	if failed := report.failed(); len(failed) > 0 {
+		return fmt.Errorf("first-publish checks failed for %s, nothing was published: %s", report.Package, strings.Join(failed, "; "))
+	}
+	return nil
+}
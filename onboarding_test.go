@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestCheckMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantStatus string
+		wantDetail string
+	}{
+		{
+			name:       "complete",
+			content:    `description: "A library", package: [licenses: ["MIT"], links: %{"GitHub" => "https://github.com/acme/my_lib"}]`,
+			wantStatus: checkPass,
+		},
+		{
+			name:       "no links",
+			content:    `description: "A library", package: [licenses: ["MIT"], links: %{}]`,
+			wantStatus: checkWarn,
+			wantDetail: "links",
+		},
+		{
+			name:       "no description",
+			content:    `package: [licenses: ["MIT"]]`,
+			wantStatus: checkFail,
+			wantDetail: "description",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, detail := checkMetadata(tt.content)
+			if status != tt.wantStatus || !strings.Contains(detail, tt.wantDetail) {
+				t.Errorf("checkMetadata() = %s, %q; want %s containing %q", status, detail, tt.wantStatus, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestExecuteFirstPublishChecks(t *testing.T) {
+	const mixExs = `defmodule MyLib.MixProject do
+  def project, do: [app: :my_lib, version: "1.0.0", description: "A library", package: package()]
+  defp package, do: [licenses: ["MIT"], links: %{"GitHub" => "https://github.com/acme/my_lib"}]
+end`
+
+	tests := []struct {
+		name           string
+		mixExs         string
+		config         map[string]any
+		dryRun         bool
+		packageStatus  int
+		orgStatus      int
+		wantSuccess    bool
+		wantOnboarding bool
+		wantStatuses   map[string]string
+		wantError      string
+		wantLookupErr  bool
+		wantPublished  bool
+	}{
+		{
+			name:           "first publish passes",
+			config:         map[string]any{"first_publish_owners": []any{"bob"}},
+			packageStatus:  http.StatusNotFound,
+			wantSuccess:    true,
+			wantOnboarding: true,
+			wantStatuses:   map[string]string{"name": checkPass, "metadata": checkPass, "owners": checkPass, "owner bob": checkPass},
+			wantPublished:  true,
+		},
+		{
+			name:           "sole owner warns",
+			packageStatus:  http.StatusNotFound,
+			wantSuccess:    true,
+			wantOnboarding: true,
+			wantStatuses:   map[string]string{"owners": checkWarn},
+			wantPublished:  true,
+		},
+		{
+			name:           "unknown co-owner blocks the publish",
+			config:         map[string]any{"first_publish_owners": []any{"nobody"}},
+			packageStatus:  http.StatusNotFound,
+			wantSuccess:    false,
+			wantOnboarding: true,
+			wantStatuses:   map[string]string{"owner nobody": checkFail},
+			wantError:      "first-publish checks failed for my_lib",
+		},
+		{
+			name:           "unknown co-owner is reported in a dry run",
+			config:         map[string]any{"first_publish_owners": []any{"nobody"}},
+			dryRun:         true,
+			packageStatus:  http.StatusNotFound,
+			wantSuccess:    true,
+			wantOnboarding: true,
+			wantStatuses:   map[string]string{"owner nobody": checkFail},
+		},
+		{
+			name:           "not a member of the organization",
+			config:         map[string]any{"organization": "acme"},
+			packageStatus:  http.StatusNotFound,
+			orgStatus:      http.StatusForbidden,
+			wantSuccess:    false,
+			wantOnboarding: true,
+			wantStatuses:   map[string]string{"owners": checkPass, "organization": checkFail},
+			wantError:      "not a member of organization acme",
+		},
+		{
+			name:          "already published",
+			packageStatus: http.StatusOK,
+			wantSuccess:   true,
+			wantPublished: true,
+		},
+		{
+			name:          "package name differs from the app",
+			mixExs:        `def project, do: [app: :my_app, version: "1.0.0", package: [name: "my_lib"]]`,
+			packageStatus: http.StatusOK,
+			wantSuccess:   true,
+			wantPublished: true,
+		},
+		{
+			name:          "lookup failure does not block",
+			packageStatus: http.StatusServiceUnavailable,
+			wantSuccess:   true,
+			wantLookupErr: true,
+			wantPublished: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/packages/my_lib", "/repos/acme/packages/my_lib":
+					w.WriteHeader(tt.packageStatus)
+					_, _ = w.Write([]byte(`{"name":"my_lib"}`))
+				case "/users/me":
+					_, _ = w.Write([]byte(`{"username":"alice"}`))
+				case "/users/bob":
+					_, _ = w.Write([]byte(`{"username":"bob"}`))
+				case "/orgs/acme":
+					w.WriteHeader(tt.orgStatus)
+					_, _ = w.Write([]byte(`{}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			chdirTemp(t)
+			if tt.mixExs != "" {
+				writeFile(t, "mix.exs", tt.mixExs)
+			} else {
+				writeFile(t, "mix.exs", mixExs)
+			}
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "api_url": server.URL, "first_publish_checks": true}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				DryRun:  tt.dryRun,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+			}
+			if _, ok := resp.Outputs["onboarding_error"]; ok != tt.wantLookupErr {
+				t.Errorf("onboarding_error = %v, want present %v", resp.Outputs["onboarding_error"], tt.wantLookupErr)
+			}
+
+			report, ok := resp.Outputs["onboarding"].(*OnboardingReport)
+			if ok != tt.wantOnboarding {
+				t.Fatalf("onboarding = %v, want present %v", resp.Outputs["onboarding"], tt.wantOnboarding)
+			}
+			if ok {
+				statuses := map[string]string{}
+				for _, c := range report.Checks {
+					statuses[c.Name] = c.Status
+				}
+				for name, want := range tt.wantStatuses {
+					if statuses[name] != want {
+						t.Errorf("check %s = %q, want %s (report:\n%s)", name, statuses[name], want, report.Report)
+					}
+				}
+				if !strings.HasPrefix(report.Report, "First publish of my_lib") {
+					t.Errorf("report = %q", report.Report)
+				}
+			}
+
+			published := false
+			for _, call := range mock.Calls {
+				if contains(call.Args, "hex.publish") {
+					published = true
+				}
+			}
+			if published != tt.wantPublished {
+				t.Errorf("published = %v, want %v (calls %+v)", published, tt.wantPublished, mock.Calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OrgKey names where an API key is read from, for one organization of
+// org_keys or one entry of packages.
+type OrgKey struct {
+	// APIKeyEnv names the environment variable holding the key.
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+	// APIKeyFile is an absolute path to a file holding the key, such as a
+	// mounted CI secret.
+	APIKeyFile string `json:"api_key_file,omitempty"`
+}
+
+// parseOrgKeys decodes org_keys, keyed by organization. A string value is
+// shorthand for the name of the environment variable holding the key.
+func parseOrgKeys(raw map[string]any) (map[string]OrgKey, *fieldError) {
+	val, ok := raw["org_keys"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, &fieldError{Field: "org_keys", Err: fmt.Errorf("must be an object keyed by organization")}
+	}
+
+	keys := make(map[string]OrgKey, len(obj))
+	for org, entry := range obj {
+		field := "org_keys." + org
+		switch v := entry.(type) {
+		case string:
+			keys[org] = OrgKey{APIKeyEnv: v}
+		case map[string]any:
+			var key OrgKey
+			if err := decodeStrict(v, &key); err != nil {
+				return nil, &fieldError{Field: field, Err: err}
+			}
+			keys[org] = key
+		default:
+			return nil, &fieldError{Field: field, Err: fmt.Errorf("must be an environment variable name or an object")}
+		}
+	}
+	return keys, nil
+}
+
+// validateOrgKeys checks every organization and key source in org_keys.
+func validateOrgKeys(raw map[string]any) []*fieldError {
+	keys, ferr := parseOrgKeys(raw)
+	if ferr != nil {
+		return []*fieldError{ferr}
+	}
+
+	var errs []*fieldError
+	for org, key := range keys {
+		field := "org_keys." + org
+		if err := validateOrganization(org); err != nil {
+			errs = append(errs, &fieldError{Field: field, Err: err})
+		}
+		switch {
+		case (key.APIKeyEnv == "") == (key.APIKeyFile == ""):
+			errs = append(errs, &fieldError{Field: field, Err: fmt.Errorf("set exactly one of api_key_env or api_key_file")})
+		case key.APIKeyFile != "" && !filepath.IsAbs(key.APIKeyFile):
+			errs = append(errs, &fieldError{Field: field + ".api_key_file", Err: fmt.Errorf("must be an absolute path")})
+		}
+	}
+
+	// Keep error order stable for reporting
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// source returns the secret_source of the key.
+func (k OrgKey) source() string {
+	if k.APIKeyFile != "" {
+		return fileSecretSource(k.APIKeyFile)
+	}
+	return envSecretSource(k.APIKeyEnv)
+}
+
+// read returns the key from its environment variable or file.
+func (k OrgKey) read() (string, error) {
+	if k.APIKeyFile != "" {
+		data, err := os.ReadFile(k.APIKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read api_key_file: %w", err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return "", fmt.Errorf("api_key_file %s is empty", k.APIKeyFile)
+		}
+		return key, nil
+	}
+	key := os.Getenv(k.APIKeyEnv)
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s is not set", k.APIKeyEnv)
+	}
+	return key, nil
+}
+
+// validatePackageKey checks the key source of a packages entry. The
+// returned field is relative to the entry.
+func validatePackageKey(pkg PackageConfig) *fieldError {
+	sources := 0
+	for _, set := range []bool{pkg.APIKey != "", pkg.APIKeyEnv != "", pkg.APIKeyFile != ""} {
+		if set {
+			sources++
+		}
+	}
+	switch {
+	case sources > 1:
+		return &fieldError{Field: ".api_key", Err: fmt.Errorf("set at most one of api_key, api_key_env or api_key_file")}
+	case pkg.APIKeyFile != "" && !filepath.IsAbs(pkg.APIKeyFile):
+		return &fieldError{Field: ".api_key_file", Err: fmt.Errorf("must be an absolute path")}
+	}
+	return nil
+}
+
+// applyPackageKey reads the API key of a packages entry that names an
+// api_key_env or api_key_file. It reports whether a package key applied.
+func (c *Config) applyPackageKey() (bool, error) {
+	if c.packageKey == nil {
+		return false, nil
+	}
+	secret, err := c.packageKey.read()
+	if err != nil {
+		return false, fmt.Errorf("package key: %w", err)
+	}
+	c.APIKey = secret
+	c.keySource = c.packageKey.source()
+	return true, nil
+}
+
+// applyOrgKey selects the API key mapped to the target organization in
+// org_keys. It reports whether a mapping applied.
+func (c *Config) applyOrgKey() (bool, error) {
+	key, ok := c.OrgKeys[c.Organization]
+	if c.Organization == "" || !ok {
+		return false, nil
+	}
+	secret, err := key.read()
+	if err != nil {
+		return false, fmt.Errorf("org_keys.%s: %w", c.Organization, err)
+	}
+	c.APIKey = secret
+	c.keySource = key.source()
+	return true, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateOrgKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		orgKeys   any
+		wantField string
+	}{
+		{name: "env shorthand", orgKeys: map[string]any{"acme": "HEX_KEY_ACME"}},
+		{name: "file", orgKeys: map[string]any{"acme": map[string]any{"api_key_file": "/run/secrets/acme"}}},
+		{name: "not an object", orgKeys: []any{"acme"}, wantField: "org_keys"},
+		{name: "invalid organization", orgKeys: map[string]any{"Acme Corp": "KEY"}, wantField: "org_keys.Acme Corp"},
+		{name: "no source", orgKeys: map[string]any{"acme": map[string]any{}}, wantField: "org_keys.acme"},
+		{name: "both sources", orgKeys: map[string]any{"acme": map[string]any{"api_key_env": "KEY", "api_key_file": "/key"}}, wantField: "org_keys.acme"},
+		{name: "relative file", orgKeys: map[string]any{"acme": map[string]any{"api_key_file": "key.txt"}}, wantField: "org_keys.acme.api_key_file"},
+		{name: "unknown field", orgKeys: map[string]any{"acme": map[string]any{"token": "KEY"}}, wantField: "org_keys.acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateOrgKeys(map[string]any{"org_keys": tt.orgKeys})
+			if tt.wantField == "" {
+				if len(errs) > 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || errs[0].Field != tt.wantField {
+				t.Errorf("expected error on %s, got %v", tt.wantField, errs)
+			}
+		})
+	}
+}
+
+func TestExecuteOrgKeys(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "globex.key")
+	if err := os.WriteFile(keyFile, []byte("globex-key-456\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HEX_KEY_ACME", "acme-key-123")
+
+	orgKeys := map[string]any{
+		"acme":    "HEX_KEY_ACME",
+		"globex":  map[string]any{"api_key_file": keyFile},
+		"initech": "HEX_KEY_INITECH_UNSET",
+	}
+
+	tests := []struct {
+		name            string
+		config          map[string]any
+		expectedSuccess bool
+		expectedKey     string
+		expectedError   string
+	}{
+		{
+			name:            "env mapping",
+			config:          map[string]any{"organization": "acme"},
+			expectedSuccess: true,
+			expectedKey:     "acme-key-123",
+		},
+		{
+			name:            "file mapping",
+			config:          map[string]any{"organization": "globex"},
+			expectedSuccess: true,
+			expectedKey:     "globex-key-456",
+		},
+		{
+			name:            "unmapped organization uses api_key",
+			config:          map[string]any{"organization": "umbrella"},
+			expectedSuccess: true,
+			expectedKey:     "default-key",
+		},
+		{
+			name:            "public package uses api_key",
+			config:          map[string]any{},
+			expectedSuccess: true,
+			expectedKey:     "default-key",
+		},
+		{
+			name:          "missing env var",
+			config:        map[string]any{"organization": "initech"},
+			expectedError: "org_keys.initech: environment variable HEX_KEY_INITECH_UNSET is not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "default-key", "org_keys": orgKeys}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if tt.expectedError != "" && !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, resp.Error)
+			}
+			if tt.expectedKey != "" {
+				if len(mock.Calls) != 1 || !contains(mock.Calls[0].Env, "HEX_API_KEY="+tt.expectedKey) {
+					t.Errorf("expected HEX_API_KEY=%s, got calls %+v", tt.expectedKey, mock.Calls)
+				}
+			}
+		})
+	}
+}
+
+func TestForPackageExplicitKeyOverridesOrgKeys(t *testing.T) {
+	cfg := &Config{APIKey: "default-key", OrgKeys: map[string]OrgKey{"acme": {APIKeyEnv: "HEX_KEY_ACME"}}}
+
+	pkg := cfg.forPackage(PackageConfig{WorkDir: "a", Organization: "acme", APIKey: "package-key"})
+	if mapped, err := pkg.applyOrgKey(); err != nil || mapped || pkg.APIKey != "package-key" {
+		t.Errorf("expected the package key to win, got %q (mapped=%v, err=%v)", pkg.APIKey, mapped, err)
+	}
+
+	pkg = cfg.forPackage(PackageConfig{WorkDir: "b", Organization: "acme"})
+	if len(pkg.OrgKeys) == 0 {
+		t.Error("expected org_keys to apply to packages without an explicit key")
+	}
+}
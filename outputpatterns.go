@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// outputPatternName restricts extracted output names to the style of the
+// built-in outputs, so pipelines can reference them as hex.<name>.
+var outputPatternName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// OutputPattern extracts a value from mix hex.publish output into an output.
+type OutputPattern struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// parseOutputPatterns decodes the output_patterns map into patterns sorted
+// by name.
+func parseOutputPatterns(raw map[string]any) ([]OutputPattern, *fieldError) {
+	val, ok := raw["output_patterns"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, &fieldError{Field: "output_patterns", Err: fmt.Errorf("must be an object")}
+	}
+
+	patterns := make([]OutputPattern, 0, len(obj))
+	for name, v := range obj {
+		field := "output_patterns." + name
+		expr, ok := v.(string)
+		if !ok || expr == "" {
+			return nil, &fieldError{Field: field, Err: fmt.Errorf("must be a non-empty regular expression")}
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, &fieldError{Field: field, Err: err}
+		}
+		patterns = append(patterns, OutputPattern{Name: name, Pattern: re})
+	}
+	sort.Slice(patterns, func(i, j int) bool { return patterns[i].Name < patterns[j].Name })
+	return patterns, nil
+}
+
+// validateOutputPatterns checks the expressions and names of
+// output_patterns. Names of built-in outputs are reserved.
+func validateOutputPatterns(raw map[string]any) []*fieldError {
+	patterns, ferr := parseOutputPatterns(raw)
+	if ferr != nil {
+		return []*fieldError{ferr}
+	}
+	var errs []*fieldError
+	for _, pat := range patterns {
+		field := "output_patterns." + pat.Name
+		switch {
+		case !outputPatternName.MatchString(pat.Name):
+			errs = append(errs, &fieldError{Field: field, Err: fmt.Errorf("name must be lowercase letters, digits and underscores")})
+		case slices.ContainsFunc(outputSpecs, func(spec OutputSpec) bool { return spec.Name == pat.Name }):
+			errs = append(errs, &fieldError{Field: field, Err: fmt.Errorf("name is reserved for a built-in output")})
+		}
+	}
+	return errs
+}
+
+// extractOutputs applies output_patterns to the command output. A match
+// records its first capture group, or the whole match when the expression
+// has none; patterns that do not match leave their output unset. Color
+// escapes are removed first.
+func extractOutputs(patterns []OutputPattern, output string, outputs map[string]any) {
+	output = ansiPattern.ReplaceAllString(output, "")
+	for _, pat := range patterns {
+		m := pat.Pattern.FindStringSubmatch(output)
+		if m == nil {
+			continue
+		}
+		if len(m) > 1 {
+			outputs[pat.Name] = m[1]
+		} else {
+			outputs[pat.Name] = m[0]
+		}
+	}
+}
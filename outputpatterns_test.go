@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateOutputPatterns(t *testing.T) {
+	tests := []struct {
+		name      string
+		patterns  any
+		wantField string
+		wantErr   string
+	}{
+		{name: "valid", patterns: map[string]any{"build_id": `Build id: (\S+)`}},
+		{name: "not an object", patterns: []any{"x"}, wantField: "output_patterns", wantErr: "must be an object"},
+		{name: "not a string", patterns: map[string]any{"build_id": 1}, wantField: "output_patterns.build_id", wantErr: "must be a non-empty regular expression"},
+		{name: "bad expression", patterns: map[string]any{"build_id": `(`}, wantField: "output_patterns.build_id", wantErr: "missing closing )"},
+		{name: "bad name", patterns: map[string]any{"Build-ID": `x`}, wantField: "output_patterns.Build-ID", wantErr: "lowercase letters"},
+		{name: "reserved name", patterns: map[string]any{"package": `x`}, wantField: "output_patterns.package", wantErr: "reserved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateOutputPatterns(map[string]any{"output_patterns": tt.patterns})
+			if tt.wantField == "" {
+				if len(errs) > 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tt.wantField || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("errors = %v, want %s: %s", errs, tt.wantField, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteOutputPatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		fail        bool
+		wantOutputs map[string]any
+	}{
+		{name: "publish succeeds", wantOutputs: map[string]any{"build_id": "b-42", "checksum": "Checksum: 9f2c", "missing": nil}},
+		{name: "publish fails", fail: true, wantOutputs: map[string]any{"build_id": "b-42", "checksum": "Checksum: 9f2c", "missing": nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					output := []byte("Building my_lib 1.0.0\n\x1b[32mBuild id: b-42\x1b[0m\nChecksum: 9f2c\n")
+					if tt.fail {
+						return output, errors.New("exit status 1")
+					}
+					return output, nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"api_key": "test-api-key",
+					"output_patterns": map[string]any{
+						"build_id": `Build id: (\S+)`,
+						"checksum": `Checksum: [0-9a-f]+`,
+						"missing":  `Release id: (\d+)`,
+					},
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success == tt.fail {
+				t.Fatalf("success = %v (error %q)", resp.Success, resp.Error)
+			}
+			for name, want := range tt.wantOutputs {
+				if got := resp.Outputs[name]; got != want {
+					t.Errorf("%s = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import "encoding/json"
+
+// OutputSpec describes one output the plugin may produce.
+type OutputSpec struct {
+	Name        string `json:"-"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// outputSpecs declares the outputs pipelines may reference as hex.<name>.
+// Outputs that are only present in some runs are still declared, so a
+// reference can be checked at plan time even when its value is optional.
+var outputSpecs = []OutputSpec{
+	{"version", "string", "Version that was (or would be) published, without the v prefix"},
+	{"major", "integer", "Major component of the version"},
+	{"minor", "integer", "Minor component of the version"},
+	{"patch", "integer", "Patch component of the version"},
+	{"prerelease", "string", "Prerelease identifier of the version, empty for stable releases"},
+	{"is_prerelease", "boolean", "Whether the version is a prerelease"},
+	{"requirement", "string", "Pessimistic version requirement matching the release, e.g. ~> 1.2"},
+	{"source_version", "string", "Release version before strip_build_metadata removed its build suffix"},
+	{"build_metadata", "string", "Build metadata removed by strip_build_metadata"},
+	{"package", "string", "Name of the published package"},
+	{"package_url", "string", "hex.pm page of the published package version"},
+	{"organization", "string", "Hex.pm organization the package was published to"},
+	{"replace", "boolean", "Whether the dry run would replace an existing version"},
+	{"command", "string", "mix command that was (or would be) run"},
+	{"argv", "array", "Argument vector of the mix hex.publish invocation"},
+	{"work_dir", "string", "Directory mix hex.publish ran in"},
+	{"env", "array", "Environment variables passed to mix, with secret values redacted"},
+	{"docs_command", "string", "mix command publishing docs when split_phases is set"},
+	{"hex_config", "array", "mix hex.config commands applied before publishing"},
+	{"output", "string", "Output of mix hex.publish"},
+	{"output_file", "string", "File holding the full mix hex.publish output when it exceeded output_spool_threshold; output then keeps its start and end"},
+	{"exit_code", "integer", "Exit code of the failed mix command"},
+	{"error_class", "string", "Classification of the failure, e.g. auth or network"},
+	{"hint", "string", "Remediation hint for a recognised failure"},
+	{"failure_message_error", "string", "Why failure_message_template could not be rendered"},
+	{"phase_budget", "array", "Budget and elapsed time of each publish phase when deadline_budget is set"},
+	{"chaos", "object", "Phase and failure injected by chaos"},
+	{"metadata_diff", "object", "Dry-run diff of description, licenses, links, files and requirements against the latest published release"},
+	{"metadata_diff_error", "string", "Why metadata_diff could not be computed"},
+	{"dependency_policy", "array", "Dependencies breaking a dependency_policy rule: package, requirement, rule, level (warn or fail) and message"},
+	{"dependency_policy_error", "string", "Why a dry run would fail dependency_policy"},
+	{"project_type", "string", "Project type of work_dir: mix, umbrella, rebar3 or gleam"},
+	{"degraded", "array", "Requested features turned off because the host lacks them, e.g. streaming_logs on hosts that predate it"},
+	{"compat_modes", "object", "How each host-dependent feature was delivered: artifacts, logging and streaming_logs are native on hosts that support them, or outputs"},
+	{"files_hygiene", "array", "Problems with the files: list of mix.exs found by require_explicit_files"},
+	{"onboarding", "object", "First-publish checklist of a package never published before: package, repository, checks with pass, warn or fail status, and a readable report"},
+	{"onboarding_error", "string", "Why the first-publish lookup could not run; the publish is not blocked"},
+	{"secret_findings", "array", "Possible secrets found by scan_secrets in the package files: path, line and rule"},
+	{"retired_dependencies", "array", "Retired dependencies found by hex.audit gates, retired_report and the publish output: package, version, retirement reason and message"},
+	{"retired_dependencies_error", "string", "Why retired_report could not run mix hex.audit"},
+	{"rehearsal", "object", "Local registry rehearsal that preceded the publish: package, and the registry dir and tarball when test_registry_dir is set; the registry commands in a dry run"},
+	{"attempts", "integer", "Number of publish attempts"},
+	{"git_state", "object", "Release context fields compared with the git state of work_dir, with the diverged ones"},
+	{"registry_health", "object", "Registry status page indicator and Hex API status probed by registry_health_check"},
+	{"registry_health_wait", "string", "Time waited for a registry incident to end"},
+	{"registry_health_error", "string", "Why the registry health could not be determined"},
+	{"redaction_audit", "object", "Where the redaction audit looked for masked secrets and where it found any, without the secrets themselves"},
+	{"redaction_audit_error", "string", "Why the redaction audit could not check a written file"},
+	{"events", "array", "Publish lifecycle events (gate_started, gate_passed, gate_failed, build_finished, upload_started, upload_finished, verified), returned when the host cannot take them live"},
+	{"attempt_budget", "object", "Publish attempts of the version counted in state_file against max_attempts_per_version"},
+	{"state_file_error", "string", "Why the publish state file could not be written"},
+	{"phases", "object", "Per-phase results (package, docs) when split_phases is set"},
+	{"bootstrapped_hex", "boolean", "Whether bootstrap_tools installed Hex before publishing"},
+	{"resumed", "boolean", "Whether resume_docs only published docs for an existing release"},
+	{"packages", "array", "Per-package results of a multi-package release"},
+	{"rollback", "array", "Packages an atomic multi-package release retired after a later package failed, with any retirement error"},
+	{"retire_preview", "array", "Dry-run list of the retirements canary or atomic would make: package, version, organization, reason, message, the trigger that causes each, and the mix hex.retire command"},
+	{"gates", "array", "Results of the pre-publish gates"},
+	{"warnings", "array", "Warnings printed by mix hex.publish"},
+	{"dirty_files", "array", "Uncommitted files reported by dirty_worktree"},
+	{"profile", "string", "Profile applied to the run"},
+	{"secret_source", "string", "Where the API key came from"},
+	{"publish_window", "string", "Publish window that allowed or deferred the run"},
+	{"publish_window_wait", "string", "Time spent waiting for the publish window"},
+	{"freeze_reason", "string", "Why a release freeze skipped the publish"},
+	{"skipped", "boolean", "Whether the publish was skipped"},
+	{"source_date_epoch", "integer", "SOURCE_DATE_EPOCH used for a reproducible build"},
+	{"manifest", "object", "Package file manifest"},
+	{"manifest_size", "integer", "Total size in bytes of the package files"},
+	{"announcement", "object", "Release announcement payload for notification plugins, including the CI run that published it"},
+	{"summary_path", "string", "Path of the JSON run summary"},
+	{"summary_markdown", "string", "Markdown summary of the run"},
+	{"transcript", "array", "Commands run during the hook"},
+	{"transcript_archive", "string", "Run directory under transcript_dir holding transcript.json and the full redacted output of each command"},
+	{"transcript_archive_error", "string", "Why the transcript could not be archived; the publish result is unaffected"},
+	{"diagnostics_path", "string", "Directory of the diagnostics bundle written after a failure"},
+	{"toolchain", "object", "Detected Elixir, OTP and Hex versions in debug mode"},
+	{"metrics_error", "string", "Why exporting publish metrics failed"},
+	{"preview", "array", "PreApprove preview of each package: name, version, registry, organization, replace, manifest and gates"},
+	{"plan", "array", "PostPlan publish plan of each package: name, version, registry, organization, replace and docs targets"},
+	{"version_files", "array", "mix.exs files bump_version rewrote (or would rewrite) on the version hooks: file, previous versions and whether it changed"},
+	{"preview_markdown", "string", "PreApprove preview rendered as markdown for the approval step"},
+}
+
+// outputSchema returns the JSON schema of the plugin outputs.
+func outputSchema() map[string]any {
+	properties := make(map[string]any, len(outputSpecs))
+	for _, spec := range outputSpecs {
+		properties[spec.Name] = spec
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// withOutputSchema embeds the outputs schema into a config schema under the
+// x-outputs extension keyword, since plugin.Info has no field of its own for it.
+func withOutputSchema(configSchema string) string {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(configSchema), &schema); err != nil {
+		return configSchema
+	}
+	schema["x-outputs"] = outputSchema()
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return configSchema
+	}
+	return string(data)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestOutputSchemaInInfo(t *testing.T) {
+	var schema struct {
+		Properties map[string]any `json:"properties"`
+		Outputs    struct {
+			Properties map[string]OutputSpec `json:"properties"`
+		} `json:"x-outputs"`
+	}
+	if err := json.Unmarshal([]byte((&HexPlugin{}).GetInfo().ConfigSchema), &schema); err != nil {
+		t.Fatalf("config schema is not valid JSON: %v", err)
+	}
+	if _, ok := schema.Properties["api_key"]; !ok {
+		t.Error("config properties missing api_key")
+	}
+	for _, name := range []string{"version", "package", "package_url", "error_class"} {
+		spec, ok := schema.Outputs.Properties[name]
+		if !ok {
+			t.Errorf("x-outputs missing %s", name)
+			continue
+		}
+		if spec.Type == "" || spec.Description == "" {
+			t.Errorf("x-outputs %s = %+v, want type and description", name, spec)
+		}
+	}
+}
+
+func TestOutputSpecsUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, spec := range outputSpecs {
+		if seen[spec.Name] {
+			t.Errorf("output %s declared twice", spec.Name)
+		}
+		seen[spec.Name] = true
+	}
+}
+
+func TestPublishOutputsDeclared(t *testing.T) {
+	declared := map[string]bool{}
+	for _, spec := range outputSpecs {
+		declared[spec.Name] = true
+	}
+
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "dry run", dryRun: true},
+		{name: "publish", dryRun: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte("Building my_pkg 1.0.0\nPackage published"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key"},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+				DryRun:  tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("unexpected failure: %s", resp.Error)
+			}
+			for name := range resp.Outputs {
+				if !declared[name] {
+					t.Errorf("output %s is not declared in outputSpecs", name)
+				}
+			}
+		})
+	}
+}
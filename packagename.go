@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	// mixPackageBlockPattern matches the opening of the package metadata
+	// keyword list, inline or in a package function.
+	mixPackageBlockPattern = regexp.MustCompile(`(?:\bpackage:\s*|\bdefp?\s+package(?:\(\))?\s*(?:,\s*do:|do)\s*)\[`)
+	// mixPackageNamePattern matches the name override in package metadata.
+	mixPackageNamePattern = regexp.MustCompile(`\bname:\s*"([^"]+)"`)
+)
+
+// parseMixPackageName extracts the package name set in the package metadata
+// of mix.exs, which overrides the application name on Hex. It returns ""
+// when the metadata does not override it.
+func parseMixPackageName(content string) string {
+	for _, loc := range mixPackageBlockPattern.FindAllStringIndex(content, -1) {
+		block := content[loc[1]:]
+		block = block[:closingBracket(block)]
+		if m := mixPackageNamePattern.FindStringSubmatch(block); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// closingBracket returns the index of the bracket closing a list whose
+// opening bracket precedes s, or len(s) when the list is unterminated.
+func closingBracket(s string) int {
+	depth := 1
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// declaredPackageName reads the name the project publishes under and the
+// file declaring it.
+func declaredPackageName(dir, projectType string) (string, string) {
+	switch projectType {
+	case projectTypeGleam:
+		return nativePackageName(dir, projectType), gleamTomlFile
+	case projectTypeRebar3:
+		return nativePackageName(dir, projectType), filepath.Join("src", "*.app.src")
+	}
+	content, err := readMixExs(dir)
+	if err != nil {
+		return "", mixExsFile
+	}
+	if name := parseMixPackageName(content); name != "" {
+		return name, mixExsFile
+	}
+	return parseMixApp(content), mixExsFile
+}
+
+// validatePackageName checks that package_name is a valid Hex package name.
+func validatePackageName(name string) *fieldError {
+	if name == "" || hexPackageNamePattern.MatchString(name) {
+		return nil
+	}
+	return &fieldError{Field: "package_name", Err: fmt.Errorf("%q is not a valid Hex package name: use lowercase letters, digits and underscores, starting with a letter", name)}
+}
+
+// checkPackageName cross-checks package_name against the name the project
+// declares, so a pipeline template given the wrong project fails instead of
+// publishing it.
+func checkPackageName(cfg *Config, projectType string) error {
+	if cfg.PackageName == "" {
+		return nil
+	}
+	declared, file := declaredPackageName(cfg.WorkDir, projectType)
+	if declared == "" {
+		return fmt.Errorf("package_name is %s, but no package name could be read from %s in %s", cfg.PackageName, file, cfg.WorkDir)
+	}
+	if declared != cfg.PackageName {
+		return fmt.Errorf("package_name is %s, but %s in %s declares %s; nothing was published", cfg.PackageName, file, cfg.WorkDir, declared)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseMixPackageName(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "inline package metadata",
+			content: `def project, do: [app: :my_lib, name: "MyLib", package: [name: "my_lib_core", licenses: ["MIT"]]]`,
+			want:    "my_lib_core",
+		},
+		{
+			name: "package function",
+			content: `def project do
+    [app: :my_lib, name: "MyLib", package: package()]
+  end
+
+  defp package do
+    [
+      name: "my_lib_core",
+      links: %{"GitHub" => "https://github.com/acme/my_lib"}
+    ]
+  end`,
+			want: "my_lib_core",
+		},
+		{
+			name:    "docs name only",
+			content: `def project, do: [app: :my_lib, name: "MyLib", package: [licenses: ["MIT"]]]`,
+		},
+		{
+			name:    "no package metadata",
+			content: `def project, do: [app: :my_lib, name: "MyLib"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMixPackageName(tt.content); got != tt.want {
+				t.Errorf("parseMixPackageName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutePackageName(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		config      map[string]any
+		wantSuccess bool
+		wantError   string
+	}{
+		{
+			name:        "matches the app",
+			files:       map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			config:      map[string]any{"package_name": "my_lib"},
+			wantSuccess: true,
+		},
+		{
+			name:        "matches the package metadata name",
+			files:       map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0", package: [name: "my_lib_core"]]`},
+			config:      map[string]any{"package_name": "my_lib_core"},
+			wantSuccess: true,
+		},
+		{
+			name:      "the app is not the package name",
+			files:     map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0", package: [name: "my_lib_core"]]`},
+			config:    map[string]any{"package_name": "my_lib"},
+			wantError: "package_name is my_lib, but mix.exs in . declares my_lib_core",
+		},
+		{
+			name:      "wrong project",
+			files:     map[string]string{"mix.exs": `def project, do: [app: :other_lib, version: "1.0.0"]`},
+			config:    map[string]any{"package_name": "my_lib"},
+			wantError: "declares other_lib; nothing was published",
+		},
+		{
+			name:      "gleam project",
+			files:     map[string]string{"gleam.toml": "name = \"other_lib\"\nversion = \"1.0.0\"\n"},
+			config:    map[string]any{"package_name": "my_lib"},
+			wantError: "gleam.toml in . declares other_lib",
+		},
+		{
+			name:      "invalid name",
+			files:     map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			config:    map[string]any{"package_name": "My-Lib"},
+			wantError: "invalid package_name",
+		},
+		{
+			name:  "checked per package",
+			files: map[string]string{"mix.exs": `def project, do: [app: :my_lib, version: "1.0.0"]`},
+			config: map[string]any{"package_name": "ignored", "packages": []any{
+				map[string]any{"work_dir": ".", "package_name": "other_lib"},
+			}},
+			wantError: "declares my_lib",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			for path, content := range tt.files {
+				writeFile(t, path, content)
+			}
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+			}
+			if !tt.wantSuccess && len(mock.Calls) > 0 {
+				t.Errorf("expected no commands, got %+v", mock.Calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// label returns a human-readable name for the package entry.
+func (pkg PackageConfig) label() string {
+	if pkg.Name != "" {
+		return pkg.Name
+	}
+	return filepath.Base(pkg.WorkDir)
+}
+
+// orderPackages sorts packages so each follows the packages it depends_on,
+// keeping the configured order wherever depends_on leaves it free.
+// Dependencies are named by package label.
+func orderPackages(packages []PackageConfig) ([]PackageConfig, *fieldError) {
+	index := make(map[string]int, len(packages))
+	for i, pkg := range packages {
+		if _, dup := index[pkg.label()]; dup {
+			index[pkg.label()] = -1
+			continue
+		}
+		index[pkg.label()] = i
+	}
+
+	// waiting[i] counts the unpublished dependencies of package i
+	waiting := make([]int, len(packages))
+	dependents := make([][]int, len(packages))
+	for i, pkg := range packages {
+		field := fmt.Sprintf("packages[%d].depends_on", i)
+		for _, dep := range pkg.DependsOn {
+			j, ok := index[dep]
+			switch {
+			case !ok:
+				return nil, &fieldError{Field: field, Err: fmt.Errorf("unknown package %q", dep)}
+			case j < 0:
+				return nil, &fieldError{Field: field, Err: fmt.Errorf("package %q is ambiguous: several packages have that name", dep)}
+			case j == i:
+				return nil, &fieldError{Field: field, Err: fmt.Errorf("package %q cannot depend on itself", dep)}
+			}
+			waiting[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	ordered := make([]PackageConfig, 0, len(packages))
+	done := make([]bool, len(packages))
+	for len(ordered) < len(packages) {
+		next := -1
+		for i := range packages {
+			if !done[i] && waiting[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []string
+			for i, pkg := range packages {
+				if !done[i] {
+					cycle = append(cycle, pkg.label())
+				}
+			}
+			return nil, &fieldError{Field: "packages", Err: fmt.Errorf("depends_on forms a cycle between %s", strings.Join(cycle, ", "))}
+		}
+		done[next] = true
+		ordered = append(ordered, packages[next])
+		for _, i := range dependents[next] {
+			waiting[i]--
+		}
+	}
+	return ordered, nil
+}
+
+// orderedPackages returns the packages in publish order. An invalid order,
+// reported by validateNestedConfig, falls back to the configured order.
+func (c *Config) orderedPackages() []PackageConfig {
+	ordered, err := orderPackages(c.Packages)
+	if err != nil {
+		return c.Packages
+	}
+	return ordered
+}
+
+// forPackage returns a copy of the config with the package overrides applied.
+func (c *Config) forPackage(pkg PackageConfig) *Config {
+	clone := *c
+	clone.Packages = nil
+	clone.WorkDir = pkg.WorkDir
+	if pkg.Organization != "" {
+		clone.Organization = pkg.Organization
+	}
+	if pkg.Replace != nil {
+		clone.Replace = *pkg.Replace
+	}
+	if pkg.ProjectType != "" {
+		clone.ProjectType = pkg.ProjectType
+	}
+	// A top-level package_name names a single project, never every package
+	clone.PackageName = pkg.PackageName
+	if pkg.APIKey != "" {
+		// An explicit package key wins over the org_keys mapping
+		clone.APIKey = pkg.APIKey
+		clone.OrgKeys = nil
+		clone.keySource = secretSourceConfig
+	}
+	if pkg.APIKeyEnv != "" || pkg.APIKeyFile != "" {
+		// Read when publishing, so a missing key fails only this package
+		clone.packageKey = &OrgKey{APIKeyEnv: pkg.APIKeyEnv, APIKeyFile: pkg.APIKeyFile}
+		clone.OrgKeys = nil
+	}
+	return &clone
+}
+
+// publishPackages publishes each configured package in order, after the
+// packages it depends_on, stopping at the first failure so later packages never reference an unpublished dependency.
+// With atomic set, the packages already published are then retired.
+func (p *HexPlugin) publishPackages(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool, summary *RunSummary) (*plugin.ExecuteResponse, error) {
+	results := make([]map[string]any, 0, len(cfg.Packages))
+	outputs := map[string]any{"packages": results}
+	var published []publishedPackage
+	// A dry run previews what an atomic rollback would retire
+	var planned []publishedPackage
+	var labels []string
+
+	for _, pkg := range cfg.orderedPackages() {
+		pkgCfg := cfg.forPackage(pkg)
+		resp, err := p.publish(ctx, pkgCfg, releaseCtx, dryRun, summary)
+		if err != nil {
+			return resp, err
+		}
+
+		result := map[string]any{
+			"name":         pkg.label(),
+			"work_dir":     pkg.WorkDir,
+			"project_type": resp.Outputs["project_type"],
+			"success":      resp.Success,
+			"outputs":      resp.Outputs,
+		}
+		if resp.Error != "" {
+			result["error"] = resp.Error
+		}
+		results = append(results, result)
+		outputs["packages"] = results
+
+		if !resp.Success {
+			message := fmt.Sprintf("package %s: %s", pkg.label(), resp.Error)
+			if cfg.Atomic && !dryRun {
+				// A package can fail after its upload, e.g. while publishing docs
+				if publishedBeforeFailure(resp) {
+					published = append(published, newPublishedPackage(pkgCfg, pkg, releaseCtx, resp))
+				}
+				if len(published) > 0 {
+					rolledBack := p.rollback(ctx, published, pkg.label(), summary)
+					outputs["rollback"] = rolledBack
+					message = fmt.Sprintf("%s after package %s failed: %s", describeRollback(rolledBack), pkg.label(), resp.Error)
+				}
+			}
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   message,
+				Outputs: outputs,
+			}, nil
+		}
+		if !dryRun {
+			published = append(published, newPublishedPackage(pkgCfg, pkg, releaseCtx, resp))
+		} else if cfg.Atomic {
+			planned = append(planned, newPublishedPackage(pkgCfg, pkg, releaseCtx, resp))
+			labels = append(labels, pkg.label())
+		}
+	}
+	if len(planned) > 0 {
+		outputs["retire_preview"] = atomicRetirePreview(planned, labels)
+	}
+
+	message := fmt.Sprintf("Published %d packages to Hex.pm", len(results))
+	if dryRun {
+		message = fmt.Sprintf("Would publish %d packages to Hex.pm", len(results))
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: message,
+		Outputs: outputs,
+	}, nil
+}
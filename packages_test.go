@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestForPackage(t *testing.T) {
+	replace := true
+	cfg := &Config{APIKey: "shared", Organization: "acme", WorkDir: ".", Packages: []PackageConfig{{WorkDir: "a"}}}
+
+	got := cfg.forPackage(PackageConfig{WorkDir: "apps/core", Replace: &replace, APIKey: "core-key"})
+	if got.WorkDir != "apps/core" || !got.Replace || got.APIKey != "core-key" || got.Organization != "acme" {
+		t.Errorf("unexpected config: %+v", got)
+	}
+	if got.Packages != nil {
+		t.Error("package config should not carry the package list")
+	}
+	if cfg.WorkDir != "." || cfg.Replace {
+		t.Error("forPackage should not modify the original config")
+	}
+}
+
+func TestPublishPackages(t *testing.T) {
+	packages := []any{
+		map[string]any{"name": "core", "work_dir": "apps/core"},
+		map[string]any{"name": "web", "work_dir": "apps/web", "organization": "acme"},
+	}
+
+	t.Run("publishes each package in its directory", func(t *testing.T) {
+		mock := &MockCommandExecutor{}
+		p := &HexPlugin{executor: mock}
+
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "k", "packages": packages},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		if len(mock.Calls) != 2 || mock.Calls[0].Dir != "apps/core" || mock.Calls[1].Dir != "apps/web" {
+			t.Fatalf("unexpected calls: %+v", mock.Calls)
+		}
+		if !contains(mock.Calls[1].Args, "--organization") {
+			t.Errorf("expected organization override, got %v", mock.Calls[1].Args)
+		}
+		if results := resp.Outputs["packages"].([]map[string]any); len(results) != 2 {
+			t.Errorf("expected 2 package results, got %d", len(results))
+		}
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		mock := &MockCommandExecutor{
+			RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+				return []byte("boom"), errors.New("exit status 1")
+			},
+		}
+		p := &HexPlugin{executor: mock}
+
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "k", "packages": packages},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Success {
+			t.Fatal("expected failure")
+		}
+		if len(mock.Calls) != 1 {
+			t.Errorf("expected publishing to stop after the first package, got %d calls", len(mock.Calls))
+		}
+	})
+}
+
+func TestOrderPackages(t *testing.T) {
+	tests := []struct {
+		name      string
+		packages  []PackageConfig
+		want      []string
+		wantField string
+		wantErr   string
+	}{
+		{
+			name:     "configured order",
+			packages: []PackageConfig{{WorkDir: "apps/core"}, {WorkDir: "apps/web"}},
+			want:     []string{"core", "web"},
+		},
+		{
+			name: "dependencies first",
+			packages: []PackageConfig{
+				{WorkDir: "apps/web", DependsOn: []string{"core", "my_ext"}},
+				{WorkDir: "apps/core"},
+				{Name: "my_ext", WorkDir: "apps/ext", DependsOn: []string{"core"}},
+				{WorkDir: "apps/cli"},
+			},
+			want: []string{"core", "my_ext", "web", "cli"},
+		},
+		{
+			name:      "unknown package",
+			packages:  []PackageConfig{{WorkDir: "apps/web", DependsOn: []string{"core"}}},
+			wantField: "packages[0].depends_on",
+			wantErr:   `unknown package "core"`,
+		},
+		{
+			name:      "itself",
+			packages:  []PackageConfig{{WorkDir: "apps/web", DependsOn: []string{"web"}}},
+			wantField: "packages[0].depends_on",
+			wantErr:   "cannot depend on itself",
+		},
+		{
+			name:      "ambiguous",
+			packages:  []PackageConfig{{WorkDir: "a/core"}, {WorkDir: "b/core"}, {WorkDir: "web", DependsOn: []string{"core"}}},
+			wantField: "packages[2].depends_on",
+			wantErr:   "ambiguous",
+		},
+		{
+			name: "cycle",
+			packages: []PackageConfig{
+				{WorkDir: "apps/cli"},
+				{WorkDir: "apps/core", DependsOn: []string{"web"}},
+				{WorkDir: "apps/web", DependsOn: []string{"core"}},
+			},
+			wantField: "packages",
+			wantErr:   "depends_on forms a cycle between core, web",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, ferr := orderPackages(tt.packages)
+			if tt.wantField != "" {
+				if ferr == nil || ferr.Field != tt.wantField || !strings.Contains(ferr.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %s: %s", ferr, tt.wantField, tt.wantErr)
+				}
+				return
+			}
+			if ferr != nil {
+				t.Fatalf("unexpected error: %v", ferr)
+			}
+			var got []string
+			for _, pkg := range ordered {
+				got = append(got, pkg.label())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutePackagesDependsOn(t *testing.T) {
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{"api_key": "k", "packages": []any{
+			map[string]any{"work_dir": "apps/web", "depends_on": []any{"core"}},
+			map[string]any{"work_dir": "apps/core"},
+		}},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got %s", resp.Error)
+	}
+	if len(mock.Calls) != 2 || mock.Calls[0].Dir != "apps/core" || mock.Calls[1].Dir != "apps/web" {
+		t.Fatalf("expected core before web, got %+v", mock.Calls)
+	}
+
+	resp, err = p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{"api_key": "k", "packages": []any{
+			map[string]any{"work_dir": "apps/web", "depends_on": []any{"core"}},
+		}},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Success || !strings.Contains(resp.Error, `unknown package "core"`) {
+		t.Errorf("expected an unknown dependency error, got success=%v error=%q", resp.Success, resp.Error)
+	}
+}
+
+func TestExecutePackageKeys(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "web.key")
+	if err := os.WriteFile(keyFile, []byte("web-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CORE_HEX_KEY", "core-key")
+
+	tests := []struct {
+		name      string
+		packages  []any
+		wantKeys  []string
+		wantError string
+	}{
+		{
+			name: "each package uses its own key",
+			packages: []any{
+				map[string]any{"work_dir": "apps/core", "api_key_env": "CORE_HEX_KEY"},
+				map[string]any{"work_dir": "apps/web", "organization": "acme", "api_key_file": keyFile},
+				map[string]any{"work_dir": "apps/cli"},
+			},
+			wantKeys: []string{"HEX_API_KEY=core-key", "HEX_API_KEY=web-key", "HEX_API_KEY=top-level-key"},
+		},
+		{
+			name: "missing key fails its package",
+			packages: []any{
+				map[string]any{"work_dir": "apps/core"},
+				map[string]any{"work_dir": "apps/web", "api_key_env": "WEB_HEX_KEY_UNSET"},
+			},
+			wantKeys:  []string{"HEX_API_KEY=top-level-key"},
+			wantError: "package web: package key: environment variable WEB_HEX_KEY_UNSET is not set",
+		},
+		{
+			name: "several sources",
+			packages: []any{
+				map[string]any{"work_dir": "apps/core", "api_key": "inline", "api_key_env": "CORE_HEX_KEY"},
+			},
+			wantError: "invalid packages[0].api_key: set at most one of api_key, api_key_env or api_key_file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "top-level-key", "packages": tt.packages},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != (tt.wantError == "") {
+				t.Fatalf("success = %v (error %q)", resp.Success, resp.Error)
+			}
+			if tt.wantError != "" && !strings.HasPrefix(resp.Error, tt.wantError) {
+				t.Errorf("error = %q, want prefix %q", resp.Error, tt.wantError)
+			}
+
+			var keys []string
+			for _, c := range mock.Calls {
+				for _, e := range c.Env {
+					if strings.HasPrefix(e, "HEX_API_KEY=") {
+						keys = append(keys, e)
+					}
+				}
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestExecuteMixedToolPackages(t *testing.T) {
+	chdirTemp(t)
+	for name, content := range map[string]string{
+		"apps/core/mix.exs":                `defmodule Core.MixProject do def project, do: [app: :core, version: "1.0.0"] end`,
+		"apps/erl_lib/rebar.config":        "{deps, []}.",
+		"apps/erl_lib/src/erl_lib.app.src": "{application, erl_lib, []}.",
+		"apps/gleam_lib/gleam.toml":        `name = "gleam_lib"`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, name, content)
+	}
+
+	tests := []struct {
+		name      string
+		packages  []any
+		wantTools []string
+		wantTypes []string
+		wantError string
+	}{
+		{
+			name: "detected per package",
+			packages: []any{
+				map[string]any{"work_dir": "apps/core"},
+				map[string]any{"work_dir": "apps/erl_lib"},
+				map[string]any{"work_dir": "apps/gleam_lib"},
+			},
+			wantTools: []string{"mix", "rebar3", "gleam"},
+			wantTypes: []string{projectTypeMix, projectTypeRebar3, projectTypeGleam},
+		},
+		{
+			name: "selected per package",
+			packages: []any{
+				map[string]any{"work_dir": "apps/core", "project_type": "mix"},
+				map[string]any{"work_dir": "apps/erl_lib", "project_type": "rebar3"},
+			},
+			wantTools: []string{"mix", "rebar3"},
+			wantTypes: []string{projectTypeMix, projectTypeRebar3},
+		},
+		{
+			name: "invalid tool",
+			packages: []any{
+				map[string]any{"work_dir": "apps/core", "project_type": "cargo"},
+			},
+			wantError: "packages[0].project_type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "k", "packages": tt.packages},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantError != "" {
+				if resp.Success || !strings.Contains(resp.Error, tt.wantError) {
+					t.Fatalf("expected error containing %q, got success=%v error=%q", tt.wantError, resp.Success, resp.Error)
+				}
+				return
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got %s", resp.Error)
+			}
+			var tools []string
+			for _, call := range mock.Calls {
+				tools = append(tools, call.Name)
+			}
+			if !reflect.DeepEqual(tools, tt.wantTools) {
+				t.Errorf("ran %v, want %v", tools, tt.wantTools)
+			}
+			results := resp.Outputs["packages"].([]map[string]any)
+			for i, want := range tt.wantTypes {
+				if results[i]["project_type"] != want {
+					t.Errorf("packages[%d].project_type = %v, want %s", i, results[i]["project_type"], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Publish phases run separately when split_phases is enabled.
+const (
+	phasePackage = "package"
+	phaseDocs    = "docs"
+)
+
+// PhaseResult reports how one publish phase went.
+type PhaseResult struct {
+	Attempts   int    `json:"attempts"`
+	ExitCode   int    `json:"exit_code"`
+	Duration   string `json:"duration"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Bootstrapped is set when the Hex archive was installed mid-phase.
+	Bootstrapped bool `json:"bootstrapped,omitempty"`
+}
+
+// missingHexPattern matches mix's failure when the Hex archive is not
+// installed, e.g. ** (Mix) The task "hex.publish" could not be found.
+var missingHexPattern = regexp.MustCompile(`The task "hex\.[a-z_.]+" could not be found`)
+
+// errHexMissing is the failure reported when Hex is not installed and
+// bootstrap_tools is off.
+var errHexMissing = errors.New("the Hex archive is not installed: run mix local.hex --force, or set bootstrap_tools: true to install it automatically")
+
+// bootstrapHexArgs installs the Hex archive without prompting.
+var bootstrapHexArgs = []string{"local.hex", "--force"}
+
+// splitsDocs reports whether docs are published to hexdocs in their own
+// phase. Custom docs destinations are uploaded separately anyway.
+func (c *Config) splitsDocs() bool {
+	return c.SplitPhases && c.DocsDestination != docsDestinationCustom
+}
+
+// docsPublishArgs turns the package publish arguments into those of the
+// docs phase. Docs of an existing release are always overwritten, so
+// --replace is dropped.
+func docsPublishArgs(args []string) []string {
+	docs := []string{args[0], phaseDocs}
+	for _, arg := range args[1:] {
+		if arg != phasePackage && arg != "--replace" {
+			docs = append(docs, arg)
+		}
+	}
+	return docs
+}
+
+// phaseContext bounds a phase by its own timeout, if any, on top of the
+// hook timeout.
+func phaseContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// runPhase runs a mix hex.publish phase with its own timeout and retries,
+// forwarding the terminal for the confirmation prompt when yes is off.
+func (p *HexPlugin) runPhase(ctx context.Context, cfg *Config, timeout time.Duration, args, env []string, summary *RunSummary) ([]byte, PhaseResult, error) {
+	ctx, cancel := phaseContext(ctx, timeout)
+	defer cancel()
+
+	tracker := attemptTrackerFrom(ctx)
+	run := func() ([]byte, error) {
+		if tracker != nil {
+			if err := tracker.take(); err != nil {
+				return nil, err
+			}
+		}
+		if cfg.Yes {
+			return p.runMix(ctx, cfg, summary, args, env)
+		}
+		return p.runMixInteractive(ctx, cfg, summary, args, env)
+	}
+
+	start := time.Now()
+	output, attempts, errorClass, err := p.runWithRetry(ctx, cfg, run)
+
+	// A missing Hex archive fails identically on every retry
+	bootstrapped := false
+	if err != nil && missingHexPattern.Match(output) {
+		if !cfg.BootstrapTools {
+			err = fmt.Errorf("%w (%w)", errHexMissing, err)
+		} else if bootOutput, bootErr := p.runMix(ctx, cfg, summary, bootstrapHexArgs, env); bootErr != nil {
+			output, err = bootOutput, fmt.Errorf("installing Hex with mix local.hex --force failed: %w", bootErr)
+		} else {
+			summary.debugf("installed the Hex archive with mix local.hex --force")
+			bootstrapped = true
+			var more int
+			output, more, errorClass, err = p.runWithRetry(ctx, cfg, run)
+			attempts += more
+		}
+	}
+
+	// Wrapped aliases may exit 0 after the publish itself failed
+	if err == nil {
+		if err = cfg.checkOutputCriteria(output); err != nil {
+			errorClass = classifyError(ctx, output, err)
+		}
+	}
+
+	result := PhaseResult{
+		Bootstrapped: bootstrapped,
+		Attempts:     attempts,
+		ExitCode:     exitCodeOf(err),
+		Duration:     time.Since(start).Round(time.Millisecond).String(),
+	}
+	if err != nil {
+		result.ErrorClass = errorClass
+		result.Error = fmt.Sprintf("mix %s failed: %v", args[0], err)
+	}
+	return output, result, err
+}
+
+// publishDocsPhase publishes the docs of a release whose package phase
+// succeeded. A failure here leaves the package published.
+func (p *HexPlugin) publishDocsPhase(ctx context.Context, cfg *Config, args, env []string, summary *RunSummary) (PhaseResult, error) {
+	docsArgs := docsPublishArgs(args)
+	summary.debugf("publishing docs in a separate phase: %s", cfg.mixDisplay(docsArgs...))
+	output, result, err := p.runPhase(ctx, cfg, cfg.DocsTimeout, docsArgs, env, summary)
+	if err != nil {
+		return result, fmt.Errorf("%v\nOutput: %s", err, string(output))
+	}
+	return result, nil
+}
+
+// resumeDocs publishes only the docs when the registry already serves the
+// release, so a run whose docs failed after the package published can be
+// retried. It reports false when the release does not exist yet.
+func (p *HexPlugin) resumeDocs(ctx context.Context, cfg *Config, args, env []string, version string, outputs map[string]any, summary *RunSummary) (*plugin.ExecuteResponse, bool) {
+	name, file := declaredPackageName(cfg.WorkDir, projectTypeMix)
+	if name == "" {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("resume_docs: could not determine the package name from %s", file),
+			Outputs: outputs,
+		}, true
+	}
+
+	exists, err := p.hexAPI(cfg).releaseExists(ctx, cfg.Organization, name, version)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("resume_docs: failed to look up %s %s: %v", name, version, err),
+			Outputs: outputs,
+		}, true
+	}
+	if !exists {
+		summary.debugf("resume_docs: %s %s is not published yet", name, version)
+		return nil, false
+	}
+
+	summary.debugf("resume_docs: %s %s is already published, publishing docs only", name, version)
+	summary.Package = name
+	outputs["package"] = name
+	outputs["resumed"] = phaseDocs
+
+	if cfg.DocsDestination != docsDestinationCustom {
+		result, err := p.publishDocsPhase(ctx, cfg, args, env, summary)
+		outputs["phases"] = map[string]PhaseResult{phaseDocs: result}
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("package v%s already published but docs publish failed: %v", version, err),
+				Outputs: outputs,
+			}, true
+		}
+		if cfg.Organization == "" {
+			summary.addURL("docs", hexdocsURL(name, version))
+		}
+	}
+
+	if cfg.usesCustomDocs() {
+		uploaded, err := p.publishCustomDocs(ctx, cfg, version, summary)
+		outputs["docs_targets"] = uploaded
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("package v%s already published but docs upload failed: %v", version, err),
+				Outputs: outputs,
+			}, true
+		}
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: fmt.Sprintf("Published docs for existing release %s v%s", name, version),
+		Outputs: outputs,
+	}, true
+}
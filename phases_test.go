@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestDocsPublishArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "plain",
+			args: []string{"hex.publish", "package", "--yes"},
+			want: []string{"hex.publish", "docs", "--yes"},
+		},
+		{
+			name: "unsplit",
+			args: []string{"hex.publish", "--replace", "--yes"},
+			want: []string{"hex.publish", "docs", "--yes"},
+		},
+		{
+			name: "organization and replace",
+			args: []string{"hex.publish", "package", "--organization", "acme", "--replace", "--yes"},
+			want: []string{"hex.publish", "docs", "--organization", "acme", "--yes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := docsPublishArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("docsPublishArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteSplitPhases(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		docsFails   bool
+		wantSuccess bool
+		wantCalls   [][]string
+		wantDocs    *PhaseResult
+	}{
+		{
+			name:        "both phases succeed",
+			config:      map[string]any{},
+			wantSuccess: true,
+			wantCalls:   [][]string{{"hex.publish", "package", "--yes"}, {"hex.publish", "docs", "--yes"}},
+			wantDocs:    &PhaseResult{Attempts: 1},
+		},
+		{
+			name:      "docs retried independently",
+			config:    map[string]any{"retries": 1, "retry_delay": "1ms"},
+			docsFails: true,
+			wantCalls: [][]string{{"hex.publish", "package", "--yes"}, {"hex.publish", "docs", "--yes"}, {"hex.publish", "docs", "--yes"}},
+			wantDocs:  &PhaseResult{Attempts: 2, ExitCode: -1, ErrorClass: errorClassNetwork},
+		},
+		{
+			name:        "custom docs have no docs phase",
+			config:      map[string]any{"docs_destination": "custom", "docs_targets": []any{"https://docs.example.com/{version}.tar.gz"}},
+			wantSuccess: false,
+			wantCalls:   [][]string{{"hex.publish", "package", "--yes"}, {"docs"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if tt.docsFails && contains(args, "docs") {
+						return []byte("** (Mix) econnrefused"), errors.New("exit status 1")
+					}
+					return []byte("mock output"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "split_phases": true}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var calls [][]string
+			for _, c := range mock.Calls {
+				calls = append(calls, c.Args)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if tt.wantDocs == nil {
+				return
+			}
+
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error %q)", resp.Success, tt.wantSuccess, resp.Error)
+			}
+			if !tt.wantSuccess && !strings.Contains(resp.Error, "package v1.0.0 published but docs publish failed") {
+				t.Errorf("unexpected error: %s", resp.Error)
+			}
+			phases := resp.Outputs["phases"].(map[string]PhaseResult)
+			if phases[phasePackage].Attempts != 1 || phases[phasePackage].Error != "" {
+				t.Errorf("package phase = %+v", phases[phasePackage])
+			}
+			docs := phases[phaseDocs]
+			if docs.Attempts != tt.wantDocs.Attempts || docs.ExitCode != tt.wantDocs.ExitCode || docs.ErrorClass != tt.wantDocs.ErrorClass {
+				t.Errorf("docs phase = %+v, want %+v", docs, *tt.wantDocs)
+			}
+		})
+	}
+}
+
+func TestExecuteSplitPhasesPackageTimeout(t *testing.T) {
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, _ := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "split_phases": true, "package_timeout": "10ms"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if resp.Success {
+		t.Fatal("expected the package phase to time out")
+	}
+	if phase := resp.Outputs["phases"].(map[string]PhaseResult)[phasePackage]; phase.ErrorClass != errorClassTimeout {
+		t.Errorf("package phase = %+v, want a timeout", phase)
+	}
+	if len(mock.Calls) != 1 {
+		t.Errorf("expected no docs phase after a failed package phase, got %d calls", len(mock.Calls))
+	}
+}
+
+func TestExecuteSplitPhasesDryRun(t *testing.T) {
+	p := &HexPlugin{executor: &MockCommandExecutor{}}
+	resp, _ := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "split_phases": true},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+		DryRun:  true,
+	})
+	if resp.Outputs["command"] != "mix hex.publish package --yes" || resp.Outputs["docs_command"] != "mix hex.publish docs --yes" {
+		t.Errorf("unexpected commands: %v, %v", resp.Outputs["command"], resp.Outputs["docs_command"])
+	}
+}
+
+func TestExecuteResumeDocs(t *testing.T) {
+	tests := []struct {
+		name        string
+		mixExs      string
+		config      map[string]any
+		published   bool
+		docsFails   bool
+		wantSuccess bool
+		wantCalls   [][]string
+		wantResumed bool
+	}{
+		{
+			name:        "existing release publishes docs only",
+			published:   true,
+			wantSuccess: true,
+			wantCalls:   [][]string{{"hex.publish", "docs", "--yes"}},
+			wantResumed: true,
+		},
+		{
+			name:        "package name differs from the app",
+			mixExs:      `[app: :my_app, package: [name: "my_lib"]]`,
+			published:   true,
+			wantSuccess: true,
+			wantCalls:   [][]string{{"hex.publish", "docs", "--yes"}},
+			wantResumed: true,
+		},
+		{
+			name:        "new release publishes normally",
+			wantSuccess: true,
+			wantCalls:   [][]string{{"hex.publish", "--yes"}},
+		},
+		{
+			name:        "replace publishes normally",
+			config:      map[string]any{"replace": true},
+			published:   true,
+			wantSuccess: true,
+			wantCalls:   [][]string{{"hex.publish", "--replace", "--yes"}},
+		},
+		{
+			name:        "docs failure is reported",
+			published:   true,
+			docsFails:   true,
+			wantCalls:   [][]string{{"hex.publish", "docs", "--yes"}},
+			wantResumed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t)
+			mixExs := tt.mixExs
+			if mixExs == "" {
+				mixExs = `[app: :my_lib]`
+			}
+			if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte(mixExs), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.published || r.URL.Path != "/packages/my_lib/releases/1.0.0" {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(`{"version":"1.0.0"}`))
+			}))
+			defer server.Close()
+
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if tt.docsFails {
+						return []byte("** (Mix) docs upload failed"), errors.New("exit status 1")
+					}
+					return []byte("mock output"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "api_url": server.URL, "resume_docs": true}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error %q)", resp.Success, tt.wantSuccess, resp.Error)
+			}
+			if !tt.wantSuccess && !strings.Contains(resp.Error, "package v1.0.0 already published but docs publish failed") {
+				t.Errorf("unexpected error: %s", resp.Error)
+			}
+
+			var calls [][]string
+			for _, c := range mock.Calls {
+				calls = append(calls, c.Args)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if got := resp.Outputs["resumed"] == phaseDocs; got != tt.wantResumed {
+				t.Errorf("resumed = %v, want %v", resp.Outputs["resumed"], tt.wantResumed)
+			}
+		})
+	}
+}
+
+func TestExecuteMissingHexArchive(t *testing.T) {
+	tests := []struct {
+		name         string
+		bootstrap    bool
+		bootstrapErr error
+		wantSuccess  bool
+		wantError    string
+		wantCalls    [][]string
+	}{
+		{
+			name:      "fails fast with the fix",
+			wantError: "the Hex archive is not installed: run mix local.hex --force",
+			wantCalls: [][]string{{"hex.publish", "--yes"}},
+		},
+		{
+			name:        "bootstraps and retries",
+			bootstrap:   true,
+			wantSuccess: true,
+			wantCalls:   [][]string{{"hex.publish", "--yes"}, {"local.hex", "--force"}, {"hex.publish", "--yes"}},
+		},
+		{
+			name:         "bootstrap failure",
+			bootstrap:    true,
+			bootstrapErr: errors.New("exit status 1"),
+			wantError:    "installing Hex with mix local.hex --force failed",
+			wantCalls:    [][]string{{"hex.publish", "--yes"}, {"local.hex", "--force"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installed := false
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if args[0] == "local.hex" {
+						installed = tt.bootstrapErr == nil
+						return []byte("could not fetch archive"), tt.bootstrapErr
+					}
+					if !installed {
+						return []byte(`** (Mix) The task "hex.publish" could not be found`), errors.New("exit status 1")
+					}
+					return []byte("mock output"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "bootstrap_tools": tt.bootstrap, "retries": 2, "retry_delay": "1ms"},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error %q)", resp.Success, tt.wantSuccess, resp.Error)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+			}
+			if tt.wantSuccess && resp.Outputs["bootstrapped_hex"] != true {
+				t.Errorf("expected bootstrapped_hex, got %v", resp.Outputs["bootstrapped_hex"])
+			}
+
+			var calls [][]string
+			for _, c := range mock.Calls {
+				calls = append(calls, c.Args)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
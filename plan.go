@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// PlannedPublish is one package of the hex publish plan.
+type PlannedPublish struct {
+	Package      string   `json:"package,omitempty"`
+	WorkDir      string   `json:"work_dir"`
+	Version      string   `json:"version,omitempty"`
+	Registry     string   `json:"registry"`
+	Organization string   `json:"organization,omitempty"`
+	Replace      bool     `json:"replace"`
+	Docs         string   `json:"docs"`
+	DocsTargets  []string `json:"docs_targets,omitempty"`
+}
+
+// describe summarizes the planned publish in one line.
+func (pp PlannedPublish) describe() string {
+	name := pp.Package
+	if name == "" {
+		name = pp.WorkDir
+	}
+	target := pp.Registry
+	if pp.Organization != "" {
+		target += " (organization " + pp.Organization + ")"
+	}
+	line := fmt.Sprintf("%s %s to %s", name, pp.Version, target)
+	if pp.Replace {
+		line += ", replacing an existing version"
+	}
+	return line
+}
+
+// planPackage describes what publishing cfg will do, from config alone.
+func planPackage(cfg *Config, version string) PlannedPublish {
+	planned := PlannedPublish{
+		WorkDir:      cfg.WorkDir,
+		Version:      version,
+		Registry:     cfg.registryLabel(),
+		Organization: cfg.Organization,
+		Replace:      cfg.Replace,
+		Docs:         cfg.DocsDestination,
+	}
+	if content, err := readMixExs(cfg.WorkDir); err == nil {
+		planned.Package = parseMixApp(content)
+	}
+	if cfg.DocsDestination != docsDestinationHexdocs {
+		for _, target := range cfg.DocsTargets {
+			planned.DocsTargets = append(planned.DocsTargets, target.URL)
+		}
+	}
+	return planned
+}
+
+// plan handles PostPlan: it contributes the hex publish plan to the release
+// plan, so the packages, versions, targets and use of replace are visible
+// before anything is versioned or tagged. Nothing is run. A planned version
+// Hex would reject fails the hook here rather than at publish time.
+func (p *HexPlugin) plan(cfg *Config, releaseCtx plugin.ReleaseContext) *plugin.ExecuteResponse {
+	version := strings.TrimPrefix(releaseCtx.Version, "v")
+	if version != "" {
+		if err := validateHexVersion(version); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("planned version is invalid for Hex: %v", err),
+			}
+		}
+	}
+
+	var planned []PlannedPublish
+	if len(cfg.Packages) == 0 {
+		planned = append(planned, planPackage(cfg, version))
+	}
+	for _, pkg := range cfg.orderedPackages() {
+		pp := planPackage(cfg.forPackage(pkg), version)
+		if pp.Package == "" {
+			pp.Package = pkg.label()
+		}
+		planned = append(planned, pp)
+	}
+
+	lines := make([]string, 0, len(planned))
+	for _, pp := range planned {
+		lines = append(lines, pp.describe())
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: "Hex publish plan: " + strings.Join(lines, "; "),
+		Outputs: map[string]any{"plan": planned},
+	}
+}
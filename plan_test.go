@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecutePostPlan(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		version     string
+		wantSuccess bool
+		wantPlan    []PlannedPublish
+		wantMessage string
+	}{
+		{
+			name:        "single package",
+			config:      map[string]any{"organization": "acme", "replace": true},
+			version:     "v1.2.0",
+			wantSuccess: true,
+			wantPlan: []PlannedPublish{
+				{Package: "my_lib", WorkDir: ".", Version: "1.2.0", Registry: defaultHexAPIURL, Organization: "acme", Replace: true, Docs: docsDestinationHexdocs},
+			},
+			wantMessage: "Hex publish plan: my_lib 1.2.0 to https://hex.pm/api (organization acme), replacing an existing version",
+		},
+		{
+			name: "packages and custom docs",
+			config: map[string]any{
+				"api_url":          "https://hex.example.com/api",
+				"docs_destination": "both",
+				"docs_targets":     []any{"https://docs.example.com/{version}/"},
+				"packages": []any{
+					map[string]any{"work_dir": "core"},
+					map[string]any{"name": "my_ext", "work_dir": "ext", "replace": true},
+				},
+			},
+			version:     "2.0.0",
+			wantSuccess: true,
+			wantPlan: []PlannedPublish{
+				{Package: "my_core", WorkDir: "core", Version: "2.0.0", Registry: "https://hex.example.com/api", Docs: "both", DocsTargets: []string{"https://docs.example.com/{version}/"}},
+				{Package: "my_ext", WorkDir: "ext", Version: "2.0.0", Registry: "https://hex.example.com/api", Replace: true, Docs: "both", DocsTargets: []string{"https://docs.example.com/{version}/"}},
+			},
+			wantMessage: "Hex publish plan: my_core 2.0.0 to https://hex.example.com/api; my_ext 2.0.0 to https://hex.example.com/api, replacing an existing version",
+		},
+		{
+			name:    "invalid planned version",
+			config:  map[string]any{},
+			version: "2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t)
+			if err := os.WriteFile(mixExsFile, []byte(`[app: :my_lib]`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Mkdir(filepath.Join(dir, "core"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "core", mixExsFile), []byte(`[app: :my_core]`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPlan,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: tt.version},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(mock.Calls) != 0 {
+				t.Errorf("planning must not run commands, got %+v", mock.Calls)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error %q)", resp.Success, tt.wantSuccess, resp.Error)
+			}
+			if !tt.wantSuccess {
+				if !strings.Contains(resp.Error, "planned version is invalid for Hex") {
+					t.Errorf("unexpected error: %s", resp.Error)
+				}
+				return
+			}
+			if got := resp.Outputs["plan"].([]PlannedPublish); !reflect.DeepEqual(got, tt.wantPlan) {
+				t.Errorf("plan = %+v, want %+v", got, tt.wantPlan)
+			}
+			if resp.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", resp.Message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -3,11 +3,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
 	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
@@ -18,19 +22,52 @@ type CommandExecutor interface {
 	Run(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error)
 }
 
+// outputRecorder is implemented by executors that persist command output,
+// which must not keep the secrets the run knows.
+type outputRecorder interface {
+	// maskSecrets masks what was and will be recorded with m.
+	maskSecrets(m *secretMasker)
+}
+
 // RealCommandExecutor executes actual system commands.
 type RealCommandExecutor struct{}
 
-// Run executes the command with the given arguments.
+// Run executes the command with the given arguments. When ctx ends the
+// command is interrupted, then killed after the shutdown grace period.
+// Output beyond the spool threshold is written to a temp file, and output
+// is copied to the log stream carried by ctx as it arrives. Commands run
+// with the locale carried by ctx unless env overrides it.
 func (e *RealCommandExecutor) Run(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
-	if len(env) > 0 {
+	cmd := newCommand(ctx, name, args, env, dir)
+	output := &spoolWriter{threshold: spoolThresholdFrom(ctx)}
+	var w io.Writer = output
+	if stream := logStreamFrom(ctx); stream != nil {
+		w = io.MultiWriter(output, stream)
+		defer stream.flush()
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	err := waitGracefully(ctx, cmd, shutdownGraceFrom(ctx))
+	return output.Bytes(), err
+}
+
+// newCommand builds a command running in dir with env added to the
+// environment, and the locale carried by ctx unless env overrides it. The
+// caller starts it and waits with waitGracefully.
+func newCommand(ctx context.Context, name string, args []string, env []string, dir string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	if env = commandEnv(ctx, env); len(env) > 0 {
 		cmd.Env = append(os.Environ(), env...)
 	}
 	if dir != "" {
 		cmd.Dir = dir
 	}
-	return cmd.CombinedOutput()
+	return cmd
 }
 
 // Config represents the Hex plugin configuration.
@@ -40,11 +77,241 @@ type Config struct {
 	Replace      bool
 	Yes          bool
 	WorkDir      string
+	Timeout      time.Duration
+	// DeadlineBudget splits the time left before the hook deadline across
+	// the publish phases, failing the phase that overruns its share.
+	DeadlineBudget bool
+	// ShutdownGrace is how long a cancelled command may clean up after SIGINT.
+	ShutdownGrace time.Duration
+	Verbosity     string
+
+	// OutputSpoolThreshold is the output size past which a command's output
+	// is spooled to a temp file; zero keeps all output in memory.
+	OutputSpoolThreshold int64
+	// Locale sets LANG and LC_ALL for spawned commands; inherit keeps the
+	// environment's own.
+	Locale string
+	// StreamLogs copies command output to stderr while commands run, when
+	// the host supports streaming logs.
+	StreamLogs bool
+
+	ReplacePolicy      string
+	AllowStableReplace bool
+	CheckReleaseType   bool
+	StripBuildMetadata bool
+	DirtyWorktree      string
+	VerifyCheckout     bool
+	// GitState compares the release context with the git state of work_dir.
+	GitState string
+
+	PublishWindows      []PublishWindow
+	PublishWindowAction string
+
+	FreezeFile   string
+	FreezeURL    string
+	FreezeAction string
+
+	MixPath     string
+	ElixirPath  string
+	PathPrepend []string
+	// MixHome and HexHome place mix and Hex caches and credentials, e.g.
+	// on a persistent volume.
+	MixHome string
+	HexHome string
+	// BootstrapTools installs a missing Hex archive instead of failing.
+	BootstrapTools bool
+	// HexConfig is applied with mix hex.config in an isolated HEX_HOME.
+	HexConfig []HexConfigEntry
+	// Chaos injects a simulated failure into a publish phase.
+	Chaos *ChaosConfig
+	// OutputPatterns extract project-specific values from mix output.
+	OutputPatterns []OutputPattern
+	// SuccessPattern and FailurePattern judge mix output that exited 0.
+	SuccessPattern *regexp.Regexp
+	FailurePattern *regexp.Regexp
+
+	CommandPrefix []string
+	// Task is the mix task or alias that publishes, hex.publish by default.
+	Task string
+
+	Profile string
+	APIURL  string
+	Mirror  string
+	// NoVerifyRepoOrigin sets HEX_NO_VERIFY_REPO_ORIGIN. Security-sensitive:
+	// it lets a mirror serve packages signed for another repository.
+	NoVerifyRepoOrigin bool
+	// OrgKeys maps organizations to the source of their API key.
+	OrgKeys map[string]OrgKey
+	// KeyExpiryCheck makes Validate warn about lapsing keys.
+	KeyExpiryCheck   bool
+	KeyExpiryHorizon time.Duration
+	KeyMaxAge        time.Duration
+	// SecretSourcePolicy restricts where the API key may come from.
+	SecretSourcePolicy string
+	// keySource records where the API key came from, e.g. "env:HEX_API_KEY".
+	keySource string
+	// packageKey is the key source of a packages entry, read when publishing.
+	packageKey *OrgKey
+	// ProvisionOrgKey mints a write-scoped organization key for the publish.
+	ProvisionOrgKey bool
+	// provisioningKey is the key that minted the provisioned key.
+	provisioningKey string
+
+	DocsDestination string
+	DocsTargets     []DocsTarget
+	DocsTokenEnv    string
+
+	SummaryPath string
+	StepSummary bool
+	// TranscriptDir archives the full, redacted transcript of every command
+	// per release, as retained evidence of what was published.
+	TranscriptDir string
+
+	MaskValues []string
+	MaskEnv    []string
+	// RedactionAudit fails the run when a masked secret is found in the
+	// final response, summary file or transcript archive.
+	RedactionAudit bool
+
+	Gates           []GateConfig
+	AllowedLicenses []string
+	JUnitPath       string
+	VerifyMatrix    []MatrixEntry
+
+	WarningsAsErrorsPublish bool
+	PackageManifest         bool
+	DependencyTree          bool
+	ArtifactsDir            string
+	Reproducible            bool
+
+	// ScanSecrets blocks the publish when the package files hold possible
+	// secrets. SecretScanAllow lists path patterns excluded from the scan.
+	ScanSecrets     bool
+	SecretScanAllow []string
+	// RequireExplicitFiles fails when mix.exs omits files: or lists overly
+	// broad entries.
+	RequireExplicitFiles bool
+	// FirstPublishChecks runs the onboarding checklist before a package's
+	// first publish. FirstPublishOwners are the expected co-owners.
+	FirstPublishChecks bool
+	FirstPublishOwners []string
+
+	SmokeTest        bool
+	SmokeTestTimeout time.Duration
+
+	Canary            bool
+	CanaryWindow      time.Duration
+	CanaryConfirmFile string
+
+	APICache    bool
+	APICacheDir string
+
+	// ToolchainCache keeps detected toolchain versions on disk, keyed by
+	// the modification time of the mix and elixir binaries.
+	ToolchainCache    bool
+	ToolchainCacheDir string
+
+	Telemetry         bool
+	TelemetryEndpoint string
+
+	// MetricsEndpoint receives publish metrics: statsd://host:port or an
+	// http(s) Prometheus pushgateway.
+	MetricsEndpoint string
+	MetricsPrefix   string
+
+	// buildEnv is added to every mix command, e.g. by reproducible mode.
+	buildEnv []string
+
+	PrePublishTasks  [][]string
+	PostPublishTasks [][]string
+
+	TestRegistry    bool
+	TestRegistryDir string
+
+	// Rehearse publishes into a local test registry before the real publish.
+	Rehearse bool
+
+	Packages []PackageConfig
+	// Atomic retires the packages already published when a later one fails.
+	Atomic bool
+
+	Retries    int
+	RetryOn    []string
+	RetryDelay time.Duration
+	// MaxAttemptsPerVersion caps the publish attempts of a version across
+	// retries and re-runs, counted in StateFile.
+	MaxAttemptsPerVersion int
+	StateFile             string
+
+	// RegistryHealthCheck probes the registry status page and API before
+	// publishing; RegistryIncidentAction fails or waits out incidents.
+	RegistryHealthCheck     bool
+	RegistryStatusURL       string
+	RegistryIncidentAction  string
+	RegistryIncidentMaxWait time.Duration
+
+	// SplitPhases publishes the package and hexdocs docs as separate phases.
+	SplitPhases    bool
+	PackageTimeout time.Duration
+	DocsTimeout    time.Duration
+	// ResumeDocs publishes only the docs when the release already exists.
+	ResumeDocs bool
+
+	// DiagnosticsDir receives a diagnostics bundle when a publish fails.
+	DiagnosticsDir   string
+	DiagnosticsLines int
+
+	// FailureMessageTemplate replaces the error of a failed run, rendered
+	// with FailureMessageData.
+	FailureMessageTemplate string
+
+	DependencyChanges bool
+	PreviousTag       string
+
+	// MetadataDiff compares a dry run's metadata with the published release.
+	MetadataDiff bool
+	// Preview renders the publish preview on PreApprove.
+	Preview bool
+
+	// ProjectType overrides the detected project type: mix, rebar3 or gleam.
+	ProjectType string
+	// PackageName is the package name the project must declare.
+	PackageName string
+	// BumpVersion rewrites the version in mix.exs on the version hooks.
+	BumpVersion bool
+
+	// DependencyPolicy checks the dependencies declared in mix.exs before
+	// publishing.
+	DependencyPolicy *DependencyPolicy
+
+	// RetiredReport runs mix hex.audit to report retired dependencies even
+	// without an audit gate.
+	RetiredReport bool
+
+	Rotation *RotationConfig
 }
 
 // HexPlugin implements the Publish packages to Hex.pm (Elixir) plugin.
 type HexPlugin struct {
-	executor CommandExecutor
+	executor   CommandExecutor
+	httpClient HTTPClient
+	// terminal overrides stdin terminal detection, for tests.
+	terminal func() bool
+	// clock overrides the current time, for tests.
+	clock func() time.Time
+	// beforeRedactionAudit runs just before the redaction audit, so tests
+	// can simulate a value that slipped past masking.
+	beforeRedactionAudit func(summary *RunSummary, resp *plugin.ExecuteResponse)
+	// toolchains caches detected toolchain versions for the process.
+	toolchains toolchainCache
+}
+
+// now returns the current time from clock, defaulting to time.Now.
+func (p *HexPlugin) now() time.Time {
+	if p.clock != nil {
+		return p.clock()
+	}
+	return time.Now()
 }
 
 // getExecutor returns the command executor, defaulting to RealCommandExecutor.
@@ -64,20 +331,153 @@ func (p *HexPlugin) GetInfo() plugin.Info {
 		Author:      "Relicta Team",
 		Hooks: []plugin.Hook{
 			plugin.HookPostPublish,
+			plugin.HookOnSuccess,
+			plugin.HookPrePublish,
+			plugin.HookPreApprove,
+			plugin.HookPostPlan,
+			plugin.HookPreVersion,
+			plugin.HookPostVersion,
 		},
-		ConfigSchema: `{
+		ConfigSchema: withCapabilities(withOutputSchema(`{
 			"type": "object",
 			"properties": {
 				"api_key": {"type": "string", "description": "Hex.pm API key (or use HEX_API_KEY env)"},
-				"organization": {"type": "string", "description": "Hex.pm organization for private packages"},
+				"organization": {"type": "string", "minLength": 3, "maxLength": 32, "pattern": "^[a-z]([a-z0-9]|[-_][a-z0-9])*$", "description": "Hex.pm organization for private packages"},
 				"replace": {"type": "boolean", "description": "Replace existing package version", "default": false},
-				"yes": {"type": "boolean", "description": "Skip confirmation prompt", "default": true},
-				"work_dir": {"type": "string", "description": "Working directory for mix command", "default": "."}
+				"yes": {"type": "boolean", "description": "Skip confirmation prompt; when false the prompt is forwarded to the terminal, or the publish fails fast when none is attached", "default": true},
+				"work_dir": {"type": "string", "description": "Working directory for mix command", "default": "."},
+				"app": {"type": "string", "description": "Umbrella app to publish; its directory is resolved from apps_path in the mix.exs at work_dir, e.g. app: my_lib instead of work_dir: apps/my_lib"},
+				"timeout": {"type": ["string", "number"], "description": "Maximum duration of the hook, as a Go duration (\"90s\", \"5m\") or seconds"},
+				"deadline_budget": {"type": "boolean", "description": "When the hook has a deadline (the host's or timeout), share the remaining time across the gates, build, upload and verification phases by weight, unused time rolling over; the phase that overruns its share fails and phase_budget reports per-phase timings", "default": false},
+				"docs_destination": {"type": "string", "enum": ["hexdocs", "custom", "both"], "description": "Where to publish docs: hexdocs.pm, custom targets, or both", "default": "hexdocs"},
+				"docs_targets": {"type": "array", "items": {"oneOf": [{"type": "string"}, {"type": "object", "properties": {"url": {"type": "string"}, "token_env": {"type": "string"}}, "required": ["url"], "additionalProperties": false}]}, "description": "Docs upload targets (https:// URLs receive a PUT, s3:// URLs use aws s3 cp; http:// is only accepted for localhost); {version} is expanded and a trailing slash appends the tarball name"},
+				"docs_token_env": {"type": "string", "description": "Environment variable holding the bearer token for HTTP docs targets", "default": "HEX_DOCS_UPLOAD_TOKEN"},
+				"summary_path": {"type": "string", "description": "Write a JSON summary of the run (commands, artifacts, URLs, errors) to this path"},
+				"transcript_dir": {"type": "string", "description": "Archive the full, redacted transcript of every command (output, timing, how stdin was handled) under <dir>/<version>/<hook>-<time>/ for audits"},
+				"step_summary": {"type": "boolean", "description": "Append a markdown summary to GITHUB_STEP_SUMMARY when running under GitHub Actions", "default": true},
+				"replace_policy": {"type": "string", "enum": ["any", "prerelease"], "description": "Versions that replace may overwrite; prerelease refuses to replace stable releases", "default": "any"},
+				"allow_stable_replace": {"type": "boolean", "description": "Override replace_policy prerelease to replace a stable release", "default": false},
+				"check_release_type": {"type": "boolean", "description": "Block publishes whose version bump does not match the planned release type (e.g. a patch release changing the major)", "default": true},
+				"strip_build_metadata": {"type": "boolean", "description": "Remove a +build suffix from the release version before publishing, since Hex rejects build metadata; the original version is reported as source_version", "default": false},
+				"dirty_worktree": {"type": "string", "enum": ["ignore", "warn", "fail"], "description": "How to handle uncommitted changes in work_dir reported by git status: warn lists them as dirty_files, fail blocks the publish", "default": "ignore"},
+				"git_state": {"type": "string", "enum": ["ignore", "warn", "fail"], "description": "Compare the release context (version, tag, commit, branch) with the git state of work_dir (tags at HEAD, HEAD, branch) and report it as git_state: warn only reports divergence, fail blocks the publish. Fields git cannot answer, such as the branch of a detached HEAD, are unknown rather than diverged", "default": "ignore"},
+				"verify_checkout": {"type": "boolean", "description": "Verify that HEAD in work_dir is the release commit and that the release tag exists locally before publishing", "default": false},
+				"config_file": {"type": "string", "description": "Project config file in work_dir merged under the Relicta config", "default": ".relicta-hex.yml"},
+				"defaults": {"type": "object", "description": "Options shared by every hook, overridden by top-level options and the hooks block"},
+				"hooks": {"type": "object", "description": "Options for a single hook keyed by hook name (e.g. post-publish), overriding top-level options and defaults"},
+				"locale": {"type": "string", "description": "Locale set as LANG and LC_ALL for every spawned command (mix, git, rebar3, gleam), so Elixir runs with UTF-8 encoding in minimal containers without a configured locale; inherit keeps the environment's own. Command-specific env still wins", "default": "en_US.UTF-8"},
+				"output_spool_threshold": {"type": "integer", "minimum": 0, "description": "Bytes of command output kept in memory; longer output is written to a temp file named by the output_file output, keeping only its start and end in outputs. 0 keeps all output in memory", "default": 8388608},
+				"stream_logs": {"type": "boolean", "description": "Stream command output to stderr line by line while commands run. Hosts without streaming_logs support get the output when each command finishes, noted in the degraded output", "default": false},
+				"shutdown_grace": {"type": ["string", "number"], "description": "Time a cancelled or timed out command gets after SIGINT before its process group is killed", "default": "10s"},
+				"mix_path": {"type": "string", "description": "mix executable to run instead of the first mix on PATH"},
+				"elixir_path": {"type": "string", "description": "Absolute path of the elixir executable; its directory is prepended to PATH"},
+				"path_prepend": {"type": "array", "items": {"type": "string"}, "description": "Directories prepended to PATH for mix commands (e.g. Nix store paths)"},
+				"mix_home": {"type": "string", "description": "Absolute MIX_HOME for mix commands, so archives and caches live where the runner's cache policy expects"},
+				"hex_home": {"type": "string", "description": "Absolute HEX_HOME for mix commands, holding Hex caches and configuration; hex_config is written here when set"},
+				"bootstrap_tools": {"type": "boolean", "description": "When mix reports that the hex tasks could not be found, install Hex with mix local.hex --force and retry instead of failing", "default": false},
+				"hex_config": {"type": "object", "additionalProperties": {"type": ["string", "number", "boolean"]}, "description": "Settings applied with mix hex.config KEY VALUE in an isolated HEX_HOME before publishing, for options without an environment variable (api_url, offline, unsafe_https, http_proxy, ...)"},
+				"output_patterns": {"type": "object", "additionalProperties": {"type": "string"}, "description": "Output name to regular expression applied to the mix hex.publish output; the first capture group (or the whole match) becomes that output. Built-in output names are reserved"},
+				"success_pattern": {"type": "string", "description": "Regular expression the output of mix hex.publish must match, in addition to exiting 0, for the publish to count as successful"},
+				"failure_pattern": {"type": "string", "description": "Regular expression marking the publish as failed when it matches the output of mix hex.publish, even if mix exited 0; checked before success_pattern"},
+				"chaos": {"type": "object", "properties": {"phase": {"type": "string", "enum": ["gates", "build", "upload", "docs", "verification"]}, "failure": {"type": "string", "enum": ["network", "timeout", "server_error", "auth", "version_exists"]}}, "required": ["phase", "failure"], "additionalProperties": false, "description": "Rehearse rollback and retire automation: fail the given publish phase with a simulated mix error; registry writes (hex.publish, hex.retire, hex.owner) are simulated so nothing is published. Not allowed with custom docs destinations"},
+				"task": {"type": "string", "description": "Mix task or alias run in place of hex.publish, e.g. a release alias adding asset builds; it receives the hex.publish arguments and must pass them on to hex.publish", "default": "hex.publish"},
+				"command_prefix": {"type": ["string", "array"], "items": {"type": "string"}, "description": "Command wrapping every mix invocation, e.g. \"nix develop -c\" or [\"devbox\", \"run\"]"},
+				"api_url": {"type": "string", "description": "Hex API URL (or HEX_API_URL env var); defaults to https://hex.pm/api"},
+				"retries": {"type": "integer", "minimum": 0, "description": "Retries of a failed mix hex.publish whose error class is selected by retry_on", "default": 0},
+				"max_attempts_per_version": {"type": "integer", "minimum": 0, "description": "Safety cap on the publish attempts of a package version, counting retries and re-runs in state_file; once used up the publish fails without contacting the registry. 0 disables the cap", "default": 0},
+				"registry_health_check": {"type": "boolean", "description": "Before publishing, read the registry status page and check that the Hex API answers; a major or critical incident, or a 5xx from the API, fails with error_class registry_incident or is waited out per registry_incident_action. Probe failures are reported as registry_health_error without blocking", "default": false},
+				"registry_status_url": {"type": "string", "description": "Status page API read by registry_health_check (Statuspage format)", "default": "https://status.hex.pm/api/v2/status.json"},
+				"registry_incident_action": {"type": "string", "enum": ["fail", "wait"], "description": "On a registry incident, fail (probing again as retries and retry_on registry_incident allow) or wait with backoff from retry_delay for up to registry_incident_max_wait", "default": "fail"},
+				"registry_incident_max_wait": {"type": ["string", "number"], "description": "Longest wait for the registry to recover with registry_incident_action wait", "default": "30m"},
+				"state_file": {"type": "string", "description": "JSON file, kept between runs, where max_attempts_per_version counts attempts per package version"},
+				"retry_on": {"type": "array", "items": {"type": "string", "enum": ["network", "5xx", "timeout", "registry_incident"]}, "description": "Error classes that are retried; auth and validation failures are never retried (defaults to all). registry_incident re-probes the registry health when registry_health_check finds an incident"},
+				"split_phases": {"type": "boolean", "description": "Publish the package (mix hex.publish package) and docs (mix hex.publish docs) as separate phases with their own retries and timeouts, reported under phases; a docs failure leaves the package published", "default": false},
+				"package_timeout": {"type": ["string", "number"], "description": "Maximum duration of the package phase with split_phases, including retries"},
+				"docs_timeout": {"type": ["string", "number"], "description": "Maximum duration of the docs phase with split_phases, including retries"},
+				"resume_docs": {"type": "boolean", "description": "When the release already exists on the registry, publish only its docs, so a run whose docs failed after the package published can be retried; ignored with replace", "default": false},
+				"diagnostics_dir": {"type": "string", "description": "Directory receiving a diagnostics tarball (hex/elixir/OTP versions, mix.exs summary, redacted env, output tail, hex config) when a publish fails; its path is reported as diagnostics_path"},
+				"diagnostics_lines": {"type": "integer", "minimum": 1, "description": "Lines of the failed command's output kept in the diagnostics bundle", "default": 200},
+				"failure_message_template": {"type": "string", "description": "Go text/template replacing the error of a failed run, e.g. to link a runbook; fields: .Hook, .ErrorClass, .ExitCode, .Package, .Version, .Organization, .Error, .Hint"},
+				"retry_delay": {"type": ["string", "number"], "description": "Delay before the first retry, doubled for each further attempt", "default": "5s"},
+				"dependency_changes": {"type": "boolean", "description": "Compare mix.lock with the previous release tag and output added, removed and upgraded dependencies", "default": false},
+				"previous_tag": {"type": "string", "description": "Tag to compare mix.lock against (defaults to the previous version with the current tag prefix)"},
+				"preview": {"type": "boolean", "description": "On PreApprove, render the publish preview for the approval step: build the package with mix hex.build for its manifest and run the gates (listed unrun in dry runs); rebar3 and Gleam projects are previewed without building", "default": false},
+				"metadata_diff": {"type": "boolean", "description": "In dry runs, build the package and diff its description, licenses, links, files and requirements against the latest published release", "default": false},
+				"package_name": {"type": "string", "pattern": "^[a-z][a-z0-9_]*$", "description": "Expected Hex package name, cross-checked against the name declared in mix.exs (the package metadata name, else the app), gleam.toml or the .app.src; a mismatch fails before anything is built or published"},
+				"bump_version": {"type": "boolean", "description": "On the PreVersion and PostVersion hooks, rewrite the version: field of the project in mix.exs and any @version attribute to the release version, for work_dir (and the apps of an umbrella) or each mix package of packages; dry runs report the files without writing them", "default": false},
+				"project_type": {"type": "string", "enum": ["auto", "mix", "rebar3", "gleam"], "description": "Project type of work_dir; auto detects it from mix.exs (with apps_path for umbrellas), gleam.toml or rebar.config. rebar3 and Gleam projects publish with rebar3 hex publish and gleam publish; umbrella roots fail with the apps to pick from", "default": "auto"},
+				"dependency_policy": {"type": "object", "properties": {"git": {"type": "string", "enum": ["off", "warn", "fail"], "default": "off"}, "path": {"type": "string", "enum": ["off", "warn", "fail"], "default": "off"}, "exact": {"type": "string", "enum": ["off", "warn", "fail"], "default": "off"}, "upper_bound": {"type": "string", "enum": ["off", "warn", "fail"], "default": "off"}}, "additionalProperties": false, "description": "Checks on the dependencies declared in mix.exs before publishing: git and path dependencies, exact == pins, and requirements without an upper bound; each rule is off, warn (reported in dependency_policy) or fail (blocks the publish)"},
+				"retired_report": {"type": "boolean", "description": "Run mix hex.audit before publishing and report retired dependencies without blocking the publish", "default": false},
+				"rotation": {"type": "object", "properties": {"key_name_prefix": {"type": "string", "default": "relicta-hex"}, "permissions": {"type": "array", "items": {"type": "string"}}, "grace_period": {"type": ["string", "number"], "default": "24h"}, "backend": {"type": "object", "properties": {"type": {"type": "string", "enum": ["file", "command"]}, "path": {"type": "string"}, "command": {"type": "array", "items": {"type": "string"}}}, "required": ["type"]}}, "required": ["backend"], "description": "API key rotation used by the rotate-key standalone operation"},
+				"profile": {"type": "string", "description": "Registry profile to use (or RELICTA_HEX_PROFILE env var)"},
+				"no_verify_repo_origin": {"type": "boolean", "description": "SECURITY-SENSITIVE: set HEX_NO_VERIFY_REPO_ORIGIN so mirrored registries whose origin checks fail are accepted; this disables a protection against a mirror serving packages from another repository, so only enable it for mirrors you control", "default": false},
+				"profiles": {"type": "object", "additionalProperties": {"type": "object", "properties": {"api_url": {"type": "string"}, "organization": {"type": "string"}, "api_key_env": {"type": "string"}, "api_key": {"type": "string"}, "mirror": {"type": "string"}, "no_verify_repo_origin": {"type": "boolean"}}, "additionalProperties": false}, "description": "Named registry profiles (e.g. staging, production) bundling api_url, organization, key source and mirror"},
+				"redaction_audit": {"type": "boolean", "description": "After the run, check that no masked secret (API keys from every secret source, docs tokens, mask_values, mask_env) appears in the response, summary_path file, step summary or diagnostics bundle before they are written; any found is scrubbed and fails the run, reported in redaction_audit", "default": true},
+				"mask_values": {"type": "array", "items": {"type": "string"}, "description": "Literal secret values scrubbed from captured output, messages and summaries (the API key is always masked)"},
+				"mask_env": {"type": "array", "items": {"type": "string"}, "description": "Environment variables whose values are scrubbed from captured output, messages and summaries"},
+				"gates": {"type": "array", "items": {"oneOf": [{"type": "string", "enum": ["audit", "credo", "docs", "format", "license", "test"]}, {"type": "object", "properties": {"name": {"type": "string"}, "args": {"type": "array", "items": {"type": "string"}}, "allow_failure": {"type": "boolean"}}, "required": ["name"], "additionalProperties": false}]}, "description": "Pre-publish gates that must pass before publishing; objects override mix args or define custom gates"},
+				"warnings_as_errors_publish": {"type": "boolean", "description": "Build the package with mix hex.publish --dry-run first and fail before uploading when it prints warnings (missing metadata, excluded dependencies)", "default": false},
+				"scan_secrets": {"type": "boolean", "description": "Build the package with mix hex.build and block the publish when its files hold AWS keys, private keys, API tokens, .env files or high-entropy strings; findings are reported as secret_findings", "default": false},
+				"secret_scan_allow": {"type": "array", "items": {"type": "string"}, "description": "Path patterns of packaged files excluded from scan_secrets, e.g. test/fixtures/*.pem"},
+				"first_publish_checks": {"type": "boolean", "description": "Look the package up before publishing and, when it has never been published, run a first-publish checklist (name, metadata completeness, owners, organization membership) reported as onboarding; failed checks block the irreversible first publish", "default": false},
+				"first_publish_owners": {"type": "array", "items": {"type": "string"}, "description": "Hex users expected to co-own a new package; first_publish_checks verifies they exist and lists the mix hex.owner add commands to run"},
+				"require_explicit_files": {"type": "boolean", "description": "Fail before publishing when the package metadata in mix.exs omits files: (falling back to the Hex defaults) or lists overly broad entries such as \".\", \"**\", test or config; problems are reported as files_hygiene", "default": false},
+				"package_manifest": {"type": "boolean", "description": "Build the package tarball with mix hex.build and expose its files (paths and sizes) as the manifest output", "default": false},
+				"dependency_tree": {"type": "boolean", "description": "Attach the resolved production dependency tree (mix deps.tree) to the dependency_tree output", "default": false},
+				"pre_publish_tasks": {"type": "array", "items": {"type": "string"}, "description": "Mix tasks with arguments run before publishing, e.g. \"assets.build\""},
+				"post_publish_tasks": {"type": "array", "items": {"type": "string"}, "description": "Mix tasks with arguments run after a successful publish, e.g. \"my_app.announce\""},
+				"artifacts_dir": {"type": "string", "description": "Write the package and docs tarballs here and register them as release artifacts with digests"},
+				"reproducible": {"type": "boolean", "description": "Build with SOURCE_DATE_EPOCH from the commit timestamp, a pinned locale and timezone, and deterministic BEAM compilation", "default": false},
+				"verify_matrix": {"type": "array", "items": {"type": ["string", "object"]}, "description": "Elixir/OTP docker images the package must compile on before publishing; entries with allow_failure do not block"},
+				"smoke_test": {"type": "boolean", "description": "On success, install the published version into a throwaway mix project from the registry and compile it", "default": false},
+				"smoke_test_timeout": {"type": ["string", "number"], "description": "How long the smoke test waits for the release to appear in the registry", "default": "5m"},
+				"publish_windows": {"type": "array", "items": {"type": ["string", "object"]}, "description": "Cron-like schedules (minute hour day-of-month month day-of-week, optionally prefixed with CRON_TZ=<zone>) of the minutes in which publishing is allowed"},
+				"publish_window_action": {"type": "string", "enum": ["reject", "wait"], "description": "Outside every publish window, fail or wait for the next window to open", "default": "reject"},
+				"freeze_file": {"type": "string", "description": "File (relative to work_dir) whose presence declares a release freeze; its content, or a JSON {frozen, reason, until} object, gives the reason"},
+				"freeze_url": {"type": "string", "description": "URL serving a JSON {frozen, reason, until} freeze status, consulted before publishing; 404 means no freeze"},
+				"freeze_action": {"type": "string", "enum": ["fail", "skip"], "description": "Fail the release or skip the publish while a freeze is active", "default": "fail"},
+				"canary": {"type": "boolean", "description": "After publishing, install the release from the registry and retire it automatically if that fails or canary_confirm_file does not appear within canary_window", "default": false},
+				"canary_window": {"type": ["string", "number"], "description": "How long a canary release has to validate", "default": "15m"},
+				"canary_confirm_file": {"type": "string", "description": "File (relative to work_dir) whose creation confirms the canary; without it the smoke test alone validates the release"},
+				"api_cache": {"type": "boolean", "description": "Cache Hex API GET responses on disk and revalidate them with ETags across runs", "default": false},
+				"api_cache_dir": {"type": "string", "description": "Directory of the Hex API cache (defaults to the user cache directory)"},
+				"toolchain_cache": {"type": "boolean", "description": "Cache detected Elixir, OTP and Hex versions on disk, keyed by the modification time of the mix and elixir binaries; they are always cached for the plugin process", "default": false},
+				"toolchain_cache_dir": {"type": "string", "description": "Directory of the toolchain cache (defaults to the user cache directory)"},
+				"verbosity": {"type": "string", "enum": ["quiet", "normal", "debug"], "description": "quiet drops mix output from outputs; debug adds the decision log and an environment summary", "default": "normal"},
+				"telemetry": {"type": "boolean", "description": "Report anonymized usage (hook, outcome, duration, error class) to telemetry_endpoint after each run", "default": false},
+				"telemetry_endpoint": {"type": "string", "description": "http(s) URL receiving the anonymized usage reports as JSON"},
+				"metrics_endpoint": {"type": "string", "description": "Where publish metrics (attempts, failures by error class, duration, package size) are exported after each publish: statsd://host:port, or the http(s) URL of a Prometheus pushgateway"},
+				"metrics_prefix": {"type": "string", "description": "Prefix of the exported metric names, also the pushgateway job", "default": "relicta_hex"},
+				"strict_docs": {"type": "boolean", "description": "Fail before publishing when mix docs prints warnings such as undefined references (adds the docs gate)", "default": false},
+				"allowed_licenses": {"type": "array", "items": {"type": "string"}, "description": "Licenses accepted by the license gate"},
+				"junit_path": {"type": "string", "description": "Write gate results as a JUnit XML report to this path"},
+				"test_registry": {"type": "boolean", "description": "Publish into a locally built and signed registry (mix hex.registry build) instead of hex.pm", "default": false},
+				"test_registry_dir": {"type": "string", "description": "Directory for the local test registry (defaults to a temp dir)"},
+				"rehearse": {"type": "boolean", "description": "Before the real publish, publish into a locally built and signed registry (mix hex.registry build) and verify it can be served; a failed rehearsal publishes nothing. The registry is kept in test_registry_dir when set", "default": false},
+				"org_keys": {"type": "object", "additionalProperties": {"type": ["string", "object"], "properties": {"api_key_env": {"type": "string"}, "api_key_file": {"type": "string"}}, "additionalProperties": false}, "description": "Organization to API key source: an environment variable name, or {api_key_env} / {api_key_file}; used when publishing to that organization"},
+				"key_expiry_check": {"type": "boolean", "description": "During validation, fetch the API key's metadata and warn when it expires within key_expiry_horizon or its owner lacks two-factor authentication", "default": false},
+				"key_expiry_horizon": {"type": ["string", "number"], "description": "How far ahead key_expiry_check warns about expiry", "default": "336h"},
+				"key_max_age": {"type": ["string", "number"], "description": "Maximum key age allowed by policy; keys expire this long after creation when the registry reports no expiry"},
+				"secret_source_policy": {"type": "string", "enum": ["any", "no_inline"], "description": "no_inline rejects api_key values written into plugin config, profiles or packages; the key source is reported as secret_source", "default": "any"},
+				"provision_org_key": {"type": "boolean", "description": "Mint a write-scoped organization key with mix hex.organization key generate for the publish and revoke it afterwards; api_key must be allowed to manage the organization's keys", "default": false},
+				"packages": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string"}, "work_dir": {"type": "string"}, "organization": {"type": "string"}, "replace": {"type": "boolean"}, "api_key": {"type": "string"}, "api_key_env": {"type": "string"}, "api_key_file": {"type": "string"}, "depends_on": {"type": "array", "items": {"type": "string"}}, "project_type": {"type": "string", "enum": ["auto", "mix", "rebar3", "gleam"]}, "package_name": {"type": "string"}}, "required": ["work_dir"], "additionalProperties": false}, "description": "Publish several packages in order; each entry overrides the top-level settings, including the API key (api_key, or api_key_env / api_key_file read like org_keys) and the tool publishing it (project_type, so one step publishes the Mix, rebar3 and Gleam packages of a mixed repo), and depends_on names packages (by name, or work_dir base name) that must publish before it; package_name checks each package like the top-level option, which does not apply to packages"},
+				"atomic": {"type": "boolean", "description": "When a package of packages fails to publish, retire the packages this run already published (reason invalid) so the release is never left half-published", "default": false}
 			}
-		}`,
+		}`)),
 	}
 }
 
+// validateTarget checks the directory and organization a publish targets.
+func (c *Config) validateTarget() error {
+	if err := validatePath(c.WorkDir); err != nil {
+		return fmt.Errorf("invalid work_dir: %v", err)
+	}
+	if err := validateOrganization(c.Organization); err != nil {
+		return fmt.Errorf("invalid organization: %v", err)
+	}
+	return nil
+}
+
 // validatePath validates a file path to prevent path traversal.
 func validatePath(path string) error {
 	if path == "" {
@@ -100,27 +500,49 @@ func validatePath(path string) error {
 	return nil
 }
 
-// validateOrganization validates organization name format.
+// Hex.pm organization name length limits.
+const (
+	minOrganizationLength = 3
+	maxOrganizationLength = 32
+)
+
+// validateOrganization validates an organization name against the Hex.pm
+// registry rules, reporting the specific rule that was violated.
 func validateOrganization(org string) error {
 	if org == "" {
 		return nil
 	}
 
-	if len(org) > 128 {
-		return fmt.Errorf("organization name too long (max 128 characters)")
+	if len(org) < minOrganizationLength {
+		return fmt.Errorf("organization name too short (min %d characters)", minOrganizationLength)
+	}
+
+	if len(org) > maxOrganizationLength {
+		return fmt.Errorf("organization name too long (max %d characters)", maxOrganizationLength)
 	}
 
-	// Organization names should be alphanumeric with hyphens and underscores
+	if org[0] < 'a' || org[0] > 'z' {
+		return fmt.Errorf("organization name must start with a lowercase letter")
+	}
+
+	// Organization names are lowercase alphanumeric with hyphens and underscores
+	var prev rune
 	for _, r := range org {
 		isLower := r >= 'a' && r <= 'z'
-		isUpper := r >= 'A' && r <= 'Z'
 		isDigit := r >= '0' && r <= '9'
-		isHyphen := r == '-'
-		isUnderscore := r == '_'
+		isSeparator := r == '-' || r == '_'
 
-		if !isLower && !isUpper && !isDigit && !isHyphen && !isUnderscore {
-			return fmt.Errorf("organization name contains invalid characters: only alphanumeric, hyphens, and underscores are allowed")
+		if !isLower && !isDigit && !isSeparator {
+			return fmt.Errorf("organization name contains invalid characters: only lowercase letters, digits, hyphens, and underscores are allowed")
+		}
+		if isSeparator && (prev == '-' || prev == '_') {
+			return fmt.Errorf("organization name must not contain consecutive hyphens or underscores")
 		}
+		prev = r
+	}
+
+	if prev == '-' || prev == '_' {
+		return fmt.Errorf("organization name must not end with a hyphen or underscore")
 	}
 
 	return nil
@@ -130,55 +552,577 @@ func validateOrganization(org string) error {
 func (p *HexPlugin) parseConfig(raw map[string]any) *Config {
 	parser := helpers.NewConfigParser(raw)
 
-	return &Config{
-		APIKey:       parser.GetString("api_key", "HEX_API_KEY", ""),
-		Organization: parser.GetString("organization", "HEX_ORGANIZATION", ""),
-		Replace:      parser.GetBool("replace", false),
-		Yes:          parser.GetBool("yes", true),
-		WorkDir:      parser.GetString("work_dir", "", "."),
+	// Nested options are reported by validateRawConfig; parsing stays lenient
+	docsTargets, _ := parseDocsTargets(raw)
+	gates, _ := parseGates(raw)
+	packages, _ := parsePackages(raw)
+	matrix, _ := parseVerifyMatrix(raw)
+	windows, _ := parsePublishWindows(raw)
+	profiles, _ := parseProfiles(raw)
+	rotation, _ := parseRotation(raw)
+	orgKeys, _ := parseOrgKeys(raw)
+	hexConfig, _ := parseHexConfig(raw)
+	chaos, _ := parseChaos(raw)
+	dependencyPolicy, _ := parseDependencyPolicy(raw)
+	outputPatterns, _ := parseOutputPatterns(raw)
+	successPattern, _ := compileCriterion(raw, "success_pattern")
+	failurePattern, _ := compileCriterion(raw, "failure_pattern")
+
+	cfg := &Config{
+		APIKey:        parser.GetString("api_key", "HEX_API_KEY", ""),
+		Organization:  parser.GetString("organization", "HEX_ORGANIZATION", ""),
+		Replace:       parser.GetBool("replace", false),
+		Yes:           parser.GetBool("yes", true),
+		WorkDir:       parser.GetString("work_dir", "", "."),
+		Timeout:       getDuration(raw, "timeout", 0),
+		ShutdownGrace: getDuration(raw, "shutdown_grace", defaultShutdownGrace),
+		Verbosity:     parser.GetString("verbosity", "", verbosityNormal),
+
+		DeadlineBudget: parser.GetBool("deadline_budget", false),
+
+		OutputSpoolThreshold: int64(parser.GetInt("output_spool_threshold", defaultSpoolThreshold)),
+		Locale:               parser.GetString("locale", "", defaultLocale),
+		StreamLogs:           parser.GetBool("stream_logs", false),
+
+		ReplacePolicy:      parser.GetString("replace_policy", "", replacePolicyAny),
+		AllowStableReplace: parser.GetBool("allow_stable_replace", false),
+		CheckReleaseType:   parser.GetBool("check_release_type", true),
+		StripBuildMetadata: parser.GetBool("strip_build_metadata", false),
+		DirtyWorktree:      parser.GetString("dirty_worktree", "", dirtyWorktreeIgnore),
+		VerifyCheckout:     parser.GetBool("verify_checkout", false),
+		GitState:           parser.GetString("git_state", "", gitStateIgnore),
+		APIURL:             parser.GetString("api_url", "HEX_API_URL", ""),
+		NoVerifyRepoOrigin: parser.GetBool("no_verify_repo_origin", false),
+
+		PublishWindows:      windows,
+		PublishWindowAction: parser.GetString("publish_window_action", "", windowActionReject),
+
+		FreezeFile:   parser.GetString("freeze_file", "", ""),
+		FreezeURL:    parser.GetString("freeze_url", "", ""),
+		FreezeAction: parser.GetString("freeze_action", "", freezeActionFail),
+
+		MixPath:     parser.GetString("mix_path", "", ""),
+		ElixirPath:  parser.GetString("elixir_path", "", ""),
+		PathPrepend: parser.GetStringSlice("path_prepend", nil),
+
+		MixHome:        parser.GetString("mix_home", "", ""),
+		HexHome:        parser.GetString("hex_home", "", ""),
+		BootstrapTools: parser.GetBool("bootstrap_tools", false),
+		HexConfig:      hexConfig,
+		Chaos:          chaos,
+		OutputPatterns: outputPatterns,
+		SuccessPattern: successPattern,
+		FailurePattern: failurePattern,
+
+		CommandPrefix: parseCommandPrefix(raw),
+		Task:          parser.GetString("task", "", defaultPublishTask),
+
+		DocsDestination: parser.GetString("docs_destination", "", docsDestinationHexdocs),
+		DocsTargets:     docsTargets,
+		DocsTokenEnv:    parser.GetString("docs_token_env", "", defaultDocsTokenEnv),
+
+		SummaryPath: parser.GetString("summary_path", "", ""),
+		StepSummary: parser.GetBool("step_summary", true),
+
+		TranscriptDir: parser.GetString("transcript_dir", "", ""),
+
+		MaskValues: parser.GetStringSlice("mask_values", nil),
+		MaskEnv:    parser.GetStringSlice("mask_env", nil),
+
+		RedactionAudit: parser.GetBool("redaction_audit", true),
+
+		Gates:           withDocsGate(gates, parser.GetBool("strict_docs", false)),
+		AllowedLicenses: parser.GetStringSlice("allowed_licenses", nil),
+		JUnitPath:       parser.GetString("junit_path", "", ""),
+		VerifyMatrix:    matrix,
+
+		WarningsAsErrorsPublish: parser.GetBool("warnings_as_errors_publish", false),
+		PackageManifest:         parser.GetBool("package_manifest", false),
+		ScanSecrets:             parser.GetBool("scan_secrets", false),
+		SecretScanAllow:         parser.GetStringSlice("secret_scan_allow", nil),
+		RequireExplicitFiles:    parser.GetBool("require_explicit_files", false),
+		FirstPublishChecks:      parser.GetBool("first_publish_checks", false),
+		FirstPublishOwners:      parser.GetStringSlice("first_publish_owners", nil),
+		DependencyTree:          parser.GetBool("dependency_tree", false),
+		ArtifactsDir:            parser.GetString("artifacts_dir", "", ""),
+		Reproducible:            parser.GetBool("reproducible", false),
+
+		SmokeTest:        parser.GetBool("smoke_test", false),
+		SmokeTestTimeout: getDuration(raw, "smoke_test_timeout", defaultSmokeTestTimeout),
+
+		Canary:            parser.GetBool("canary", false),
+		CanaryWindow:      getDuration(raw, "canary_window", defaultCanaryWindow),
+		CanaryConfirmFile: parser.GetString("canary_confirm_file", "", ""),
+
+		APICache:    parser.GetBool("api_cache", false),
+		APICacheDir: parser.GetString("api_cache_dir", "", ""),
+
+		ToolchainCache:    parser.GetBool("toolchain_cache", false),
+		ToolchainCacheDir: parser.GetString("toolchain_cache_dir", "", ""),
+
+		Telemetry:         parser.GetBool("telemetry", false),
+		TelemetryEndpoint: parser.GetString("telemetry_endpoint", "", ""),
+
+		MetricsEndpoint: parser.GetString("metrics_endpoint", "", ""),
+		MetricsPrefix:   parser.GetString("metrics_prefix", "", defaultMetricsPrefix),
+
+		PrePublishTasks:  parseTasks(raw, "pre_publish_tasks"),
+		PostPublishTasks: parseTasks(raw, "post_publish_tasks"),
+
+		TestRegistry:    parser.GetBool("test_registry", false),
+		TestRegistryDir: parser.GetString("test_registry_dir", "", ""),
+
+		Rehearse: parser.GetBool("rehearse", false),
+
+		Packages: packages,
+		Atomic:   parser.GetBool("atomic", false),
+
+		Retries:    parser.GetInt("retries", 0),
+		RetryOn:    parser.GetStringSlice("retry_on", nil),
+		RetryDelay: getDuration(raw, "retry_delay", defaultRetryDelay),
+
+		MaxAttemptsPerVersion: parser.GetInt("max_attempts_per_version", 0),
+		StateFile:             parser.GetString("state_file", "", ""),
+
+		RegistryHealthCheck:     parser.GetBool("registry_health_check", false),
+		RegistryStatusURL:       parser.GetString("registry_status_url", "", ""),
+		RegistryIncidentAction:  parser.GetString("registry_incident_action", "", registryIncidentFail),
+		RegistryIncidentMaxWait: getDuration(raw, "registry_incident_max_wait", defaultRegistryIncidentMaxWait),
+
+		SplitPhases:    parser.GetBool("split_phases", false),
+		PackageTimeout: getDuration(raw, "package_timeout", 0),
+		DocsTimeout:    getDuration(raw, "docs_timeout", 0),
+		ResumeDocs:     parser.GetBool("resume_docs", false),
+
+		DiagnosticsDir:   parser.GetString("diagnostics_dir", "", ""),
+		DiagnosticsLines: parser.GetInt("diagnostics_lines", defaultDiagnosticsLines),
+
+		FailureMessageTemplate: parser.GetString("failure_message_template", "", ""),
+
+		DependencyChanges: parser.GetBool("dependency_changes", false),
+		PreviousTag:       parser.GetString("previous_tag", "", ""),
+
+		MetadataDiff:  parser.GetBool("metadata_diff", false),
+		Preview:       parser.GetBool("preview", false),
+		RetiredReport: parser.GetBool("retired_report", false),
+
+		DependencyPolicy: dependencyPolicy,
+		ProjectType:      parser.GetString("project_type", "", projectTypeAuto),
+		PackageName:      parser.GetString("package_name", "", ""),
+		BumpVersion:      parser.GetBool("bump_version", false),
+
+		OrgKeys:         orgKeys,
+		ProvisionOrgKey: parser.GetBool("provision_org_key", false),
+
+		SecretSourcePolicy: parser.GetString("secret_source_policy", "", secretPolicyAny),
+		keySource:          apiKeySource(raw),
+
+		KeyExpiryCheck:   parser.GetBool("key_expiry_check", false),
+		KeyExpiryHorizon: getDuration(raw, "key_expiry_horizon", defaultKeyExpiryHorizon),
+		KeyMaxAge:        getDuration(raw, "key_max_age", 0),
+
+		Rotation: rotation,
+	}
+
+	if name := selectedProfile(raw); name != "" {
+		if profile, ok := profiles[name]; ok {
+			cfg.applyProfile(name, profile)
+		}
 	}
+
+	return cfg
+}
+
+// handlesHook reports whether the plugin registered the hook in GetInfo.
+func (p *HexPlugin) handlesHook(hook plugin.Hook) bool {
+	for _, h := range p.GetInfo().Hooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
 }
 
 // Execute runs the plugin for a given hook.
 func (p *HexPlugin) Execute(ctx context.Context, req plugin.ExecuteRequest) (*plugin.ExecuteResponse, error) {
-	cfg := p.parseConfig(req.Config)
-
-	switch req.Hook {
-	case plugin.HookPostPublish:
-		return p.publish(ctx, cfg, req.Context, req.DryRun)
-	default:
+	if !p.handlesHook(req.Hook) {
 		return &plugin.ExecuteResponse{
 			Success: true,
 			Message: fmt.Sprintf("Hook %s not handled", req.Hook),
 		}, nil
 	}
+
+	config, errs := mergeConfig(req.Config, req.Hook)
+	if len(errs) > 0 {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid %v", errs[0]),
+		}, nil
+	}
+	req.Config = config
+
+	if errs := validateRawConfig(req.Config); len(errs) > 0 {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid %v", errs[0]),
+		}, nil
+	}
+
+	cfg := p.parseConfig(req.Config)
+
+	if err := validateLocale(cfg.Locale); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid %v", err),
+		}, nil
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	ctx = withShutdownGrace(ctx, cfg.ShutdownGrace)
+	ctx = withSpoolThreshold(ctx, cfg.OutputSpoolThreshold)
+	ctx = withLocaleEnv(ctx, cfg.localeEnv())
+
+	// Internal build metadata must not reach Hex, which rejects it
+	sourceVersion := req.Context.Version
+	var buildMetadata string
+	if cfg.StripBuildMetadata {
+		req.Context.Version, buildMetadata = stripBuildMetadata(req.Context.Version)
+	}
+
+	host := hostCapabilities()
+	degraded := cfg.negotiateCapabilities(host)
+	compat := newCompatMatrix(host, cfg)
+
+	summary := newRunSummary(p.GetInfo(), req)
+	summary.masker = newSecretMasker(cfg)
+	recorder, recording := p.executor.(outputRecorder)
+	if recording {
+		recorder.maskSecrets(summary.masker)
+	}
+	for _, note := range degraded {
+		summary.debugf("degraded %s", note)
+	}
+	if cfg.StreamLogs {
+		ctx = withLogStream(ctx, newLogStream(os.Stderr, summary.masker))
+	}
+	summary.verbosity = cfg.Verbosity
+	summary.logger = compat.structuredLogger(os.Stderr)
+	summary.events = compat.eventWriter(os.Stderr)
+	summary.transcripts = cfg.TranscriptDir != ""
+	summary.debugf("hook %s, dry_run %t, work_dir %s", req.Hook, req.DryRun, cfg.WorkDir)
+	if buildMetadata != "" {
+		summary.debugf("stripped build metadata +%s from version %s", buildMetadata, sourceVersion)
+	}
+
+	// Stripped containers may have no HOME, which mix needs to start
+	if fallback, err := homeFallbackEnv(); err != nil {
+		summary.debugf("HOME is unset and no fallback could be created: %v", err)
+	} else if len(fallback) > 0 {
+		cfg.buildEnv = append(cfg.buildEnv, fallback...)
+		summary.debugf("HOME is unset, using %s", strings.Join(fallback, " "))
+	}
+
+	var resp *plugin.ExecuteResponse
+	var err error
+
+	switch req.Hook {
+	case plugin.HookPostPublish:
+		if len(cfg.Packages) > 0 {
+			resp, err = p.publishPackages(ctx, cfg, req.Context, req.DryRun, summary)
+		} else {
+			resp, err = p.publish(ctx, cfg, req.Context, req.DryRun, summary)
+		}
+	case plugin.HookOnSuccess:
+		resp, err = p.smokeTest(ctx, cfg, req.Context, req.DryRun, summary)
+	case plugin.HookPrePublish:
+		resp = p.checkVersion(req.Context)
+	case plugin.HookPreApprove:
+		resp = p.preview(ctx, cfg, req.Context, req.DryRun, summary)
+	case plugin.HookPostPlan:
+		resp = p.plan(cfg, req.Context)
+	case plugin.HookPreVersion, plugin.HookPostVersion:
+		resp = p.bumpVersion(cfg, req.Context, req.DryRun, summary)
+	}
+	// Secrets learned during the run must leave the recording too
+	if recording {
+		recorder.maskSecrets(summary.masker)
+	}
+
+	if err != nil {
+		return resp, err
+	}
+
+	summary.masker.maskResponse(resp)
+	sanitizeResponse(resp)
+	summary.finish(resp)
+	if resp.Outputs == nil {
+		resp.Outputs = map[string]any{}
+	}
+	if len(degraded) > 0 {
+		resp.Outputs["degraded"] = degraded
+	}
+
+	addRemediationHint(resp)
+	applyFailureTemplate(cfg, req, resp, summary)
+
+	// Debug output reports the toolchain the publish ran with
+	if cfg.Verbosity == verbosityDebug && req.Hook == plugin.HookPostPublish && !req.DryRun {
+		if versions, err := p.detectToolchain(ctx, cfg, summary); err != nil {
+			summary.debugf("toolchain detection failed: %v", err)
+		} else {
+			resp.Outputs["toolchain"] = versions
+		}
+	}
+
+	// Bug reports need the environment the publish failed in
+	var diagnostics []diagnosticsFile
+	if !resp.Success && req.Hook == plugin.HookPostPublish && cfg.DiagnosticsDir != "" && !req.DryRun {
+		if files, err := p.collectDiagnostics(ctx, cfg, resp, summary); err != nil {
+			resp.Outputs["diagnostics_error"] = err.Error()
+		} else {
+			diagnostics = files
+		}
+	}
+
+	if buildMetadata != "" {
+		resp.Outputs["source_version"] = sourceVersion
+		resp.Outputs["build_metadata"] = buildMetadata
+	}
+
+	if len(summary.Commands) > 0 {
+		resp.Outputs["transcript"] = summary.Commands
+	}
+	if len(summary.Events) > 0 {
+		resp.Outputs["events"] = summary.Events
+	}
+
+	applyVerbosity(resp, cfg, summary)
+	compat.apply(resp)
+
+	stepSummaryPath := ""
+	if cfg.StepSummary {
+		stepSummaryPath = os.Getenv(githubStepSummaryEnv)
+	}
+
+	// Defense in depth: nothing written below may carry a secret masking
+	// missed, so the audit runs before any file is written
+	if cfg.RedactionAudit {
+		if p.beforeRedactionAudit != nil {
+			p.beforeRedactionAudit(summary, resp)
+		}
+		markdown := summary.markdown()
+		resp.Outputs["summary_markdown"] = markdown
+		var contents []auditedContent
+		if data, err := summary.encode(); err == nil && cfg.SummaryPath != "" {
+			contents = append(contents, auditedContent{Name: cfg.SummaryPath, Data: data})
+		}
+		if stepSummaryPath != "" {
+			contents = append(contents, auditedContent{Name: "step_summary", Data: []byte(markdown)})
+		}
+		for _, file := range diagnostics {
+			contents = append(contents, auditedContent{Name: "diagnostics/" + file.Name, Data: []byte(file.Content)})
+		}
+		if auditRedaction(summary.masker, resp, contents...) {
+			// The written summary must record the failed audit
+			summary.finish(resp)
+		}
+	}
+
+	markdown := summary.masker.mask(summary.markdown())
+	resp.Outputs["summary_markdown"] = markdown
+
+	if stepSummaryPath != "" {
+		if err := appendStepSummary(stepSummaryPath, markdown); err != nil {
+			resp.Outputs["step_summary_error"] = err.Error()
+		}
+	}
+
+	if len(diagnostics) > 0 {
+		if path, err := writeDiagnostics(cfg, diagnostics); err != nil {
+			resp.Outputs["diagnostics_error"] = err.Error()
+		} else {
+			resp.Outputs["diagnostics_path"] = path
+		}
+	}
+
+	if cfg.SummaryPath != "" {
+		if err := validatePath(cfg.SummaryPath); err != nil {
+			resp.Outputs["summary_error"] = fmt.Sprintf("invalid summary_path: %v", err)
+		} else if err := summary.write(cfg.SummaryPath); err != nil {
+			resp.Outputs["summary_error"] = err.Error()
+		} else {
+			resp.Outputs["summary_path"] = cfg.SummaryPath
+		}
+	}
+
+	if cfg.TranscriptDir != "" {
+		if err := validatePath(cfg.TranscriptDir); err != nil {
+			resp.Outputs["transcript_archive_error"] = fmt.Sprintf("invalid transcript_dir: %v", err)
+		} else if dir, err := summary.writeTranscript(cfg.TranscriptDir); err != nil {
+			resp.Outputs["transcript_archive_error"] = err.Error()
+		} else {
+			resp.Outputs["transcript_archive"] = dir
+		}
+	}
+
+	if cfg.Telemetry {
+		if err := validateHTTPURL(cfg.TelemetryEndpoint); err != nil {
+			resp.Outputs["telemetry_error"] = fmt.Sprintf("invalid telemetry_endpoint: %v", err)
+		} else if err := p.sendTelemetry(context.WithoutCancel(ctx), cfg.TelemetryEndpoint, newTelemetryEvent(summary, resp)); err != nil {
+			resp.Outputs["telemetry_error"] = err.Error()
+		}
+	}
+
+	// A chaos rehearsal published nothing, so it must not skew the metrics
+	if cfg.MetricsEndpoint != "" && req.Hook == plugin.HookPostPublish && !req.DryRun && cfg.Chaos == nil {
+		if err := validateMetricsEndpoint(cfg.MetricsEndpoint); err != nil {
+			resp.Outputs["metrics_error"] = fmt.Sprintf("invalid metrics_endpoint: %v", err)
+		} else if err := validateMetricsPrefix(cfg.MetricsPrefix); err != nil {
+			resp.Outputs["metrics_error"] = fmt.Sprintf("invalid metrics_prefix: %v", err)
+		} else if err := p.sendMetrics(context.WithoutCancel(ctx), cfg, newPublishMetrics(summary, resp)); err != nil {
+			resp.Outputs["metrics_error"] = err.Error()
+		}
+	}
+
+	return resp, nil
 }
 
 // publish executes mix hex.publish to publish the package to Hex.pm.
-func (p *HexPlugin) publish(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+func (p *HexPlugin) publish(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool, summary *RunSummary) (resp *plugin.ExecuteResponse, err error) {
 	// Validate configuration
-	if err := validatePath(cfg.WorkDir); err != nil {
+	if errs := cfg.validate(); len(errs) > 0 {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid %v", errs[0]),
+		}, nil
+	}
+
+	// A package owned by another account brings its own credential
+	if applied, err := cfg.applyPackageKey(); err != nil {
 		return &plugin.ExecuteResponse{
 			Success: false,
-			Error:   fmt.Sprintf("invalid work_dir: %v", err),
+			Error:   err.Error(),
 		}, nil
+	} else if applied {
+		summary.masker.add(cfg.APIKey)
+		summary.debugf("using the package credential from %s", cfg.keySource)
+	}
+
+	// Multi-tenant pipelines pick the credential of the target organization
+	if mapped, err := cfg.applyOrgKey(); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	} else if mapped {
+		summary.masker.add(cfg.APIKey)
+		summary.debugf("using the org_keys credential of organization %s", cfg.Organization)
+	}
+
+	if err := checkSecretSource(cfg); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	// Contexts without a version publish whatever mix.exs declares
+	if releaseCtx.Version != "" {
+		if err := validateHexVersion(releaseCtx.Version); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("invalid version for Hex: %v", err),
+			}, nil
+		}
+	}
+
+	if err := checkReplacePolicy(cfg, strings.TrimPrefix(releaseCtx.Version, "v")); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !cfg.CheckReleaseType {
+		summary.debugf("release type check disabled")
+	} else if err := checkReleaseType(releaseCtx); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("version does not match the release plan: %v", err),
+		}, nil
+	}
+
+	// Only Mix projects run through mix; umbrella roots have nothing to publish
+	projectType, detected := cfg.resolveProjectType()
+	summary.debugf("project type %s (detected: %t)", projectType, detected)
+	var gitState *GitState
+	defer func() {
+		if resp != nil {
+			if resp.Outputs == nil {
+				resp.Outputs = map[string]any{}
+			}
+			resp.Outputs["project_type"] = projectType
+			if gitState != nil {
+				resp.Outputs["git_state"] = gitState
+			}
+		}
+	}()
+	if projectType != projectTypeUmbrella {
+		if err := checkPackageName(cfg, projectType); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
 	}
 
-	if err := validateOrganization(cfg.Organization); err != nil {
+	// Multi-repo pipelines can hand a step the context of another repository
+	if cfg.GitState == gitStateWarn || cfg.GitState == gitStateFail {
+		state, err := p.compareGitState(ctx, cfg, releaseCtx, summary)
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("failed to compare the release context with git: %v", err),
+			}, nil
+		}
+		gitState = state
+		summary.debugf("git_state %s: %d diverged field(s)", cfg.GitState, len(state.Diverged))
+		if err := state.err(); err != nil && cfg.GitState == gitStateFail {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+	}
+	switch projectType {
+	case projectTypeUmbrella:
 		return &plugin.ExecuteResponse{
 			Success: false,
-			Error:   fmt.Sprintf("invalid organization: %v", err),
+			Error:   umbrellaRootError(cfg.WorkDir).Error(),
 		}, nil
+	case projectTypeRebar3, projectTypeGleam:
+		return p.publishNative(ctx, cfg, projectType, strings.TrimPrefix(releaseCtx.Version, "v"), dryRun, summary), nil
 	}
 
 	// Build command arguments
-	args := []string{"hex.publish"}
+	args := []string{cfg.Task}
+
+	// Only the package goes to Hex.pm when docs stay on self-hosted targets or
+	// follow in their own phase
+	if cfg.DocsDestination == docsDestinationCustom || cfg.SplitPhases {
+		args = append(args, "package")
+	}
 
 	if cfg.Organization != "" {
 		args = append(args, "--organization", cfg.Organization)
 	}
 
 	if cfg.Replace {
+		summary.debugf("replacing an existing version (replace_policy %s)", cfg.ReplacePolicy)
 		args = append(args, "--replace")
 	}
 
@@ -188,68 +1132,769 @@ func (p *HexPlugin) publish(ctx context.Context, cfg *Config, releaseCtx plugin.
 
 	version := strings.TrimPrefix(releaseCtx.Version, "v")
 
+	if cfg.VerifyCheckout {
+		if err := p.verifyCheckout(ctx, cfg, releaseCtx, summary); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("checkout does not match the release: %v", err),
+			}, nil
+		}
+	}
+
+	var epoch int64
+	if cfg.Reproducible {
+		var err error
+		if epoch, err = p.sourceDateEpoch(ctx, cfg, releaseCtx, summary); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("reproducible build: %v", err),
+			}, nil
+		}
+		cfg.buildEnv = reproducibleEnv(epoch)
+	}
+
+	// Uncommitted changes would be published without matching the tagged commit
+	var dirty []string
+	if cfg.DirtyWorktree == dirtyWorktreeWarn || cfg.DirtyWorktree == dirtyWorktreeFail {
+		files, err := p.dirtyFiles(ctx, cfg, summary)
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("failed to check worktree: %v", err),
+			}, nil
+		}
+		if len(files) > 0 && cfg.DirtyWorktree == dirtyWorktreeFail {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("work_dir has uncommitted changes: %s", strings.Join(files, ", ")),
+				Outputs: map[string]any{"dirty_files": files},
+			}, nil
+		}
+		summary.debugf("dirty_worktree %s: %d uncommitted file(s)", cfg.DirtyWorktree, len(files))
+		dirty = files
+	}
+
 	if dryRun {
+		summary.debugf("dry run: not running %s", cfg.mixDisplay(args...))
+		outputs := map[string]any{
+			"command":      cfg.mixDisplay(args...),
+			"version":      version,
+			"organization": cfg.Organization,
+			"replace":      cfg.Replace,
+		}
+		addVersionOutputs(outputs, version)
+		if len(cfg.HexConfig) > 0 {
+			commands := make([]string, 0, len(cfg.HexConfig))
+			for _, e := range cfg.HexConfig {
+				commands = append(commands, cfg.mixDisplay(e.args()...))
+			}
+			outputs["hex_config"] = commands
+		}
+		if cfg.splitsDocs() {
+			outputs["docs_command"] = cfg.mixDisplay(docsPublishArgs(args)...)
+		}
+		if len(dirty) > 0 {
+			outputs["dirty_files"] = dirty
+		}
+		if cfg.Reproducible {
+			outputs["source_date_epoch"] = epoch
+		}
+		if cfg.usesCustomDocs() {
+			targets := make([]string, 0, len(cfg.DocsTargets))
+			for _, target := range cfg.DocsTargets {
+				targets = append(targets, resolveDocsTarget(target.URL, version))
+			}
+			outputs["docs_destination"] = cfg.DocsDestination
+			outputs["docs_targets"] = targets
+		}
+		if len(cfg.Gates) > 0 {
+			outputs["gates"] = gateNames(cfg.Gates)
+		}
+		if len(cfg.VerifyMatrix) > 0 {
+			outputs["verify_matrix"] = matrixImages(cfg.VerifyMatrix)
+		}
+		if len(cfg.PrePublishTasks) > 0 {
+			outputs["pre_publish_tasks"] = cfg.taskDisplay(cfg.PrePublishTasks)
+		}
+		if len(cfg.PostPublishTasks) > 0 {
+			outputs["post_publish_tasks"] = cfg.taskDisplay(cfg.PostPublishTasks)
+		}
+		if cfg.Profile != "" {
+			outputs["profile"] = cfg.Profile
+		}
+		if cfg.MaxAttemptsPerVersion > 0 {
+			if tracker, err := newAttemptTracker(cfg, version); err != nil {
+				outputs["state_file_error"] = err.Error()
+			} else {
+				outputs["attempt_budget"] = tracker.budget()
+			}
+		}
+		if cfg.Canary {
+			outputs["canary_window"] = cfg.CanaryWindow.String()
+			outputs["retire_preview"] = canaryRetirePreview(cfg, version)
+		}
+		if cfg.ProvisionOrgKey {
+			outputs["provisioned_key"] = provisionedKeyName(p.now())
+		}
+		if cfg.FreezeFile != "" || cfg.FreezeURL != "" {
+			if status, err := p.checkFreeze(ctx, cfg); err != nil {
+				outputs["freeze_error"] = err.Error()
+			} else if status.Frozen {
+				outputs["freeze_reason"] = status.describe()
+			}
+		}
+		if cfg.RegistryHealthCheck {
+			if health, err := p.probeRegistry(ctx, cfg); err != nil {
+				outputs["registry_health_error"] = err.Error()
+			} else {
+				health.Probes = 1
+				outputs["registry_health"] = health
+			}
+		}
+		if len(cfg.PublishWindows) > 0 {
+			outputs["publish_window"] = "open"
+			if _, err := checkPublishWindows(ctx, cfg, p.now(), false); err != nil {
+				outputs["publish_window"] = err.Error()
+			}
+		}
+		if cfg.DependencyChanges {
+			p.addDependencyChanges(ctx, cfg, releaseCtx, outputs, summary)
+		}
+		if cfg.MetadataDiff {
+			p.addMetadataDiff(ctx, cfg, outputs, summary)
+		}
+		if cfg.RetiredReport {
+			p.addRetiredReport(ctx, cfg, outputs, summary)
+		}
+		if cfg.DependencyPolicy != nil {
+			violations, err := checkDependencyPolicy(cfg)
+			if violations != nil {
+				outputs["dependency_policy"] = violations
+			}
+			if err != nil {
+				outputs["dependency_policy_error"] = err.Error()
+			}
+		}
+		if cfg.RequireExplicitFiles {
+			// Problems are reported in files_hygiene; the dry run goes on
+			_ = requireExplicitFiles(cfg, outputs)
+		}
+		if cfg.FirstPublishChecks {
+			// Failed checks are reported in onboarding; the dry run goes on
+			_ = p.checkFirstPublish(ctx, cfg, outputs, summary)
+		}
+		if cfg.Rehearse {
+			outputs["rehearsal"] = Rehearsal{Commands: testRegistryCommands()}
+		}
+		if cfg.TestRegistry {
+			outputs["command"] = strings.Join(testRegistryCommands(), " && ")
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: "Would publish package to local test registry",
+				Outputs: outputs,
+			}, nil
+		}
 		return &plugin.ExecuteResponse{
 			Success: true,
 			Message: "Would publish package to Hex.pm",
-			Outputs: map[string]any{
-				"command":      "mix " + strings.Join(args, " "),
-				"version":      version,
-				"organization": cfg.Organization,
-				"replace":      cfg.Replace,
-			},
+			Outputs: outputs,
 		}, nil
 	}
 
-	// Check for API key
-	if cfg.APIKey == "" {
+	// Check for API key; the local test registry needs none
+	if cfg.APIKey == "" && !cfg.TestRegistry {
 		return &plugin.ExecuteResponse{
 			Success: false,
 			Error:   "HEX_API_KEY is required: set api_key in config or HEX_API_KEY environment variable",
 		}, nil
 	}
 
-	// Build environment with HEX_API_KEY
-	env := []string{
-		fmt.Sprintf("HEX_API_KEY=%s", cfg.APIKey),
+	// Without --yes mix prompts for confirmation, which hangs without a terminal
+	if !cfg.Yes && !cfg.TestRegistry && !p.isInteractive() {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   errNonInteractive,
+		}, nil
+	}
+
+	// Organizations may forbid releases outside agreed hours
+	waited, err := checkPublishWindows(ctx, cfg, p.now(), cfg.PublishWindowAction == windowActionWait)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
 	}
 
-	// Execute mix hex.publish
-	output, err := p.getExecutor().Run(ctx, "mix", args, env, cfg.WorkDir)
+	// An org-wide change freeze overrides the release
+	freeze, err := p.checkFreeze(ctx, cfg)
 	if err != nil {
 		return &plugin.ExecuteResponse{
 			Success: false,
-			Error:   fmt.Sprintf("mix hex.publish failed: %v\nOutput: %s", err, string(output)),
+			Error:   err.Error(),
+		}, nil
+	}
+	if freeze.Frozen {
+		summary.debugf("release freeze active, freeze_action %s", cfg.FreezeAction)
+		outputs := map[string]any{"freeze_reason": freeze.describe()}
+		if cfg.FreezeAction == freezeActionSkip {
+			outputs["skipped"] = true
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: fmt.Sprintf("Skipped publishing v%s: release freeze active: %s", version, freeze.describe()),
+				Outputs: outputs,
+			}, nil
+		}
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("release freeze active: %s", freeze.describe()),
+			Outputs: outputs,
 		}, nil
 	}
 
-	return &plugin.ExecuteResponse{
+	// Publishing into a registry incident fails halfway or not at all
+	var health *RegistryHealth
+	var healthWait time.Duration
+	var healthErr error
+	if cfg.RegistryHealthCheck {
+		probed, waited, err := p.checkRegistryHealth(ctx, cfg, summary)
+		health, healthWait = &probed, waited
+		if err != nil && probed.Incident {
+			outputs := map[string]any{"registry_health": probed, "error_class": errorClassIncident}
+			if waited > 0 {
+				outputs["registry_health_wait"] = waited.String()
+			}
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+				Outputs: outputs,
+			}, nil
+		}
+		healthErr = err
+	}
+
+	outputs := map[string]any{
+		"version":      version,
+		"organization": cfg.Organization,
+	}
+	addVersionOutputs(outputs, version)
+	switch {
+	case healthErr != nil:
+		outputs["registry_health_error"] = healthErr.Error()
+	case health != nil:
+		outputs["registry_health"] = *health
+		if healthWait > 0 {
+			outputs["registry_health_wait"] = healthWait.String()
+		}
+	}
+	if cfg.keySource != "" {
+		summary.SecretSource = cfg.keySource
+		outputs["secret_source"] = cfg.keySource
+	}
+	if waited > 0 {
+		summary.debugf("waited %s for the publish window", waited)
+		outputs["publish_window_wait"] = waited.String()
+	}
+
+	// Automation loops must not hammer the registry with a broken package
+	tracker, failed := newPublishAttempts(cfg, version, outputs)
+	if failed != nil {
+		return failed, nil
+	}
+	if cfg.Profile != "" {
+		outputs["profile"] = cfg.Profile
+	}
+	if len(dirty) > 0 {
+		outputs["dirty_files"] = dirty
+	}
+	if cfg.Reproducible {
+		outputs["source_date_epoch"] = epoch
+	}
+	if cfg.DependencyChanges {
+		p.addDependencyChanges(ctx, cfg, releaseCtx, outputs, summary)
+	}
+
+	// A host deadline is shared out across the phases that follow
+	budget := newDeadlineBudget(ctx, cfg)
+	defer func() { budget.report(resp) }()
+	ctx = budget.enter(ctx, budgetGates)
+	if failed := cfg.Chaos.inject(budgetGates, outputs, summary); failed != nil {
+		return failed, nil
+	}
+
+	// Run pre-publish gates
+	if len(cfg.Gates) > 0 {
+		summary.debugf("running gates: %s", strings.Join(gateNames(cfg.Gates), ", "))
+		results := p.runGates(ctx, cfg, summary)
+		outputs["gates"] = results
+		addRetiredFromGates(outputs, results)
+
+		if cfg.JUnitPath != "" {
+			if err := writeJUnitReport(cfg.JUnitPath, "hex publish gates", results); err != nil {
+				outputs["junit_error"] = err.Error()
+			} else {
+				outputs["junit_path"] = cfg.JUnitPath
+			}
+		}
+
+		if failed := failedGates(results); len(failed) > 0 {
+			summary.debugf("skipping publish: blocking gates failed: %s", strings.Join(failed, ", "))
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("pre-publish gates failed: %s", strings.Join(failed, ", ")),
+				Outputs: outputs,
+			}, nil
+		}
+	}
+
+	if cfg.DependencyPolicy != nil {
+		violations, err := checkDependencyPolicy(cfg)
+		if violations != nil {
+			outputs["dependency_policy"] = violations
+		}
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+				Outputs: outputs,
+			}, nil
+		}
+	}
+
+	if cfg.RequireExplicitFiles {
+		if err := requireExplicitFiles(cfg, outputs); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+				Outputs: outputs,
+			}, nil
+		}
+	}
+
+	// The first publish of a name cannot be taken back
+	if cfg.FirstPublishChecks {
+		if err := p.checkFirstPublish(ctx, cfg, outputs, summary); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+				Outputs: outputs,
+			}, nil
+		}
+	}
+
+	// Compile against every Elixir/OTP image before anything is uploaded
+	if len(cfg.VerifyMatrix) > 0 {
+		results := p.runVerifyMatrix(ctx, cfg, summary)
+		outputs["verify_matrix"] = results
+
+		if failed := failedGates(results); len(failed) > 0 {
+			summary.debugf("skipping publish: verify_matrix failed on: %s", strings.Join(failed, ", "))
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("verify_matrix failed on: %s", strings.Join(failed, ", ")),
+				Outputs: outputs,
+			}, nil
+		}
+	}
+
+	ctx = budget.enter(ctx, budgetBuild)
+	buildStart := time.Now()
+	// buildFailed ends the build on the timeline with the failure
+	buildFailed := func(failed *plugin.ExecuteResponse) *plugin.ExecuteResponse {
+		summary.emit(finished(eventBuildFinished, cfg.WorkDir, buildStart, errors.New(failed.Error)))
+		return failed
+	}
+	if failed := cfg.Chaos.inject(budgetBuild, outputs, summary); failed != nil {
+		return buildFailed(failed), nil
+	}
+
+	// Project steps such as asset builds must finish before the package is built
+	if err := p.runTasks(ctx, cfg, cfg.PrePublishTasks, summary); err != nil {
+		return buildFailed(&plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("pre_publish_tasks failed: %v", err),
+			Outputs: outputs,
+		}), nil
+	}
+
+	if cfg.TestRegistry {
+		summary.debugf("publishing to the local test registry instead of Hex.pm")
+		resp := p.publishToTestRegistry(ctx, cfg, version, outputs, summary)
+		return p.runPostPublishTasks(ctx, cfg, version, resp, summary), nil
+	}
+
+	// A rehearsal failure stops the run before anything reaches the registry
+	if cfg.Rehearse {
+		if failed := p.rehearse(ctx, cfg, version, outputs, summary); failed != nil {
+			return buildFailed(failed), nil
+		}
+	}
+
+	// Publish with a key that only lives as long as this run
+	if cfg.ProvisionOrgKey {
+		name, err := p.provisionOrgKey(ctx, cfg, summary)
+		if err != nil {
+			return buildFailed(&plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+				Outputs: outputs,
+			}), nil
+		}
+		outputs["provisioned_key"] = name
+		summary.SecretSource = cfg.keySource
+		outputs["secret_source"] = cfg.keySource
+		defer func() {
+			if err := p.revokeOrgKey(context.WithoutCancel(ctx), cfg, name, summary); err != nil {
+				outputs["provisioned_key_error"] = err.Error()
+			}
+		}()
+	}
+
+	// Build environment with HEX_API_KEY and the profile's registry
+	env := cfg.hexEnv()
+	if cfg.NoVerifyRepoOrigin {
+		summary.debugf("registry origin verification disabled by no_verify_repo_origin")
+	}
+
+	// Settings without an environment variable go through mix hex.config
+	if len(cfg.HexConfig) > 0 {
+		cleanup, err := p.applyHexConfig(ctx, cfg, env, summary)
+		if err != nil {
+			return buildFailed(&plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+				Outputs: outputs,
+			}), nil
+		}
+		defer cleanup()
+	}
+
+	// A follow-up run only publishes the docs a failed run left behind
+	if cfg.ResumeDocs && !cfg.Replace {
+		if resp, resumed := p.resumeDocs(ctx, cfg, args, env, version, outputs, summary); resumed {
+			if !resp.Success {
+				return buildFailed(resp), nil
+			}
+			summary.emit(finished(eventBuildFinished, cfg.WorkDir, buildStart, nil))
+			return resp, nil
+		}
+	}
+
+	// Surface metadata warnings before anything is uploaded
+	if cfg.WarningsAsErrorsPublish {
+		output, err := p.runMix(ctx, cfg, summary, publishDryRunArgs(args), env)
+		if err != nil {
+			return buildFailed(&plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("mix %s --dry-run failed: %v\nOutput: %s", cfg.Task, err, string(output)),
+				Outputs: outputs,
+			}), nil
+		}
+		if warnings := parsePublishWarnings(string(output)); len(warnings) > 0 {
+			outputs["warnings"] = warnings
+			return buildFailed(&plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("mix %s reported %d warning(s) with warnings_as_errors_publish enabled: %s", cfg.Task, len(warnings), strings.Join(warnings, "; ")),
+				Outputs: outputs,
+			}), nil
+		}
+	}
+
+	if cfg.RetiredReport {
+		p.addRetiredReport(ctx, cfg, outputs, summary)
+	}
+	if cfg.PackageManifest {
+		p.addPackageManifest(ctx, cfg, outputs, summary)
+	}
+	if cfg.DependencyTree {
+		p.addDependencyTree(ctx, cfg, outputs, summary)
+	}
+
+	// Secrets must be caught here: a published release cannot be unpublished
+	if cfg.ScanSecrets {
+		if err := p.checkPackageSecrets(ctx, cfg, outputs, summary); err != nil {
+			return buildFailed(&plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+				Outputs: outputs,
+			}), nil
+		}
+	}
+
+	// Artifacts never block the publish; failures are reported as artifacts_error
+	var artifacts []ReleaseArtifact
+	var artifactErrors []string
+	if cfg.ArtifactsDir != "" {
+		if artifact, err := p.buildPackageArtifact(ctx, cfg, version, summary); err != nil {
+			artifactErrors = append(artifactErrors, err.Error())
+		} else {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+
+	summary.emit(finished(eventBuildFinished, cfg.WorkDir, buildStart, nil))
+
+	ctx = budget.enter(ctx, budgetUpload)
+	uploadStart := time.Now()
+	summary.emit(PublishEvent{Event: eventUploadStarted, WorkDir: cfg.WorkDir})
+	if failed := cfg.Chaos.inject(budgetUpload, outputs, summary); failed != nil {
+		summary.emit(finished(eventUploadFinished, cfg.WorkDir, uploadStart, errors.New(failed.Error)))
+		return failed, nil
+	}
+
+	// Execute mix hex.publish, forwarding the terminal for the confirmation prompt
+	var packageTimeout time.Duration
+	if cfg.SplitPhases {
+		packageTimeout = cfg.PackageTimeout
+	}
+	output, phase, err := p.runPhase(withAttemptTracker(ctx, tracker), cfg, packageTimeout, args, env, summary)
+	tracker.record(err, outputs)
+	uploaded := finished(eventUploadFinished, cfg.WorkDir, uploadStart, err)
+	uploaded.Attempts = phase.Attempts
+	summary.emit(uploaded)
+	attempts, errorClass := phase.Attempts, phase.ErrorClass
+	if attempts > 1 {
+		outputs["attempts"] = attempts
+	}
+	if phase.Bootstrapped {
+		outputs["bootstrapped_hex"] = true
+	}
+	if path := spooledOutputPath(output); path != "" {
+		outputs["output_file"] = path
+	}
+	phases := map[string]PhaseResult{phasePackage: phase}
+	if cfg.SplitPhases {
+		outputs["phases"] = phases
+	}
+	summary.debugf("mix %s finished after %d attempt(s), error class %q", cfg.Task, attempts, errorClass)
+
+	// Record exactly what ran so a failed publish can be reproduced by hand
+	outputs["exit_code"] = exitCodeOf(err)
+	mixName, mixArgs, mixEnv := cfg.mixCommand(args, env)
+	outputs["argv"] = append([]string{mixName}, mixArgs...)
+	outputs["work_dir"] = cfg.WorkDir
+	outputs["env"] = redactEnv(mixEnv)
+
+	var terminated *TerminatedError
+	if errors.As(err, &terminated) {
+		outputs["terminated_by"] = terminated.Signal
+	}
+	if warnings := parsePublishWarnings(string(output)); len(warnings) > 0 {
+		outputs["warnings"] = warnings
+	}
+	addRetired(outputs, parseRetiredWarnings(string(output)))
+	extractOutputs(cfg.OutputPatterns, string(output), outputs)
+
+	if err != nil {
+		outputs["error_class"] = errorClass
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("mix %s failed: %v\nOutput: %s", cfg.Task, err, string(output)),
+			Outputs: outputs,
+		}, nil
+	}
+
+	outputs["output"] = string(output)
+
+	if name := parsePublishedPackage(string(output)); name != "" {
+		summary.Package = name
+		outputs["package"] = name
+		outputs["package_url"] = packageURL(cfg.Organization, name, version)
+		summary.addURL("package", outputs["package_url"].(string))
+		if cfg.DocsDestination != docsDestinationCustom && cfg.Organization == "" {
+			summary.addURL("docs", hexdocsURL(name, version))
+		}
+	}
+
+	// Notification plugins post about the release from this payload
+	mixExs, _ := readMixExs(cfg.WorkDir)
+	announcement := buildAnnouncement(cfg, releaseCtx, parsePublishedPackage(string(output)), version, mixExs)
+	announcement.CI = summary.CI
+	outputs["announcement"] = announcement
+
+	// A docs failure must not force re-publishing the package
+	if cfg.splitsDocs() {
+		if failed := cfg.Chaos.inject(phaseDocs, outputs, summary); failed != nil {
+			return failed, nil
+		}
+		result, err := p.publishDocsPhase(ctx, cfg, args, env, summary)
+		phases[phaseDocs] = result
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("package v%s published but docs publish failed: %v", version, err),
+				Outputs: outputs,
+			}, nil
+		}
+	}
+
+	if cfg.usesCustomDocs() {
+		uploaded, err := p.publishCustomDocs(ctx, cfg, version, summary)
+		outputs["docs_targets"] = uploaded
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("package v%s published but docs upload failed: %v", version, err),
+				Outputs: outputs,
+			}, nil
+		}
+	}
+
+	if cfg.ArtifactsDir != "" {
+		if artifact, err := buildDocsArtifact(cfg, version); err != nil {
+			artifactErrors = append(artifactErrors, err.Error())
+		} else {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	if len(artifactErrors) > 0 {
+		outputs["artifacts_error"] = strings.Join(artifactErrors, "; ")
+	}
+
+	resp = &plugin.ExecuteResponse{
 		Success: true,
 		Message: fmt.Sprintf("Published package v%s to Hex.pm", version),
-		Outputs: map[string]any{
-			"version":      version,
-			"organization": cfg.Organization,
-			"output":       string(output),
-		},
-	}, nil
+		Outputs: outputs,
+	}
+	registerArtifacts(resp, artifacts, summary)
+	ctx = budget.enter(ctx, budgetVerification)
+	verifyStart := time.Now()
+	if failed := cfg.Chaos.inject(budgetVerification, outputs, summary); failed != nil {
+		summary.emit(finished(eventVerified, cfg.WorkDir, verifyStart, errors.New(failed.Error)))
+		return failed, nil
+	}
+	if cfg.Canary {
+		name := parsePublishedPackage(string(output))
+		if name == "" {
+			name, _ = declaredPackageName(cfg.WorkDir, projectTypeMix)
+		}
+		resp = p.runCanary(ctx, cfg, name, version, resp, summary)
+	}
+	resp = p.runPostPublishTasks(ctx, cfg, version, resp, summary)
+	var verifyErr error
+	if !resp.Success {
+		verifyErr = errors.New(resp.Error)
+	}
+	summary.emit(finished(eventVerified, cfg.WorkDir, verifyStart, verifyErr))
+	return resp, nil
 }
 
 // Validate validates the plugin configuration.
-func (p *HexPlugin) Validate(_ context.Context, config map[string]any) (*plugin.ValidateResponse, error) {
+func (p *HexPlugin) Validate(ctx context.Context, config map[string]any) (*plugin.ValidateResponse, error) {
 	vb := helpers.NewValidationBuilder()
+
+	// Validate the defaults and hooks blocks, then the effective publish config
+	merged, errs := mergeConfig(config, plugin.HookPostPublish)
+	for _, err := range errs {
+		vb.AddError(err.Field, err.Error())
+	}
+	if len(errs) > 0 {
+		return vb.Build(), nil
+	}
+	config = merged
 	parser := helpers.NewConfigParser(config)
 
-	// Validate work_dir if provided
-	workDir := parser.GetString("work_dir", "", ".")
-	if err := validatePath(workDir); err != nil {
-		vb.AddError("work_dir", err.Error())
+	// Validate duration options and nested objects
+	for _, err := range validateRawConfig(config) {
+		vb.AddError(err.Field, err.Error())
+	}
+
+	// Validate the options the publish hook checks too
+	cfg := p.parseConfig(config)
+	for _, err := range cfg.validate() {
+		vb.AddError(err.Field, err.Error())
+	}
+
+	if parser.GetString("secret_source_policy", "", secretPolicyAny) == secretPolicyNoInline {
+		for _, field := range inlineSecretFields(config) {
+			vb.AddError(field, fmt.Sprintf("inline api keys are forbidden by secret_source_policy %s", secretPolicyNoInline))
+		}
+	}
+
+	// Validate retry policy
+	if parser.GetInt("retries", 0) < 0 {
+		vb.AddError("retries", "must not be negative")
+	}
+	if err := validateLocale(parser.GetString("locale", "", defaultLocale)); err != nil {
+		vb.AddError(err.Field, err.Error())
+	}
+
+	if parser.GetInt("output_spool_threshold", 0) < 0 {
+		vb.AddError("output_spool_threshold", "must not be negative")
+	}
+
+	if text := parser.GetString("failure_message_template", "", ""); text != "" {
+		if _, err := parseFailureTemplate(text); err != nil {
+			vb.AddError("failure_message_template", err.Error())
+		}
+	}
+
+	if dir := parser.GetString("diagnostics_dir", "", ""); dir != "" {
+		if err := validatePath(dir); err != nil {
+			vb.AddError("diagnostics_dir", err.Error())
+		}
+	}
+	if parser.GetInt("diagnostics_lines", defaultDiagnosticsLines) < 1 {
+		vb.AddError("diagnostics_lines", "must be at least 1")
+	}
+
+	if parser.GetBool("telemetry", false) {
+		if err := validateHTTPURL(parser.GetString("telemetry_endpoint", "", "")); err != nil {
+			vb.AddError("telemetry_endpoint", err.Error())
+		}
+	}
+
+	if endpoint := parser.GetString("metrics_endpoint", "", ""); endpoint != "" {
+		if err := validateMetricsEndpoint(endpoint); err != nil {
+			vb.AddError("metrics_endpoint", err.Error())
+		}
+	}
+	if err := validateMetricsPrefix(parser.GetString("metrics_prefix", "", defaultMetricsPrefix)); err != nil {
+		vb.AddError("metrics_prefix", err.Error())
+	}
+
+	// Validate summary_path if provided
+	if summaryPath := parser.GetString("summary_path", "", ""); summaryPath != "" {
+		if err := validatePath(summaryPath); err != nil {
+			vb.AddError("summary_path", err.Error())
+		}
+	}
+	if transcriptDir := parser.GetString("transcript_dir", "", ""); transcriptDir != "" {
+		if err := validatePath(transcriptDir); err != nil {
+			vb.AddError("transcript_dir", err.Error())
+		}
+	}
+
+	// Catch mangled keys locally, before the key health check calls the API
+	keyValid := true
+	if cfg.APIKey != "" {
+		if err := checkAPIKeyFormat(cfg.APIKey); err != nil {
+			keyValid = false
+			vb.AddError("api_key", fmt.Sprintf("key from %s %v", cfg.keySource, err))
+		}
+	}
+
+	for i, pkg := range cfg.Packages {
+		if pkg.APIKey == "" {
+			continue
+		}
+		if err := checkAPIKeyFormat(pkg.APIKey); err != nil {
+			vb.AddError(fmt.Sprintf("packages[%d].api_key", i), err.Error())
+		}
 	}
 
-	// Validate organization if provided
-	org := parser.GetString("organization", "HEX_ORGANIZATION", "")
-	if err := validateOrganization(org); err != nil {
-		vb.AddError("organization", err.Error())
+	resp := vb.Build()
+
+	// Warnings are reported without invalidating the config
+	if cfg.KeyExpiryCheck && cfg.APIKey != "" && keyValid {
+		warnings, err := p.checkKeyHealth(ctx, cfg, time.Now())
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		}
+		for _, w := range warnings {
+			resp.Errors = append(resp.Errors, plugin.ValidationError{Field: "api_key", Message: w, Code: keyWarningCode})
+		}
 	}
 
-	return vb.Build(), nil
+	return resp, nil
 }
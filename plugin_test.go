@@ -72,7 +72,7 @@ func TestGetInfo(t *testing.T) {
 		{
 			name:     "hooks count",
 			got:      len(info.Hooks),
-			expected: 1,
+			expected: 7,
 		},
 	}
 
@@ -592,6 +592,7 @@ func TestExecuteActualRun(t *testing.T) {
 		config          map[string]any
 		mockOutput      []byte
 		mockError       error
+		terminal        bool
 		expectedSuccess bool
 		expectedMessage string
 		expectedError   string
@@ -701,11 +702,12 @@ func TestExecuteActualRun(t *testing.T) {
 			},
 		},
 		{
-			name: "publish without yes flag",
+			name: "publish without yes flag from a terminal",
 			config: map[string]any{
 				"api_key": "test-api-key",
 				"yes":     false,
 			},
+			terminal:        true,
 			mockOutput:      []byte("Published my_package v1.0.0"),
 			mockError:       nil,
 			expectedSuccess: true,
@@ -721,6 +723,20 @@ func TestExecuteActualRun(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "publish without yes flag fails fast non-interactively",
+			config: map[string]any{
+				"api_key": "test-api-key",
+				"yes":     false,
+			},
+			expectedSuccess: false,
+			expectedError:   "confirmation required but running non-interactively",
+			verifyCall: func(t *testing.T, calls []MockCall) {
+				if len(calls) != 0 {
+					t.Errorf("expected 0 calls without a terminal, got %d", len(calls))
+				}
+			},
+		},
 		{
 			name:   "missing api_key fails",
 			config: map[string]any{
@@ -760,7 +776,8 @@ func TestExecuteActualRun(t *testing.T) {
 				},
 			}
 
-			p := &HexPlugin{executor: mock}
+			terminal := tt.terminal
+			p := &HexPlugin{executor: mock, terminal: func() bool { return terminal }}
 			req := plugin.ExecuteRequest{
 				Hook:   plugin.HookPostPublish,
 				DryRun: false,
@@ -807,15 +824,9 @@ func TestExecuteUnhandledHook(t *testing.T) {
 		plugin.HookPreInit,
 		plugin.HookPostInit,
 		plugin.HookPrePlan,
-		plugin.HookPostPlan,
-		plugin.HookPreVersion,
-		plugin.HookPostVersion,
 		plugin.HookPreNotes,
 		plugin.HookPostNotes,
-		plugin.HookPreApprove,
 		plugin.HookPostApprove,
-		plugin.HookPrePublish,
-		plugin.HookOnSuccess,
 		plugin.HookOnError,
 	}
 
@@ -1111,9 +1122,46 @@ func TestValidateOrganization(t *testing.T) {
 			expectError: false,
 		},
 		{
-			name:        "mixed case is valid",
+			name:        "uppercase letters are invalid",
+			org:         "myOrg",
+			expectError: true,
+			errorMsg:    "invalid characters",
+		},
+		{
+			name:        "leading uppercase is invalid",
 			org:         "MyOrg",
-			expectError: false,
+			expectError: true,
+			errorMsg:    "must start with a lowercase letter",
+		},
+		{
+			name:        "leading digit is invalid",
+			org:         "1org",
+			expectError: true,
+			errorMsg:    "must start with a lowercase letter",
+		},
+		{
+			name:        "leading hyphen is invalid",
+			org:         "-myorg",
+			expectError: true,
+			errorMsg:    "must start with a lowercase letter",
+		},
+		{
+			name:        "trailing underscore is invalid",
+			org:         "myorg_",
+			expectError: true,
+			errorMsg:    "must not end with",
+		},
+		{
+			name:        "consecutive separators are invalid",
+			org:         "my-_org",
+			expectError: true,
+			errorMsg:    "consecutive",
+		},
+		{
+			name:        "too short name is invalid",
+			org:         "ab",
+			expectError: true,
+			errorMsg:    "too short",
 		},
 		{
 			name:        "name with spaces is invalid",
@@ -1135,7 +1183,7 @@ func TestValidateOrganization(t *testing.T) {
 		},
 		{
 			name:        "too long name is invalid",
-			org:         strings.Repeat("a", 129),
+			org:         strings.Repeat("a", 33),
 			expectError: true,
 			errorMsg:    "too long",
 		},
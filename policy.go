@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Replace policies selected by replace_policy.
+const (
+	replacePolicyAny        = "any"
+	replacePolicyPrerelease = "prerelease"
+)
+
+// validateReplacePolicy checks that replace_policy names a known policy.
+func validateReplacePolicy(policy string) error {
+	switch policy {
+	case "", replacePolicyAny, replacePolicyPrerelease:
+		return nil
+	default:
+		return fmt.Errorf("must be %s or %s", replacePolicyAny, replacePolicyPrerelease)
+	}
+}
+
+// isPrerelease reports whether a semantic version has a prerelease suffix
+// such as "-rc.1". Build metadata after "+" does not count.
+func isPrerelease(version string) bool {
+	version, _, _ = strings.Cut(strings.TrimPrefix(version, "v"), "+")
+	return strings.Contains(version, "-")
+}
+
+// checkReplacePolicy refuses --replace for stable versions under the
+// prerelease policy, unless allow_stable_replace overrides it.
+func checkReplacePolicy(cfg *Config, version string) error {
+	if !cfg.Replace || cfg.ReplacePolicy != replacePolicyPrerelease || cfg.AllowStableReplace {
+		return nil
+	}
+	if isPrerelease(version) {
+		return nil
+	}
+	return fmt.Errorf("replace is restricted to prerelease versions but %s is stable: set allow_stable_replace to replace it anyway", version)
+}
+
+// Release types planned by Relicta.
+const (
+	releaseTypeMajor = "major"
+	releaseTypeMinor = "minor"
+	releaseTypePatch = "patch"
+)
+
+// parseVersionCore returns the major, minor and patch numbers of a semantic
+// version, ignoring any prerelease or build suffix.
+func parseVersionCore(version string) ([3]int, bool) {
+	var core [3]int
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return core, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return core, false
+		}
+		core[i] = n
+	}
+	return core, true
+}
+
+// compareSemver compares two semantic versions, ordering a prerelease
+// before the release with the same numeric core.
+func compareSemver(a, b string) int {
+	a = strings.TrimPrefix(a, "v")
+	b = strings.TrimPrefix(b, "v")
+	aCore, _, _ := strings.Cut(strings.SplitN(a, "+", 2)[0], "-")
+	bCore, _, _ := strings.Cut(strings.SplitN(b, "+", 2)[0], "-")
+	if c := compareVersions(aCore, bCore); c != 0 {
+		return c
+	}
+	switch aPre, bPre := isPrerelease(a), isPrerelease(b); {
+	case aPre && !bPre:
+		return -1
+	case !aPre && bPre:
+		return 1
+	}
+	return compareVersions(a, b)
+}
+
+// versionBump returns the release type implied by going from previous to
+// version, or "" when the numeric core did not change.
+func versionBump(previous, version [3]int) string {
+	switch {
+	case version[0] != previous[0]:
+		return releaseTypeMajor
+	case version[1] != previous[1]:
+		return releaseTypeMinor
+	case version[2] != previous[2]:
+		return releaseTypePatch
+	}
+	return ""
+}
+
+// checkReleaseType verifies that the version bump matches the release type
+// planned by Relicta. Breaking changes bump the minor version before 1.0.0,
+// so a major release from 0.x may bump the minor. The check is skipped when
+// the release type or previous version is unknown, and for prerelease
+// iterations that keep the same numeric core.
+func checkReleaseType(releaseCtx plugin.ReleaseContext) error {
+	releaseType := strings.ToLower(releaseCtx.ReleaseType)
+	switch releaseType {
+	case releaseTypeMajor, releaseTypeMinor, releaseTypePatch:
+	default:
+		return nil
+	}
+
+	previous, ok := parseVersionCore(releaseCtx.PreviousVersion)
+	if !ok {
+		return nil
+	}
+	version, ok := parseVersionCore(releaseCtx.Version)
+	if !ok {
+		return nil
+	}
+
+	if compareSemver(releaseCtx.Version, releaseCtx.PreviousVersion) <= 0 {
+		return fmt.Errorf("version %s does not increase on previous version %s", releaseCtx.Version, releaseCtx.PreviousVersion)
+	}
+
+	bump := versionBump(previous, version)
+	switch {
+	case bump == "" && (isPrerelease(releaseCtx.Version) || isPrerelease(releaseCtx.PreviousVersion)):
+		return nil
+	case bump == releaseType:
+		return nil
+	case releaseType == releaseTypeMajor && previous[0] == 0 && version[0] == 0 && bump == releaseTypeMinor:
+		return nil
+	}
+
+	actual := bump
+	if actual == "" {
+		actual = "no"
+	}
+	return fmt.Errorf("release type %s does not match the %s bump from %s to %s", releaseType, actual, releaseCtx.PreviousVersion, releaseCtx.Version)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestIsPrerelease(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"1.0.0", false},
+		{"v1.0.0", false},
+		{"1.0.0-rc.1", true},
+		{"v2.0.0-beta", true},
+		{"1.0.0+build-5", false},
+		{"1.0.0-dev+build.5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := isPrerelease(tt.version); got != tt.want {
+				t.Errorf("isPrerelease(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckReplacePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		version string
+		wantErr bool
+	}{
+		{"replace disabled", Config{ReplacePolicy: replacePolicyPrerelease}, "1.0.0", false},
+		{"any policy allows stable", Config{Replace: true, ReplacePolicy: replacePolicyAny}, "1.0.0", false},
+		{"prerelease policy allows prerelease", Config{Replace: true, ReplacePolicy: replacePolicyPrerelease}, "1.0.0-rc.1", false},
+		{"prerelease policy refuses stable", Config{Replace: true, ReplacePolicy: replacePolicyPrerelease}, "1.0.0", true},
+		{"override allows stable", Config{Replace: true, ReplacePolicy: replacePolicyPrerelease, AllowStableReplace: true}, "1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReplacePolicy(&tt.cfg, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkReplacePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteReplacePolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        map[string]any
+		version       string
+		expectedError string
+	}{
+		{
+			name:          "stable replace refused",
+			config:        map[string]any{"api_key": "test-api-key", "replace": true, "replace_policy": "prerelease"},
+			version:       "1.2.0",
+			expectedError: "restricted to prerelease",
+		},
+		{
+			name:    "prerelease replace allowed",
+			config:  map[string]any{"api_key": "test-api-key", "replace": true, "replace_policy": "prerelease"},
+			version: "1.2.0-rc.2",
+		},
+		{
+			name:          "unknown policy",
+			config:        map[string]any{"api_key": "test-api-key", "replace_policy": "never"},
+			version:       "1.2.0",
+			expectedError: "invalid replace_policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  tt.config,
+				Context: plugin.ReleaseContext{Version: tt.version},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectedError == "" {
+				if !resp.Success {
+					t.Fatalf("expected success, got error: %s", resp.Error)
+				}
+				return
+			}
+			if resp.Success || !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got success=%v error=%q", tt.expectedError, resp.Success, resp.Error)
+			}
+			if len(mock.Calls) != 0 {
+				t.Errorf("expected no commands to run, got %d", len(mock.Calls))
+			}
+		})
+	}
+}
+
+func TestCheckReleaseType(t *testing.T) {
+	tests := []struct {
+		name        string
+		releaseType string
+		previous    string
+		version     string
+		wantErr     bool
+	}{
+		{"patch bump", "patch", "1.2.3", "1.2.4", false},
+		{"minor bump", "minor", "1.2.3", "1.3.0", false},
+		{"major bump", "major", "v1.2.3", "v2.0.0", false},
+		{"patch changing major", "patch", "1.2.3", "2.0.0", true},
+		{"minor changing only patch", "minor", "1.2.3", "1.2.4", true},
+		{"major before 1.0 bumps minor", "major", "0.4.1", "0.5.0", false},
+		{"major prerelease", "major", "1.2.3", "2.0.0-rc.1", false},
+		{"prerelease iteration", "major", "2.0.0-rc.1", "2.0.0-rc.2", false},
+		{"prerelease promoted", "major", "2.0.0-rc.2", "2.0.0", false},
+		{"version decreases", "patch", "1.2.3", "1.2.2", true},
+		{"unchanged version", "patch", "1.2.3", "1.2.3", true},
+		{"unknown release type", "", "1.2.3", "3.0.0", false},
+		{"no previous version", "patch", "", "3.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReleaseType(plugin.ReleaseContext{ReleaseType: tt.releaseType, PreviousVersion: tt.previous, Version: tt.version})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkReleaseType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteReleaseTypeMismatch(t *testing.T) {
+	releaseCtx := plugin.ReleaseContext{ReleaseType: "patch", PreviousVersion: "1.2.3", Version: "2.0.0"}
+
+	t.Run("blocked by default", func(t *testing.T) {
+		mock := &MockCommandExecutor{}
+		p := &HexPlugin{executor: mock}
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "test-api-key"},
+			Context: releaseCtx,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Success || !strings.Contains(resp.Error, "release type patch") {
+			t.Errorf("expected release type error, got success=%v error=%q", resp.Success, resp.Error)
+		}
+		if len(mock.Calls) != 0 {
+			t.Errorf("expected no commands to run, got %d", len(mock.Calls))
+		}
+	})
+
+	t.Run("check disabled", func(t *testing.T) {
+		p := &HexPlugin{executor: &MockCommandExecutor{}}
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "test-api-key", "check_release_type": false},
+			Context: releaseCtx,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success {
+			t.Errorf("expected success, got error: %s", resp.Error)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// maxPreviewFiles caps the files listed in a preview's markdown; the
+// structured preview always carries the full manifest.
+const maxPreviewFiles = 50
+
+// gateNotRun is the message of gates a dry-run preview lists without running.
+const gateNotRun = "not run (dry run)"
+
+// PublishPreview describes what a package publish will do once approved.
+type PublishPreview struct {
+	Package      string          `json:"package,omitempty"`
+	Version      string          `json:"version"`
+	Registry     string          `json:"registry"`
+	Organization string          `json:"organization,omitempty"`
+	Replace      bool            `json:"replace"`
+	Manifest     []ManifestEntry `json:"manifest,omitempty"`
+	ManifestSize int64           `json:"manifest_size,omitempty"`
+	// ManifestError explains why the tarball could not be built for the preview.
+	ManifestError string `json:"manifest_error,omitempty"`
+	// Gates holds the gate results, or the configured gates unrun in a dry run.
+	Gates []GateResult `json:"gates,omitempty"`
+}
+
+// registryLabel names the registry a publish targets.
+func (c *Config) registryLabel() string {
+	switch {
+	case c.TestRegistry:
+		return "local test registry"
+	case c.APIURL != "":
+		return c.APIURL
+	default:
+		return defaultHexAPIURL
+	}
+}
+
+// previewPackage builds the preview of a single package. The tarball is
+// built and the gates run so the approver sees their current state; a dry
+// run lists the gates without running anything. Both use mix, so rebar3 and
+// Gleam projects and umbrella roots are previewed without them.
+func (p *HexPlugin) previewPackage(ctx context.Context, cfg *Config, version string, dryRun bool, summary *RunSummary) PublishPreview {
+	preview := PublishPreview{
+		Version:      version,
+		Registry:     cfg.registryLabel(),
+		Organization: cfg.Organization,
+		Replace:      cfg.Replace,
+	}
+	projectType, _ := cfg.resolveProjectType()
+	switch projectType {
+	case projectTypeUmbrella:
+		preview.ManifestError = umbrellaRootError(cfg.WorkDir).Error()
+		return preview
+	case projectTypeGleam, projectTypeRebar3:
+		preview.Package, _ = declaredPackageName(cfg.WorkDir, projectType)
+		preview.ManifestError = fmt.Sprintf("%s projects are previewed without building the package", projectType)
+		return preview
+	}
+	if content, err := readMixExs(cfg.WorkDir); err == nil {
+		preview.Package = parseMixApp(content)
+	}
+
+	if dryRun {
+		for _, gate := range cfg.Gates {
+			preview.Gates = append(preview.Gates, GateResult{Name: gate.Name, Message: gateNotRun, AllowFailure: gate.AllowFailure})
+		}
+		return preview
+	}
+
+	if manifest, err := p.packageManifest(ctx, cfg, summary); err != nil {
+		preview.ManifestError = err.Error()
+	} else {
+		preview.Manifest = manifest
+		for _, entry := range manifest {
+			preview.ManifestSize += entry.Size
+		}
+	}
+	if len(cfg.Gates) > 0 {
+		preview.Gates = p.runGates(ctx, cfg, summary)
+	}
+	return preview
+}
+
+// markdown renders the preview for the approval step.
+func (pv PublishPreview) markdown() string {
+	var b strings.Builder
+
+	name := pv.Package
+	if name == "" {
+		name = "package"
+	}
+	fmt.Fprintf(&b, "## Hex publish preview: %s\n\n", strings.TrimSpace(name+" "+pv.Version))
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Registry | %s |\n", pv.Registry)
+	if pv.Organization != "" {
+		fmt.Fprintf(&b, "| Organization | `%s` |\n", pv.Organization)
+	}
+	replace := "no"
+	if pv.Replace {
+		replace = "yes, an existing version will be overwritten"
+	}
+	fmt.Fprintf(&b, "| Replace | %s |\n", replace)
+
+	switch {
+	case pv.ManifestError != "":
+		fmt.Fprintf(&b, "\n### Files\n\nThe package could not be built:\n```\n%s\n```\n", strings.TrimSpace(pv.ManifestError))
+	case len(pv.Manifest) > 0:
+		fmt.Fprintf(&b, "\n### Files (%d, %d bytes)\n\n", len(pv.Manifest), pv.ManifestSize)
+		for i, entry := range pv.Manifest {
+			if i == maxPreviewFiles {
+				fmt.Fprintf(&b, "- … and %d more\n", len(pv.Manifest)-maxPreviewFiles)
+				break
+			}
+			fmt.Fprintf(&b, "- `%s` (%d bytes)\n", entry.Path, entry.Size)
+		}
+	}
+
+	if len(pv.Gates) > 0 {
+		b.WriteString("\n### Gates\n\n| Gate | Result |\n|---|---|\n")
+		for _, g := range pv.Gates {
+			result := "✅ passed"
+			switch {
+			case g.Message == gateNotRun:
+				result = "⏸ " + gateNotRun
+			case !g.Success && g.AllowFailure:
+				result = "⚠️ failed (allowed)"
+			case !g.Success:
+				result = "❌ failed"
+			}
+			fmt.Fprintf(&b, "| %s | %s |\n", g.Name, result)
+		}
+	}
+
+	return b.String()
+}
+
+// preview handles PreApprove when preview is set: it shows exactly what
+// will be published if the release is approved. Blocking gate failures are
+// reported, not enforced; the publish itself still refuses to run past them.
+func (p *HexPlugin) preview(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool, summary *RunSummary) *plugin.ExecuteResponse {
+	if !cfg.Preview {
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "preview is not set; no publish preview rendered",
+		}
+	}
+
+	// Contexts without a version publish whatever mix.exs declares
+	version := strings.TrimPrefix(releaseCtx.Version, "v")
+	if version != "" {
+		if err := validateHexVersion(version); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("invalid version for Hex: %v", err),
+			}
+		}
+	}
+
+	targets := []*Config{cfg}
+	labels := []string{""}
+	if len(cfg.Packages) > 0 {
+		targets, labels = nil, nil
+		for _, pkg := range cfg.orderedPackages() {
+			targets = append(targets, cfg.forPackage(pkg))
+			labels = append(labels, pkg.label())
+		}
+	}
+	for _, target := range targets {
+		if err := target.validateTarget(); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+	}
+
+	previews := make([]PublishPreview, 0, len(targets))
+	for i, target := range targets {
+		preview := p.previewPackage(ctx, target, version, dryRun, summary)
+		if preview.Package == "" {
+			preview.Package = labels[i]
+		}
+		previews = append(previews, preview)
+	}
+
+	sections := make([]string, 0, len(previews))
+	for _, preview := range previews {
+		sections = append(sections, preview.markdown())
+	}
+	markdown := strings.Join(sections, "\n")
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: markdown,
+		Outputs: map[string]any{
+			"preview":          previews,
+			"preview_markdown": markdown,
+		},
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestRegistryLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{name: "hex.pm", want: defaultHexAPIURL},
+		{name: "mirror", cfg: Config{APIURL: "https://hex.example.com/api"}, want: "https://hex.example.com/api"},
+		{name: "test registry", cfg: Config{TestRegistry: true, APIURL: "https://hex.example.com/api"}, want: "local test registry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.registryLabel(); got != tt.want {
+				t.Errorf("registryLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutePreApprovePreview(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]any
+		dryRun    bool
+		wantCalls []string
+		wantText  []string
+	}{
+		{
+			name:      "builds and runs gates",
+			config:    map[string]any{"organization": "acme", "gates": []any{"test", map[string]any{"name": "credo", "allow_failure": true}}},
+			wantCalls: []string{"hex.build", "test", "credo"},
+			wantText: []string{
+				"## Hex publish preview: my_lib 1.2.0",
+				"| Organization | `acme` |",
+				"| Replace | no |",
+				"### Files (2, 14 bytes)",
+				"- `lib/my_lib.ex` (11 bytes)",
+				"| test | ✅ passed |",
+				"| credo | ⚠️ failed (allowed) |",
+			},
+		},
+		{
+			name:     "dry run runs nothing",
+			config:   map[string]any{"replace": true, "gates": []any{"test"}},
+			dryRun:   true,
+			wantText: []string{"| Replace | yes", "| test | ⏸ not run (dry run) |"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			if err := os.WriteFile(mixExsFile, []byte(`[app: :my_lib, version: "1.2.0"]`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					switch args[0] {
+					case "hex.build":
+						writeTestTarball(t, args[len(args)-1], map[string]string{"mix.exs": "mix", "lib/my_lib.ex": "defmodule x"})
+						return []byte("Saved to package.tar"), nil
+					case "credo":
+						return []byte("issues found"), errors.New("exit status 1")
+					}
+					return []byte("ok"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "preview": true}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPreApprove,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "v1.2.0"},
+				DryRun:  tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("unexpected failure: %s", resp.Error)
+			}
+
+			var calls []string
+			for _, c := range mock.Calls {
+				calls = append(calls, c.Args[0])
+			}
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			for _, want := range tt.wantText {
+				if !strings.Contains(resp.Message, want) {
+					t.Errorf("preview missing %q:\n%s", want, resp.Message)
+				}
+			}
+			previews := resp.Outputs["preview"].([]PublishPreview)
+			if len(previews) != 1 || previews[0].Package != "my_lib" || previews[0].Version != "1.2.0" {
+				t.Errorf("preview = %+v", previews)
+			}
+		})
+	}
+}
+
+func TestExecutePreApproveInvalidVersion(t *testing.T) {
+	p := &HexPlugin{executor: &MockCommandExecutor{}}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPreApprove,
+		Config:  map[string]any{"api_key": "test-api-key", "preview": true},
+		Context: plugin.ReleaseContext{Version: "1.2"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Success || !strings.Contains(resp.Error, "invalid version for Hex") {
+		t.Errorf("expected an invalid version error, got %+v", resp)
+	}
+}
+
+func TestExecutePreApproveSkipped(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		config       map[string]any
+		wantSuccess  bool
+		wantMessage  string
+		wantError    string
+		wantManifest string
+	}{
+		{
+			name:        "preview not set",
+			files:       map[string]string{mixExsFile: `[app: :my_lib, version: "1.2.0"]`},
+			config:      map[string]any{"gates": []any{"test"}},
+			wantSuccess: true,
+			wantMessage: "preview is not set",
+		},
+		{
+			name:         "gleam project",
+			files:        map[string]string{"gleam.toml": "name = \"my_lib\"\nversion = \"1.2.0\"\n"},
+			config:       map[string]any{"preview": true, "gates": []any{"test"}},
+			wantSuccess:  true,
+			wantMessage:  "## Hex publish preview: my_lib 1.2.0",
+			wantManifest: "gleam projects are previewed without building the package",
+		},
+		{
+			name:      "invalid work_dir",
+			config:    map[string]any{"preview": true, "work_dir": "../elsewhere"},
+			wantError: "invalid work_dir",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			for path, content := range tt.files {
+				writeFile(t, path, content)
+			}
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPreApprove,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.2.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if tt.wantMessage != "" && !strings.Contains(resp.Message, tt.wantMessage) {
+				t.Errorf("expected message containing %q, got %q", tt.wantMessage, resp.Message)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+			}
+			if tt.wantManifest != "" {
+				previews := resp.Outputs["preview"].([]PublishPreview)
+				if len(previews) != 1 || previews[0].ManifestError != tt.wantManifest {
+					t.Errorf("preview = %+v", previews)
+				}
+			}
+			if len(mock.Calls) > 0 {
+				t.Errorf("expected no commands, got %+v", mock.Calls)
+			}
+		})
+	}
+}
+
+func TestExecutePreApproveWithoutVersion(t *testing.T) {
+	chdirTemp(t)
+	writeFile(t, mixExsFile, `[app: :my_lib, version: "1.2.0"]`)
+	p := &HexPlugin{executor: &MockCommandExecutor{}}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPreApprove,
+		Config:  map[string]any{"api_key": "test-api-key", "preview": true},
+		Context: plugin.ReleaseContext{},
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+	if !strings.Contains(resp.Message, "## Hex publish preview: my_lib\n") {
+		t.Errorf("unexpected preview:\n%s", resp.Message)
+	}
+}
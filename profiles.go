@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+)
+
+// profileEnv selects a profile when the profile option is not set.
+const profileEnv = "RELICTA_HEX_PROFILE"
+
+// ProfileConfig bundles the registry settings of one environment, such as a
+// staging registry used to rehearse releases.
+type ProfileConfig struct {
+	APIURL       string `json:"api_url,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	// APIKeyEnv names the environment variable holding the profile's API key.
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+	APIKey    string `json:"api_key,omitempty"`
+	Mirror    string `json:"mirror,omitempty"`
+	// NoVerifyRepoOrigin disables registry origin checks for the mirror.
+	NoVerifyRepoOrigin bool `json:"no_verify_repo_origin,omitempty"`
+}
+
+// parseProfiles decodes the profiles map keyed by profile name.
+func parseProfiles(raw map[string]any) (map[string]ProfileConfig, *fieldError) {
+	val, ok := raw["profiles"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, &fieldError{Field: "profiles", Err: fmt.Errorf("must be an object keyed by profile name")}
+	}
+
+	profiles := make(map[string]ProfileConfig, len(obj))
+	for name, entry := range obj {
+		field := "profiles." + name
+		m, ok := entry.(map[string]any)
+		if !ok {
+			return nil, &fieldError{Field: field, Err: fmt.Errorf("must be an object")}
+		}
+		var profile ProfileConfig
+		if err := decodeStrict(m, &profile); err != nil {
+			return nil, &fieldError{Field: field, Err: err}
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// selectedProfile returns the profile named by the profile option or RELICTA_HEX_PROFILE.
+func selectedProfile(raw map[string]any) string {
+	if name, ok := raw["profile"].(string); ok && name != "" {
+		return name
+	}
+	return os.Getenv(profileEnv)
+}
+
+// validateProfiles checks the profiles map and that the selected profile exists.
+func validateProfiles(raw map[string]any) []*fieldError {
+	profiles, ferr := parseProfiles(raw)
+	if ferr != nil {
+		return []*fieldError{ferr}
+	}
+
+	var errs []*fieldError
+	for name, profile := range profiles {
+		field := "profiles." + name
+		for key, value := range map[string]string{"api_url": profile.APIURL, "mirror": profile.Mirror} {
+			if value == "" {
+				continue
+			}
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, &fieldError{Field: field + "." + key, Err: fmt.Errorf("must be an http or https URL")})
+			}
+		}
+		if err := validateOrganization(profile.Organization); err != nil {
+			errs = append(errs, &fieldError{Field: field + ".organization", Err: err})
+		}
+	}
+
+	if name := selectedProfile(raw); name != "" {
+		if _, ok := profiles[name]; !ok {
+			names := make([]string, 0, len(profiles))
+			for n := range profiles {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			errs = append(errs, &fieldError{Field: "profile", Err: fmt.Errorf("unknown profile %q (defined: %v)", name, names)})
+		}
+	}
+
+	// Keep error order stable for reporting
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// applyProfile overrides registry settings with those of the selected profile.
+func (c *Config) applyProfile(name string, profile ProfileConfig) {
+	c.Profile = name
+	if profile.APIURL != "" {
+		c.APIURL = profile.APIURL
+	}
+	if profile.Mirror != "" {
+		c.Mirror = profile.Mirror
+	}
+	if profile.NoVerifyRepoOrigin {
+		c.NoVerifyRepoOrigin = true
+	}
+	if profile.Organization != "" {
+		c.Organization = profile.Organization
+	}
+	if profile.APIKey != "" {
+		c.APIKey = profile.APIKey
+		c.keySource = secretSourceConfig
+	} else if profile.APIKeyEnv != "" {
+		c.APIKey = os.Getenv(profile.APIKeyEnv)
+		c.keySource = envSecretSource(profile.APIKeyEnv)
+	}
+}
+
+// hexEnv returns the environment passed to mix hex commands.
+func (c *Config) hexEnv() []string {
+	env := []string{fmt.Sprintf("HEX_API_KEY=%s", c.APIKey)}
+	if c.APIURL != "" {
+		env = append(env, fmt.Sprintf("HEX_API_URL=%s", c.APIURL))
+	}
+	if c.Mirror != "" {
+		env = append(env, fmt.Sprintf("HEX_MIRROR=%s", c.Mirror))
+	}
+	if c.NoVerifyRepoOrigin {
+		env = append(env, "HEX_NO_VERIFY_REPO_ORIGIN=1")
+	}
+	return env
+}
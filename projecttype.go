@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Project types selected by project_type.
+const (
+	projectTypeAuto     = "auto"
+	projectTypeMix      = "mix"
+	projectTypeUmbrella = "umbrella"
+	projectTypeRebar3   = "rebar3"
+	projectTypeGleam    = "gleam"
+)
+
+// Project definition files used to detect the project type.
+const (
+	rebarConfigFile = "rebar.config"
+	gleamTomlFile   = "gleam.toml"
+)
+
+// validateProjectType checks that project_type names a type that can be
+// published; umbrella roots are only ever detected.
+func validateProjectType(projectType string) *fieldError {
+	switch projectType {
+	case "", projectTypeAuto, projectTypeMix, projectTypeRebar3, projectTypeGleam:
+		return nil
+	}
+	return &fieldError{Field: "project_type", Err: fmt.Errorf("must be %s, %s, %s or %s", projectTypeAuto, projectTypeMix, projectTypeRebar3, projectTypeGleam)}
+}
+
+// detectProjectType identifies the project in dir from its definition
+// file. mix.exs wins over rebar.config, which Mix projects with Erlang
+// sources may carry as well. It returns "" when no definition file exists.
+func detectProjectType(dir string) string {
+	if content, err := readMixExs(dir); err == nil {
+		if _, umbrella := parseMixAppsPath(content); umbrella {
+			return projectTypeUmbrella
+		}
+		return projectTypeMix
+	}
+	if _, err := os.Stat(filepath.Join(dir, gleamTomlFile)); err == nil {
+		return projectTypeGleam
+	}
+	if _, err := os.Stat(filepath.Join(dir, rebarConfigFile)); err == nil {
+		return projectTypeRebar3
+	}
+	return ""
+}
+
+// resolveProjectType returns the project type and whether it was detected
+// rather than configured. Undetectable projects are treated as Mix projects,
+// whose errors explain what is missing.
+func (c *Config) resolveProjectType() (string, bool) {
+	if c.ProjectType != "" && c.ProjectType != projectTypeAuto {
+		return c.ProjectType, false
+	}
+	if detected := detectProjectType(c.WorkDir); detected != "" {
+		return detected, true
+	}
+	return projectTypeMix, true
+}
+
+// umbrellaApps lists the apps of an umbrella project.
+func umbrellaApps(root string) []string {
+	content, err := readMixExs(root)
+	if err != nil {
+		return nil
+	}
+	appsPath, ok := parseMixAppsPath(content)
+	if !ok || validatePath(appsPath) != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(filepath.Join(root, appsPath))
+	if err != nil {
+		return nil
+	}
+	var apps []string
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(root, appsPath, entry.Name(), mixExsFile)); entry.IsDir() && err == nil {
+			apps = append(apps, entry.Name())
+		}
+	}
+	return apps
+}
+
+// umbrellaRootError explains that an umbrella root cannot be published.
+func umbrellaRootError(root string) error {
+	msg := fmt.Sprintf("work_dir %s is an umbrella root, which cannot be published to Hex: set app to the umbrella app to publish, or list the apps under packages", root)
+	if apps := umbrellaApps(root); len(apps) > 0 {
+		msg += fmt.Sprintf(" (apps: %s)", strings.Join(apps, ", "))
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// mixOnlyOptions lists the configured options that run mix tasks, which
+// rebar3 and Gleam projects do not have.
+func (c *Config) mixOnlyOptions() []string {
+	var options []string
+	for _, opt := range []struct {
+		name string
+		set  bool
+	}{
+		{"task", c.Task != defaultPublishTask},
+		{"gates", len(c.Gates) > 0},
+		{"pre_publish_tasks", len(c.PrePublishTasks) > 0},
+		{"post_publish_tasks", len(c.PostPublishTasks) > 0},
+		{"split_phases", c.SplitPhases},
+		{"docs_destination", c.usesCustomDocs()},
+		{"hex_config", len(c.HexConfig) > 0},
+		{"package_manifest", c.PackageManifest},
+		{"scan_secrets", c.ScanSecrets},
+		{"first_publish_checks", c.FirstPublishChecks},
+		{"artifacts_dir", c.ArtifactsDir != ""},
+		{"test_registry", c.TestRegistry},
+		{"rehearse", c.Rehearse},
+		{"canary", c.Canary},
+	} {
+		if opt.set {
+			options = append(options, opt.name)
+		}
+	}
+	return options
+}
+
+// nativePublishCommand returns the command publishing a rebar3 or Gleam
+// project and the environment carrying the API key. Gleam reads the key
+// from HEXPM_API_KEY and cannot publish to organizations.
+func nativePublishCommand(cfg *Config, projectType string) (string, []string, []string, error) {
+	env := slices.Concat(cfg.toolchainEnv(), cfg.buildEnv, cfg.hexEnv())
+	switch projectType {
+	case projectTypeRebar3:
+		args := []string{"hex", "publish"}
+		if cfg.Organization != "" {
+			args = append(args, "--repo", "hexpm:"+cfg.Organization)
+		}
+		if cfg.Replace {
+			args = append(args, "--replace")
+		}
+		if cfg.Yes {
+			args = append(args, "--yes")
+		}
+		return "rebar3", args, env, nil
+	case projectTypeGleam:
+		if cfg.Organization != "" {
+			return "", nil, nil, fmt.Errorf("gleam publish cannot publish to an organization")
+		}
+		args := []string{"publish"}
+		if cfg.Replace {
+			args = append(args, "--replace")
+		}
+		if cfg.Yes {
+			args = append(args, "--yes")
+		}
+		return "gleam", args, append(env, "HEXPM_API_KEY="+cfg.APIKey), nil
+	}
+	return "", nil, nil, fmt.Errorf("%s projects are published with mix", projectType)
+}
+
+var (
+	// gleamNamePattern matches the package name in gleam.toml.
+	gleamNamePattern = regexp.MustCompile(`(?m)^name\s*=\s*"([^"]+)"`)
+	// appSrcPattern matches the application name in an .app.src file.
+	appSrcPattern = regexp.MustCompile(`\{\s*application\s*,\s*'?([a-z][a-zA-Z0-9_]*)'?`)
+)
+
+// nativePackageName reads the package name of a rebar3 or Gleam project.
+func nativePackageName(dir, projectType string) string {
+	switch projectType {
+	case projectTypeGleam:
+		if data, err := os.ReadFile(filepath.Join(dir, gleamTomlFile)); err == nil {
+			if m := gleamNamePattern.FindSubmatch(data); m != nil {
+				return string(m[1])
+			}
+		}
+	case projectTypeRebar3:
+		matches, _ := filepath.Glob(filepath.Join(dir, "src", "*.app.src"))
+		for _, path := range matches {
+			if data, err := os.ReadFile(path); err == nil {
+				if m := appSrcPattern.FindSubmatch(data); m != nil {
+					return string(m[1])
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// publishNative publishes a rebar3 or Gleam project with its own tool. The
+// checks shared with Mix projects have already run.
+func (p *HexPlugin) publishNative(ctx context.Context, cfg *Config, projectType, version string, dryRun bool, summary *RunSummary) *plugin.ExecuteResponse {
+	if options := cfg.mixOnlyOptions(); len(options) > 0 {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("%s requires a Mix project, but work_dir is a %s project", strings.Join(options, ", "), projectType),
+		}
+	}
+	name, args, env, err := nativePublishCommand(cfg, projectType)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	command := strings.Join(append([]string{name}, args...), " ")
+	outputs := map[string]any{
+		"command":      command,
+		"version":      version,
+		"organization": cfg.Organization,
+		"replace":      cfg.Replace,
+	}
+	if dryRun {
+		summary.debugf("dry run: not running %s", command)
+		addVersionOutputs(outputs, version)
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "Would publish package to Hex.pm",
+			Outputs: outputs,
+		}
+	}
+
+	tracker, failed := newPublishAttempts(cfg, version, outputs)
+	if failed != nil {
+		return failed
+	}
+	if tracker != nil {
+		if err := tracker.take(); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+				Outputs: outputs,
+			}
+		}
+	}
+	start := time.Now()
+	summary.emit(PublishEvent{Event: eventUploadStarted, WorkDir: cfg.WorkDir})
+	output, err := p.runCommand(ctx, summary, name, args, env, cfg.WorkDir)
+	tracker.record(err, outputs)
+	summary.emit(finished(eventUploadFinished, cfg.WorkDir, start, err))
+	outputs["exit_code"] = exitCodeOf(err)
+	outputs["argv"] = append([]string{name}, args...)
+	outputs["work_dir"] = cfg.WorkDir
+	if err != nil {
+		outputs["error_class"] = classifyError(ctx, output, err)
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("%s failed: %v\nOutput: %s", command, err, string(output)),
+			Outputs: outputs,
+		}
+	}
+	outputs["output"] = string(output)
+	if pkg := nativePackageName(cfg.WorkDir, projectType); pkg != "" {
+		summary.Package = pkg
+		outputs["package"] = pkg
+		outputs["package_url"] = packageURL(cfg.Organization, pkg, version)
+		summary.addURL("package", outputs["package_url"].(string))
+	}
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: fmt.Sprintf("Published package v%s to Hex.pm", version),
+		Outputs: outputs,
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestDetectProjectType(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{name: "mix", files: map[string]string{mixExsFile: `app: :my_lib`}, want: projectTypeMix},
+		{name: "mix with rebar.config", files: map[string]string{mixExsFile: `app: :my_nif`, rebarConfigFile: `{erl_opts, []}.`}, want: projectTypeMix},
+		{name: "umbrella", files: map[string]string{mixExsFile: `apps_path: "apps"`}, want: projectTypeUmbrella},
+		{name: "rebar3", files: map[string]string{rebarConfigFile: `{erl_opts, []}.`}, want: projectTypeRebar3},
+		{name: "gleam", files: map[string]string{gleamTomlFile: `name = "my_lib"`}, want: projectTypeGleam},
+		{name: "unknown", files: map[string]string{"README.md": "hi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			if got := detectProjectType(dir); got != tt.want {
+				t.Errorf("detectProjectType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNativePublishCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		projectType string
+		wantName    string
+		wantArgs    []string
+		wantKeyEnv  string
+		wantErr     string
+	}{
+		{name: "rebar3", cfg: Config{APIKey: "k", Yes: true}, projectType: projectTypeRebar3, wantName: "rebar3", wantArgs: []string{"hex", "publish", "--yes"}, wantKeyEnv: "HEX_API_KEY=k"},
+		{name: "rebar3 organization", cfg: Config{APIKey: "k", Organization: "acme", Replace: true, Yes: true}, projectType: projectTypeRebar3, wantName: "rebar3", wantArgs: []string{"hex", "publish", "--repo", "hexpm:acme", "--replace", "--yes"}, wantKeyEnv: "HEX_API_KEY=k"},
+		{name: "gleam", cfg: Config{APIKey: "k", Yes: true}, projectType: projectTypeGleam, wantName: "gleam", wantArgs: []string{"publish", "--yes"}, wantKeyEnv: "HEXPM_API_KEY=k"},
+		{name: "gleam organization", cfg: Config{APIKey: "k", Organization: "acme"}, projectType: projectTypeGleam, wantErr: "cannot publish to an organization"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, args, env, err := nativePublishCommand(&tt.cfg, tt.projectType)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("got %s %v, want %s %v", name, args, tt.wantName, tt.wantArgs)
+			}
+			if !strings.Contains(strings.Join(env, " "), tt.wantKeyEnv) {
+				t.Errorf("env %v missing %s", env, tt.wantKeyEnv)
+			}
+		})
+	}
+}
+
+func TestExecuteProjectType(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		config      map[string]any
+		wantSuccess bool
+		wantError   string
+		wantType    string
+		wantCommand []string
+		wantPackage string
+	}{
+		{
+			name:        "mix",
+			files:       map[string]string{mixExsFile: `app: :my_lib`},
+			wantSuccess: true,
+			wantType:    projectTypeMix,
+			wantCommand: []string{"mix", "hex.publish", "--yes"},
+		},
+		{
+			name:        "gleam",
+			files:       map[string]string{gleamTomlFile: "name = \"my_gleam\"\nversion = \"1.0.0\"\n"},
+			wantSuccess: true,
+			wantType:    projectTypeGleam,
+			wantCommand: []string{"gleam", "publish", "--yes"},
+			wantPackage: "my_gleam",
+		},
+		{
+			name:        "rebar3",
+			files:       map[string]string{rebarConfigFile: "{deps, []}.", "src/my_erl.app.src": "{application, my_erl, [{vsn, \"1.0.0\"}]}."},
+			wantSuccess: true,
+			wantType:    projectTypeRebar3,
+			wantCommand: []string{"rebar3", "hex", "publish", "--yes"},
+			wantPackage: "my_erl",
+		},
+		{
+			name:        "override",
+			files:       map[string]string{rebarConfigFile: "{deps, []}."},
+			config:      map[string]any{"project_type": "mix"},
+			wantSuccess: true,
+			wantType:    projectTypeMix,
+			wantCommand: []string{"mix", "hex.publish", "--yes"},
+		},
+		{
+			name:      "umbrella root",
+			files:     map[string]string{mixExsFile: `apps_path: "apps"`, "apps/core/mix.exs": "app: :core", "apps/web/mix.exs": "app: :web"},
+			wantType:  projectTypeUmbrella,
+			wantError: "is an umbrella root, which cannot be published to Hex: set app to the umbrella app to publish, or list the apps under packages (apps: core, web)",
+		},
+		{
+			name:      "mix-only option",
+			files:     map[string]string{gleamTomlFile: `name = "my_gleam"`},
+			config:    map[string]any{"pre_publish_tasks": []any{"assets.build"}},
+			wantType:  projectTypeGleam,
+			wantError: "pre_publish_tasks requires a Mix project, but work_dir is a gleam project",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			for name, content := range tt.files {
+				if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, name, content)
+			}
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+			}
+			if resp.Outputs["project_type"] != tt.wantType {
+				t.Errorf("project_type = %v, want %s", resp.Outputs["project_type"], tt.wantType)
+			}
+			if tt.wantCommand != nil {
+				if len(mock.Calls) != 1 {
+					t.Fatalf("expected 1 command, got %+v", mock.Calls)
+				}
+				if got := append([]string{mock.Calls[0].Name}, mock.Calls[0].Args...); !reflect.DeepEqual(got, tt.wantCommand) {
+					t.Errorf("ran %v, want %v", got, tt.wantCommand)
+				}
+			}
+			if tt.wantPackage != "" && resp.Outputs["package"] != tt.wantPackage {
+				t.Errorf("package = %v, want %s", resp.Outputs["package"], tt.wantPackage)
+			}
+		})
+	}
+}
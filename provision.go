@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// provisionedKeyPrefix names the keys minted for a single publish.
+const provisionedKeyPrefix = "relicta-hex-publish"
+
+// provisionedKeyPermission is the only permission a minted key receives.
+const provisionedKeyPermission = "api:write"
+
+// provisionedKeyName returns the name of a key minted at now.
+func provisionedKeyName(now time.Time) string {
+	return fmt.Sprintf("%s-%s", provisionedKeyPrefix, now.UTC().Format("20060102150405"))
+}
+
+// orgKeyGenerateArgs returns the mix arguments minting a write-scoped organization key.
+func orgKeyGenerateArgs(organization, name string) []string {
+	return []string{"hex.organization", "key", organization, "generate", "--key-name", name, "--permission", provisionedKeyPermission}
+}
+
+// orgKeyRevokeArgs returns the mix arguments revoking an organization key.
+func orgKeyRevokeArgs(organization, name string) []string {
+	return []string{"hex.organization", "key", organization, "revoke", name}
+}
+
+// parseGeneratedKey returns the secret printed by mix hex.organization key
+// generate, which is the last line of its output.
+func parseGeneratedKey(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	key := strings.TrimSpace(lines[len(lines)-1])
+	if strings.ContainsAny(key, " \t") {
+		return ""
+	}
+	return key
+}
+
+// provisionOrgKey mints a short-lived organization key with the configured
+// API key and switches cfg to it. The caller must revoke it.
+func (p *HexPlugin) provisionOrgKey(ctx context.Context, cfg *Config, summary *RunSummary) (string, error) {
+	if cfg.Organization == "" {
+		return "", fmt.Errorf("provision_org_key requires organization")
+	}
+
+	name := provisionedKeyName(p.now())
+	output, err := p.runMix(ctx, cfg, summary, orgKeyGenerateArgs(cfg.Organization, name), cfg.hexEnv())
+	if err != nil {
+		return "", fmt.Errorf("mix hex.organization key generate failed: %w", err)
+	}
+	key := parseGeneratedKey(string(output))
+	if key == "" {
+		return "", fmt.Errorf("mix hex.organization key generate printed no key")
+	}
+
+	summary.masker.add(key)
+	cfg.provisioningKey = cfg.APIKey
+	cfg.APIKey = key
+	cfg.keySource = secretSourceProvisioned
+	return name, nil
+}
+
+// revokeOrgKey revokes a provisioned key with the key that minted it and
+// restores that key in cfg.
+func (p *HexPlugin) revokeOrgKey(ctx context.Context, cfg *Config, name string, summary *RunSummary) error {
+	cfg.APIKey = cfg.provisioningKey
+	output, err := p.runMix(ctx, cfg, summary, orgKeyRevokeArgs(cfg.Organization, name), cfg.hexEnv())
+	if err != nil {
+		return fmt.Errorf("failed to revoke provisioned key %s: %v\nOutput: %s", name, err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseGeneratedKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"key only", "abc123\n", "abc123"},
+		{"after banner", "Generating key...\nabc123\n", "abc123"},
+		{"no key", "** (Mix) Missing permissions\n", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseGeneratedKey(tt.output); got != tt.want {
+				t.Errorf("parseGeneratedKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvisionedKeyName(t *testing.T) {
+	got := provisionedKeyName(time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC))
+	if got != "relicta-hex-publish-20261017093000" {
+		t.Errorf("provisionedKeyName() = %q", got)
+	}
+}
+
+func TestExecuteProvisionOrgKey(t *testing.T) {
+	tests := []struct {
+		name            string
+		generateOutput  string
+		generateErr     error
+		publishErr      error
+		revokeErr       error
+		expectedSuccess bool
+		expectedError   string
+		expectedRevoke  bool
+	}{
+		{
+			name:            "publishes with the minted key",
+			generateOutput:  "minted-key-789\n",
+			expectedSuccess: true,
+			expectedRevoke:  true,
+		},
+		{
+			name:           "revoked after a failed publish",
+			generateOutput: "minted-key-789\n",
+			publishErr:     errors.New("exit status 1"),
+			expectedError:  "mix hex.publish failed",
+			expectedRevoke: true,
+		},
+		{
+			name:          "generate failure",
+			generateErr:   errors.New("exit status 1"),
+			expectedError: "mix hex.organization key generate failed",
+		},
+		{
+			name:            "revoke failure is reported",
+			generateOutput:  "minted-key-789\n",
+			revokeErr:       errors.New("exit status 1"),
+			expectedSuccess: true,
+			expectedRevoke:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var publishEnv, revokeEnv []string
+			var revoked string
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					switch {
+					case args[0] == "hex.organization" && args[3] == "generate":
+						return []byte(tt.generateOutput), tt.generateErr
+					case args[0] == "hex.organization" && args[3] == "revoke":
+						revoked, revokeEnv = args[4], env
+						return nil, tt.revokeErr
+					case args[0] == "hex.publish":
+						publishEnv = env
+						return []byte("Building my_lib 1.0.0"), tt.publishErr
+					}
+					return nil, nil
+				},
+			}
+			now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+			p := &HexPlugin{executor: mock, clock: func() time.Time { return now }}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"api_key":           "admin-key",
+					"organization":      "acme",
+					"provision_org_key": true,
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if tt.expectedError != "" && !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, resp.Error)
+			}
+
+			if !tt.expectedRevoke {
+				if revoked != "" {
+					t.Errorf("unexpected revoke of %s", revoked)
+				}
+				return
+			}
+			if !contains(publishEnv, "HEX_API_KEY=minted-key-789") {
+				t.Errorf("expected the publish to use the minted key, got %v", publishEnv)
+			}
+			if revoked != resp.Outputs["provisioned_key"] || revoked != provisionedKeyName(now) {
+				t.Errorf("expected the provisioned key to be revoked, got %q (outputs %v)", revoked, resp.Outputs["provisioned_key"])
+			}
+			if !contains(revokeEnv, "HEX_API_KEY=admin-key") {
+				t.Errorf("expected the revoke to use the admin key, got %v", revokeEnv)
+			}
+			if _, ok := resp.Outputs["provisioned_key_error"]; ok != (tt.revokeErr != nil) {
+				t.Errorf("unexpected provisioned_key_error: %v", resp.Outputs["provisioned_key_error"])
+			}
+		})
+	}
+}
+
+func TestExecuteProvisionOrgKeyTranscript(t *testing.T) {
+	for _, threshold := range []int{0, 8} {
+		t.Run(fmt.Sprintf("spool threshold %d", threshold), func(t *testing.T) {
+			chdirTemp(t)
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					output := "Package published"
+					if args[0] == "hex.organization" && args[3] == "generate" {
+						output = "Generating key...\nminted-key-789\n"
+					}
+					w := &spoolWriter{threshold: spoolThresholdFrom(ctx)}
+					_, _ = w.Write([]byte(output))
+					return w.Bytes(), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"api_key":                "admin-key",
+					"organization":           "acme",
+					"provision_org_key":      true,
+					"transcript_dir":         "audit",
+					"output_spool_threshold": threshold,
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			runDir, ok := resp.Outputs["transcript_archive"].(string)
+			if !ok {
+				t.Fatalf("expected transcript_archive, got %v", resp.Outputs)
+			}
+			data, err := os.ReadFile(filepath.Join(runDir, "01-mix.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(data), "minted-key-789") {
+				t.Errorf("expected the provisioned key to be masked in the transcript, got %q", data)
+			}
+			if !strings.Contains(string(data), "Generating key...") {
+				t.Errorf("expected the generate output in the transcript, got %q", data)
+			}
+		})
+	}
+}
+
+func TestValidateProvisionOrgKey(t *testing.T) {
+	p := &HexPlugin{}
+
+	resp, err := p.Validate(context.Background(), map[string]any{"provision_org_key": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Valid {
+		t.Error("expected provision_org_key without organization to be rejected")
+	}
+
+	resp, _ = p.Validate(context.Background(), map[string]any{"provision_org_key": true, "organization": "acme"})
+	if !resp.Valid {
+		t.Errorf("unexpected errors: %+v", resp.Errors)
+	}
+}
+
+func TestDryRunProvisionedKeyName(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	p := &HexPlugin{executor: &MockCommandExecutor{}, clock: func() time.Time { return now }}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "admin-key", "organization": "acme", "provision_org_key": true},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resp.Outputs["provisioned_key"]; got != provisionedKeyName(now) {
+		t.Errorf("provisioned_key = %v, want %s", got, provisionedKeyName(now))
+	}
+}
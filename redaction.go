@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// RedactionAudit reports where the redaction audit looked for secrets and
+// where it found them. Leaks never include the secret itself.
+type RedactionAudit struct {
+	Secrets int      `json:"secrets"`
+	Checked []string `json:"checked"`
+	Leaks   []string `json:"leaks"`
+}
+
+// leaks reports whether data contains a known secret, as is or JSON-escaped.
+func (m *secretMasker) leaks(data []byte) bool {
+	if m == nil {
+		return false
+	}
+	for _, v := range m.values {
+		if bytes.Contains(data, []byte(v)) {
+			return true
+		}
+		if escaped, err := json.Marshal(v); err == nil && bytes.Contains(data, escaped[1:len(escaped)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeForAudit renders a value the way a host serializes the response,
+// without the HTML escaping that would hide secrets containing <, > or &.
+func encodeForAudit(v any) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return []byte(fmt.Sprint(v))
+	}
+	return buf.Bytes()
+}
+
+// auditResponse checks the message, error, outputs and artifacts of the
+// final response. Leaking values are masked, or replaced outright when they
+// are not plain text, so the failed response is safe to log.
+func (a *RedactionAudit) auditResponse(m *secretMasker, resp *plugin.ExecuteResponse) {
+	a.Checked = append(a.Checked, "message", "error")
+	if m.leaks([]byte(resp.Message)) {
+		a.Leaks = append(a.Leaks, "message")
+		resp.Message = m.mask(resp.Message)
+	}
+	if m.leaks([]byte(resp.Error)) {
+		a.Leaks = append(a.Leaks, "error")
+		resp.Error = m.mask(resp.Error)
+	}
+
+	keys := make([]string, 0, len(resp.Outputs))
+	for key := range resp.Outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		a.Checked = append(a.Checked, "outputs."+key)
+		val := resp.Outputs[key]
+		if !m.leaks(encodeForAudit(val)) {
+			continue
+		}
+		a.Leaks = append(a.Leaks, "outputs."+key)
+		if s, ok := val.(string); ok {
+			resp.Outputs[key] = m.mask(s)
+		} else {
+			resp.Outputs[key] = maskReplacement
+		}
+	}
+
+	a.Checked = append(a.Checked, "artifacts")
+	for i, artifact := range resp.Artifacts {
+		if m.leaks(encodeForAudit(artifact)) {
+			a.Leaks = append(a.Leaks, fmt.Sprintf("artifacts[%d]", i))
+			resp.Artifacts[i].Name = m.mask(artifact.Name)
+			resp.Artifacts[i].Path = m.mask(artifact.Path)
+		}
+	}
+}
+
+// auditedContent is content the run is about to write, named after where
+// it goes.
+type auditedContent struct {
+	Name string
+	Data []byte
+}
+
+// auditContents checks content before it is written. Leaking content is
+// written masked, so only the leak is recorded here.
+func (a *RedactionAudit) auditContents(m *secretMasker, contents ...auditedContent) {
+	for _, c := range contents {
+		a.Checked = append(a.Checked, c.Name)
+		if m.leaks(c.Data) {
+			a.Leaks = append(a.Leaks, c.Name)
+		}
+	}
+}
+
+// auditRedaction checks that no known secret survived in the final response
+// or in the summary, step summary and diagnostics the run is about to
+// write. A leak fails the run: the values found are scrubbed, but redaction
+// missed them and must be fixed. It reports whether it failed the run.
+func auditRedaction(m *secretMasker, resp *plugin.ExecuteResponse, contents ...auditedContent) bool {
+	audit := &RedactionAudit{}
+	if m != nil {
+		audit.Secrets = len(m.values)
+	}
+	audit.auditResponse(m, resp)
+	audit.auditContents(m, contents...)
+
+	resp.Outputs["redaction_audit"] = audit
+	if len(audit.Leaks) == 0 {
+		return false
+	}
+	resp.Success = false
+	resp.Error = strings.TrimSpace(fmt.Sprintf("redaction audit found secrets that masking missed in %s; they were scrubbed, but the run fails so the gap gets fixed\n%s",
+		strings.Join(audit.Leaks, ", "), resp.Error))
+	return true
+}
+
+// maskJSON scrubs known secrets from encoded JSON, where they may appear
+// escaped.
+func (m *secretMasker) maskJSON(data []byte) []byte {
+	if m == nil {
+		return data
+	}
+	for _, v := range m.values {
+		data = bytes.ReplaceAll(data, []byte(v), []byte(maskReplacement))
+		if escaped, err := json.Marshal(v); err == nil {
+			data = bytes.ReplaceAll(data, escaped[1:len(escaped)-1], []byte(maskReplacement))
+		}
+	}
+	return data
+}
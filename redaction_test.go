@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// assertRedacted fails the test when secret appears anywhere in the
+// serialized response.
+func assertRedacted(t *testing.T, resp *plugin.ExecuteResponse, secret string) {
+	t.Helper()
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Errorf("response leaks the secret: %s", data)
+	}
+}
+
+func TestExecuteRedactionAudit(t *testing.T) {
+	const secret = "hex-org-key-4f9a"
+
+	tests := []struct {
+		name        string
+		config      map[string]any
+		leak        func(summary *RunSummary, resp *plugin.ExecuteResponse)
+		wantSuccess bool
+		wantLeaks   []string
+		wantAudit   bool
+	}{
+		{
+			name:        "clean run",
+			wantSuccess: true,
+			wantAudit:   true,
+		},
+		{
+			name: "nested output",
+			leak: func(summary *RunSummary, resp *plugin.ExecuteResponse) {
+				resp.Outputs["git_state"] = &GitState{Fields: []GitStateField{{Field: "branch", Git: "https://x:" + secret + "@example.com"}}}
+			},
+			wantLeaks: []string{"outputs.git_state"},
+			wantAudit: true,
+		},
+		{
+			name: "JSON-escaped secret",
+			config: map[string]any{
+				"mask_values": []any{`tok"en\value`},
+			},
+			leak: func(summary *RunSummary, resp *plugin.ExecuteResponse) {
+				resp.Outputs["onboarding"] = &OnboardingReport{Report: `tok"en\value`}
+			},
+			wantLeaks: []string{"outputs.onboarding"},
+			wantAudit: true,
+		},
+		{
+			name: "summary file",
+			leak: func(summary *RunSummary, resp *plugin.ExecuteResponse) {
+				summary.Message = "authenticated with " + secret
+			},
+			wantLeaks: []string{"summary.json"},
+			wantAudit: true,
+		},
+		{
+			name: "summary markdown",
+			leak: func(summary *RunSummary, resp *plugin.ExecuteResponse) {
+				summary.Package = secret
+			},
+			wantLeaks: []string{"outputs.summary_markdown", "summary.json", "step_summary"},
+			wantAudit: true,
+		},
+		{
+			name:   "disabled",
+			config: map[string]any{"redaction_audit": false},
+			leak: func(summary *RunSummary, resp *plugin.ExecuteResponse) {
+				resp.Outputs["leak"] = []any{secret}
+			},
+			wantSuccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			writeFile(t, "mix.exs", `def project, do: [app: :my_lib, version: "1.0.0"]`)
+			t.Setenv("HEX_ORG_KEY", secret)
+			t.Setenv(githubStepSummaryEnv, "step.md")
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+			if tt.leak != nil {
+				p.beforeRedactionAudit = tt.leak
+			}
+
+			config := map[string]any{
+				"organization": "acme",
+				"org_keys":     map[string]any{"acme": "HEX_ORG_KEY"},
+				"summary_path": "summary.json",
+			}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+
+			audit, ok := resp.Outputs["redaction_audit"].(*RedactionAudit)
+			if ok != tt.wantAudit {
+				t.Fatalf("redaction_audit = %v, want present %v", resp.Outputs["redaction_audit"], tt.wantAudit)
+			}
+			if !ok {
+				return
+			}
+			if strings.Join(audit.Leaks, ",") != strings.Join(tt.wantLeaks, ",") {
+				t.Errorf("leaks = %v, want %v", audit.Leaks, tt.wantLeaks)
+			}
+			if !contains(audit.Checked, "summary.json") || !contains(audit.Checked, "step_summary") || !contains(audit.Checked, "outputs.argv") {
+				t.Errorf("checked = %v", audit.Checked)
+			}
+			if len(tt.wantLeaks) > 0 && !strings.Contains(resp.Error, "redaction audit found secrets that masking missed in "+tt.wantLeaks[0]) {
+				t.Errorf("unexpected error: %s", resp.Error)
+			}
+
+			assertRedacted(t, resp, secret)
+			for _, path := range []string{"summary.json", "step.md"} {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.Contains(string(data), secret) {
+					t.Errorf("%s leaks the secret: %s", path, data)
+				}
+			}
+			var written RunSummary
+			data, err := os.ReadFile("summary.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, &written); err != nil {
+				t.Fatal(err)
+			}
+			if written.Success != resp.Success {
+				t.Errorf("summary file records success=%v, response has %v", written.Success, resp.Success)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultRegistryStatusURL is the status page API of hex.pm.
+const defaultRegistryStatusURL = "https://status.hex.pm/api/v2/status.json"
+
+// Actions taken while the registry reports an incident, selected by
+// registry_incident_action.
+const (
+	registryIncidentFail = "fail"
+	registryIncidentWait = "wait"
+)
+
+const (
+	// registryProbeTimeout bounds each health request.
+	registryProbeTimeout = 30 * time.Second
+	// maxRegistryStatusResponse limits how much of a status document is read.
+	maxRegistryStatusResponse = 64 * 1024
+	// defaultRegistryIncidentMaxWait bounds registry_incident_action wait.
+	defaultRegistryIncidentMaxWait = 30 * time.Minute
+	// maxRegistryProbeDelay caps the backoff between probes.
+	maxRegistryProbeDelay = 5 * time.Minute
+)
+
+// RegistryHealth is the state of the registry before publishing.
+type RegistryHealth struct {
+	// Indicator is the status page indicator: none, minor, major or critical.
+	Indicator   string `json:"indicator,omitempty"`
+	Description string `json:"description,omitempty"`
+	// APIStatus is the HTTP status the Hex API answered with.
+	APIStatus int    `json:"api_status,omitempty"`
+	Incident  bool   `json:"incident"`
+	Reason    string `json:"reason,omitempty"`
+	// Probes counts the probes made, more than one when waiting out an incident.
+	Probes int `json:"probes"`
+}
+
+// validateRegistryHealthConfig checks registry_status_url and
+// registry_incident_action.
+func validateRegistryHealthConfig(statusURL, action string) *fieldError {
+	if statusURL != "" {
+		if err := validateHTTPURL(statusURL); err != nil {
+			return &fieldError{Field: "registry_status_url", Err: err}
+		}
+	}
+	switch action {
+	case "", registryIncidentFail, registryIncidentWait:
+		return nil
+	default:
+		return &fieldError{Field: "registry_incident_action", Err: fmt.Errorf("must be %s or %s", registryIncidentFail, registryIncidentWait)}
+	}
+}
+
+// registryStatusPage is the part of a status page API response that is read.
+type registryStatusPage struct {
+	Status struct {
+		Indicator   string `json:"indicator"`
+		Description string `json:"description"`
+	} `json:"status"`
+}
+
+// probeGet sends a GET request bounded by registryProbeTimeout and returns
+// the status code and the start of the body.
+func (p *HexPlugin) probeGet(ctx context.Context, url string) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, registryProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create health request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.getHTTPClient().Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRegistryStatusResponse))
+	return resp.StatusCode, data, err
+}
+
+// probeRegistry reads the registry status page and checks that the Hex API
+// answers. A major or critical status page indicator, or a 5xx from the API,
+// is an incident. Errors mean the health could not be determined.
+func (p *HexPlugin) probeRegistry(ctx context.Context, cfg *Config) (RegistryHealth, error) {
+	var health RegistryHealth
+
+	statusURL := cfg.RegistryStatusURL
+	if statusURL == "" {
+		statusURL = defaultRegistryStatusURL
+	}
+	code, data, err := p.probeGet(ctx, statusURL)
+	if err != nil {
+		return health, fmt.Errorf("registry status check failed: %w", err)
+	}
+	if code < 200 || code >= 300 {
+		return health, fmt.Errorf("registry status check failed: %s returned %d", statusURL, code)
+	}
+	var page registryStatusPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		return health, fmt.Errorf("failed to parse registry status from %s: %w", statusURL, err)
+	}
+	health.Indicator = page.Status.Indicator
+	health.Description = page.Status.Description
+	if health.Indicator == "major" || health.Indicator == "critical" {
+		health.Incident = true
+		health.Reason = fmt.Sprintf("status page reports a %s incident", health.Indicator)
+		if health.Description != "" {
+			health.Reason += ": " + health.Description
+		}
+		return health, nil
+	}
+
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultHexAPIURL
+	}
+	code, _, err = p.probeGet(ctx, strings.TrimSuffix(apiURL, "/")+"/")
+	if err != nil {
+		return health, fmt.Errorf("hex api health check failed: %w", err)
+	}
+	health.APIStatus = code
+	if code >= 500 {
+		health.Incident = true
+		health.Reason = fmt.Sprintf("hex api returned %d", code)
+	}
+	return health, nil
+}
+
+// checkRegistryHealth probes the registry until it reports no incident.
+// registry_incident_action wait probes again with backoff for up to
+// registry_incident_max_wait; fail probes again only as retries and
+// retry_on allow for the registry_incident class. It returns the last
+// health, the time waited and, when the incident persists, an error whose
+// class is registry_incident.
+func (p *HexPlugin) checkRegistryHealth(ctx context.Context, cfg *Config, summary *RunSummary) (RegistryHealth, time.Duration, error) {
+	delay := cfg.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	maxWait := cfg.RegistryIncidentMaxWait
+	if maxWait <= 0 {
+		maxWait = defaultRegistryIncidentMaxWait
+	}
+
+	var waited time.Duration
+	for probes := 1; ; probes++ {
+		health, err := p.probeRegistry(ctx, cfg)
+		health.Probes = probes
+		if err != nil || !health.Incident {
+			return health, waited, err
+		}
+		summary.debugf("registry incident (probe %d): %s", probes, health.Reason)
+
+		again := false
+		switch cfg.RegistryIncidentAction {
+		case registryIncidentWait:
+			again = waited+delay <= maxWait
+		default:
+			again = probes <= cfg.Retries && cfg.shouldRetry(errorClassIncident)
+		}
+		if !again {
+			return health, waited, fmt.Errorf("registry incident: %s; nothing was published", health.Reason)
+		}
+
+		select {
+		case <-ctx.Done():
+			return health, waited, fmt.Errorf("registry incident: cancelled while waiting for the registry to recover: %s", health.Reason)
+		case <-time.After(delay):
+		}
+		waited += delay
+		delay = min(delay*2, maxRegistryProbeDelay)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteRegistryHealth(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       map[string]any
+		dryRun       bool
+		indicators   []string // status page indicator per probe
+		apiStatus    int
+		statusCode   int
+		wantSuccess  bool
+		wantProbes   int
+		wantIncident bool
+		wantWait     bool
+		wantProbeErr bool
+		wantError    string
+		wantPublish  bool
+	}{
+		{
+			name:        "healthy registry",
+			indicators:  []string{"none"},
+			wantSuccess: true,
+			wantProbes:  1,
+			wantPublish: true,
+		},
+		{
+			name:        "minor degradation is not an incident",
+			indicators:  []string{"minor"},
+			wantSuccess: true,
+			wantProbes:  1,
+			wantPublish: true,
+		},
+		{
+			name:         "status page incident fails",
+			indicators:   []string{"major"},
+			wantProbes:   1,
+			wantIncident: true,
+			wantError:    "registry incident: status page reports a major incident: Partial System Outage",
+		},
+		{
+			name:         "api errors fail",
+			indicators:   []string{"none"},
+			apiStatus:    http.StatusServiceUnavailable,
+			wantProbes:   1,
+			wantIncident: true,
+			wantError:    "registry incident: hex api returned 503",
+		},
+		{
+			name:        "wait until the incident ends",
+			config:      map[string]any{"registry_incident_action": "wait"},
+			indicators:  []string{"critical", "major", "none"},
+			wantSuccess: true,
+			wantProbes:  3,
+			wantWait:    true,
+			wantPublish: true,
+		},
+		{
+			name:         "wait gives up after the max wait",
+			config:       map[string]any{"registry_incident_action": "wait", "registry_incident_max_wait": "2ms"},
+			indicators:   []string{"major", "major", "major"},
+			wantProbes:   2,
+			wantIncident: true,
+			wantWait:     true,
+			wantError:    "registry incident",
+		},
+		{
+			name:        "retry_on registry_incident probes again",
+			config:      map[string]any{"retries": 2, "retry_on": []any{"registry_incident"}},
+			indicators:  []string{"major", "none"},
+			wantSuccess: true,
+			wantProbes:  2,
+			wantWait:    true,
+			wantPublish: true,
+		},
+		{
+			name:         "retry_on without registry_incident fails at once",
+			config:       map[string]any{"retries": 2, "retry_on": []any{"network"}},
+			indicators:   []string{"major", "none"},
+			wantProbes:   1,
+			wantIncident: true,
+			wantError:    "registry incident",
+		},
+		{
+			name:         "unreachable status page does not block",
+			statusCode:   http.StatusBadGateway,
+			wantSuccess:  true,
+			wantProbeErr: true,
+			wantPublish:  true,
+		},
+		{
+			name:         "dry run reports the incident",
+			dryRun:       true,
+			indicators:   []string{"major"},
+			wantSuccess:  true,
+			wantProbes:   1,
+			wantIncident: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/status.json":
+					if tt.statusCode != 0 {
+						w.WriteHeader(tt.statusCode)
+						return
+					}
+					indicator := tt.indicators[min(probes, len(tt.indicators)-1)]
+					probes++
+					description := "All Systems Operational"
+					if indicator != "none" {
+						description = "Partial System Outage"
+					}
+					fmt.Fprintf(w, `{"status": {"indicator": %q, "description": %q}}`, indicator, description)
+				case "/api/":
+					if tt.apiStatus != 0 {
+						w.WriteHeader(tt.apiStatus)
+					}
+					_, _ = w.Write([]byte(`{}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			chdirTemp(t)
+			writeFile(t, "mix.exs", `def project, do: [app: :my_lib, version: "1.0.0"]`)
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{
+				"api_key":               "test-api-key",
+				"api_url":               server.URL + "/api",
+				"registry_health_check": true,
+				"registry_status_url":   server.URL + "/status.json",
+				"retry_delay":           "1ms",
+			}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				DryRun:  tt.dryRun,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.wantSuccess, resp.Success, resp.Error)
+			}
+			if tt.wantError != "" && !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, resp.Error)
+			}
+			if !tt.wantSuccess && resp.Outputs["error_class"] != errorClassIncident {
+				t.Errorf("error_class = %v, want %s", resp.Outputs["error_class"], errorClassIncident)
+			}
+			if _, ok := resp.Outputs["registry_health_error"]; ok != tt.wantProbeErr {
+				t.Errorf("registry_health_error = %v, want present %v", resp.Outputs["registry_health_error"], tt.wantProbeErr)
+			}
+			if _, ok := resp.Outputs["registry_health_wait"]; ok != tt.wantWait {
+				t.Errorf("registry_health_wait = %v, want present %v", resp.Outputs["registry_health_wait"], tt.wantWait)
+			}
+			if !tt.wantProbeErr {
+				health, ok := resp.Outputs["registry_health"].(RegistryHealth)
+				if !ok {
+					t.Fatalf("registry_health = %v", resp.Outputs["registry_health"])
+				}
+				if health.Probes != tt.wantProbes || health.Incident != tt.wantIncident {
+					t.Errorf("registry_health = %+v, want %d probes, incident %v", health, tt.wantProbes, tt.wantIncident)
+				}
+			}
+
+			published := false
+			for _, call := range mock.Calls {
+				if contains(call.Args, "hex.publish") {
+					published = true
+				}
+			}
+			if published != tt.wantPublish {
+				t.Errorf("published = %v, want %v", published, tt.wantPublish)
+			}
+		})
+	}
+}
+
+func TestValidateRegistryHealthConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		action    string
+		wantField string
+	}{
+		{name: "defaults"},
+		{name: "custom status page", url: "https://status.example.com/api/v2/status.json", action: "wait"},
+		{name: "invalid url", url: "status.example.com", wantField: "registry_status_url"},
+		{name: "unknown action", action: "skip", wantField: "registry_incident_action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRegistryHealthConfig(tt.url, tt.action)
+			switch {
+			case tt.wantField == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantField != "" && (err == nil || err.Field != tt.wantField):
+				t.Errorf("validateRegistryHealthConfig() = %v, want error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Rehearsal reports the local registry publish that preceded the real one.
+type Rehearsal struct {
+	Package  string   `json:"package,omitempty"`
+	Dir      string   `json:"dir,omitempty"`
+	Tarball  string   `json:"tarball,omitempty"`
+	Commands []string `json:"commands,omitempty"`
+}
+
+// validateRehearse rejects rehearse together with test_registry, which
+// already publishes locally and never reaches the real registry.
+func validateRehearse(rehearse, testRegistry bool) *fieldError {
+	if rehearse && testRegistry {
+		return &fieldError{Field: "rehearse", Err: fmt.Errorf("cannot be combined with test_registry")}
+	}
+	return nil
+}
+
+// rehearse publishes the package into a local registry and verifies it can
+// be served before the real publish runs. It returns nil when the rehearsal
+// passed and a failure otherwise, in which case nothing reached the real
+// registry. The registry lives in test_registry_dir when set, and in a temp
+// dir removed afterwards otherwise.
+func (p *HexPlugin) rehearse(ctx context.Context, cfg *Config, version string, outputs map[string]any, summary *RunSummary) *plugin.ExecuteResponse {
+	summary.debugf("rehearsing the publish against a local test registry")
+	reg, err := p.buildTestRegistry(ctx, cfg, version, summary)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("publish rehearsal failed, nothing was published: %v", err),
+			Outputs: outputs,
+		}
+	}
+
+	rehearsal := Rehearsal{Package: reg.Package, Dir: reg.Dir, Tarball: reg.TarballPath}
+	if cfg.TestRegistryDir == "" {
+		if err := os.RemoveAll(reg.Dir); err != nil {
+			summary.debugf("failed to remove rehearsal registry %s: %v", reg.Dir, err)
+		}
+		rehearsal.Dir, rehearsal.Tarball = "", ""
+	}
+	outputs["rehearsal"] = rehearsal
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteRehearse(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		dryRun      bool
+		incomplete  bool
+		wantSuccess bool
+		wantError   string
+		wantPublish bool
+		wantDir     bool
+	}{
+		{name: "rehearsal passes", config: map[string]any{}, wantSuccess: true, wantPublish: true},
+		{name: "registry kept in test_registry_dir", config: map[string]any{"test_registry_dir": "rehearsal"}, wantSuccess: true, wantPublish: true, wantDir: true},
+		{name: "rehearsal fails", config: map[string]any{}, incomplete: true, wantError: "publish rehearsal failed, nothing was published: test registry verification failed: GET /names returned 404"},
+		{name: "with test_registry", config: map[string]any{"test_registry": true}, wantError: "invalid rehearse: cannot be combined with test_registry"},
+		{name: "dry run", config: map[string]any{}, dryRun: true, wantSuccess: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			mock := fakeRegistryExecutor(t)
+			if tt.incomplete {
+				mock = &MockCommandExecutor{
+					RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+						if args[0] == "hex.build" {
+							writeFile(t, args[len(args)-1], "tarball")
+							return []byte("Building my_lib 1.0.0\n"), nil
+						}
+						return nil, nil
+					},
+				}
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "rehearse": true}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				DryRun:  tt.dryRun,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error %q)", resp.Success, tt.wantSuccess, resp.Error)
+			}
+			if tt.wantError != "" && !strings.HasPrefix(resp.Error, tt.wantError) {
+				t.Errorf("error = %q, want prefix %q", resp.Error, tt.wantError)
+			}
+
+			var tasks []string
+			for _, c := range mock.Calls {
+				tasks = append(tasks, c.Args[0])
+			}
+			published := slices.Index(tasks, "hex.publish")
+			if (published >= 0) != tt.wantPublish {
+				t.Fatalf("mix tasks = %v, want publish %v", tasks, tt.wantPublish)
+			}
+			if tt.wantPublish && slices.Index(tasks, "hex.registry") > published {
+				t.Errorf("rehearsal ran after the publish: %v", tasks)
+			}
+			if !tt.wantSuccess {
+				return
+			}
+
+			rehearsal := resp.Outputs["rehearsal"].(Rehearsal)
+			if tt.dryRun {
+				if len(rehearsal.Commands) != 2 {
+					t.Errorf("dry run rehearsal = %+v", rehearsal)
+				}
+				return
+			}
+			if rehearsal.Package != "my_lib" {
+				t.Errorf("rehearsal package = %q", rehearsal.Package)
+			}
+			if (rehearsal.Tarball != "") != tt.wantDir {
+				t.Errorf("rehearsal tarball = %q, want kept %v", rehearsal.Tarball, tt.wantDir)
+			}
+			if tt.wantDir {
+				if _, err := os.Stat(rehearsal.Tarball); err != nil {
+					t.Errorf("expected the rehearsal tarball: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// fixtureVersion is the current fixture file format version.
+const fixtureVersion = 1
+
+// Fixture is a recorded set of command invocations.
+type Fixture struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded command invocation.
+type Interaction struct {
+	Name           string   `json:"name"`
+	Args           []string `json:"args"`
+	Dir            string   `json:"dir,omitempty"`
+	EnvFingerprint string   `json:"env_fingerprint,omitempty"`
+	Output         string   `json:"output"`
+	Error          string   `json:"error,omitempty"`
+	ExitCode       int      `json:"exit_code"`
+}
+
+// envFingerprint hashes the extra environment of a command. Values are
+// included in the hash but never stored, so fixtures do not leak secrets.
+func envFingerprint(env []string) string {
+	if len(env) == 0 {
+		return ""
+	}
+	sorted := append([]string(nil), env...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ReplayedError is returned by ReplayExecutor for recorded failures.
+type ReplayedError struct {
+	Message string
+	Code    int
+}
+
+// Error implements error.
+func (e *ReplayedError) Error() string {
+	return e.Message
+}
+
+// ExitCode returns the recorded exit code.
+func (e *ReplayedError) ExitCode() int {
+	return e.Code
+}
+
+// RecordingExecutor runs commands through an inner executor and records every
+// invocation into a fixture file.
+type RecordingExecutor struct {
+	Inner CommandExecutor
+	Path  string
+
+	mu      sync.Mutex
+	fixture Fixture
+	masker  *secretMasker
+}
+
+// NewRecordingExecutor creates a recorder writing fixtures to path.
+func NewRecordingExecutor(inner CommandExecutor, path string) *RecordingExecutor {
+	return &RecordingExecutor{
+		Inner:   inner,
+		Path:    path,
+		fixture: Fixture{Version: fixtureVersion, Interactions: []Interaction{}},
+	}
+}
+
+// Run executes the command and appends it to the fixture.
+func (e *RecordingExecutor) Run(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+	output, runErr := e.Inner.Run(ctx, name, args, env, dir)
+
+	interaction := Interaction{
+		Name:           name,
+		Args:           append([]string{}, args...),
+		Dir:            dir,
+		EnvFingerprint: envFingerprint(env),
+		Output:         string(output),
+		ExitCode:       exitCodeOf(runErr),
+	}
+	if runErr != nil {
+		interaction.Error = runErr.Error()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.fixture.Interactions = append(e.fixture.Interactions, interaction)
+	e.mask()
+	e.write()
+
+	return output, runErr
+}
+
+// maskSecrets masks the fixture with the secrets of the run and rewrites
+// it, as secrets such as a provisioned key become known after the command
+// that printed them.
+func (e *RecordingExecutor) maskSecrets(m *secretMasker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.masker = m
+	if len(e.fixture.Interactions) > 0 {
+		e.mask()
+		e.write()
+	}
+}
+
+// mask scrubs the known secrets from the recorded interactions. The caller
+// holds e.mu.
+func (e *RecordingExecutor) mask() {
+	for i := range e.fixture.Interactions {
+		in := &e.fixture.Interactions[i]
+		in.Args = e.masker.maskAll(in.Args)
+		in.Output = e.masker.mask(in.Output)
+		in.Error = e.masker.mask(in.Error)
+	}
+}
+
+// write stores the fixture. The caller holds e.mu.
+func (e *RecordingExecutor) write() {
+	// A broken fixture must never change the outcome of the real command
+	if err := writeFixture(e.Path, e.fixture); err != nil {
+		fmt.Fprintf(os.Stderr, "hex: failed to record interaction: %v\n", err)
+	}
+}
+
+// ReplayExecutor serves recorded invocations instead of running commands.
+// Interactions are matched on name, args and dir and consumed in order, so
+// repeated commands (e.g. retries) replay their recorded sequence.
+type ReplayExecutor struct {
+	// StrictEnv also requires the environment fingerprint to match.
+	StrictEnv bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayExecutor loads a fixture file for replay.
+func NewReplayExecutor(path string) (*ReplayExecutor, error) {
+	fixture, err := readFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayExecutor{
+		interactions: fixture.Interactions,
+		used:         make([]bool, len(fixture.Interactions)),
+	}, nil
+}
+
+// Run returns the next recorded interaction matching the invocation.
+func (e *ReplayExecutor) Run(_ context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	fingerprint := envFingerprint(env)
+	for i, in := range e.interactions {
+		if e.used[i] || in.Name != name || in.Dir != dir || !reflect.DeepEqual(in.Args, args) {
+			continue
+		}
+		if e.StrictEnv && in.EnvFingerprint != fingerprint {
+			continue
+		}
+
+		e.used[i] = true
+		if in.Error != "" {
+			return []byte(in.Output), &ReplayedError{Message: in.Error, Code: in.ExitCode}
+		}
+		return []byte(in.Output), nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction for %s %s (dir %q)", name, strings.Join(args, " "), dir)
+}
+
+// Unused returns the recorded interactions that were never replayed.
+func (e *ReplayExecutor) Unused() []Interaction {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var unused []Interaction
+	for i, in := range e.interactions {
+		if !e.used[i] {
+			unused = append(unused, in)
+		}
+	}
+	return unused
+}
+
+// readFixture loads a fixture file.
+func readFixture(path string) (Fixture, error) {
+	var fixture Fixture
+	if err := readJSONFile(path, &fixture); err != nil {
+		return fixture, err
+	}
+	if fixture.Version != fixtureVersion {
+		return fixture, fmt.Errorf("unsupported fixture version %d in %s", fixture.Version, path)
+	}
+	return fixture, nil
+}
+
+// writeFixture stores a fixture file as indented JSON, readable only by its
+// owner as recorded output may be sensitive.
+func writeFixture(path string, fixture Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	inner := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			if args[0] == "test" {
+				return []byte("1 failure"), &ReplayedError{Message: "exit status 2", Code: 2}
+			}
+			return []byte("Building my_lib 1.0.0"), nil
+		},
+	}
+
+	recorder := NewRecordingExecutor(inner, path)
+	_, _ = recorder.Run(context.Background(), "mix", []string{"hex.publish", "--yes"}, []string{"HEX_API_KEY=secret"}, ".")
+	_, _ = recorder.Run(context.Background(), "mix", []string{"test"}, nil, ".")
+
+	fixture, err := readFixture(path)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	if len(fixture.Interactions) != 2 {
+		t.Fatalf("expected 2 interactions, got %d", len(fixture.Interactions))
+	}
+	if fixture.Interactions[0].EnvFingerprint == "" {
+		t.Error("expected env fingerprint to be recorded")
+	}
+	if fixture.Interactions[1].ExitCode != 2 {
+		t.Errorf("expected exit code 2, got %d", fixture.Interactions[1].ExitCode)
+	}
+
+	replay, err := NewReplayExecutor(path)
+	if err != nil {
+		t.Fatalf("load replay: %v", err)
+	}
+	replay.StrictEnv = true
+
+	output, err := replay.Run(context.Background(), "mix", []string{"hex.publish", "--yes"}, []string{"HEX_API_KEY=secret"}, ".")
+	if err != nil || string(output) != "Building my_lib 1.0.0" {
+		t.Errorf("unexpected replay: %q, %v", output, err)
+	}
+
+	_, err = replay.Run(context.Background(), "mix", []string{"test"}, nil, ".")
+	if exitCodeOf(err) != 2 {
+		t.Errorf("expected replayed exit code 2, got %d (%v)", exitCodeOf(err), err)
+	}
+
+	t.Run("interactions are consumed once", func(t *testing.T) {
+		_, err := replay.Run(context.Background(), "mix", []string{"test"}, nil, ".")
+		if err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+			t.Errorf("expected missing interaction error, got %v", err)
+		}
+		if len(replay.Unused()) != 0 {
+			t.Errorf("expected all interactions used, got %v", replay.Unused())
+		}
+	})
+
+	t.Run("strict env rejects different environment", func(t *testing.T) {
+		replay, _ := NewReplayExecutor(path)
+		replay.StrictEnv = true
+		_, err := replay.Run(context.Background(), "mix", []string{"hex.publish", "--yes"}, []string{"HEX_API_KEY=other"}, ".")
+		if err == nil {
+			t.Error("expected mismatch with a different environment")
+		}
+	})
+}
+
+func TestEnvFingerprintDoesNotLeakValues(t *testing.T) {
+	fp := envFingerprint([]string{"HEX_API_KEY=super-secret"})
+	if strings.Contains(fp, "super-secret") || fp == "" {
+		t.Errorf("unexpected fingerprint %q", fp)
+	}
+	if envFingerprint([]string{"B=2", "A=1"}) != envFingerprint([]string{"A=1", "B=2"}) {
+		t.Error("fingerprint should not depend on env order")
+	}
+}
+
+func TestExitCodeOf(t *testing.T) {
+	if exitCodeOf(nil) != 0 {
+		t.Error("nil error should have exit code 0")
+	}
+	if exitCodeOf(errors.New("boom")) != -1 {
+		t.Error("plain error should have unknown exit code")
+	}
+}
+
+// TestReplayFixtures exercises output parsing against recorded mix sessions.
+func TestReplayFixtures(t *testing.T) {
+	tests := []struct {
+		fixture       string
+		config        map[string]any
+		expectSuccess bool
+		expectOutputs map[string]any
+		expectError   string
+	}{
+		{
+			fixture:       "publish_success.json",
+			config:        map[string]any{"api_key": "test-key", "organization": "acme"},
+			expectSuccess: true,
+			expectOutputs: map[string]any{
+				"package":     "my_lib",
+				"package_url": "https://hex.pm/packages/acme/my_lib/1.4.0",
+			},
+		},
+		{
+			fixture:       "publish_conflict.json",
+			config:        map[string]any{"api_key": "test-key"},
+			expectSuccess: false,
+			expectError:   "must include the --replace flag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			replay, err := NewReplayExecutor(filepath.Join("testdata", "fixtures", tt.fixture))
+			if err != nil {
+				t.Fatalf("load fixture: %v", err)
+			}
+
+			p := &HexPlugin{executor: replay}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  tt.config,
+				Context: plugin.ReleaseContext{Version: "v1.4.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Success != tt.expectSuccess {
+				t.Fatalf("success: got %v, expected %v (%s)", resp.Success, tt.expectSuccess, resp.Error)
+			}
+			for key, want := range tt.expectOutputs {
+				if resp.Outputs[key] != want {
+					t.Errorf("output %q: got %v, expected %v", key, resp.Outputs[key], want)
+				}
+			}
+			if !strings.Contains(resp.Error, tt.expectError) {
+				t.Errorf("error: expected to contain %q, got %q", tt.expectError, resp.Error)
+			}
+			if unused := replay.Unused(); len(unused) != 0 {
+				t.Errorf("fixture has unused interactions: %+v", unused)
+			}
+		})
+	}
+}
+
+func TestRecordingMasksSecrets(t *testing.T) {
+	chdirTemp(t)
+	writeFile(t, mixExsFile, `def project, do: [app: :my_lib, version: "1.0.0"]`)
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	inner := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("Publishing with hex-api-key-123"), nil
+		},
+	}
+	recorder := NewRecordingExecutor(inner, path)
+	p := &HexPlugin{executor: recorder}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "hex-api-key-123"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+
+	if data, err := os.ReadFile(path); err != nil || strings.Contains(string(data), "hex-api-key-123") {
+		t.Errorf("fixture leaks the API key (%v):\n%s", err, data)
+	}
+
+	// A secret learned after the command ran is masked when the run ends
+	m := &secretMasker{}
+	m.add("hex-api-key-123")
+	recorder.maskSecrets(m)
+	_, _ = recorder.Run(context.Background(), "mix", []string{"hex.organization", "key", "acme", "generate"}, nil, ".")
+	m.add("Publishing")
+	recorder.maskSecrets(m)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Publishing") {
+		t.Errorf("fixture leaks a secret learned later:\n%s", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("fixture mode = %o, want 600", perm)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// sourceDateEpochEnv is the reproducible-builds timestamp variable.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// reproducibleBaseEnv pins locale and timezone, and compiles BEAM files
+// deterministically so they embed no absolute source paths.
+var reproducibleBaseEnv = []string{
+	"LC_ALL=C.UTF-8",
+	"LANG=C.UTF-8",
+	"TZ=UTC",
+	"ERL_COMPILER_OPTIONS=[deterministic]",
+}
+
+// sourceDateEpoch returns SOURCE_DATE_EPOCH from the environment, or the
+// commit timestamp of the release commit (HEAD when unknown).
+func (p *HexPlugin) sourceDateEpoch(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, summary *RunSummary) (int64, error) {
+	if val := os.Getenv(sourceDateEpochEnv); val != "" {
+		epoch, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %w", sourceDateEpochEnv, val, err)
+		}
+		return epoch, nil
+	}
+
+	rev := releaseCtx.CommitSHA
+	if rev == "" {
+		rev = "HEAD"
+	}
+	output, err := p.runCommand(ctx, summary, "git", []string{"log", "-1", "--format=%ct", rev}, nil, cfg.WorkDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the commit timestamp: %v\nOutput: %s", err, string(output))
+	}
+	epoch, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected commit timestamp %q", strings.TrimSpace(string(output)))
+	}
+	return epoch, nil
+}
+
+// reproducibleEnv returns the environment for a reproducible build.
+func reproducibleEnv(epoch int64) []string {
+	return append([]string{fmt.Sprintf("%s=%d", sourceDateEpochEnv, epoch)}, reproducibleBaseEnv...)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteReproducible(t *testing.T) {
+	tests := []struct {
+		name          string
+		envEpoch      string
+		expectedEpoch int64
+		expectedCalls int
+	}{
+		{name: "epoch from commit timestamp", expectedEpoch: 1714557600, expectedCalls: 2},
+		{name: "epoch from environment", envEpoch: "1700000000", expectedEpoch: 1700000000, expectedCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(sourceDateEpochEnv, tt.envEpoch)
+
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if name == "git" {
+						return []byte("1714557600\n"), nil
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "reproducible": true},
+				Context: plugin.ReleaseContext{Version: "1.0.0", CommitSHA: "abc1234"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			if len(mock.Calls) != tt.expectedCalls {
+				t.Fatalf("expected %d calls, got %d", tt.expectedCalls, len(mock.Calls))
+			}
+			if tt.expectedCalls == 2 && mock.Calls[0].Args[len(mock.Calls[0].Args)-1] != "abc1234" {
+				t.Errorf("expected timestamp of the release commit, got %v", mock.Calls[0].Args)
+			}
+			if resp.Outputs["source_date_epoch"] != tt.expectedEpoch {
+				t.Errorf("source_date_epoch = %v, want %d", resp.Outputs["source_date_epoch"], tt.expectedEpoch)
+			}
+
+			publish := mock.Calls[len(mock.Calls)-1]
+			for _, want := range reproducibleEnv(tt.expectedEpoch) {
+				if !contains(publish.Env, want) {
+					t.Errorf("expected %s in publish env %v", want, publish.Env)
+				}
+			}
+		})
+	}
+}
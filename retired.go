@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Sources of a retired dependency finding.
+const (
+	retiredSourceAudit   = "audit"
+	retiredSourcePublish = "publish"
+)
+
+// RetiredDependency is a locked dependency whose version was retired by its
+// maintainers.
+type RetiredDependency struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+	Source  string `json:"source"`
+}
+
+var (
+	// auditRowPattern matches a mix hex.audit table row:
+	// "plug   1.7.0   (security) Message".
+	auditRowPattern = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+\((\w+)\)\s*(.*)$`)
+	// retiredLinePattern matches "plug 1.7.0 RETIRED!" in dependency listings.
+	retiredLinePattern = regexp.MustCompile(`^\s*(\S+) (\S+) RETIRED!\s*$`)
+	// retirementPattern matches the "(reason) message" line that follows it.
+	retirementPattern = regexp.MustCompile(`^\s*\((\w+)\)\s*(.*)$`)
+)
+
+// parseAuditReport reads the retired dependencies listed by mix hex.audit.
+func parseAuditReport(output string) []RetiredDependency {
+	var retired []RetiredDependency
+	for _, line := range strings.Split(ansiPattern.ReplaceAllString(output, ""), "\n") {
+		m := auditRowPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		retired = append(retired, RetiredDependency{Package: m[1], Version: m[2], Reason: m[3], Message: strings.TrimSpace(m[4]), Source: retiredSourceAudit})
+	}
+	return retired
+}
+
+// parseRetiredWarnings reads the "RETIRED!" markers Hex prints while
+// resolving dependencies, with the reason on the following line.
+func parseRetiredWarnings(output string) []RetiredDependency {
+	lines := strings.Split(ansiPattern.ReplaceAllString(output, ""), "\n")
+	var retired []RetiredDependency
+	for i, line := range lines {
+		m := retiredLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		dep := RetiredDependency{Package: m[1], Version: m[2], Source: retiredSourcePublish}
+		if i+1 < len(lines) {
+			if r := retirementPattern.FindStringSubmatch(strings.TrimRight(lines[i+1], "\r")); r != nil {
+				dep.Reason, dep.Message = r[1], strings.TrimSpace(r[2])
+			}
+		}
+		retired = append(retired, dep)
+	}
+	return retired
+}
+
+// addRetired merges findings into the retired_dependencies output, keeping
+// the first finding of each package version.
+func addRetired(outputs map[string]any, found []RetiredDependency) {
+	if len(found) == 0 {
+		return
+	}
+	report, _ := outputs["retired_dependencies"].([]RetiredDependency)
+	for _, dep := range found {
+		if !slices.ContainsFunc(report, func(r RetiredDependency) bool { return r.Package == dep.Package && r.Version == dep.Version }) {
+			report = append(report, dep)
+		}
+	}
+	outputs["retired_dependencies"] = report
+}
+
+// addRetiredFromGates reports the retired dependencies found by hex.audit
+// gates.
+func addRetiredFromGates(outputs map[string]any, results []GateResult) {
+	for _, result := range results {
+		if strings.Contains(result.Command, "hex.audit") {
+			addRetired(outputs, parseAuditReport(result.Output))
+		}
+	}
+}
+
+// addRetiredReport runs mix hex.audit for retired_report. The audit fails
+// when it finds retired dependencies, so its exit status is ignored; the
+// report never blocks the publish.
+func (p *HexPlugin) addRetiredReport(ctx context.Context, cfg *Config, outputs map[string]any, summary *RunSummary) {
+	output, err := p.runMix(ctx, cfg, summary, []string{"hex.audit"}, nil)
+	found := parseAuditReport(string(output))
+	if err != nil && len(found) == 0 {
+		outputs["retired_dependencies_error"] = "mix hex.audit failed: " + err.Error()
+		return
+	}
+	addRetired(outputs, found)
+	if _, ok := outputs["retired_dependencies"]; !ok {
+		outputs["retired_dependencies"] = []RetiredDependency{}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const testAuditOutput = `Dependency  Version  Retirement reason
+plug        1.7.0    (security) Upgrade to 1.7.1
+cowboy      2.6.0    (deprecated)
+Found retired packages
+`
+
+const testRetiredPublishOutput = "Resolving Hex dependencies...\n" +
+	"Dependency resolution completed:\n" +
+	"  \x1b[33mplug 1.7.0 RETIRED!\x1b[0m\n" +
+	"    (security) Upgrade to 1.7.1\n" +
+	"  jason 1.4.0 RETIRED!\n" +
+	"Building my_lib 1.0.0\n"
+
+func TestParseAuditReport(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []RetiredDependency
+	}{
+		{name: "no retired packages", output: "No retired dependencies\n"},
+		{
+			name:   "retired packages",
+			output: testAuditOutput,
+			want: []RetiredDependency{
+				{Package: "plug", Version: "1.7.0", Reason: "security", Message: "Upgrade to 1.7.1", Source: retiredSourceAudit},
+				{Package: "cowboy", Version: "2.6.0", Reason: "deprecated", Source: retiredSourceAudit},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAuditReport(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAuditReport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetiredWarnings(t *testing.T) {
+	want := []RetiredDependency{
+		{Package: "plug", Version: "1.7.0", Reason: "security", Message: "Upgrade to 1.7.1", Source: retiredSourcePublish},
+		{Package: "jason", Version: "1.4.0", Source: retiredSourcePublish},
+	}
+	if got := parseRetiredWarnings(testRetiredPublishOutput); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRetiredWarnings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExecuteRetiredDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]any
+		auditErr  error
+		want      []RetiredDependency
+		wantError string
+	}{
+		{
+			name:   "audit gate and publish output",
+			config: map[string]any{"gates": []any{map[string]any{"name": "audit", "allow_failure": true}}},
+			want: []RetiredDependency{
+				{Package: "plug", Version: "1.7.0", Reason: "security", Message: "Upgrade to 1.7.1", Source: retiredSourceAudit},
+				{Package: "cowboy", Version: "2.6.0", Reason: "deprecated", Source: retiredSourceAudit},
+				{Package: "jason", Version: "1.4.0", Source: retiredSourcePublish},
+			},
+		},
+		{
+			name:     "retired report does not block",
+			config:   map[string]any{"retired_report": true},
+			auditErr: errors.New("exit status 1"),
+			want: []RetiredDependency{
+				{Package: "plug", Version: "1.7.0", Reason: "security", Message: "Upgrade to 1.7.1", Source: retiredSourceAudit},
+				{Package: "cowboy", Version: "2.6.0", Reason: "deprecated", Source: retiredSourceAudit},
+				{Package: "jason", Version: "1.4.0", Source: retiredSourcePublish},
+			},
+		},
+		{
+			name:      "retired report failure",
+			config:    map[string]any{"retired_report": true},
+			auditErr:  errors.New("exit status 2"),
+			wantError: "mix hex.audit failed: exit status 2",
+			want: []RetiredDependency{
+				{Package: "plug", Version: "1.7.0", Reason: "security", Message: "Upgrade to 1.7.1", Source: retiredSourcePublish},
+				{Package: "jason", Version: "1.4.0", Source: retiredSourcePublish},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if args[0] == "hex.audit" {
+						if tt.wantError != "" {
+							return []byte("could not load mix.lock"), tt.auditErr
+						}
+						return []byte(testAuditOutput), tt.auditErr
+					}
+					return []byte(testRetiredPublishOutput), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			if got, _ := resp.Outputs["retired_dependencies"].([]RetiredDependency); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("retired_dependencies = %+v, want %+v", got, tt.want)
+			}
+			if got, _ := resp.Outputs["retired_dependencies_error"].(string); got != tt.wantError {
+				t.Errorf("retired_dependencies_error = %q, want %q", got, tt.wantError)
+			}
+		})
+	}
+}
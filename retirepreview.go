@@ -0,0 +1,58 @@
+package main
+
+// RetirePreview is a retirement the run would make if its trigger happened,
+// listed by dry runs so operators can review destructive registry changes
+// before they are automated.
+type RetirePreview struct {
+	Package      string `json:"package"`
+	Version      string `json:"version"`
+	Organization string `json:"organization,omitempty"`
+	Reason       string `json:"reason"`
+	Message      string `json:"message"`
+	// Trigger is the failure that makes the run retire the release.
+	Trigger string `json:"trigger"`
+	// Command is the mix command the run would execute.
+	Command string `json:"command"`
+}
+
+// newRetirePreview describes retiring a release as invalid. An unknown
+// package name is shown as a placeholder.
+func newRetirePreview(cfg *Config, name, version, message, trigger string) RetirePreview {
+	if name == "" {
+		name = "<package>"
+	}
+	return RetirePreview{
+		Package:      name,
+		Version:      version,
+		Organization: cfg.Organization,
+		Reason:       retireReasonInvalid,
+		Message:      message,
+		Trigger:      trigger,
+		Command:      cfg.mixDisplay(invalidRetireArgs(cfg.Organization, name, version, message)...),
+	}
+}
+
+// canaryRetirePreview lists the retirement of a canary release that fails
+// validation. The message quotes the validation error, unknown until then.
+func canaryRetirePreview(cfg *Config, version string) []RetirePreview {
+	projectType, _ := cfg.resolveProjectType()
+	name, _ := declaredPackageName(cfg.WorkDir, projectType)
+	return []RetirePreview{newRetirePreview(cfg, name, version, canaryRetireMessage("<validation error>"), "canary validation fails")}
+}
+
+// atomicRetirePreview lists, for each package of an atomic release, the
+// retirements its failure would cause: the package itself when it fails
+// after its upload, then the packages published before it, newest first,
+// as rollback retires them.
+func atomicRetirePreview(packages []publishedPackage, labels []string) []RetirePreview {
+	var previews []RetirePreview
+	for k, label := range labels {
+		failed := packages[k]
+		previews = append(previews, newRetirePreview(failed.cfg, failed.name, failed.version, rollbackMessage(label), "package "+label+" fails after its upload"))
+		for j := k - 1; j >= 0; j-- {
+			pkg := packages[j]
+			previews = append(previews, newRetirePreview(pkg.cfg, pkg.name, pkg.version, rollbackMessage(label), "package "+label+" fails"))
+		}
+	}
+	return previews
+}
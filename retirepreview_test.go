@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteRetirePreview(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   []RetirePreview
+	}{
+		{
+			name:   "canary",
+			config: map[string]any{"canary": true, "work_dir": "apps/core"},
+			want: []RetirePreview{{
+				Package: "my_core", Version: "1.0.0", Reason: "invalid",
+				Message: "canary validation failed: <validation error>",
+				Trigger: "canary validation fails",
+				Command: `mix hex.retire my_core 1.0.0 invalid --message canary validation failed: <validation error>`,
+			}},
+		},
+		{
+			name:   "canary with a declared package name",
+			config: map[string]any{"canary": true, "work_dir": "apps/renamed"},
+			want: []RetirePreview{{
+				Package: "my_lib", Version: "1.0.0", Reason: "invalid",
+				Message: "canary validation failed: <validation error>",
+				Trigger: "canary validation fails",
+				Command: `mix hex.retire my_lib 1.0.0 invalid --message canary validation failed: <validation error>`,
+			}},
+		},
+		{
+			name: "atomic",
+			config: map[string]any{"atomic": true, "packages": []any{
+				map[string]any{"name": "core", "work_dir": "apps/core"},
+				map[string]any{"name": "web", "work_dir": "apps/web", "organization": "acme"},
+			}},
+			want: []RetirePreview{
+				{
+					Package: "my_core", Version: "1.0.0", Reason: "invalid",
+					Message: "rolled back: core failed to publish in the same release",
+					Trigger: "package core fails after its upload",
+					Command: `mix hex.retire my_core 1.0.0 invalid --message rolled back: core failed to publish in the same release`,
+				},
+				{
+					Package: "my_web", Version: "1.0.0", Organization: "acme", Reason: "invalid",
+					Message: "rolled back: web failed to publish in the same release",
+					Trigger: "package web fails after its upload",
+					Command: `mix hex.retire my_web 1.0.0 invalid --message rolled back: web failed to publish in the same release --organization acme`,
+				},
+				{
+					Package: "my_core", Version: "1.0.0", Reason: "invalid",
+					Message: "rolled back: web failed to publish in the same release",
+					Trigger: "package web fails",
+					Command: `mix hex.retire my_core 1.0.0 invalid --message rolled back: web failed to publish in the same release`,
+				},
+			},
+		},
+		{
+			name:   "nothing to retire",
+			config: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			for _, app := range []string{"core", "web"} {
+				if err := os.MkdirAll(filepath.Join("apps", app), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, filepath.Join("apps", app, "mix.exs"), `def project, do: [app: :my_`+app+`, version: "1.0.0"]`)
+			}
+			if err := os.MkdirAll(filepath.Join("apps", "renamed"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, filepath.Join("apps", "renamed", "mix.exs"), `def project, do: [app: :my_app, version: "1.0.0", package: [name: "my_lib"]]`)
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				DryRun:  true,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			got, _ := resp.Outputs["retire_preview"].([]RetirePreview)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("retire_preview = %+v, want %+v", got, tt.want)
+			}
+			for _, call := range mock.Calls {
+				if contains(call.Args, "hex.retire") {
+					t.Errorf("dry run retired a release: %+v", call)
+				}
+			}
+		})
+	}
+}
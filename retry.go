@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultRetryDelay is the wait before the first retry; it doubles per attempt.
+const defaultRetryDelay = 5 * time.Second
+
+// validateRetryOn checks that retry_on only selects retryable error classes.
+func validateRetryOn(classes []string) error {
+	for _, class := range classes {
+		if !slices.Contains(retryableClasses, class) {
+			return fmt.Errorf("cannot retry on %q: must be one of %s", class, strings.Join(retryableClasses, ", "))
+		}
+	}
+	return nil
+}
+
+// shouldRetry reports whether a failure of the given class may be retried.
+func (c *Config) shouldRetry(class string) bool {
+	on := c.RetryOn
+	if len(on) == 0 {
+		on = retryableClasses
+	}
+	return slices.Contains(on, class)
+}
+
+// runWithRetry runs fn, retrying failures whose error class is selected by
+// retry_on. It returns the last output, the attempt count, the error class of
+// the last failure and its error.
+func (p *HexPlugin) runWithRetry(ctx context.Context, cfg *Config, fn func() ([]byte, error)) ([]byte, int, string, error) {
+	delay := cfg.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		output, err := fn()
+		class := classifyError(ctx, output, err)
+		if err == nil || attempt > cfg.Retries || !cfg.shouldRetry(class) || ctx.Err() != nil || errors.Is(err, errAttemptBudgetExhausted) {
+			return output, attempt, class, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return output, attempt, class, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
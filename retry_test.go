@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateRetryOn(t *testing.T) {
+	if err := validateRetryOn([]string{"network", "5xx", "timeout"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, class := range []string{"auth", "validation", "unknown"} {
+		if err := validateRetryOn([]string{class}); err == nil {
+			t.Errorf("expected %q to be rejected", class)
+		}
+	}
+}
+
+func TestPublishRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        map[string]any
+		outputs       []string
+		expectSuccess bool
+		expectCalls   int
+		expectClass   string
+	}{
+		{
+			name:          "network failure is retried",
+			config:        map[string]any{"retries": 2},
+			outputs:       []string{"econnrefused", "Building my_lib 1.0.0"},
+			expectSuccess: true,
+			expectCalls:   2,
+		},
+		{
+			name:          "retries are exhausted",
+			config:        map[string]any{"retries": 1},
+			outputs:       []string{"(status 503)", "(status 503)", "Building my_lib 1.0.0"},
+			expectSuccess: false,
+			expectCalls:   2,
+			expectClass:   errorClass5xx,
+		},
+		{
+			name:          "auth failure is never retried",
+			config:        map[string]any{"retries": 3},
+			outputs:       []string{"Invalid API key", "Building my_lib 1.0.0"},
+			expectSuccess: false,
+			expectCalls:   1,
+			expectClass:   errorClassAuth,
+		},
+		{
+			name:          "classes outside retry_on are not retried",
+			config:        map[string]any{"retries": 3, "retry_on": []any{"network"}},
+			outputs:       []string{"(status 500)", "Building my_lib 1.0.0"},
+			expectSuccess: false,
+			expectCalls:   1,
+			expectClass:   errorClass5xx,
+		},
+		{
+			name:          "no retries by default",
+			config:        map[string]any{},
+			outputs:       []string{"econnrefused", "Building my_lib 1.0.0"},
+			expectSuccess: false,
+			expectCalls:   1,
+			expectClass:   errorClassNetwork,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					out := tt.outputs[0]
+					tt.outputs = tt.outputs[1:]
+					if !strings.HasPrefix(out, "Building") {
+						return []byte(out), errors.New("exit status 1")
+					}
+					return []byte(out), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "k", "retry_delay": "1ms"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Success != tt.expectSuccess {
+				t.Errorf("success: got %v (%s)", resp.Success, resp.Error)
+			}
+			if len(mock.Calls) != tt.expectCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectCalls, len(mock.Calls))
+			}
+			if tt.expectClass != "" && resp.Outputs["error_class"] != tt.expectClass {
+				t.Errorf("error_class: got %v, expected %q", resp.Outputs["error_class"], tt.expectClass)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// operationRotateKey is the standalone operation that rotates the API key.
+const operationRotateKey = "rotate-key"
+
+// Rotation defaults.
+const (
+	defaultRotationKeyPrefix   = "relicta-hex"
+	defaultRotationGracePeriod = 24 * time.Hour
+)
+
+// Secret backends that receive a rotated key.
+const (
+	secretBackendFile    = "file"
+	secretBackendCommand = "command"
+)
+
+// newAPIKeyEnv passes the rotated key to command backends.
+const newAPIKeyEnv = "HEX_NEW_API_KEY"
+
+// RotationConfig configures API key rotation.
+type RotationConfig struct {
+	// KeyNamePrefix marks the keys managed by rotation; only they are revoked.
+	KeyNamePrefix string   `json:"key_name_prefix,omitempty"`
+	Permissions   []string `json:"permissions,omitempty"`
+	GracePeriod   any      `json:"grace_period,omitempty"`
+	Backend       struct {
+		Type    string   `json:"type"`
+		Path    string   `json:"path,omitempty"`
+		Command []string `json:"command,omitempty"`
+	} `json:"backend"`
+}
+
+// prefix returns the key name prefix, defaulting to relicta-hex.
+func (r *RotationConfig) prefix() string {
+	if r.KeyNamePrefix != "" {
+		return r.KeyNamePrefix
+	}
+	return defaultRotationKeyPrefix
+}
+
+// grace returns how long a replaced key stays valid before it is revoked.
+func (r *RotationConfig) grace() time.Duration {
+	if r.GracePeriod == nil {
+		return defaultRotationGracePeriod
+	}
+	d, err := parseDurationValue(r.GracePeriod)
+	if err != nil {
+		return defaultRotationGracePeriod
+	}
+	return d
+}
+
+// permissions converts "domain:resource" entries, defaulting to api:write.
+func (r *RotationConfig) permissions() []HexKeyPermission {
+	if len(r.Permissions) == 0 {
+		return []HexKeyPermission{{Domain: "api", Resource: "write"}}
+	}
+	perms := make([]HexKeyPermission, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		domain, resource, _ := strings.Cut(p, ":")
+		perms = append(perms, HexKeyPermission{Domain: domain, Resource: resource})
+	}
+	return perms
+}
+
+// parseRotation decodes the rotation object.
+func parseRotation(raw map[string]any) (*RotationConfig, *fieldError) {
+	val, ok := raw["rotation"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, &fieldError{Field: "rotation", Err: fmt.Errorf("must be an object")}
+	}
+	var rotation RotationConfig
+	if err := decodeStrict(obj, &rotation); err != nil {
+		return nil, &fieldError{Field: "rotation", Err: err}
+	}
+	return &rotation, nil
+}
+
+// validateRotation checks the rotation backend, grace period and permissions.
+func validateRotation(raw map[string]any) []*fieldError {
+	rotation, ferr := parseRotation(raw)
+	if ferr != nil {
+		return []*fieldError{ferr}
+	}
+	if rotation == nil {
+		return nil
+	}
+
+	var errs []*fieldError
+	if d, err := parseDurationValue(rotation.GracePeriod); err != nil {
+		errs = append(errs, &fieldError{Field: "rotation.grace_period", Err: err})
+	} else if d < 0 {
+		errs = append(errs, &fieldError{Field: "rotation.grace_period", Err: fmt.Errorf("must not be negative")})
+	}
+
+	for i, p := range rotation.Permissions {
+		domain, _, _ := strings.Cut(p, ":")
+		if domain == "" {
+			errs = append(errs, &fieldError{Field: fmt.Sprintf("rotation.permissions[%d]", i), Err: fmt.Errorf("must be domain or domain:resource")})
+		}
+	}
+
+	switch rotation.Backend.Type {
+	case secretBackendFile:
+		if rotation.Backend.Path == "" {
+			errs = append(errs, &fieldError{Field: "rotation.backend.path", Err: fmt.Errorf("is required for the file backend")})
+		} else if !filepath.IsAbs(rotation.Backend.Path) {
+			// Absolute paths may keep the secret out of the repository
+			if err := validatePath(rotation.Backend.Path); err != nil {
+				errs = append(errs, &fieldError{Field: "rotation.backend.path", Err: err})
+			}
+		}
+	case secretBackendCommand:
+		if len(rotation.Backend.Command) == 0 {
+			errs = append(errs, &fieldError{Field: "rotation.backend.command", Err: fmt.Errorf("is required for the command backend")})
+		}
+	default:
+		errs = append(errs, &fieldError{Field: "rotation.backend.type", Err: fmt.Errorf("must be %s or %s", secretBackendFile, secretBackendCommand)})
+	}
+
+	return errs
+}
+
+// pendingRevocation is a replaced key still inside its grace period.
+type pendingRevocation struct {
+	Name        string    `json:"name"`
+	RevokeAfter time.Time `json:"revoke_after"`
+}
+
+// revocationPlan splits the replaced managed keys into those whose grace
+// period has elapsed and those still pending. A key's grace period starts
+// when its successor was created; the newest key is never revoked.
+func revocationPlan(keys []HexKey, grace time.Duration, now time.Time) (due []string, pending []pendingRevocation) {
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].InsertedAt.Before(keys[j].InsertedAt) })
+	for i := 0; i < len(keys)-1; i++ {
+		revokeAfter := keys[i+1].InsertedAt.Add(grace)
+		if !now.Before(revokeAfter) {
+			due = append(due, keys[i].Name)
+		} else {
+			pending = append(pending, pendingRevocation{Name: keys[i].Name, RevokeAfter: revokeAfter})
+		}
+	}
+	return due, pending
+}
+
+// managedKeys returns the unrevoked keys created by rotation.
+func managedKeys(keys []HexKey, prefix string) []HexKey {
+	var managed []HexKey
+	for _, k := range keys {
+		if k.RevokedAt == nil && strings.HasPrefix(k.Name, prefix+"-") {
+			managed = append(managed, k)
+		}
+	}
+	return managed
+}
+
+// storeRotatedKey hands the new key to the configured secret backend.
+func (p *HexPlugin) storeRotatedKey(ctx context.Context, cfg *Config, key *HexKey, summary *RunSummary) error {
+	backend := cfg.Rotation.Backend
+	switch backend.Type {
+	case secretBackendFile:
+		path := backend.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.WorkDir, path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("failed to create secret directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(key.Secret), 0o600); err != nil {
+			return fmt.Errorf("failed to write secret file: %w", err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, 0o600); err != nil {
+			return fmt.Errorf("failed to restrict secret file: %w", err)
+		}
+		return nil
+	case secretBackendCommand:
+		env := []string{newAPIKeyEnv + "=" + key.Secret, "HEX_NEW_API_KEY_NAME=" + key.Name}
+		output, err := p.runCommand(ctx, summary, backend.Command[0], backend.Command[1:], env, cfg.WorkDir)
+		if err != nil {
+			return fmt.Errorf("secret backend command failed: %v\nOutput: %s", err, string(output))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown secret backend %q", backend.Type)
+	}
+}
+
+// rotateKey generates a new API key, stores it in the secret backend and
+// revokes managed keys whose grace period has elapsed. Run it periodically:
+// each run both rotates and retires keys replaced by earlier runs.
+func (p *HexPlugin) rotateKey(ctx context.Context, cfg *Config, dryRun bool, summary *RunSummary) (*plugin.ExecuteResponse, error) {
+	if cfg.Rotation == nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   "rotation is not configured",
+		}, nil
+	}
+	if cfg.APIKey == "" {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   "HEX_API_KEY is required: set api_key in config or HEX_API_KEY environment variable",
+		}, nil
+	}
+
+	api := p.hexAPI(cfg)
+	keys, err := api.listKeys(ctx)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to list api keys: %v", err),
+		}, nil
+	}
+
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s", cfg.Rotation.prefix(), now.Format("20060102150405"))
+	managed := managedKeys(keys, cfg.Rotation.prefix())
+
+	outputs := map[string]any{
+		"new_key_name":   name,
+		"secret_backend": cfg.Rotation.Backend.Type,
+	}
+
+	if dryRun {
+		due, pending := revocationPlan(append(managed, HexKey{Name: name, InsertedAt: now}), cfg.Rotation.grace(), now)
+		outputs["would_revoke"] = due
+		outputs["pending"] = pending
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "Would rotate Hex API key",
+			Outputs: outputs,
+		}, nil
+	}
+
+	key, err := api.createKey(ctx, name, cfg.Rotation.permissions())
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to generate api key: %v", err),
+			Outputs: outputs,
+		}, nil
+	}
+	summary.masker.add(key.Secret)
+
+	if err := p.storeRotatedKey(ctx, cfg, key, summary); err != nil {
+		// An unstored key is unusable; revoke it rather than leave it orphaned
+		if revokeErr := api.revokeKey(ctx, name); revokeErr != nil {
+			err = fmt.Errorf("%v (revoking the new key also failed: %v)", err, revokeErr)
+		}
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to store rotated key: %v", err),
+			Outputs: outputs,
+		}, nil
+	}
+
+	if key.InsertedAt.IsZero() {
+		key.InsertedAt = now
+	}
+	due, pending := revocationPlan(append(managed, *key), cfg.Rotation.grace(), now)
+	outputs["pending"] = pending
+
+	revoked := make([]string, 0, len(due))
+	var failed []string
+	for _, old := range due {
+		if err := api.revokeKey(ctx, old); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", old, err))
+			continue
+		}
+		revoked = append(revoked, old)
+	}
+	outputs["revoked"] = revoked
+
+	if len(failed) > 0 {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("rotated to key %s but failed to revoke old keys: %s", name, strings.Join(failed, "; ")),
+			Outputs: outputs,
+		}, nil
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: fmt.Sprintf("Rotated Hex API key to %s (revoked %d)", name, len(revoked)),
+		Outputs: outputs,
+	}, nil
+}
+
+// runOperation runs a standalone operation outside the release hooks.
+func (p *HexPlugin) runOperation(ctx context.Context, operation string, req plugin.ExecuteRequest) (*plugin.ExecuteResponse, error) {
+	config, errs := mergeConfig(req.Config, req.Hook)
+	if len(errs) > 0 {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid %v", errs[0]),
+		}, nil
+	}
+	req.Config = config
+
+	if errs := validateRawConfig(req.Config); len(errs) > 0 {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid %v", errs[0]),
+		}, nil
+	}
+
+	cfg := p.parseConfig(req.Config)
+	if err := validateLocale(cfg.Locale); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid %v", err),
+		}, nil
+	}
+	ctx = withLocaleEnv(ctx, cfg.localeEnv())
+	summary := newRunSummary(p.GetInfo(), req)
+	summary.masker = newSecretMasker(cfg)
+
+	var resp *plugin.ExecuteResponse
+	var err error
+
+	switch operation {
+	case operationRotateKey:
+		resp, err = p.rotateKey(ctx, cfg, req.DryRun, summary)
+	default:
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("unknown operation %q: must be %s", operation, operationRotateKey),
+		}, nil
+	}
+	if err != nil {
+		return resp, err
+	}
+
+	summary.masker.maskResponse(resp)
+	return resp, nil
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// fakeHexKeysAPI serves the /keys endpoints of the Hex API.
+type fakeHexKeysAPI struct {
+	mu      sync.Mutex
+	keys    []HexKey
+	revoked []string
+	created []string
+}
+
+func (f *fakeHexKeysAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "current-key" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"invalid API key"}`))
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/keys":
+		_ = json.NewEncoder(w).Encode(f.keys)
+	case r.Method == http.MethodPost && r.URL.Path == "/keys":
+		var body struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.created = append(f.created, body.Name)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(HexKey{Name: body.Name, Secret: "new-secret-value", InsertedAt: time.Now().UTC()})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/keys/"):
+		f.revoked = append(f.revoked, strings.TrimPrefix(r.URL.Path, "/keys/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRevocationPlan(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	keys := []HexKey{
+		{Name: "k3", InsertedAt: now},
+		{Name: "k1", InsertedAt: now.Add(-72 * time.Hour)},
+		{Name: "k2", InsertedAt: now.Add(-48 * time.Hour)},
+	}
+
+	due, pending := revocationPlan(keys, 24*time.Hour, now)
+	if strings.Join(due, ",") != "k1" {
+		t.Errorf("due: got %v", due)
+	}
+	if len(pending) != 1 || pending[0].Name != "k2" || !pending[0].RevokeAfter.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("pending: got %+v", pending)
+	}
+
+	due, pending = revocationPlan(keys, 0, now)
+	if len(due) != 2 || len(pending) != 0 {
+		t.Errorf("zero grace should revoke all replaced keys, got %v / %v", due, pending)
+	}
+}
+
+func TestValidateRotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		rotation    map[string]any
+		expectField string
+	}{
+		{
+			name:     "file backend",
+			rotation: map[string]any{"grace_period": "48h", "backend": map[string]any{"type": "file", "path": "secrets/hex_key"}},
+		},
+		{
+			name:     "file backend outside the repository",
+			rotation: map[string]any{"backend": map[string]any{"type": "file", "path": filepath.Join(t.TempDir(), "hex_key")}},
+		},
+		{
+			name:        "file backend escaping work_dir",
+			rotation:    map[string]any{"backend": map[string]any{"type": "file", "path": "../hex_key"}},
+			expectField: "rotation.backend.path",
+		},
+		{
+			name:     "command backend",
+			rotation: map[string]any{"permissions": []any{"api:write", "repository:acme"}, "backend": map[string]any{"type": "command", "command": []any{"vault", "kv", "put"}}},
+		},
+		{
+			name:        "missing backend type",
+			rotation:    map[string]any{},
+			expectField: "rotation.backend.type",
+		},
+		{
+			name:        "file backend without path",
+			rotation:    map[string]any{"backend": map[string]any{"type": "file"}},
+			expectField: "rotation.backend.path",
+		},
+		{
+			name:        "invalid grace period",
+			rotation:    map[string]any{"grace_period": "a while", "backend": map[string]any{"type": "file", "path": "k"}},
+			expectField: "rotation.grace_period",
+		},
+		{
+			name:        "unknown field",
+			rotation:    map[string]any{"grace": "1h", "backend": map[string]any{"type": "file", "path": "k"}},
+			expectField: "rotation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateRotation(map[string]any{"rotation": tt.rotation})
+			if tt.expectField == "" {
+				if len(errs) != 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tt.expectField {
+				t.Errorf("expected error for %q, got %v", tt.expectField, errs)
+			}
+		})
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	chdirTemp(t)
+
+	old := time.Now().UTC().Add(-72 * time.Hour)
+	api := &fakeHexKeysAPI{keys: []HexKey{
+		{Name: "personal", InsertedAt: old},
+		{Name: "relicta-hex-old", InsertedAt: old},
+		{Name: "relicta-hex-current", InsertedAt: old.Add(time.Hour)},
+	}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	config := map[string]any{
+		"api_key": "current-key",
+		"api_url": server.URL,
+		"rotation": map[string]any{
+			"grace_period": "24h",
+			"backend":      map[string]any{"type": "file", "path": "secrets/hex_key"},
+		},
+	}
+
+	t.Run("dry run plans without changes", func(t *testing.T) {
+		p := &HexPlugin{httpClient: server.Client()}
+		resp, err := p.runOperation(context.Background(), operationRotateKey, plugin.ExecuteRequest{Config: config, DryRun: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		if due := resp.Outputs["would_revoke"].([]string); strings.Join(due, ",") != "relicta-hex-old" {
+			t.Errorf("expected only the key replaced before the grace period to be revoked, got %v", due)
+		}
+		if pending := resp.Outputs["pending"].([]pendingRevocation); len(pending) != 1 || pending[0].Name != "relicta-hex-current" {
+			t.Errorf("expected the current key to stay valid during the grace period, got %+v", pending)
+		}
+		if len(api.created) != 0 || len(api.revoked) != 0 {
+			t.Error("dry run must not change keys")
+		}
+	})
+
+	t.Run("rotates, stores and revokes", func(t *testing.T) {
+		p := &HexPlugin{httpClient: server.Client()}
+		resp, err := p.runOperation(context.Background(), operationRotateKey, plugin.ExecuteRequest{Config: config})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+
+		data, err := os.ReadFile("secrets/hex_key")
+		if err != nil || string(data) != "new-secret-value" {
+			t.Errorf("secret file: %q, %v", data, err)
+		}
+		if len(api.created) != 1 || !strings.HasPrefix(api.created[0], "relicta-hex-") {
+			t.Errorf("unexpected created keys: %v", api.created)
+		}
+		if strings.Join(api.revoked, ",") != "relicta-hex-old" {
+			t.Errorf("unexpected revoked keys: %v", api.revoked)
+		}
+		if strings.Contains(resp.Message+resp.Error, "new-secret-value") {
+			t.Error("response leaked the new key")
+		}
+	})
+
+	t.Run("absolute path replaces a readable secret file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hex_key")
+		if err := os.WriteFile(path, []byte("old-secret"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := map[string]any{
+			"api_key":  "current-key",
+			"api_url":  server.URL,
+			"work_dir": "apps/core",
+			"rotation": map[string]any{"backend": map[string]any{"type": "file", "path": path}},
+		}
+		p := &HexPlugin{httpClient: server.Client()}
+		resp, err := p.runOperation(context.Background(), operationRotateKey, plugin.ExecuteRequest{Config: cfg})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != "new-secret-value" {
+			t.Errorf("secret file: %q, %v", data, err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("secret file mode: %v, %v", info.Mode().Perm(), err)
+		}
+	})
+
+	t.Run("relative path is joined to work_dir", func(t *testing.T) {
+		if err := os.MkdirAll(filepath.Join("apps", "core"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		cfg := map[string]any{
+			"api_key":  "current-key",
+			"api_url":  server.URL,
+			"work_dir": "apps/core",
+			"rotation": map[string]any{"backend": map[string]any{"type": "file", "path": "hex_key"}},
+		}
+		p := &HexPlugin{httpClient: server.Client()}
+		resp, err := p.runOperation(context.Background(), operationRotateKey, plugin.ExecuteRequest{Config: cfg})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		if data, err := os.ReadFile(filepath.Join("apps", "core", "hex_key")); err != nil || string(data) != "new-secret-value" {
+			t.Errorf("secret file: %q, %v", data, err)
+		}
+	})
+
+	t.Run("failed store revokes the new key", func(t *testing.T) {
+		api.created, api.revoked = nil, nil
+		mock := &MockCommandExecutor{
+			RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+				return []byte("permission denied"), &ReplayedError{Message: "exit status 1", Code: 1}
+			},
+		}
+		p := &HexPlugin{executor: mock, httpClient: server.Client()}
+		cfg := map[string]any{
+			"api_key":  "current-key",
+			"api_url":  server.URL,
+			"rotation": map[string]any{"backend": map[string]any{"type": "command", "command": []any{"store-secret"}}},
+		}
+		resp, err := p.runOperation(context.Background(), operationRotateKey, plugin.ExecuteRequest{Config: cfg})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Success || !strings.Contains(resp.Error, "failed to store rotated key") {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+		if len(api.created) != 1 || len(api.revoked) != 1 || api.revoked[0] != api.created[0] {
+			t.Errorf("expected only the new key to be revoked, created=%v revoked=%v", api.created, api.revoked)
+		}
+		if !contains(mock.Calls[0].Env, newAPIKeyEnv+"=new-secret-value") {
+			t.Errorf("expected the new key in the backend env, got %v", mock.Calls[0].Env)
+		}
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		p := &HexPlugin{}
+		resp, _ := p.runOperation(context.Background(), "rotate", plugin.ExecuteRequest{Config: config})
+		if resp.Success || !strings.Contains(resp.Error, "unknown operation") {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+}
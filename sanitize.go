@@ -0,0 +1,115 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+var (
+	// escapeSequencePattern matches terminal escape sequences: CSI sequences
+	// such as cursor movement, OSC sequences such as window titles and
+	// hyperlinks, two-character escapes, and stray escape characters.
+	escapeSequencePattern = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b\n]*(?:\x07|\x1b\\)?|[@-Z\\-_])?`)
+	// sgrPattern matches color sequences, which the output parsers strip
+	// themselves and which render fine when output is shown in a terminal.
+	sgrPattern = regexp.MustCompile(`^\x1b\[[0-9;]*m$`)
+)
+
+// sanitizeOutput makes command output safe to embed in responses. Native
+// builds can print text in a legacy encoding and drive the terminal with
+// control sequences, either of which corrupts the ExecuteResponse payload.
+func sanitizeOutput(output []byte) []byte {
+	if len(output) == 0 {
+		return output
+	}
+	return []byte(sanitizeText(string(output)))
+}
+
+// sanitizeText transcodes bytes that are not valid UTF-8 as Latin-1, the
+// usual encoding of such output, drops control characters and escape
+// sequences other than colors, and turns the bare carriage returns of
+// progress output into newlines.
+func sanitizeText(s string) string {
+	if isCleanText(s) {
+		return s
+	}
+	s = escapeSequencePattern.ReplaceAllStringFunc(s, func(seq string) string {
+		if sgrPattern.MatchString(seq) {
+			return seq
+		}
+		return ""
+	})
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			r = rune(s[i])
+		}
+		i += size
+		switch {
+		case r == '\r':
+			if i < len(s) && s[i] == '\n' {
+				b.WriteByte('\r')
+			} else {
+				b.WriteByte('\n')
+			}
+		case r == '\n' || r == '\t' || r == 0x1b:
+			b.WriteRune(r)
+		case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isCleanText reports whether s is valid UTF-8 without control characters
+// other than newlines, tabs and color sequences, which is true of almost
+// all output.
+func isCleanText(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 0x1b:
+			loc := escapeSequencePattern.FindStringIndex(s[i:])
+			if loc[0] != 0 || !sgrPattern.MatchString(s[i:i+loc[1]]) {
+				return false
+			}
+		case c == '\r':
+			if i+1 >= len(s) || s[i+1] != '\n' {
+				return false
+			}
+		case c < 0x20 && c != '\n' && c != '\t', c == 0x7f:
+			return false
+		}
+	}
+	return !strings.ContainsFunc(s, func(r rune) bool { return r >= 0x80 && r < 0xa0 })
+}
+
+// sanitizeResponse sanitizes the message, error and string outputs of a
+// response, which may quote output that never went through runCommand,
+// such as registry error bodies.
+func sanitizeResponse(resp *plugin.ExecuteResponse) {
+	if resp == nil {
+		return
+	}
+	resp.Message = sanitizeText(resp.Message)
+	resp.Error = sanitizeText(resp.Error)
+	for key, val := range resp.Outputs {
+		switch v := val.(type) {
+		case string:
+			resp.Outputs[key] = sanitizeText(v)
+		case []string:
+			for i := range v {
+				v[i] = sanitizeText(v[i])
+			}
+		}
+	}
+}
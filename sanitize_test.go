@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "clean", input: "Building my_lib 1.0.0\r\n  \x1b[33mwarning\x1b[0m\tdone\n", want: "Building my_lib 1.0.0\r\n  \x1b[33mwarning\x1b[0m\tdone\n"},
+		{name: "latin-1", input: "Compiling caf\xe9.c\n", want: "Compiling café.c\n"},
+		{name: "valid utf-8 kept", input: "Compiling café.c ✓\n", want: "Compiling café.c ✓\n"},
+		{name: "cursor movement", input: "\x1b[2K\x1b[1Gcompiling\x1b[?25l\n", want: "compiling\n"},
+		{name: "window title", input: "\x1b]0;make\x07done\n", want: "done\n"},
+		{name: "hyperlink", input: "\x1b]8;;https://hex.pm\x1b\\hex.pm\x1b]8;;\x1b\\\n", want: "hex.pm\n"},
+		{name: "progress", input: "10%\r50%\r100%\n", want: "10%\n50%\n100%\n"},
+		{name: "control characters", input: "a\x00b\x07c\x08d\x7fe\x1bf", want: "abcdef"},
+		{name: "c1 controls", input: "a\xc2\x9bb\x85c", want: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeText(tt.input)
+			if got != tt.want {
+				t.Errorf("sanitizeText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("sanitizeText(%q) is not valid UTF-8", tt.input)
+			}
+		})
+	}
+}
+
+func TestExecuteSanitizesOutput(t *testing.T) {
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("\x1b]0;nif\x07cc: erreur fatale: fichier \xab nif.h \xbb introuvable\x00\n"), context.DeadlineExceeded
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(resp.Error, "cc: erreur fatale: fichier « nif.h » introuvable\n") {
+		t.Errorf("expected transcoded output in error, got %q", resp.Error)
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	if strings.Contains(string(data), `�`) || strings.Contains(string(data), `\u0000`) {
+		t.Errorf("response still holds invalid text: %s", data)
+	}
+}
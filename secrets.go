@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Secret source policies selected by secret_source_policy.
+const (
+	secretPolicyAny      = "any"
+	secretPolicyNoInline = "no_inline"
+)
+
+// Sources of the API key recorded as secret_source.
+const (
+	secretSourceConfig      = "config"
+	secretSourceProvisioned = "provisioned"
+)
+
+// validateSecretPolicy checks that secret_source_policy names a known policy.
+func validateSecretPolicy(policy string) error {
+	switch policy {
+	case "", secretPolicyAny, secretPolicyNoInline:
+		return nil
+	default:
+		return fmt.Errorf("must be %s or %s", secretPolicyAny, secretPolicyNoInline)
+	}
+}
+
+// envSecretSource names an API key read from an environment variable.
+func envSecretSource(name string) string {
+	return "env:" + name
+}
+
+// fileSecretSource names an API key read from a file.
+func fileSecretSource(path string) string {
+	return "file:" + path
+}
+
+// apiKeySource returns where the top-level API key comes from: inline
+// config or HEX_API_KEY. It is empty when no key is set.
+func apiKeySource(raw map[string]any) string {
+	if key, ok := raw["api_key"].(string); ok && key != "" {
+		return secretSourceConfig
+	}
+	if os.Getenv("HEX_API_KEY") != "" {
+		return envSecretSource("HEX_API_KEY")
+	}
+	return ""
+}
+
+// inlineSecretFields lists every config field holding an API key inline.
+func inlineSecretFields(raw map[string]any) []string {
+	var fields []string
+	if key, ok := raw["api_key"].(string); ok && key != "" {
+		fields = append(fields, "api_key")
+	}
+	profiles, _ := parseProfiles(raw)
+	for name, profile := range profiles {
+		if profile.APIKey != "" {
+			fields = append(fields, "profiles."+name+".api_key")
+		}
+	}
+	packages, _ := parsePackages(raw)
+	for i, pkg := range packages {
+		if pkg.APIKey != "" {
+			fields = append(fields, fmt.Sprintf("packages[%d].api_key", i))
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// checkSecretSource enforces secret_source_policy on the resolved API key.
+func checkSecretSource(cfg *Config) error {
+	if cfg.SecretSourcePolicy == secretPolicyNoInline && cfg.keySource == secretSourceConfig {
+		return fmt.Errorf("secret_source_policy %s forbids api_key in plugin config: use HEX_API_KEY, a profile api_key_env, org_keys or provision_org_key", secretPolicyNoInline)
+	}
+	return nil
+}
+
+// maxAPIKeyLength bounds plausible API keys. Hex.pm keys are 32 hex
+// characters; anything far longer is a paste of more than the key.
+const maxAPIKeyLength = 256
+
+// keyQuotes are the quote characters a copied key may carry along.
+const keyQuotes = "\"'`"
+
+// checkAPIKeyFormat catches keys mangled by copy-paste: surrounding
+// whitespace or quotes, and characters no API key contains. It runs locally,
+// so a pasted key with a trailing newline fails before any network call.
+func checkAPIKeyFormat(key string) error {
+	trimmed := strings.TrimSpace(key)
+	switch {
+	case trimmed == "":
+		return fmt.Errorf("is blank")
+	case trimmed != key:
+		return fmt.Errorf("has leading or trailing whitespace (a newline from copy-paste?)")
+	case len(key) >= 2 && strings.ContainsRune(keyQuotes, rune(key[0])) && key[len(key)-1] == key[0]:
+		return fmt.Errorf("is wrapped in quotes")
+	case len(key) > maxAPIKeyLength:
+		return fmt.Errorf("is %d characters long, more than any API key", len(key))
+	}
+	for _, r := range key {
+		if r <= ' ' || r > '~' || strings.ContainsRune(keyQuotes, r) {
+			return fmt.Errorf("contains %q, which API keys never contain", r)
+		}
+	}
+	return nil
+}
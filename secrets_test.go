@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestInlineSecretFields(t *testing.T) {
+	raw := map[string]any{
+		"api_key": "inline",
+		"profiles": map[string]any{
+			"staging":    map[string]any{"api_key": "inline"},
+			"production": map[string]any{"api_key_env": "HEX_PROD_KEY"},
+		},
+		"packages": []any{
+			map[string]any{"work_dir": "a"},
+			map[string]any{"work_dir": "b", "api_key": "inline"},
+		},
+	}
+
+	got := strings.Join(inlineSecretFields(raw), ",")
+	if got != "api_key,packages[1].api_key,profiles.staging.api_key" {
+		t.Errorf("inlineSecretFields() = %q", got)
+	}
+	if fields := inlineSecretFields(map[string]any{}); len(fields) != 0 {
+		t.Errorf("expected no inline fields, got %v", fields)
+	}
+}
+
+func TestExecuteSecretSource(t *testing.T) {
+	tests := []struct {
+		name            string
+		env             map[string]string
+		config          map[string]any
+		expectedSuccess bool
+		expectedSource  string
+		expectedError   string
+	}{
+		{
+			name:            "inline key allowed by default",
+			config:          map[string]any{"api_key": "inline-key"},
+			expectedSuccess: true,
+			expectedSource:  "config",
+		},
+		{
+			name:          "inline key rejected",
+			config:        map[string]any{"api_key": "inline-key", "secret_source_policy": "no_inline"},
+			expectedError: "forbids api_key in plugin config",
+		},
+		{
+			name:            "environment key",
+			env:             map[string]string{"HEX_API_KEY": "env-key"},
+			config:          map[string]any{"secret_source_policy": "no_inline"},
+			expectedSuccess: true,
+			expectedSource:  "env:HEX_API_KEY",
+		},
+		{
+			name: "profile key env",
+			env:  map[string]string{"HEX_PROD_KEY": "prod-key"},
+			config: map[string]any{
+				"secret_source_policy": "no_inline",
+				"profile":              "production",
+				"profiles":             map[string]any{"production": map[string]any{"api_key_env": "HEX_PROD_KEY"}},
+			},
+			expectedSuccess: true,
+			expectedSource:  "env:HEX_PROD_KEY",
+		},
+		{
+			name: "org key env",
+			env:  map[string]string{"HEX_KEY_ACME": "acme-key"},
+			config: map[string]any{
+				"secret_source_policy": "no_inline",
+				"organization":         "acme",
+				"org_keys":             map[string]any{"acme": "HEX_KEY_ACME"},
+			},
+			expectedSuccess: true,
+			expectedSource:  "env:HEX_KEY_ACME",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HEX_API_KEY", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			summaryPath := filepath.Join(chdirTemp(t), "summary.json")
+			config := map[string]any{"summary_path": "summary.json"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+
+			p := &HexPlugin{executor: &MockCommandExecutor{}}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if tt.expectedError != "" && !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, resp.Error)
+			}
+			if tt.expectedSource == "" {
+				return
+			}
+			if got := resp.Outputs["secret_source"]; got != tt.expectedSource {
+				t.Errorf("expected secret_source %q, got %v", tt.expectedSource, got)
+			}
+
+			data, err := os.ReadFile(summaryPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var summary RunSummary
+			if err := json.Unmarshal(data, &summary); err != nil {
+				t.Fatal(err)
+			}
+			if summary.SecretSource != tt.expectedSource {
+				t.Errorf("expected the summary to record %q, got %q", tt.expectedSource, summary.SecretSource)
+			}
+		})
+	}
+}
+
+func TestValidateSecretSourcePolicy(t *testing.T) {
+	p := &HexPlugin{}
+
+	resp, err := p.Validate(context.Background(), map[string]any{
+		"secret_source_policy": "no_inline",
+		"api_key":              "inline",
+		"packages":             []any{map[string]any{"work_dir": "a", "api_key": "inline"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Valid || len(resp.Errors) != 2 {
+		t.Errorf("expected both inline keys to be rejected, got %+v", resp.Errors)
+	}
+
+	resp, _ = p.Validate(context.Background(), map[string]any{"secret_source_policy": "strict"})
+	if resp.Valid {
+		t.Error("expected unknown policy to be rejected")
+	}
+}
+
+func TestCheckAPIKeyFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr string
+	}{
+		{name: "hex key", key: "0123456789abcdef0123456789abcdef"},
+		{name: "short test key", key: "test-api-key"},
+		{name: "blank", key: "  ", wantErr: "is blank"},
+		{name: "trailing newline", key: "0123456789abcdef\n", wantErr: "trailing whitespace"},
+		{name: "leading space", key: " 0123456789abcdef", wantErr: "trailing whitespace"},
+		{name: "double quoted", key: `"0123456789abcdef"`, wantErr: "wrapped in quotes"},
+		{name: "single quoted", key: "'0123456789abcdef'", wantErr: "wrapped in quotes"},
+		{name: "stray quote", key: `0123456789abcdef"`, wantErr: "contains"},
+		{name: "inner space", key: "0123 4567", wantErr: "contains"},
+		{name: "non-ascii", key: "0123\u200b4567", wantErr: "contains"},
+		{name: "too long", key: strings.Repeat("a", maxAPIKeyLength+1), wantErr: "more than any API key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAPIKeyFormat(tt.key)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAPIKeyFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s: the format check must run before the key health check", r.URL.Path)
+	}))
+	defer server.Close()
+	t.Setenv("HEX_API_KEY", "0123456789abcdef0123456789abcdef\n")
+
+	resp, err := (&HexPlugin{}).Validate(context.Background(), map[string]any{
+		"api_url":          server.URL,
+		"key_expiry_check": true,
+		"packages":         []any{map[string]any{"work_dir": "a", "api_key": `"quoted"`}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Valid {
+		t.Fatal("expected mangled keys to be rejected")
+	}
+
+	messages := map[string]string{}
+	for _, e := range resp.Errors {
+		messages[e.Field] = e.Message
+	}
+	if !strings.Contains(messages["api_key"], "key from env:HEX_API_KEY has leading or trailing whitespace") {
+		t.Errorf("api_key error = %q", messages["api_key"])
+	}
+	if !strings.Contains(messages["packages[0].api_key"], "wrapped in quotes") {
+		t.Errorf("packages[0].api_key error = %q", messages["packages[0].api_key"])
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Rules reported by the secret scan.
+const (
+	secretRuleAWSKey     = "aws_access_key"
+	secretRulePrivateKey = "private_key"
+	secretRuleToken      = "api_token"
+	secretRuleEnvFile    = "env_file"
+	secretRuleEntropy    = "high_entropy"
+)
+
+// secretPatterns are the known credential formats.
+var secretPatterns = []struct {
+	rule    string
+	pattern *regexp.Regexp
+}{
+	{secretRuleAWSKey, regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{secretRulePrivateKey, regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY( BLOCK)?-----`)},
+	{secretRuleToken, regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{22,}|xox[abprs]-[A-Za-z0-9-]{10,}|sk_live_[A-Za-z0-9]{24,})\b`)},
+}
+
+// quotedTokenPattern matches quoted strings that could hold an encoded key.
+var quotedTokenPattern = regexp.MustCompile(`["']([A-Za-z0-9+/=_\-]{32,})["']`)
+
+// secretEntropyThreshold is the Shannon entropy, in bits per character,
+// above which a quoted token is reported. Hex digests stay below 4; random
+// base64 keys of 32 characters or more are above it.
+const secretEntropyThreshold = 4.5
+
+// maxScannedFileSize skips files too large to be source or config.
+const maxScannedFileSize = 10 << 20
+
+// SecretFinding is a possible secret in a packaged file. The secret itself
+// is never reported.
+type SecretFinding struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+	Rule string `json:"rule"`
+}
+
+// validateSecretScanAllow checks the secret_scan_allow path patterns.
+func validateSecretScanAllow(patterns []string) *fieldError {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return &fieldError{Field: "secret_scan_allow", Err: fmt.Errorf("invalid pattern %q: %w", pattern, err)}
+		}
+	}
+	return nil
+}
+
+// secretScanAllowed reports whether a packaged file is excluded from the
+// scan by secret_scan_allow.
+func secretScanAllowed(name string, allow []string) bool {
+	for _, pattern := range allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnvFile reports whether a file is a dotenv file. Examples and templates
+// are meant to be shared.
+func isEnvFile(name string) bool {
+	base := path.Base(name)
+	if base != ".env" && !strings.HasPrefix(base, ".env.") {
+		return false
+	}
+	switch path.Ext(base) {
+	case ".example", ".sample", ".template", ".dist":
+		return false
+	}
+	return true
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// scanSecrets reports the lines of a file matching a credential pattern or
+// holding a high-entropy quoted string. Binary files are skipped.
+func scanSecrets(name string, r io.Reader) ([]SecretFinding, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxScannedFileSize))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil, nil
+	}
+
+	var findings []SecretFinding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), maxScannedFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		found := false
+		for _, sp := range secretPatterns {
+			if sp.pattern.MatchString(text) {
+				findings = append(findings, SecretFinding{Path: name, Line: line, Rule: sp.rule})
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		for _, m := range quotedTokenPattern.FindAllStringSubmatch(text, -1) {
+			if shannonEntropy(m[1]) > secretEntropyThreshold {
+				findings = append(findings, SecretFinding{Path: name, Line: line, Rule: secretRuleEntropy})
+				break
+			}
+		}
+	}
+	return findings, scanner.Err()
+}
+
+// scanTarballSecrets scans every file of a package tarball not excluded by
+// allow. Dotenv files are reported whatever they contain.
+func scanTarballSecrets(tarball string, allow []string) ([]SecretFinding, error) {
+	findings := []SecretFinding{}
+	err := walkTarballContents(tarball, func(hdr *tar.Header, r io.Reader) error {
+		if secretScanAllowed(hdr.Name, allow) {
+			return nil
+		}
+		if isEnvFile(hdr.Name) {
+			findings = append(findings, SecretFinding{Path: hdr.Name, Rule: secretRuleEnvFile})
+			return nil
+		}
+		if hdr.Size > maxScannedFileSize {
+			return nil
+		}
+		found, err := scanSecrets(hdr.Name, r)
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %w", hdr.Name, err)
+		}
+		findings = append(findings, found...)
+		return nil
+	})
+	return findings, err
+}
+
+// describeFindings lists findings as path:line (rule).
+func describeFindings(findings []SecretFinding) string {
+	parts := make([]string, 0, len(findings))
+	for _, f := range findings {
+		loc := f.Path
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", f.Path, f.Line)
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", loc, f.Rule))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// checkPackageSecrets builds the package and scans its files for secrets.
+// A publish cannot be taken back, so any finding blocks it.
+func (p *HexPlugin) checkPackageSecrets(ctx context.Context, cfg *Config, outputs map[string]any, summary *RunSummary) error {
+	dir, err := os.MkdirTemp("", "relicta-hex-scan-")
+	if err != nil {
+		return fmt.Errorf("secret scan: failed to create build directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tarball := filepath.Join(dir, "package.tar")
+	if err := p.buildPackage(ctx, cfg, tarball, summary); err != nil {
+		return fmt.Errorf("secret scan: %w", err)
+	}
+	findings, err := scanTarballSecrets(tarball, cfg.SecretScanAllow)
+	if err != nil {
+		return fmt.Errorf("secret scan: %w", err)
+	}
+	outputs["secret_findings"] = findings
+	if len(findings) > 0 {
+		return fmt.Errorf("secret scan found %d possible secret(s) in the package, nothing was published: %s; exclude false positives with secret_scan_allow", len(findings), describeFindings(findings))
+	}
+	summary.debugf("secret scan found nothing in the package")
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// defaultShutdownGrace is how long a cancelled command may clean up after
+// the interrupt before its process group is killed.
+const defaultShutdownGrace = 10 * time.Second
+
+// Signals reported by TerminatedError.
+const (
+	signalInterrupt = "SIGINT"
+	signalKill      = "SIGKILL"
+)
+
+// shutdownGraceKey carries the shutdown grace period to the executor.
+type shutdownGraceKey struct{}
+
+// withShutdownGrace returns a context carrying the shutdown grace period.
+func withShutdownGrace(ctx context.Context, grace time.Duration) context.Context {
+	return context.WithValue(ctx, shutdownGraceKey{}, grace)
+}
+
+// shutdownGraceFrom returns the grace period carried by ctx, or the default.
+func shutdownGraceFrom(ctx context.Context) time.Duration {
+	if grace, ok := ctx.Value(shutdownGraceKey{}).(time.Duration); ok && grace > 0 {
+		return grace
+	}
+	return defaultShutdownGrace
+}
+
+// TerminatedError reports a command stopped because its context ended.
+type TerminatedError struct {
+	// Signal is the signal that ended the process.
+	Signal string
+	// Cause is the context error that triggered the shutdown.
+	Cause error
+	// Err is the error returned by the process.
+	Err error
+}
+
+// Error implements error.
+func (e *TerminatedError) Error() string {
+	return fmt.Sprintf("process terminated by %s after %v: %v", e.Signal, e.Cause, e.Err)
+}
+
+// Unwrap exposes both the context error and the process error.
+func (e *TerminatedError) Unwrap() []error {
+	return []error{e.Cause, e.Err}
+}
+
+// waitGracefully waits for a started command. When ctx ends first, the
+// process group is interrupted so hex can clean up a partial upload, then
+// killed if it is still running after the grace period.
+func waitGracefully(ctx context.Context, cmd *exec.Cmd, grace time.Duration) error {
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	if err := interruptProcess(cmd); err == nil {
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+
+		select {
+		case err := <-done:
+			return &TerminatedError{Signal: signalInterrupt, Cause: ctx.Err(), Err: err}
+		case <-timer.C:
+		}
+	}
+
+	_ = killProcess(cmd)
+	return &TerminatedError{Signal: signalKill, Cause: ctx.Err(), Err: <-done}
+}
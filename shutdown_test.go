@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestShutdownGraceFrom(t *testing.T) {
+	if got := shutdownGraceFrom(context.Background()); got != defaultShutdownGrace {
+		t.Errorf("default grace = %v, want %v", got, defaultShutdownGrace)
+	}
+	ctx := withShutdownGrace(context.Background(), 3*time.Second)
+	if got := shutdownGraceFrom(ctx); got != 3*time.Second {
+		t.Errorf("grace = %v, want 3s", got)
+	}
+}
+
+func TestRealCommandExecutorShutdown(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported on windows")
+	}
+
+	tests := []struct {
+		name       string
+		script     string
+		wantSignal string
+		wantOutput string
+	}{
+		{
+			name:       "exits after interrupt",
+			script:     `trap 'echo cleaned up; exit 1' INT; echo started; sleep 5`,
+			wantSignal: signalInterrupt,
+			wantOutput: "started\ncleaned up\n",
+		},
+		{
+			name:       "killed when interrupt is ignored",
+			script:     `trap '' INT; echo started; sleep 5`,
+			wantSignal: signalKill,
+			wantOutput: "started\n",
+		},
+	}
+
+	runners := map[string]func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error){
+		"run":         (&RealCommandExecutor{}).Run,
+		"interactive": (&RealCommandExecutor{}).RunInteractive,
+	}
+
+	for _, tt := range tests {
+		for runnerName, run := range runners {
+			t.Run(tt.name+"/"+runnerName, func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+				defer cancel()
+				ctx = withShutdownGrace(ctx, 300*time.Millisecond)
+
+				start := time.Now()
+				output, err := run(ctx, "sh", []string{"-c", tt.script}, nil, "")
+				if elapsed := time.Since(start); elapsed > 3*time.Second {
+					t.Fatalf("command ran for %v, expected it to be stopped", elapsed)
+				}
+
+				var terminated *TerminatedError
+				if !errors.As(err, &terminated) {
+					t.Fatalf("expected TerminatedError, got %v", err)
+				}
+				if terminated.Signal != tt.wantSignal {
+					t.Errorf("signal = %s, want %s", terminated.Signal, tt.wantSignal)
+				}
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+				}
+				if string(output) != tt.wantOutput {
+					t.Errorf("output = %q, want %q", output, tt.wantOutput)
+				}
+			})
+		}
+	}
+}
+
+func TestRecordCommandTerminatedBy(t *testing.T) {
+	summary := &RunSummary{}
+	err := &TerminatedError{Signal: signalKill, Cause: context.DeadlineExceeded, Err: errors.New("signal: killed")}
+	summary.recordCommand("mix", []string{"hex.publish"}, nil, ".", time.Now(), err)
+
+	if len(summary.Commands) != 1 {
+		t.Fatalf("expected 1 command record, got %d", len(summary.Commands))
+	}
+	if got := summary.Commands[0].TerminatedBy; got != signalKill {
+		t.Errorf("TerminatedBy = %q, want %q", got, signalKill)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// defaultSmokeTestTimeout bounds the wait for registry propagation.
+const defaultSmokeTestTimeout = 5 * time.Minute
+
+// smokeTestPollInterval is how often the registry is checked for the release.
+var smokeTestPollInterval = 10 * time.Second
+
+// smokeTestMixExs is the throwaway consumer project depending on the release.
+const smokeTestMixExs = `defmodule RelictaHexSmokeTest.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :relicta_hex_smoke_test, version: "0.1.0", deps: deps()]
+  end
+
+  defp deps do
+    [{:%s, "== %s", hex: :%s%s}]
+  end
+end
+`
+
+// smokeTestProject returns the mix.exs of a project depending on the release
+// of package name, which provides the OTP application app.
+func smokeTestProject(organization, app, name, version string) string {
+	opts := ""
+	if organization != "" {
+		opts = fmt.Sprintf(", organization: %q", organization)
+	}
+	return fmt.Sprintf(smokeTestMixExs, app, version, name, opts)
+}
+
+// releaseApp returns the OTP application the package published from dir
+// provides, which is the package name unless mix.exs names another.
+func releaseApp(dir, name string) string {
+	if content, err := readMixExs(dir); err == nil && parseMixApp(content) != "" {
+		return parseMixApp(content)
+	}
+	return name
+}
+
+// waitForRelease polls the registry until it serves the release, so the
+// smoke test does not race registry propagation.
+func (p *HexPlugin) waitForRelease(ctx context.Context, cfg *Config, name, version string) error {
+	api := p.hexAPI(cfg)
+	for {
+		exists, err := api.releaseExists(ctx, cfg.Organization, name, version)
+		if err == nil && exists {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("release %s %s not available: %w", name, version, err)
+			}
+			return fmt.Errorf("release %s %s not available after %s", name, version, cfg.SmokeTestTimeout)
+		case <-time.After(smokeTestPollInterval):
+		}
+	}
+}
+
+// smokeTest installs the published release into a throwaway mix project
+// from the registry and compiles it, verifying consumers can use it.
+func (p *HexPlugin) smokeTest(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool, summary *RunSummary) (*plugin.ExecuteResponse, error) {
+	if !cfg.SmokeTest {
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "No post-publish verification configured",
+		}, nil
+	}
+
+	version := strings.TrimPrefix(releaseCtx.Version, "v")
+	projectType, _ := cfg.resolveProjectType()
+	name, file := declaredPackageName(cfg.WorkDir, projectType)
+	if name == "" {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("smoke test: could not determine the package name from %s", file),
+		}, nil
+	}
+
+	outputs := map[string]any{"package": name, "version": version}
+	if dryRun {
+		outputs["smoke_test"] = smokeTestProject(cfg.Organization, releaseApp(cfg.WorkDir, name), name, version)
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: fmt.Sprintf("Would install %s %s from the registry and compile it", name, version),
+			Outputs: outputs,
+		}, nil
+	}
+
+	if _, err := cfg.applyOrgKey(); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("smoke test: %v", err),
+			Outputs: outputs,
+		}, nil
+	}
+	summary.masker.add(cfg.APIKey)
+
+	waitCtx, cancel := context.WithTimeout(ctx, cfg.SmokeTestTimeout)
+	defer cancel()
+	if output, err := p.installRelease(ctx, waitCtx, cfg, name, version, summary); err != nil {
+		if output != "" {
+			outputs["smoke_test_output"] = output
+		}
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("smoke test: %v", err),
+			Outputs: outputs,
+		}, nil
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: fmt.Sprintf("Installed %s %s from the registry and compiled it", name, version),
+		Outputs: outputs,
+	}, nil
+}
+
+// installRelease waits until waitCtx for the registry to serve the release,
+// then installs it into a throwaway mix project and compiles it. The output
+// of a failed mix step is returned with the error.
+func (p *HexPlugin) installRelease(ctx, waitCtx context.Context, cfg *Config, name, version string, summary *RunSummary) (string, error) {
+	if err := p.waitForRelease(waitCtx, cfg, name, version); err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "relicta-hex-smoke-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create project: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte(smokeTestProject(cfg.Organization, releaseApp(cfg.WorkDir, name), name, version)), 0o644); err != nil {
+		return "", fmt.Errorf("failed to create project: %w", err)
+	}
+
+	consumer := *cfg
+	consumer.WorkDir = dir
+	for _, args := range [][]string{{"deps.get"}, {"compile"}} {
+		output, err := p.runMix(ctx, &consumer, summary, args, cfg.hexEnv())
+		if err != nil {
+			return string(output), fmt.Errorf("mix %s failed installing %s %s from the registry: %w", args[0], name, version, err)
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestSmokeTestProject(t *testing.T) {
+	got := smokeTestProject("acme", "my_lib", "my_lib", "1.2.0")
+	if !strings.Contains(got, `{:my_lib, "== 1.2.0", hex: :my_lib, organization: "acme"}`) {
+		t.Errorf("unexpected dependency in project:\n%s", got)
+	}
+	if got := smokeTestProject("", "my_lib", "my_lib", "1.2.0"); !strings.Contains(got, `{:my_lib, "== 1.2.0", hex: :my_lib}`) {
+		t.Errorf("unexpected public dependency in project:\n%s", got)
+	}
+	if got := smokeTestProject("", "my_app", "my_lib", "1.2.0"); !strings.Contains(got, `{:my_app, "== 1.2.0", hex: :my_lib}`) {
+		t.Errorf("unexpected renamed dependency in project:\n%s", got)
+	}
+}
+
+func TestReleasePath(t *testing.T) {
+	if got := releasePath("", "my_lib", "1.0.0"); got != "/packages/my_lib/releases/1.0.0" {
+		t.Errorf("public release path = %q", got)
+	}
+	if got := releasePath("acme", "my_lib", "1.0.0"); got != "/repos/acme/packages/my_lib/releases/1.0.0" {
+		t.Errorf("organization release path = %q", got)
+	}
+}
+
+func TestExecuteSmokeTest(t *testing.T) {
+	prevInterval := smokeTestPollInterval
+	smokeTestPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { smokeTestPollInterval = prevInterval })
+
+	tests := []struct {
+		name            string
+		mixExs          string
+		config          map[string]any
+		propagatedAfter int32
+		compileErr      error
+		expectedSuccess bool
+		expectedError   string
+		expectedCalls   int
+	}{
+		{
+			name:            "disabled",
+			config:          map[string]any{},
+			expectedSuccess: true,
+		},
+		{
+			name:            "installs after propagation",
+			config:          map[string]any{"smoke_test": true},
+			propagatedAfter: 2,
+			expectedSuccess: true,
+			expectedCalls:   2,
+		},
+		{
+			name:            "package name differs from the app",
+			mixExs:          `[app: :my_app, package: [name: "my_lib"]]`,
+			config:          map[string]any{"smoke_test": true},
+			expectedSuccess: true,
+			expectedCalls:   2,
+		},
+		{
+			name:            "compile failure",
+			config:          map[string]any{"smoke_test": true},
+			compileErr:      errors.New("exit status 1"),
+			expectedSuccess: false,
+			expectedError:   "mix compile failed installing my_lib 1.0.0",
+			expectedCalls:   2,
+		},
+		{
+			name:            "release never appears",
+			config:          map[string]any{"smoke_test": true, "smoke_test_timeout": "50ms"},
+			propagatedAfter: 1 << 30,
+			expectedSuccess: false,
+			expectedError:   "not available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t)
+			mixExs := tt.mixExs
+			if mixExs == "" {
+				mixExs = `[app: :my_lib]`
+			}
+			if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte(mixExs), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			var polls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/packages/my_lib/releases/1.0.0" {
+					http.NotFound(w, r)
+					return
+				}
+				if atomic.AddInt32(&polls, 1) <= tt.propagatedAfter {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(`{"version":"1.0.0"}`))
+			}))
+			defer server.Close()
+
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					consumer, err := os.ReadFile(filepath.Join(dir, mixExsFile))
+					if err != nil {
+						t.Errorf("expected consumer project in %s: %v", dir, err)
+					}
+					if !strings.Contains(string(consumer), "hex: :my_lib") {
+						t.Errorf("expected the consumer to depend on package my_lib:\n%s", consumer)
+					}
+					if args[0] == "compile" {
+						return []byte("== Compilation error"), tt.compileErr
+					}
+					return []byte("ok"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key", "api_url": server.URL}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookOnSuccess,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if tt.expectedError != "" && !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, resp.Error)
+			}
+			if len(mock.Calls) != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, len(mock.Calls))
+			}
+			for _, call := range mock.Calls {
+				if call.Dir == "." || call.Dir == dir {
+					t.Errorf("expected mix to run in a throwaway project, ran in %q", call.Dir)
+				}
+			}
+		})
+	}
+}
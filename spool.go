@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+// defaultSpoolThreshold is how much output a command may produce before the
+// rest is spooled to disk. Native dependency builds can print hundreds of MB.
+const defaultSpoolThreshold = 8 << 20
+
+// spoolExcerpt is how much of the start and the end of spooled output is
+// kept in memory, enough for the package name and the final error.
+const spoolExcerpt = 64 << 10
+
+// spoolMarker separates the excerpts of spooled output and names the file
+// holding all of it.
+const spoolMarker = "\n[... %d bytes of output spooled to %s ...]\n"
+
+// spoolMarkerPattern finds the spool file named by spoolMarker.
+var spoolMarkerPattern = regexp.MustCompile(`\n\[\.\.\. \d+ bytes of output spooled to (.+) \.\.\.\]\n`)
+
+// spoolThresholdKey carries the spool threshold to the executor.
+type spoolThresholdKey struct{}
+
+// withSpoolThreshold returns a context carrying the spool threshold.
+func withSpoolThreshold(ctx context.Context, threshold int64) context.Context {
+	return context.WithValue(ctx, spoolThresholdKey{}, threshold)
+}
+
+// spoolThresholdFrom returns the spool threshold carried by ctx, or the
+// default. Zero or less disables spooling.
+func spoolThresholdFrom(ctx context.Context) int64 {
+	if threshold, ok := ctx.Value(spoolThresholdKey{}).(int64); ok {
+		return threshold
+	}
+	return defaultSpoolThreshold
+}
+
+// spoolWriter captures command output in memory until it exceeds the
+// threshold, then writes all of it to a temp file and keeps only the first
+// and last spoolExcerpt bytes in memory. Stdout and stderr share one
+// writer, which exec.Cmd never calls concurrently.
+type spoolWriter struct {
+	threshold int64
+	head      bytes.Buffer
+	tail      []byte
+	file      *os.File
+	total     int64
+}
+
+func (w *spoolWriter) Write(p []byte) (int, error) {
+	w.total += int64(len(p))
+	if w.file == nil {
+		if w.threshold <= 0 || int64(w.head.Len()+len(p)) <= w.threshold {
+			return w.head.Write(p)
+		}
+		f, err := os.CreateTemp("", "relicta-hex-output-*.log")
+		if err != nil {
+			// Keep capturing in memory rather than losing output
+			w.threshold = 0
+			return w.head.Write(p)
+		}
+		w.file = f
+		w.tail = bytes.Clone(w.head.Bytes())
+		if _, err := f.Write(w.head.Bytes()); err != nil {
+			return 0, err
+		}
+		w.head.Truncate(min(w.head.Len(), spoolExcerpt))
+	}
+
+	if _, err := w.file.Write(p); err != nil {
+		return 0, err
+	}
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > spoolExcerpt {
+		w.tail = append(w.tail[:0], w.tail[len(w.tail)-spoolExcerpt:]...)
+	}
+	return len(p), nil
+}
+
+// Bytes closes the spool file, if any, and returns the captured output: all
+// of it, or its excerpts around a marker naming the file.
+func (w *spoolWriter) Bytes() []byte {
+	if w.file == nil {
+		return w.head.Bytes()
+	}
+	_ = w.file.Close()
+	out := bytes.Clone(w.head.Bytes())
+	out = fmt.Appendf(out, spoolMarker, w.total, w.file.Name())
+	return append(out, w.tail...)
+}
+
+// spooledOutputPath returns the spool file named in output, if any.
+func spooledOutputPath(output []byte) string {
+	if m := spoolMarkerPattern.FindSubmatch(output); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// maskSpoolFile scrubs secrets from a spool file line by line, so the file
+// is as safe to share as the in-memory output.
+func (m *secretMasker) maskSpoolFile(path string) error {
+	if m == nil || len(m.values) == 0 {
+		return nil
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp("", "relicta-hex-output-*.log")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+
+	r := bufio.NewReader(in)
+	w := bufio.NewWriter(out)
+	for {
+		line, err := r.ReadString('\n')
+		if _, werr := w.WriteString(m.mask(line)); werr != nil {
+			out.Close()
+			return werr
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), path)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpoolWriter(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int64
+		chunks    []string
+		wantSpool bool
+	}{
+		{name: "under threshold", threshold: 100, chunks: []string{"Building my_lib 1.0.0\n", "done\n"}},
+		{name: "disabled", threshold: 0, chunks: []string{strings.Repeat("x", 200)}},
+		{name: "over threshold", threshold: 100, chunks: []string{"Building my_lib 1.0.0\n", strings.Repeat("compiling nif\n", 20), "** (Mix) failed\n"}, wantSpool: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &spoolWriter{threshold: tt.threshold}
+			var all bytes.Buffer
+			for _, chunk := range tt.chunks {
+				all.WriteString(chunk)
+				if _, err := w.Write([]byte(chunk)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := w.Bytes()
+
+			path := spooledOutputPath(got)
+			if (path != "") != tt.wantSpool {
+				t.Fatalf("spool file = %q, want spooled %v", path, tt.wantSpool)
+			}
+			if !tt.wantSpool {
+				if !bytes.Equal(got, all.Bytes()) {
+					t.Errorf("output = %q, want %q", got, all.Bytes())
+				}
+				return
+			}
+			defer os.Remove(path)
+
+			spooled, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(spooled, all.Bytes()) {
+				t.Errorf("spool file holds %d bytes, want all %d", len(spooled), all.Len())
+			}
+			if !strings.HasPrefix(string(got), "Building my_lib 1.0.0") || !strings.HasSuffix(string(got), "** (Mix) failed\n") {
+				t.Errorf("excerpts lost the start or end: %q", got)
+			}
+		})
+	}
+}
+
+func TestSpoolWriterKeepsExcerpts(t *testing.T) {
+	w := &spoolWriter{threshold: 1024}
+	line := []byte(strings.Repeat("y", 1023) + "\n")
+	for i := 0; i < 3*spoolExcerpt/len(line); i++ {
+		if _, err := w.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := w.Bytes()
+	defer os.Remove(spooledOutputPath(got))
+
+	if len(got) > 2*spoolExcerpt+200 {
+		t.Errorf("kept %d bytes in memory, want at most two excerpts", len(got))
+	}
+}
+
+func TestRealExecutorSpoolsAndMasks(t *testing.T) {
+	ctx := withSpoolThreshold(context.Background(), 64)
+	summary := &RunSummary{masker: &secretMasker{}}
+	summary.masker.add("s3cr3t-key")
+	p := &HexPlugin{executor: &RealCommandExecutor{}}
+
+	output, err := p.runCommand(ctx, summary, "sh", []string{"-c", `echo start; i=0; while [ $i -lt 20 ]; do echo "key s3cr3t-key line $i"; i=$((i+1)); done; echo end`}, nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := spooledOutputPath(output)
+	if path == "" {
+		t.Fatalf("expected spooled output, got %q", output)
+	}
+	defer os.Remove(path)
+
+	spooled, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(spooled), "s3cr3t-key") || strings.Count(string(spooled), "key *** line") != 20 {
+		t.Errorf("spool file not masked: %q", spooled)
+	}
+	if strings.Contains(string(output), "s3cr3t-key") {
+		t.Errorf("output not masked: %q", output)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// standaloneFlag switches the binary into local debug mode instead of serving the plugin.
+const standaloneFlag = "--standalone"
+
+// standaloneInput is the request file format accepted by --input.
+type standaloneInput struct {
+	Hook    plugin.Hook           `json:"hook"`
+	DryRun  *bool                 `json:"dry_run"`
+	Config  map[string]any        `json:"config"`
+	Context plugin.ReleaseContext `json:"context"`
+}
+
+// standaloneResult is the JSON document printed by standalone mode.
+type standaloneResult struct {
+	Validate *plugin.ValidateResponse `json:"validate"`
+	Execute  *plugin.ExecuteResponse  `json:"execute,omitempty"`
+}
+
+// runStandalone validates and executes the plugin locally without a Relicta host.
+// It returns the process exit code.
+func runStandalone(ctx context.Context, p *HexPlugin, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("plugin-hex --standalone", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	inputPath := fs.String("input", "", "JSON file with hook, dry_run, config and context")
+	configPath := fs.String("config", "", "JSON file with the plugin configuration")
+	contextPath := fs.String("context", "", "JSON file with the release context")
+	hook := fs.String("hook", string(plugin.HookPostPublish), "hook to execute")
+	version := fs.String("version", "", "release version (overrides the context file)")
+	dryRun := fs.Bool("dry-run", true, "run in dry-run mode; pass -dry-run=false to really publish")
+	validateOnly := fs.Bool("validate-only", false, "only validate the configuration")
+	operation := fs.String("operation", "", "run an operation instead of the hook ("+operationRotateKey+")")
+	plain := fs.Bool("plain", false, "print the human-readable summary without color")
+	jsonOutput := fs.Bool("json", false, "print the JSON result even when stdout is a terminal")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	input := standaloneInput{Hook: plugin.Hook(*hook)}
+
+	if *inputPath != "" {
+		if err := readJSONFile(*inputPath, &input); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		if input.Hook == "" {
+			input.Hook = plugin.Hook(*hook)
+		}
+	}
+	if *configPath != "" {
+		if err := readJSONFile(*configPath, &input.Config); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+	}
+	if *contextPath != "" {
+		if err := readJSONFile(*contextPath, &input.Context); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+	}
+	if *version != "" {
+		input.Context.Version = *version
+	}
+
+	// Explicit flags win over the input file; the file wins over the flag default
+	req := plugin.ExecuteRequest{
+		Hook:    input.Hook,
+		Config:  input.Config,
+		Context: input.Context,
+		DryRun:  *dryRun,
+	}
+	if input.DryRun != nil && !flagPassed(fs, "dry-run") {
+		req.DryRun = *input.DryRun
+	}
+
+	result := standaloneResult{}
+	code := 0
+
+	validation, err := p.Validate(ctx, req.Config)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: validate: %v\n", err)
+		return 1
+	}
+	result.Validate = validation
+	if !validation.Valid {
+		code = 1
+	}
+
+	if !*validateOnly && validation.Valid {
+		var resp *plugin.ExecuteResponse
+		var err error
+		if *operation != "" {
+			resp, err = p.runOperation(ctx, *operation, req)
+		} else {
+			resp, err = p.Execute(ctx, req)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "error: execute: %v\n", err)
+			return 1
+		}
+		result.Execute = resp
+		if !resp.Success {
+			code = 1
+		}
+	}
+
+	// People at a terminal get a summary; pipes and scripts keep the JSON
+	if !*jsonOutput && (*plain || writerIsTerminal(stdout)) {
+		renderTerminalSummary(stdout, result, req.DryRun, terminalStyle{color: !*plain && colorEnabled()})
+		return code
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	return code
+}
+
+// readJSONFile decodes a JSON file into v.
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// flagPassed reports whether the named flag was set on the command line.
+func flagPassed(fs *flag.FlagSet, name string) bool {
+	passed := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
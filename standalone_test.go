@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunStandalone(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.json")
+	writeFile(t, inputPath, `{
+  "hook": "post-publish",
+  "config": {"api_key": "test-key", "organization": "acme"},
+  "context": {"version": "v1.2.0", "tag_name": "v1.2.0"}
+}`)
+	invalidPath := filepath.Join(dir, "invalid.json")
+	writeFile(t, invalidPath, `{"work_dir": "../outside"}`)
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectCode   int
+		expectStdout []string
+		expectStderr string
+		verifyCalls  func(t *testing.T, calls []MockCall)
+	}{
+		{
+			name:         "dry run from input file",
+			args:         []string{"-input", inputPath},
+			expectCode:   0,
+			expectStdout: []string{`"valid": true`, `"command": "mix hex.publish --organization acme --yes"`, `"version": "1.2.0"`},
+			verifyCalls: func(t *testing.T, calls []MockCall) {
+				if len(calls) != 0 {
+					t.Errorf("dry run must not execute commands, got %d calls", len(calls))
+				}
+			},
+		},
+		{
+			name:         "real run when dry run is disabled",
+			args:         []string{"-input", inputPath, "-dry-run=false", "-version", "1.3.0"},
+			expectCode:   0,
+			expectStdout: []string{`"message": "Published package v1.3.0 to Hex.pm"`},
+			verifyCalls: func(t *testing.T, calls []MockCall) {
+				if len(calls) != 1 {
+					t.Errorf("expected 1 call, got %d", len(calls))
+				}
+			},
+		},
+		{
+			name:         "invalid config fails validation",
+			args:         []string{"-config", invalidPath},
+			expectCode:   1,
+			expectStdout: []string{`"valid": false`, `"field": "work_dir"`},
+		},
+		{
+			name:         "validate only skips execute",
+			args:         []string{"-input", inputPath, "-validate-only"},
+			expectCode:   0,
+			expectStdout: []string{`"valid": true`},
+		},
+		{
+			name:         "missing input file is a usage error",
+			args:         []string{"-input", filepath.Join(dir, "missing.json")},
+			expectCode:   2,
+			expectStderr: "failed to read",
+		},
+		{
+			name:       "unknown flag is a usage error",
+			args:       []string{"-bogus"},
+			expectCode: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{}
+			var stdout, stderr bytes.Buffer
+
+			code := runStandalone(context.Background(), &HexPlugin{executor: mock}, tt.args, &stdout, &stderr)
+
+			if code != tt.expectCode {
+				t.Errorf("exit code: got %d, expected %d (stderr: %s)", code, tt.expectCode, stderr.String())
+			}
+			for _, want := range tt.expectStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout missing %q:\n%s", want, stdout.String())
+				}
+			}
+			if tt.expectStderr != "" && !strings.Contains(stderr.String(), tt.expectStderr) {
+				t.Errorf("stderr: expected to contain %q, got %q", tt.expectStderr, stderr.String())
+			}
+			if tt.expectCode != 2 && !json.Valid(stdout.Bytes()) {
+				t.Errorf("stdout is not valid JSON:\n%s", stdout.String())
+			}
+			if tt.verifyCalls != nil {
+				tt.verifyCalls(t, mock.Calls)
+			}
+		})
+	}
+}
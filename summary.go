@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// RunSummary is the machine-readable record of everything the plugin did
+// during a single execution. It is written to summary_path when configured.
+type RunSummary struct {
+	Plugin         string            `json:"plugin"`
+	PluginVersion  string            `json:"plugin_version"`
+	Hook           string            `json:"hook"`
+	DryRun         bool              `json:"dry_run"`
+	Package        string            `json:"package,omitempty"`
+	ReleaseVersion string            `json:"release_version,omitempty"`
+	SecretSource   string            `json:"secret_source,omitempty"`
+	Success        bool              `json:"success"`
+	Message        string            `json:"message,omitempty"`
+	StartedAt      time.Time         `json:"started_at"`
+	FinishedAt     time.Time         `json:"finished_at"`
+	DurationMs     int64             `json:"duration_ms"`
+	Gates          []GateResult      `json:"gates"`
+	Commands       []CommandRecord   `json:"commands"`
+	Artifacts      []plugin.Artifact `json:"artifacts"`
+	URLs           map[string]string `json:"urls"`
+	Errors         []string          `json:"errors"`
+	// Decisions is the debug log of choices made during the run.
+	Decisions []string `json:"decisions,omitempty"`
+	// CI identifies the CI run that executed the plugin.
+	CI *CIInfo `json:"ci,omitempty"`
+	// Events is the publish timeline.
+	Events []PublishEvent `json:"events,omitempty"`
+
+	// verbosity selects whether decisions are recorded.
+	verbosity string
+	// transcripts keeps command output for the transcript archive.
+	transcripts bool
+	transcript  []TranscriptEntry
+	// logger emits decisions as they are made on hosts with structured
+	// logging.
+	logger *slog.Logger
+	// events receives lifecycle events as they happen on hosts with events.
+	events io.Writer
+	// masker scrubs secrets from everything the run captures.
+	masker *secretMasker
+}
+
+// CommandRecord describes a single command executed by the plugin.
+type CommandRecord struct {
+	Command    string   `json:"command"`
+	Argv       []string `json:"argv"`
+	Dir        string   `json:"dir,omitempty"`
+	Env        []string `json:"env,omitempty"`
+	ExitCode   int      `json:"exit_code"`
+	DurationMs int64    `json:"duration_ms"`
+	Success    bool     `json:"success"`
+	Error      string   `json:"error,omitempty"`
+	// TerminatedBy is the signal that ended a cancelled command.
+	TerminatedBy string `json:"terminated_by,omitempty"`
+}
+
+// secretEnvMarkers identify environment variables whose values are redacted.
+var secretEnvMarkers = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "PASSPHRASE", "CREDENTIAL"}
+
+// redactEnv returns the env entries with secret values replaced, so the
+// variables a command received can be reported without leaking them.
+func redactEnv(env []string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	redacted := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		upper := strings.ToUpper(name)
+		for _, marker := range secretEnvMarkers {
+			if strings.Contains(upper, marker) {
+				kv = name + "=<redacted>"
+				break
+			}
+		}
+		redacted = append(redacted, kv)
+	}
+	return redacted
+}
+
+// newRunSummary starts a summary for the given request.
+func newRunSummary(info plugin.Info, req plugin.ExecuteRequest) *RunSummary {
+	return &RunSummary{
+		Plugin:         info.Name,
+		PluginVersion:  info.Version,
+		Hook:           string(req.Hook),
+		DryRun:         req.DryRun,
+		ReleaseVersion: strings.TrimPrefix(req.Context.Version, "v"),
+		StartedAt:      time.Now().UTC(),
+		Gates:          []GateResult{},
+		Commands:       []CommandRecord{},
+		Artifacts:      []plugin.Artifact{},
+		URLs:           map[string]string{},
+		Errors:         []string{},
+		CI:             detectCI(os.Getenv),
+	}
+}
+
+// addURL records a URL produced by the run. Nil summaries are ignored.
+func (s *RunSummary) addURL(name, url string) {
+	if s == nil || url == "" {
+		return
+	}
+	s.URLs[name] = url
+}
+
+// addArtifact records an artifact produced by the run. Nil summaries are ignored.
+func (s *RunSummary) addArtifact(artifact plugin.Artifact) {
+	if s == nil {
+		return
+	}
+	s.Artifacts = append(s.Artifacts, artifact)
+}
+
+// finish records the final outcome of the run.
+func (s *RunSummary) finish(resp *plugin.ExecuteResponse) {
+	s.FinishedAt = time.Now().UTC()
+	s.DurationMs = s.FinishedAt.Sub(s.StartedAt).Milliseconds()
+	s.Success = resp.Success
+	s.Message = resp.Message
+	if resp.Error != "" {
+		s.Errors = append(s.Errors, resp.Error)
+	}
+}
+
+// encode renders the summary as indented JSON.
+func (s *RunSummary) encode() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode summary: %w", err)
+	}
+	return data, nil
+}
+
+// write stores the summary as indented JSON at path, masking any known
+// secret it still holds.
+func (s *RunSummary) write(path string) error {
+	data, err := s.encode()
+	if err != nil {
+		return err
+	}
+	data = s.masker.maskJSON(data)
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create summary directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
+
+// runCommand executes a command through the configured executor and records it in the summary.
+func (p *HexPlugin) runCommand(ctx context.Context, summary *RunSummary, name string, args []string, env []string, dir string) ([]byte, error) {
+	start := time.Now()
+	output, err := p.getExecutor().Run(ctx, name, args, env, dir)
+	output, err = summary.maskResult(sanitizeOutput(output), err)
+	summary.recordCommand(name, args, env, dir, start, err)
+	summary.recordTranscript(output, stdinNone, start)
+	return output, err
+}
+
+// maskResult scrubs secrets from a command's captured output and error.
+func (s *RunSummary) maskResult(output []byte, err error) ([]byte, error) {
+	if s == nil || s.masker == nil {
+		return output, err
+	}
+	if path := spooledOutputPath(output); path != "" {
+		if merr := s.masker.maskSpoolFile(path); merr != nil {
+			s.debugf("failed to mask spooled output %s: %v", path, merr)
+		}
+	}
+	return []byte(s.masker.mask(string(output))), s.masker.maskError(err)
+}
+
+// recordCommand appends a finished command to the summary.
+func (s *RunSummary) recordCommand(name string, args []string, env []string, dir string, start time.Time, err error) {
+	if s == nil {
+		return
+	}
+	record := CommandRecord{
+		Command:    strings.TrimSpace(name + " " + strings.Join(args, " ")),
+		Argv:       append([]string{name}, args...),
+		Dir:        dir,
+		Env:        s.masker.maskAll(redactEnv(env)),
+		ExitCode:   exitCodeOf(err),
+		DurationMs: time.Since(start).Milliseconds(),
+		Success:    err == nil,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	var terminated *TerminatedError
+	if errors.As(err, &terminated) {
+		record.TerminatedBy = terminated.Signal
+	}
+	s.Commands = append(s.Commands, record)
+}
+
+// publishBuildingPattern matches the "Building <name> <version>" line printed by mix hex.publish.
+var publishBuildingPattern = regexp.MustCompile(`(?m)^Building (\S+) (\S+)`)
+
+// parsePublishedPackage extracts the package name from mix hex.publish output.
+func parsePublishedPackage(output string) string {
+	if m := publishBuildingPattern.FindStringSubmatch(output); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// packageURL returns the hex.pm page of a published package version.
+func packageURL(organization, name, version string) string {
+	if organization != "" {
+		return fmt.Sprintf("https://hex.pm/packages/%s/%s/%s", organization, name, version)
+	}
+	return fmt.Sprintf("https://hex.pm/packages/%s/%s", name, version)
+}
+
+// hexdocsURL returns the hexdocs.pm location of a public package version.
+func hexdocsURL(name, version string) string {
+	return fmt.Sprintf("https://hexdocs.pm/%s/%s", name, version)
+}
+
+// exitCoder is implemented by errors carrying a process exit code.
+type exitCoder interface {
+	ExitCode() int
+}
+
+// exitCodeOf returns the exit code carried by err, 0 for nil and -1 when unknown.
+func exitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ec exitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return -1
+}
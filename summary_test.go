@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParsePublishedPackage(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "building line",
+			output:   "Building my_package 1.0.0\n  Dependencies:\n",
+			expected: "my_package",
+		},
+		{
+			name:     "no building line",
+			output:   "Published my_package v1.0.0",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePublishedPackage(tt.output); got != tt.expected {
+				t.Errorf("got %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	if got := packageURL("", "my_lib", "1.0.0"); got != "https://hex.pm/packages/my_lib/1.0.0" {
+		t.Errorf("public url: got %q", got)
+	}
+	if got := packageURL("acme", "my_lib", "1.0.0"); got != "https://hex.pm/packages/acme/my_lib/1.0.0" {
+		t.Errorf("organization url: got %q", got)
+	}
+}
+
+func TestExecuteWritesSummary(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockOutput    []byte
+		mockError     error
+		expectSuccess bool
+		verify        func(t *testing.T, s RunSummary)
+	}{
+		{
+			name:          "successful publish",
+			mockOutput:    []byte("Building my_lib 1.0.0\nPackage published to https://hex.pm/packages/my_lib/1.0.0"),
+			expectSuccess: true,
+			verify: func(t *testing.T, s RunSummary) {
+				if s.Package != "my_lib" {
+					t.Errorf("package: got %q", s.Package)
+				}
+				if s.URLs["package"] != "https://hex.pm/packages/my_lib/1.0.0" {
+					t.Errorf("package url: got %q", s.URLs["package"])
+				}
+				if s.URLs["docs"] != "https://hexdocs.pm/my_lib/1.0.0" {
+					t.Errorf("docs url: got %q", s.URLs["docs"])
+				}
+				if len(s.Errors) != 0 {
+					t.Errorf("expected no errors, got %v", s.Errors)
+				}
+			},
+		},
+		{
+			name:          "failed publish",
+			mockOutput:    []byte("** (Mix) Could not find package"),
+			mockError:     errors.New("exit status 1"),
+			expectSuccess: false,
+			verify: func(t *testing.T, s RunSummary) {
+				if len(s.Errors) != 1 {
+					t.Errorf("expected 1 error, got %v", s.Errors)
+				}
+				if len(s.Commands) != 1 || s.Commands[0].Success {
+					t.Errorf("expected failed command record, got %+v", s.Commands)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return tt.mockOutput, tt.mockError
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"api_key":      "test-key",
+					"summary_path": "out/summary.json",
+				},
+				Context: plugin.ReleaseContext{Version: "v1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectSuccess {
+				t.Fatalf("success: got %v, expected %v", resp.Success, tt.expectSuccess)
+			}
+			if resp.Outputs["summary_path"] != "out/summary.json" {
+				t.Errorf("summary_path output: got %v", resp.Outputs["summary_path"])
+			}
+
+			data, err := os.ReadFile(filepath.Join("out", "summary.json"))
+			if err != nil {
+				t.Fatalf("read summary: %v", err)
+			}
+
+			var s RunSummary
+			if err := json.Unmarshal(data, &s); err != nil {
+				t.Fatalf("decode summary: %v", err)
+			}
+
+			if s.Hook != string(plugin.HookPostPublish) || s.ReleaseVersion != "1.0.0" {
+				t.Errorf("unexpected header: hook=%q version=%q", s.Hook, s.ReleaseVersion)
+			}
+			if s.Success != tt.expectSuccess {
+				t.Errorf("summary success: got %v", s.Success)
+			}
+			if len(s.Commands) != 1 || s.Commands[0].Command != "mix hex.publish --yes" {
+				t.Errorf("unexpected commands: %+v", s.Commands)
+			}
+			tt.verify(t, s)
+		})
+	}
+
+	t.Run("invalid summary path is reported", func(t *testing.T) {
+		p := &HexPlugin{executor: &MockCommandExecutor{}}
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			DryRun:  true,
+			Config:  map[string]any{"summary_path": "../summary.json"},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := resp.Outputs["summary_error"]; !ok {
+			t.Error("expected summary_error output")
+		}
+	})
+}
+
+func TestRedactEnv(t *testing.T) {
+	got := redactEnv([]string{"HEX_API_KEY=secret", "MIX_ENV=test", "HEX_DOCS_UPLOAD_TOKEN=t0k3n", "EMPTY"})
+	want := []string{"HEX_API_KEY=<redacted>", "MIX_ENV=test", "HEX_DOCS_UPLOAD_TOKEN=<redacted>", "EMPTY"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+	if redactEnv(nil) != nil {
+		t.Error("expected nil for empty env")
+	}
+}
+
+func TestExecuteCommandTranscript(t *testing.T) {
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("** (Mix) Stale package"), &ReplayedError{Message: "exit status 1", Code: 1}
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "super-secret", "work_dir": "apps/core"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Success {
+		t.Fatal("expected failure")
+	}
+
+	if resp.Outputs["exit_code"] != 1 {
+		t.Errorf("exit_code: got %v", resp.Outputs["exit_code"])
+	}
+	if argv := resp.Outputs["argv"].([]string); strings.Join(argv, " ") != "mix hex.publish --yes" {
+		t.Errorf("argv: got %v", argv)
+	}
+	if resp.Outputs["work_dir"] != "apps/core" {
+		t.Errorf("work_dir: got %v", resp.Outputs["work_dir"])
+	}
+
+	transcript, ok := resp.Outputs["transcript"].([]CommandRecord)
+	if !ok || len(transcript) != 1 || transcript[0].ExitCode != 1 || transcript[0].Dir != "apps/core" {
+		t.Fatalf("unexpected transcript: %+v", resp.Outputs["transcript"])
+	}
+	for _, kv := range transcript[0].Env {
+		if strings.Contains(kv, "super-secret") {
+			t.Errorf("transcript leaked the api key: %v", transcript[0].Env)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// tarballContents is the gzipped archive of package files inside a Hex tarball.
+const tarballContents = "contents.tar.gz"
+
+// ManifestEntry is a file shipped in the package tarball.
+type ManifestEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// readTarballManifest lists the files in a Hex package tarball.
+func readTarballManifest(path string) ([]ManifestEntry, error) {
+	manifest := []ManifestEntry{}
+	err := walkTarballContents(path, func(hdr *tar.Header, _ io.Reader) error {
+		manifest = append(manifest, ManifestEntry{Path: hdr.Name, Size: hdr.Size})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// walkTarballContents calls fn for each regular file in a Hex package
+// tarball. The outer tar holds VERSION, CHECKSUM, metadata.config and
+// contents.tar.gz; the package files are in the latter.
+func walkTarballContents(path string, fn func(hdr *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	outer := tar.NewReader(f)
+	for {
+		hdr, err := outer.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s not found in package tarball", tarballContents)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read package tarball: %w", err)
+		}
+		if hdr.Name == tarballContents {
+			return walkContents(outer, fn)
+		}
+	}
+}
+
+// walkContents calls fn for each regular file in contents.tar.gz.
+func walkContents(r io.Reader, fn func(hdr *tar.Header, r io.Reader) error) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", tarballContents, err)
+	}
+	defer gz.Close()
+
+	contents := tar.NewReader(gz)
+	for {
+		hdr, err := contents.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", tarballContents, err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if err := fn(hdr, contents); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// packageManifest builds the package with mix hex.build and lists the files
+// in the resulting tarball, so reviewers can confirm what ships.
+func (p *HexPlugin) packageManifest(ctx context.Context, cfg *Config, summary *RunSummary) ([]ManifestEntry, error) {
+	dir, err := os.MkdirTemp("", "relicta-hex-build-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tarball := filepath.Join(dir, "package.tar")
+	if err := p.buildPackage(ctx, cfg, tarball, summary); err != nil {
+		return nil, err
+	}
+	return readTarballManifest(tarball)
+}
+
+// buildPackage builds the package tarball with mix hex.build.
+func (p *HexPlugin) buildPackage(ctx context.Context, cfg *Config, dest string, summary *RunSummary) error {
+	output, err := p.runMix(ctx, cfg, summary, []string{"hex.build", "--output", dest}, nil)
+	if err != nil {
+		return fmt.Errorf("mix hex.build failed: %v\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// addPackageManifest records the package manifest in the outputs. Failures
+// never block the publish; they are reported as manifest_error.
+func (p *HexPlugin) addPackageManifest(ctx context.Context, cfg *Config, outputs map[string]any, summary *RunSummary) {
+	manifest, err := p.packageManifest(ctx, cfg, summary)
+	if err != nil {
+		outputs["manifest_error"] = err.Error()
+		return
+	}
+	var total int64
+	for _, entry := range manifest {
+		total += entry.Size
+	}
+	outputs["manifest"] = manifest
+	outputs["manifest_size"] = total
+}
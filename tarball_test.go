@@ -0,0 +1,132 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// writeTestTarball writes a Hex package tarball containing the given files.
+func writeTestTarball(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	var contents bytes.Buffer
+	gz := gzip.NewWriter(&contents)
+	inner := tar.NewWriter(gz)
+	if err := inner.WriteHeader(&tar.Header{Name: "lib", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		body := files[name]
+		if err := inner.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := inner.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := inner.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var outer bytes.Buffer
+	w := tar.NewWriter(&outer)
+	for _, entry := range []struct {
+		name string
+		body []byte
+	}{
+		{"VERSION", []byte("3")},
+		{"metadata.config", []byte(`{<<"name">>,<<"my_lib">>}.`)},
+		{tarballContents, contents.Bytes()},
+	} {
+		if err := w.WriteHeader(&tar.Header{Name: entry.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(entry.body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(entry.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, outer.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadTarballManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.tar")
+	writeTestTarball(t, path, map[string]string{"lib/my_lib.ex": "defmodule MyLib do\nend\n", "mix.exs": "mix"})
+
+	manifest, err := readTarballManifest(path)
+	if err != nil {
+		t.Fatalf("readTarballManifest() error = %v", err)
+	}
+	want := []ManifestEntry{{Path: "lib/my_lib.ex", Size: 23}, {Path: "mix.exs", Size: 3}}
+	if !reflect.DeepEqual(manifest, want) {
+		t.Errorf("manifest = %+v, want %+v", manifest, want)
+	}
+
+	t.Run("missing contents", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "empty.tar")
+		var buf bytes.Buffer
+		_ = tar.NewWriter(&buf).Close()
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readTarballManifest(path); err == nil {
+			t.Error("expected error for a tarball without contents")
+		}
+	})
+}
+
+func TestExecutePackageManifest(t *testing.T) {
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			if args[0] == "hex.build" {
+				writeTestTarball(t, args[len(args)-1], map[string]string{"mix.exs": "mix"})
+				return []byte("Saved to package.tar"), nil
+			}
+			return []byte("Building my_lib 1.0.0"), nil
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "package_manifest": true},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+	if len(mock.Calls) != 2 || mock.Calls[0].Args[0] != "hex.build" {
+		t.Fatalf("expected hex.build before hex.publish, got %+v", mock.Calls)
+	}
+	manifest, _ := resp.Outputs["manifest"].([]ManifestEntry)
+	if !reflect.DeepEqual(manifest, []ManifestEntry{{Path: "mix.exs", Size: 3}}) {
+		t.Errorf("unexpected manifest output: %+v", resp.Outputs["manifest"])
+	}
+	if resp.Outputs["manifest_size"] != int64(3) {
+		t.Errorf("expected manifest_size 3, got %v", resp.Outputs["manifest_size"])
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// mixTaskPattern matches mix task names such as "assets.build" or "my_app.announce".
+var mixTaskPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// defaultPublishTask is the mix task publishing the package.
+const defaultPublishTask = "hex.publish"
+
+// forbiddenTasks are publishing tasks the plugin already runs; running them
+// again from a hook would publish twice.
+var forbiddenTasks = []string{"hex.publish"}
+
+// parseTasks reads a list of mix task command lines, e.g. "assets.build --minify".
+func parseTasks(raw map[string]any, key string) [][]string {
+	var tasks [][]string
+	for _, line := range helpers.NewConfigParser(raw).GetStringSlice(key, nil) {
+		tasks = append(tasks, strings.Fields(line))
+	}
+	return tasks
+}
+
+// validateTasks checks task names and arguments of a task list.
+func validateTasks(key string, tasks [][]string) *fieldError {
+	for i, task := range tasks {
+		field := fmt.Sprintf("%s[%d]", key, i)
+		if len(task) == 0 {
+			return &fieldError{Field: field, Err: fmt.Errorf("must name a mix task")}
+		}
+		if !mixTaskPattern.MatchString(task[0]) {
+			return &fieldError{Field: field, Err: fmt.Errorf("invalid mix task name %q", task[0])}
+		}
+		for _, forbidden := range forbiddenTasks {
+			if task[0] == forbidden {
+				return &fieldError{Field: field, Err: fmt.Errorf("%s is run by the plugin and cannot be a hook task", forbidden)}
+			}
+		}
+		for _, arg := range task[1:] {
+			if strings.ContainsAny(arg, "\x00\r\n") {
+				return &fieldError{Field: field, Err: fmt.Errorf("arguments must not contain control characters")}
+			}
+		}
+	}
+	return nil
+}
+
+// validatePublishTask checks the task option, which names the mix task or
+// alias run in place of hex.publish. The alias receives the hex.publish
+// arguments, so it must pass them on to hex.publish.
+func validatePublishTask(task string) *fieldError {
+	if !mixTaskPattern.MatchString(task) {
+		return &fieldError{Field: "task", Err: fmt.Errorf("invalid mix task name %q", task)}
+	}
+	return nil
+}
+
+// taskDisplay returns the mix command lines of a task list.
+func (c *Config) taskDisplay(tasks [][]string) []string {
+	lines := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		lines = append(lines, c.mixDisplay(task...))
+	}
+	return lines
+}
+
+// runTasks runs each mix task in order, stopping at the first failure.
+func (p *HexPlugin) runTasks(ctx context.Context, cfg *Config, tasks [][]string, summary *RunSummary) error {
+	for _, task := range tasks {
+		output, err := p.runMix(ctx, cfg, summary, task, nil)
+		if err != nil {
+			return fmt.Errorf("mix %s failed: %v\nOutput: %s", strings.Join(task, " "), err, string(output))
+		}
+	}
+	return nil
+}
+
+// runPostPublishTasks runs post_publish_tasks after a successful publish,
+// turning a task failure into a failed response that keeps the outputs.
+func (p *HexPlugin) runPostPublishTasks(ctx context.Context, cfg *Config, version string, resp *plugin.ExecuteResponse, summary *RunSummary) *plugin.ExecuteResponse {
+	if !resp.Success || len(cfg.PostPublishTasks) == 0 {
+		return resp
+	}
+	if err := p.runTasks(ctx, cfg, cfg.PostPublishTasks, summary); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("package v%s published but post_publish_tasks failed: %v", version, err),
+			Outputs: resp.Outputs,
+		}
+	}
+	return resp
+}
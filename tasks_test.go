@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateTasks(t *testing.T) {
+	tests := []struct {
+		name    string
+		tasks   [][]string
+		wantErr string
+	}{
+		{name: "valid tasks", tasks: [][]string{{"assets.build"}, {"my_app.announce", "--channel", "releases"}}},
+		{name: "empty task", tasks: [][]string{{}}, wantErr: "must name a mix task"},
+		{name: "invalid name", tasks: [][]string{{"rm -rf"}}, wantErr: "invalid mix task name"},
+		{name: "shell syntax", tasks: [][]string{{"assets.build;"}}, wantErr: "invalid mix task name"},
+		{name: "publish task", tasks: [][]string{{"hex.publish"}}, wantErr: "cannot be a hook task"},
+		{name: "control characters", tasks: [][]string{{"assets.build", "a\nb"}}, wantErr: "control characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTasks("pre_publish_tasks", tt.tasks)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExecutePublishTasks(t *testing.T) {
+	tests := []struct {
+		name            string
+		failTask        string
+		expectedSuccess bool
+		expectedError   string
+		expectedTasks   []string
+	}{
+		{
+			name:            "tasks run around publish",
+			expectedSuccess: true,
+			expectedTasks:   []string{"assets.build", "hex.publish", "my_app.announce"},
+		},
+		{
+			name:          "pre task failure blocks publish",
+			failTask:      "assets.build",
+			expectedError: "pre_publish_tasks failed",
+			expectedTasks: []string{"assets.build"},
+		},
+		{
+			name:          "post task failure reported after publish",
+			failTask:      "my_app.announce",
+			expectedError: "published but post_publish_tasks failed",
+			expectedTasks: []string{"assets.build", "hex.publish", "my_app.announce"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if args[0] == tt.failTask {
+						return []byte("boom"), errors.New("exit status 1")
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"api_key":            "test-api-key",
+					"pre_publish_tasks":  []any{"assets.build"},
+					"post_publish_tasks": []any{"my_app.announce --channel releases"},
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if tt.expectedError != "" && !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, resp.Error)
+			}
+
+			var tasks []string
+			for _, call := range mock.Calls {
+				tasks = append(tasks, call.Args[0])
+			}
+			if strings.Join(tasks, ",") != strings.Join(tt.expectedTasks, ",") {
+				t.Errorf("ran %v, want %v", tasks, tt.expectedTasks)
+			}
+		})
+	}
+}
+
+func TestExecutePublishTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]any
+		dryRun      bool
+		wantSuccess bool
+		wantArgs    []string
+		wantError   string
+	}{
+		{name: "default", config: map[string]any{}, wantSuccess: true, wantArgs: []string{"hex.publish", "--yes"}},
+		{name: "alias", config: map[string]any{"task": "release", "organization": "acme"}, wantSuccess: true, wantArgs: []string{"release", "--organization", "acme", "--yes"}},
+		{name: "alias dry run", config: map[string]any{"task": "release"}, dryRun: true, wantSuccess: true},
+		{name: "invalid name", config: map[string]any{"task": "release; rm -rf"}, wantError: `invalid task: invalid mix task name "release; rm -rf"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte("Building my_lib 1.0.0\nPackage published to https://hex.pm/packages/my_lib/1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				DryRun:  tt.dryRun,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error %q)", resp.Success, tt.wantSuccess, resp.Error)
+			}
+			if !tt.wantSuccess {
+				if resp.Error != tt.wantError {
+					t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+				}
+				return
+			}
+			if tt.dryRun {
+				if cmd := resp.Outputs["command"].(string); !strings.HasPrefix(cmd, "mix release") {
+					t.Errorf("dry run command = %q, want the alias", cmd)
+				}
+				return
+			}
+			if len(mock.Calls) != 1 || !reflect.DeepEqual(mock.Calls[0].Args, tt.wantArgs) {
+				t.Fatalf("calls = %+v, want mix %v", mock.Calls, tt.wantArgs)
+			}
+			if resp.Outputs["package"] != "my_lib" {
+				t.Errorf("package = %v, want the package parsed from the alias output", resp.Outputs["package"])
+			}
+		})
+	}
+}
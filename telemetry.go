@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// telemetryTimeout bounds the metrics request so it never delays a release.
+const telemetryTimeout = 5 * time.Second
+
+// TelemetryEvent is the anonymized usage report of a single run. It carries
+// no package names, organizations, URLs, paths or command output.
+type TelemetryEvent struct {
+	Plugin        string `json:"plugin"`
+	PluginVersion string `json:"plugin_version"`
+	Hook          string `json:"hook"`
+	DryRun        bool   `json:"dry_run"`
+	Success       bool   `json:"success"`
+	DurationMs    int64  `json:"duration_ms"`
+	ErrorClass    string `json:"error_class,omitempty"`
+	Attempts      int    `json:"attempts,omitempty"`
+	Commands      int    `json:"commands"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+}
+
+// validateHTTPURL checks that endpoint is an http(s) URL, as required of
+// telemetry_endpoint and freeze_url.
+func validateHTTPURL(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	return nil
+}
+
+// newTelemetryEvent builds the usage report of a finished run.
+func newTelemetryEvent(summary *RunSummary, resp *plugin.ExecuteResponse) TelemetryEvent {
+	event := TelemetryEvent{
+		Plugin:        summary.Plugin,
+		PluginVersion: summary.PluginVersion,
+		Hook:          summary.Hook,
+		DryRun:        summary.DryRun,
+		Success:       resp.Success,
+		DurationMs:    summary.DurationMs,
+		Commands:      len(summary.Commands),
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+	}
+	if class, ok := resp.Outputs["error_class"].(string); ok {
+		event.ErrorClass = class
+	}
+	if attempts, ok := resp.Outputs["attempts"].(int); ok {
+		event.Attempts = attempts
+	}
+	return event
+}
+
+// sendTelemetry posts the usage report to telemetry_endpoint.
+func (p *HexPlugin) sendTelemetry(ctx context.Context, endpoint string, event TelemetryEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, telemetryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.getHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
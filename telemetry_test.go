@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateTelemetryEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  bool
+	}{
+		{"https://metrics.example.com/v1/events", false},
+		{"http://localhost:8080", false},
+		{"", true},
+		{"metrics.example.com", true},
+		{"ftp://metrics.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			err := validateHTTPURL(tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateHTTPURL(%q) error = %v, wantErr %v", tt.endpoint, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteTelemetry(t *testing.T) {
+	var events []TelemetryEvent
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Errorf("invalid telemetry body: %v", err)
+		}
+		var event TelemetryEvent
+		_ = json.Unmarshal(raw, &event)
+		events = append(events, event)
+		bodies = append(bodies, string(raw))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("mock output"), &ReplayedError{Message: "exit status 1", Code: 1}
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"api_key":            "test-api-key",
+			"organization":       "secret-org",
+			"telemetry":          true,
+			"telemetry_endpoint": server.URL,
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Success {
+		t.Fatal("expected the publish to fail")
+	}
+	if _, ok := resp.Outputs["telemetry_error"]; ok {
+		t.Errorf("unexpected telemetry_error: %v", resp.Outputs["telemetry_error"])
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 telemetry event, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.Hook != string(plugin.HookPostPublish) || event.Success || event.Commands == 0 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if event.ErrorClass != resp.Outputs["error_class"] {
+		t.Errorf("expected error class %v, got %q", resp.Outputs["error_class"], event.ErrorClass)
+	}
+	for _, secret := range []string{"secret-org", "test-api-key", "mock output"} {
+		if strings.Contains(bodies[0], secret) {
+			t.Errorf("telemetry must be anonymous, found %q in %s", secret, bodies[0])
+		}
+	}
+}
+
+func TestExecuteTelemetryFailureDoesNotFailRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		endpoint string
+		wantErr  string
+	}{
+		{"endpoint error", server.URL, "returned 503"},
+		{"invalid endpoint", "not-a-url", "invalid telemetry_endpoint"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &HexPlugin{executor: &MockCommandExecutor{}}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"api_key":            "test-api-key",
+					"telemetry":          true,
+					"telemetry_endpoint": tt.endpoint,
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			if got, _ := resp.Outputs["telemetry_error"].(string); !strings.Contains(got, tt.wantErr) {
+				t.Errorf("expected telemetry_error containing %q, got %q", tt.wantErr, got)
+			}
+		})
+	}
+}
+
+func TestValidateTelemetry(t *testing.T) {
+	p := &HexPlugin{}
+
+	resp, err := p.Validate(context.Background(), map[string]any{"telemetry": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Valid {
+		t.Error("expected telemetry without an endpoint to be rejected")
+	}
+
+	resp, _ = p.Validate(context.Background(), map[string]any{"telemetry_endpoint": "not-a-url"})
+	if !resp.Valid {
+		t.Errorf("expected the endpoint to be ignored while telemetry is off, got %+v", resp.Errors)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ANSI escapes used by the terminal summary.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// writerIsTerminal reports whether w writes to a terminal.
+func writerIsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// colorEnabled reports whether the terminal summary may use color, which
+// NO_COLOR (https://no-color.org) and TERM=dumb turn off.
+func colorEnabled() bool {
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+}
+
+// terminalStyle colors text when color is enabled.
+type terminalStyle struct {
+	color bool
+}
+
+func (s terminalStyle) paint(code, text string) string {
+	if !s.color {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// status renders a pass/fail marker.
+func (s terminalStyle) status(ok bool, passed, failed string) string {
+	if ok {
+		return s.paint(ansiGreen, passed)
+	}
+	return s.paint(ansiRed, failed)
+}
+
+// writeTable writes rows with padded columns. Widths are computed on the
+// plain cells, so colored cells line up as well.
+func writeTable(w io.Writer, indent string, rows [][2]string, plain []string) {
+	width := 0
+	for _, cell := range plain {
+		width = max(width, len(cell))
+	}
+	for i, row := range rows {
+		fmt.Fprintf(w, "%s%s%s  %s\n", indent, row[0], strings.Repeat(" ", width-len(plain[i])), row[1])
+	}
+}
+
+// responseURLs collects the URLs reported in the outputs, keyed by output
+// name without the _url suffix.
+func responseURLs(outputs map[string]any) map[string]string {
+	urls := map[string]string{}
+	for key, val := range outputs {
+		if name, ok := strings.CutSuffix(key, "_url"); ok {
+			if url, ok := val.(string); ok && url != "" {
+				urls[name] = url
+			}
+		}
+	}
+	return urls
+}
+
+// renderTerminalSummary writes a human-readable summary of a standalone run:
+// the validation result, the run status, gates, artifacts and URLs.
+func renderTerminalSummary(w io.Writer, result standaloneResult, dryRun bool, style terminalStyle) {
+	if v := result.Validate; v != nil && !v.Valid {
+		fmt.Fprintln(w, style.paint(ansiBold+ansiRed, "✗ Invalid configuration"))
+		for _, e := range v.Errors {
+			fmt.Fprintf(w, "  %s: %s\n", style.paint(ansiBold, e.Field), e.Message)
+		}
+		return
+	}
+	resp := result.Execute
+	if resp == nil {
+		fmt.Fprintln(w, style.paint(ansiBold+ansiGreen, "✓ Configuration is valid"))
+		return
+	}
+
+	switch {
+	case !resp.Success:
+		fmt.Fprintln(w, style.paint(ansiBold+ansiRed, "✗ Failed"))
+	case dryRun:
+		fmt.Fprintln(w, style.paint(ansiBold+ansiYellow, "● Dry run"))
+	default:
+		fmt.Fprintln(w, style.paint(ansiBold+ansiGreen, "✓ Published"))
+	}
+	if resp.Message != "" {
+		fmt.Fprintf(w, "  %s\n", resp.Message)
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(w, "  %s\n", style.paint(ansiRed, resp.Error))
+	}
+
+	switch gates := resp.Outputs["gates"].(type) {
+	case []GateResult:
+		if len(gates) > 0 {
+			fmt.Fprintf(w, "\n%s\n", style.paint(ansiBold, "Gates"))
+			rows := make([][2]string, 0, len(gates))
+			plain := make([]string, 0, len(gates))
+			for _, g := range gates {
+				result := style.status(g.Success, "passed", "failed")
+				if !g.Success && g.AllowFailure {
+					result = style.paint(ansiYellow, "failed (allowed)")
+				}
+				rows = append(rows, [2]string{g.Name, fmt.Sprintf("%s  %ss", result, formatSeconds(g.DurationMs))})
+				plain = append(plain, g.Name)
+			}
+			writeTable(w, "  ", rows, plain)
+		}
+	case []string:
+		if len(gates) > 0 {
+			fmt.Fprintf(w, "\n%s\n  %s\n", style.paint(ansiBold, "Gates"), strings.Join(gates, ", "))
+		}
+	}
+
+	if len(resp.Artifacts) > 0 {
+		fmt.Fprintf(w, "\n%s\n", style.paint(ansiBold, "Artifacts"))
+		rows := make([][2]string, 0, len(resp.Artifacts))
+		plain := make([]string, 0, len(resp.Artifacts))
+		for _, a := range resp.Artifacts {
+			rows = append(rows, [2]string{a.Name, a.Path})
+			plain = append(plain, a.Name)
+		}
+		writeTable(w, "  ", rows, plain)
+	}
+
+	if urls := responseURLs(resp.Outputs); len(urls) > 0 {
+		fmt.Fprintf(w, "\n%s\n", style.paint(ansiBold, "URLs"))
+		names := make([]string, 0, len(urls))
+		for name := range urls {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		rows := make([][2]string, 0, len(names))
+		for _, name := range names {
+			rows = append(rows, [2]string{name, urls[name]})
+		}
+		writeTable(w, "  ", rows, names)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestRenderTerminalSummary(t *testing.T) {
+	published := standaloneResult{
+		Validate: &plugin.ValidateResponse{Valid: true},
+		Execute: &plugin.ExecuteResponse{
+			Success: true,
+			Message: "Published my_lib 1.0.0 to Hex.pm",
+			Outputs: map[string]any{
+				"gates": []GateResult{
+					{Name: "test", Success: true, DurationMs: 1500},
+					{Name: "credo", Success: false, AllowFailure: true},
+				},
+				"package_url": "https://hex.pm/packages/my_lib/1.0.0",
+			},
+			Artifacts: []plugin.Artifact{{Name: "my_lib-1.0.0.tar", Path: "dist/my_lib-1.0.0.tar", Type: "file"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		result  standaloneResult
+		dryRun  bool
+		color   bool
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "plain",
+			result: published,
+			want: []string{
+				"✓ Published\n  Published my_lib 1.0.0 to Hex.pm\n",
+				"Gates\n  test   passed  1.500s\n  credo  failed (allowed)  0.000s\n",
+				"Artifacts\n  my_lib-1.0.0.tar  dist/my_lib-1.0.0.tar\n",
+				"URLs\n  package  https://hex.pm/packages/my_lib/1.0.0\n",
+			},
+			notWant: []string{"\x1b["},
+		},
+		{
+			name:   "color",
+			result: published,
+			color:  true,
+			want:   []string{ansiBold + ansiGreen + "✓ Published" + ansiReset, "test   " + ansiGreen + "passed" + ansiReset},
+		},
+		{
+			name:   "dry run",
+			result: standaloneResult{Validate: &plugin.ValidateResponse{Valid: true}, Execute: &plugin.ExecuteResponse{Success: true, Outputs: map[string]any{"gates": []string{"test", "audit"}}}},
+			dryRun: true,
+			want:   []string{"● Dry run\n", "Gates\n  test, audit\n"},
+		},
+		{
+			name:   "failure",
+			result: standaloneResult{Validate: &plugin.ValidateResponse{Valid: true}, Execute: &plugin.ExecuteResponse{Error: "mix hex.publish failed"}},
+			want:   []string{"✗ Failed\n  mix hex.publish failed\n"},
+		},
+		{
+			name:   "invalid config",
+			result: standaloneResult{Validate: &plugin.ValidateResponse{Errors: []plugin.ValidationError{{Field: "work_dir", Message: "must be relative"}}}},
+			want:   []string{"✗ Invalid configuration\n  work_dir: must be relative\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			renderTerminalSummary(&b, tt.result, tt.dryRun, terminalStyle{color: tt.color})
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("summary missing %q:\n%s", want, b.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(b.String(), notWant) {
+					t.Errorf("summary contains %q:\n%s", notWant, b.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunStandaloneOutputMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantJSON bool
+	}{
+		{name: "pipe keeps json", args: nil, wantJSON: true},
+		{name: "plain summary", args: []string{"-plain"}},
+		{name: "json wins over plain", args: []string{"-plain", "-json"}, wantJSON: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-version", "1.0.0"}, tt.args...)
+			code := runStandalone(context.Background(), &HexPlugin{executor: &MockCommandExecutor{}}, args, &stdout, &stderr)
+			if code != 0 {
+				t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+			}
+			if isJSON := strings.HasPrefix(stdout.String(), "{"); isJSON != tt.wantJSON {
+				t.Errorf("json output = %v, want %v:\n%s", isJSON, tt.wantJSON, stdout.String())
+			}
+			if !tt.wantJSON && !strings.Contains(stdout.String(), "● Dry run") {
+				t.Errorf("expected the dry run summary:\n%s", stdout.String())
+			}
+		})
+	}
+}
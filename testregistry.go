@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// testRegistryName is the repository name of the local test registry.
+const testRegistryName = "test_registry"
+
+// testRegistry describes a locally built registry.
+type testRegistry struct {
+	Dir         string
+	PublicDir   string
+	PrivateKey  string
+	PublicKey   string
+	TarballPath string
+	Package     string
+}
+
+// generateRegistryKeys writes a fresh RSA key pair used to sign the test registry.
+func generateRegistryKeys(dir string) (privatePath, publicPath string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate registry key: %w", err)
+	}
+
+	privatePath = filepath.Join(dir, "private_key.pem")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(privatePath, privatePEM, 0o600); err != nil {
+		return "", "", fmt.Errorf("failed to write registry private key: %w", err)
+	}
+
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode registry public key: %w", err)
+	}
+	publicPath = filepath.Join(dir, "public_key.pem")
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write registry public key: %w", err)
+	}
+
+	return privatePath, publicPath, nil
+}
+
+// testRegistryCommands returns the mix commands used to build the test registry, for dry runs.
+func testRegistryCommands() []string {
+	return []string{
+		"mix hex.build --output <registry>/package.tar",
+		fmt.Sprintf("mix hex.registry build <registry>/public --name %s --private-key <registry>/private_key.pem", testRegistryName),
+	}
+}
+
+// buildTestRegistry builds the package, signs a local registry containing it
+// and verifies the registry can be served over HTTP.
+func (p *HexPlugin) buildTestRegistry(ctx context.Context, cfg *Config, version string, summary *RunSummary) (*testRegistry, error) {
+	dir := cfg.TestRegistryDir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "relicta-hex-registry-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create registry dir: %w", err)
+		}
+		dir = tmp
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry dir: %w", err)
+	}
+
+	reg := &testRegistry{Dir: absDir, PublicDir: filepath.Join(absDir, "public")}
+	tarballsDir := filepath.Join(reg.PublicDir, "tarballs")
+	if err := os.MkdirAll(tarballsDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create registry dir: %w", err)
+	}
+
+	// Build the package tarball
+	buildPath := filepath.Join(absDir, "package.tar")
+	output, err := p.runMix(ctx, cfg, summary, []string{"hex.build", "--output", buildPath}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("mix hex.build failed: %v\nOutput: %s", err, string(output))
+	}
+
+	reg.Package = parsePublishedPackage(string(output))
+	if reg.Package == "" {
+		return nil, fmt.Errorf("could not determine package name from mix hex.build output")
+	}
+
+	reg.TarballPath = filepath.Join(tarballsDir, fmt.Sprintf("%s-%s.tar", reg.Package, version))
+	if err := os.Rename(buildPath, reg.TarballPath); err != nil {
+		return nil, fmt.Errorf("failed to move package tarball: %w", err)
+	}
+
+	reg.PrivateKey, reg.PublicKey, err = generateRegistryKeys(absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign the registry resources
+	args := []string{"hex.registry", "build", reg.PublicDir, "--name", testRegistryName, "--private-key", reg.PrivateKey}
+	output, err = p.runMix(ctx, cfg, summary, args, nil)
+	if err != nil {
+		return nil, fmt.Errorf("mix hex.registry build failed: %v\nOutput: %s", err, string(output))
+	}
+
+	if err := serveAndVerifyRegistry(ctx, reg); err != nil {
+		return nil, err
+	}
+
+	return reg, nil
+}
+
+// serveAndVerifyRegistry serves the registry on a loopback port and fetches
+// the names index and the package tarball to confirm the registry is complete.
+func serveAndVerifyRegistry(ctx context.Context, reg *testRegistry) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to start registry server: %w", err)
+	}
+
+	server := &http.Server{Handler: http.FileServer(http.Dir(reg.PublicDir)), ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	defer func() { _ = server.Close() }()
+
+	base := "http://" + listener.Addr().String()
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for _, path := range []string{"/names", "/packages/" + reg.Package, "/tarballs/" + filepath.Base(reg.TarballPath)} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+path, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("test registry verification failed: %w", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("test registry verification failed: GET %s returned %d", path, resp.StatusCode)
+		}
+	}
+
+	return nil
+}
+
+// publishToTestRegistry publishes into a local registry instead of hex.pm.
+func (p *HexPlugin) publishToTestRegistry(ctx context.Context, cfg *Config, version string, outputs map[string]any, summary *RunSummary) *plugin.ExecuteResponse {
+	reg, err := p.buildTestRegistry(ctx, cfg, version, summary)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("test registry publish failed: %v", err),
+			Outputs: outputs,
+		}
+	}
+
+	if summary != nil {
+		summary.Package = reg.Package
+	}
+	summary.addArtifact(plugin.Artifact{Name: filepath.Base(reg.TarballPath), Path: reg.TarballPath, Type: "file"})
+
+	outputs["package"] = reg.Package
+	outputs["test_registry"] = map[string]any{
+		"name":       testRegistryName,
+		"dir":        reg.Dir,
+		"public_dir": reg.PublicDir,
+		"public_key": reg.PublicKey,
+		"tarball":    reg.TarballPath,
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: fmt.Sprintf("Published package %s v%s to local test registry", reg.Package, version),
+		Outputs: outputs,
+	}
+}
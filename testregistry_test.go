@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// fakeRegistryExecutor emulates mix hex.build and mix hex.registry build on disk.
+func fakeRegistryExecutor(t *testing.T) *MockCommandExecutor {
+	return &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			switch {
+			case args[0] == "hex.build":
+				writeFile(t, args[len(args)-1], "tarball")
+				return []byte("Building my_lib 1.0.0\n"), nil
+			case args[0] == "hex.registry":
+				public := args[2]
+				writeFile(t, filepath.Join(public, "names"), "names")
+				writeFile(t, filepath.Join(public, "versions"), "versions")
+				writeFile(t, filepath.Join(public, "packages", "my_lib"), "package")
+				return []byte("* creating public/names\n"), nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestExecuteTestRegistry(t *testing.T) {
+	chdirTemp(t)
+	_ = os.Unsetenv("HEX_API_KEY")
+
+	mock := fakeRegistryExecutor(t)
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"test_registry":     true,
+			"test_registry_dir": "registry",
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success without an API key, got %s", resp.Error)
+	}
+	if resp.Outputs["package"] != "my_lib" {
+		t.Errorf("package: got %v", resp.Outputs["package"])
+	}
+
+	for _, call := range mock.Calls {
+		if call.Args[0] == "hex.publish" {
+			t.Error("test registry mode must never call hex.publish")
+		}
+	}
+
+	for _, path := range []string{
+		filepath.Join("registry", "public", "tarballs", "my_lib-1.0.0.tar"),
+		filepath.Join("registry", "private_key.pem"),
+		filepath.Join("registry", "public_key.pem"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s: %v", path, err)
+		}
+	}
+
+	t.Run("incomplete registry fails verification", func(t *testing.T) {
+		mock := &MockCommandExecutor{
+			RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+				if args[0] == "hex.build" {
+					writeFile(t, args[len(args)-1], "tarball")
+					return []byte("Building my_lib 1.0.0\n"), nil
+				}
+				return nil, nil
+			},
+		}
+		p := &HexPlugin{executor: mock}
+
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"test_registry": true, "test_registry_dir": "broken"},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Success || !strings.Contains(resp.Error, "GET /names returned 404") {
+			t.Errorf("expected verification failure, got success=%v error=%q", resp.Success, resp.Error)
+		}
+	})
+
+	t.Run("dry run lists registry commands", func(t *testing.T) {
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			DryRun:  true,
+			Config:  map[string]any{"test_registry": true},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(resp.Outputs["command"].(string), "hex.registry build") {
+			t.Errorf("unexpected dry run command: %v", resp.Outputs["command"])
+		}
+	})
+}
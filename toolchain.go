@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// validateToolchain checks that elixir_path and path_prepend entries are
+// absolute, since a relative PATH entry would resolve against work_dir.
+func validateToolchain(elixirPath string, pathPrepend []string) *fieldError {
+	if elixirPath != "" && !filepath.IsAbs(elixirPath) {
+		return &fieldError{Field: "elixir_path", Err: fmt.Errorf("must be an absolute path")}
+	}
+	for i, dir := range pathPrepend {
+		if !filepath.IsAbs(dir) {
+			return &fieldError{Field: fmt.Sprintf("path_prepend[%d]", i), Err: fmt.Errorf("must be an absolute path")}
+		}
+	}
+	return nil
+}
+
+// mixBinary returns the mix executable, honouring mix_path.
+func (c *Config) mixBinary() string {
+	if c.MixPath != "" {
+		return c.MixPath
+	}
+	return "mix"
+}
+
+// toolchainPath returns the directories prepended to PATH: path_prepend
+// first, then the directories of elixir_path and mix_path. mix starts with
+// "#!/usr/bin/env elixir", so the elixir directory must win the PATH lookup.
+func (c *Config) toolchainPath() []string {
+	dirs := append([]string{}, c.PathPrepend...)
+	for _, bin := range []string{c.ElixirPath, c.MixPath} {
+		if bin != "" && filepath.IsAbs(bin) {
+			dirs = append(dirs, filepath.Dir(bin))
+		}
+	}
+	return dirs
+}
+
+// toolchainEnv returns the PATH override for mix commands, or nil when the
+// toolchain is not configured.
+func (c *Config) toolchainEnv() []string {
+	dirs := c.toolchainPath()
+	if len(dirs) == 0 {
+		return nil
+	}
+	if path := os.Getenv("PATH"); path != "" {
+		dirs = append(dirs, path)
+	}
+	return []string{"PATH=" + strings.Join(dirs, string(os.PathListSeparator))}
+}
+
+// parseCommandPrefix reads command_prefix as a list of arguments or as a
+// whitespace-separated string. Arguments containing spaces need the list form.
+func parseCommandPrefix(raw map[string]any) []string {
+	if s, ok := raw["command_prefix"].(string); ok {
+		return strings.Fields(s)
+	}
+	return helpers.NewConfigParser(raw).GetStringSlice("command_prefix", nil)
+}
+
+// mixCommand returns the name, args and env used to run a mix task, wrapped
+// in command_prefix (e.g. "nix develop -c") when configured.
+func (c *Config) mixCommand(args, env []string) (string, []string, []string) {
+	env = slices.Concat(c.toolchainEnv(), c.buildEnv, c.homeEnv(), env)
+	if len(c.CommandPrefix) == 0 {
+		return c.mixBinary(), args, env
+	}
+	wrapped := append(append(append([]string{}, c.CommandPrefix[1:]...), c.mixBinary()), args...)
+	return c.CommandPrefix[0], wrapped, env
+}
+
+// mixDisplay returns the mix invocation as a command line, including command_prefix.
+func (c *Config) mixDisplay(args ...string) string {
+	name, args, _ := c.mixCommand(args, nil)
+	return strings.Join(append([]string{name}, args...), " ")
+}
+
+// runMix runs a mix task with the configured toolchain in work_dir.
+func (p *HexPlugin) runMix(ctx context.Context, cfg *Config, summary *RunSummary, args, env []string) ([]byte, error) {
+	if cfg.Chaos.simulates(cfg.Task, args) {
+		return simulateChaosWrite(cfg, summary, args), nil
+	}
+	name, args, env := cfg.mixCommand(args, env)
+	return p.runCommand(ctx, summary, name, args, env, cfg.WorkDir)
+}
+
+// runMixInteractive runs a mix task like runMix with the terminal attached.
+func (p *HexPlugin) runMixInteractive(ctx context.Context, cfg *Config, summary *RunSummary, args, env []string) ([]byte, error) {
+	if cfg.Chaos.simulates(cfg.Task, args) {
+		return simulateChaosWrite(cfg, summary, args), nil
+	}
+	name, args, env := cfg.mixCommand(args, env)
+	return p.runInteractiveCommand(ctx, summary, name, args, env, cfg.WorkDir)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestToolchainEnv(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	sep := string(os.PathListSeparator)
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected []string
+	}{
+		{name: "no toolchain config", cfg: Config{}, expected: nil},
+		{name: "mix on PATH is not prepended", cfg: Config{MixPath: "mix-1.16"}, expected: nil},
+		{
+			name:     "elixir and mix directories",
+			cfg:      Config{ElixirPath: "/nix/store/abc-elixir/bin/elixir", MixPath: "/opt/elixir/bin/mix"},
+			expected: []string{"PATH=/nix/store/abc-elixir/bin" + sep + "/opt/elixir/bin" + sep + "/usr/bin"},
+		},
+		{
+			name:     "path_prepend comes first",
+			cfg:      Config{PathPrepend: []string{"/opt/otp/bin"}, ElixirPath: "/opt/elixir/bin/elixir"},
+			expected: []string{"PATH=/opt/otp/bin" + sep + "/opt/elixir/bin" + sep + "/usr/bin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cfg.toolchainEnv()
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("got %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateToolchain(t *testing.T) {
+	if err := validateToolchain("/usr/local/bin/elixir", []string{"/opt/bin"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateToolchain("bin/elixir", nil); err == nil || err.Field != "elixir_path" {
+		t.Errorf("expected elixir_path error, got %v", err)
+	}
+	if err := validateToolchain("", []string{"/ok", "relative"}); err == nil || err.Field != "path_prepend[1]" {
+		t.Errorf("expected path_prepend error, got %v", err)
+	}
+}
+
+func TestExecuteUsesConfiguredMix(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"api_key":     "k",
+			"mix_path":    "/opt/elixir-1.16/bin/mix",
+			"elixir_path": "/opt/elixir-1.16/bin/elixir",
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got %s", resp.Error)
+	}
+
+	call := mock.Calls[0]
+	if call.Name != "/opt/elixir-1.16/bin/mix" {
+		t.Errorf("expected configured mix, got %q", call.Name)
+	}
+	if !strings.HasPrefix(call.Env[0], "PATH=/opt/elixir-1.16/bin") || !contains(call.Env, "HEX_API_KEY=k") {
+		t.Errorf("unexpected env: %v", call.Env)
+	}
+}
+
+func TestCommandPrefix(t *testing.T) {
+	tests := []struct {
+		name         string
+		raw          any
+		expectName   string
+		expectArgs   string
+		expectPrefix int
+	}{
+		{name: "no prefix", raw: nil, expectName: "mix", expectArgs: "hex.publish --yes"},
+		{name: "string prefix", raw: "nix develop -c", expectName: "nix", expectArgs: "develop -c mix hex.publish --yes", expectPrefix: 3},
+		{name: "list prefix", raw: []any{"flox", "activate", "--"}, expectName: "flox", expectArgs: "activate -- mix hex.publish --yes", expectPrefix: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]any{}
+			if tt.raw != nil {
+				raw["command_prefix"] = tt.raw
+			}
+			cfg := &Config{CommandPrefix: parseCommandPrefix(raw)}
+			if len(cfg.CommandPrefix) != tt.expectPrefix {
+				t.Fatalf("prefix: got %v", cfg.CommandPrefix)
+			}
+
+			name, args, _ := cfg.mixCommand([]string{"hex.publish", "--yes"}, nil)
+			if name != tt.expectName || strings.Join(args, " ") != tt.expectArgs {
+				t.Errorf("got %s %v", name, args)
+			}
+		})
+	}
+}
+
+func TestExecuteWithCommandPrefix(t *testing.T) {
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		DryRun:  true,
+		Config:  map[string]any{"command_prefix": "devbox run"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Outputs["command"] != "devbox run mix hex.publish --yes" {
+		t.Errorf("command: got %v", resp.Outputs["command"])
+	}
+
+	resp, _ = p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "k", "command_prefix": []any{"nix", "develop", "-c"}},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if !resp.Success {
+		t.Fatalf("expected success, got %s", resp.Error)
+	}
+	if call := mock.Calls[0]; call.Name != "nix" || strings.Join(call.Args, " ") != "develop -c mix hex.publish --yes" {
+		t.Errorf("unexpected call: %+v", call)
+	}
+}
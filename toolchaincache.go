@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// toolchainDetectTimeout bounds the mix hex.info call that detects versions.
+const toolchainDetectTimeout = 30 * time.Second
+
+// ToolchainVersions are the Elixir, OTP and Hex versions mix runs with.
+type ToolchainVersions struct {
+	Elixir string `json:"elixir"`
+	OTP    string `json:"otp"`
+	Hex    string `json:"hex"`
+}
+
+// String formats the versions like mix hex.info.
+func (v ToolchainVersions) String() string {
+	return fmt.Sprintf("Hex: %s\nElixir: %s\nOTP: %s\n", v.Hex, v.Elixir, v.OTP)
+}
+
+// parseHexInfo reads the versions printed by mix hex.info without a package.
+func parseHexInfo(output string) (ToolchainVersions, bool) {
+	var v ToolchainVersions
+	for _, line := range strings.Split(output, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(name) {
+		case "Hex":
+			v.Hex = value
+		case "Elixir":
+			v.Elixir = value
+		case "OTP":
+			v.OTP = value
+		}
+	}
+	return v, v.Hex != "" && v.Elixir != "" && v.OTP != ""
+}
+
+// toolchainCache remembers detected versions for the life of the plugin
+// process, keyed by everything that selects the toolchain.
+type toolchainCache struct {
+	mu       sync.Mutex
+	versions map[string]ToolchainVersions
+}
+
+func (c *toolchainCache) get(key string) (ToolchainVersions, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.versions[key]
+	return v, ok
+}
+
+func (c *toolchainCache) put(key string, v ToolchainVersions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.versions == nil {
+		c.versions = map[string]ToolchainVersions{}
+	}
+	c.versions[key] = v
+}
+
+// toolchainKey identifies the toolchain a config runs: the mix invocation
+// and the environment entries that select binaries and archives.
+func (c *Config) toolchainKey() string {
+	name, args, env := c.mixCommand([]string{"hex.info"}, nil)
+	parts := append([]string{name}, args...)
+	for _, kv := range env {
+		switch key, _, _ := strings.Cut(kv, "="); key {
+		case "PATH", "MIX_HOME", "HEX_HOME", "HOME":
+			parts = append(parts, kv)
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// mixBinaryPath resolves the mix executable the way the toolchain PATH
+// would, for stat-ing it.
+func (c *Config) mixBinaryPath() (string, error) {
+	bin := c.mixBinary()
+	if filepath.IsAbs(bin) {
+		return bin, nil
+	}
+	for _, dir := range c.toolchainPath() {
+		if path := filepath.Join(dir, bin); fileExists(path) {
+			return path, nil
+		}
+	}
+	return exec.LookPath(bin)
+}
+
+// fileExists reports whether path names an existing file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// toolchainDiskKey extends the toolchain key with the modification time of
+// the mix and elixir binaries, so an upgraded toolchain is detected again.
+// It reports false when the binaries cannot be stat-ed, e.g. behind a
+// command_prefix, and the result must not be cached on disk.
+func (c *Config) toolchainDiskKey() (string, bool) {
+	if len(c.CommandPrefix) > 0 {
+		return "", false
+	}
+	mix, err := c.mixBinaryPath()
+	if err != nil {
+		return "", false
+	}
+	stamp := []string{c.toolchainKey()}
+	for _, bin := range []string{mix, c.ElixirPath} {
+		if bin == "" {
+			continue
+		}
+		info, err := os.Stat(bin)
+		if err != nil {
+			return "", false
+		}
+		stamp = append(stamp, fmt.Sprintf("%s@%d/%d", bin, info.ModTime().UnixNano(), info.Size()))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(stamp, "\x00")))
+	return hex.EncodeToString(sum[:]), true
+}
+
+// toolchainCacheDir returns the on-disk cache directory, defaulting to the
+// user cache directory.
+func (c *Config) toolchainCacheDir() (string, error) {
+	if c.ToolchainCacheDir != "" {
+		return c.ToolchainCacheDir, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no user cache directory: set toolchain_cache_dir: %w", err)
+	}
+	return filepath.Join(base, apiCacheDirName, "toolchain"), nil
+}
+
+// readToolchainCache returns versions cached on disk, if any.
+func readToolchainCache(dir, key string) (ToolchainVersions, bool) {
+	var v ToolchainVersions
+	data, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil || json.Unmarshal(data, &v) != nil {
+		return v, false
+	}
+	return v, v.Hex != "" && v.Elixir != "" && v.OTP != ""
+}
+
+// writeToolchainCache stores versions on disk. Failures are ignored: the
+// cache only saves a command.
+func writeToolchainCache(dir, key string, v ToolchainVersions) {
+	data, err := json.Marshal(v)
+	if err != nil || os.MkdirAll(dir, 0o755) != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, key+".json"), data, 0o644)
+}
+
+// detectToolchain returns the Elixir, OTP and Hex versions of the
+// configured toolchain. Results are cached for the plugin process, and on
+// disk with toolchain_cache, so repeated calls in one release skip mix.
+func (p *HexPlugin) detectToolchain(ctx context.Context, cfg *Config, summary *RunSummary) (ToolchainVersions, error) {
+	key := cfg.toolchainKey()
+	if v, ok := p.toolchains.get(key); ok {
+		return v, nil
+	}
+
+	var dir, diskKey string
+	if cfg.ToolchainCache {
+		if d, err := cfg.toolchainCacheDir(); err == nil {
+			if k, ok := cfg.toolchainDiskKey(); ok {
+				dir, diskKey = d, k
+			}
+		}
+	}
+	if diskKey != "" {
+		if v, ok := readToolchainCache(dir, diskKey); ok {
+			summary.debugf("toolchain versions read from the cache in %s", dir)
+			p.toolchains.put(key, v)
+			return v, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), toolchainDetectTimeout)
+	defer cancel()
+	output, err := p.runMix(ctx, cfg, summary, []string{"hex.info"}, cfg.hexEnv())
+	if err != nil {
+		return ToolchainVersions{}, fmt.Errorf("%s failed: %v\nOutput: %s", cfg.mixDisplay("hex.info"), err, string(output))
+	}
+	v, ok := parseHexInfo(string(output))
+	if !ok {
+		return ToolchainVersions{}, fmt.Errorf("could not read versions from %s: %s", cfg.mixDisplay("hex.info"), strings.TrimSpace(string(output)))
+	}
+
+	p.toolchains.put(key, v)
+	if diskKey != "" {
+		writeToolchainCache(dir, diskKey, v)
+	}
+	return v, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const testHexInfo = "Hex: 2.1.1\nElixir: 1.17.2\nOTP: 27.0\n\nBuilt with: Elixir 1.17.2 and OTP 27.0\n"
+
+func TestParseHexInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   ToolchainVersions
+		ok     bool
+	}{
+		{name: "full", output: testHexInfo, want: ToolchainVersions{Elixir: "1.17.2", OTP: "27.0", Hex: "2.1.1"}, ok: true},
+		{name: "missing otp", output: "Hex: 2.1.1\nElixir: 1.17.2\n", want: ToolchainVersions{Elixir: "1.17.2", Hex: "2.1.1"}},
+		{name: "unrelated", output: "** (Mix) The task \"hex.info\" could not be found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseHexInfo(tt.output)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseHexInfo() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDetectToolchainCaches(t *testing.T) {
+	mix := filepath.Join(t.TempDir(), "mix")
+	if err := os.WriteFile(mix, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := t.TempDir()
+	raw := map[string]any{"mix_path": mix, "toolchain_cache": true, "toolchain_cache_dir": cacheDir}
+	want := ToolchainVersions{Elixir: "1.17.2", OTP: "27.0", Hex: "2.1.1"}
+
+	detect := func(p *HexPlugin) ToolchainVersions {
+		t.Helper()
+		cfg := p.parseConfig(raw)
+		summary := newRunSummary(p.GetInfo(), plugin.ExecuteRequest{})
+		summary.masker = newSecretMasker(cfg)
+		v, err := p.detectToolchain(context.Background(), cfg, summary)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return v
+	}
+	newPlugin := func() (*HexPlugin, *MockCommandExecutor) {
+		mock := &MockCommandExecutor{
+			RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+				return []byte(testHexInfo), nil
+			},
+		}
+		return &HexPlugin{executor: mock}, mock
+	}
+
+	// The first plugin detects once, then serves repeated calls from memory
+	p, mock := newPlugin()
+	for i := 0; i < 3; i++ {
+		if got := detect(p); got != want {
+			t.Fatalf("detectToolchain() = %+v, want %+v", got, want)
+		}
+	}
+	if len(mock.Calls) != 1 {
+		t.Errorf("expected one detection in process, got %d", len(mock.Calls))
+	}
+
+	// A new process reads the disk cache
+	p, mock = newPlugin()
+	if got := detect(p); got != want {
+		t.Fatalf("detectToolchain() = %+v, want %+v", got, want)
+	}
+	if len(mock.Calls) != 0 {
+		t.Errorf("expected the disk cache to be used, got %d calls", len(mock.Calls))
+	}
+
+	// Upgrading the binary invalidates the disk cache
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(mix, later, later); err != nil {
+		t.Fatal(err)
+	}
+	p, mock = newPlugin()
+	detect(p)
+	if len(mock.Calls) != 1 {
+		t.Errorf("expected detection after the binary changed, got %d calls", len(mock.Calls))
+	}
+}
+
+func TestExecuteDebugReportsToolchain(t *testing.T) {
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			if args[0] == "hex.info" {
+				return []byte(testHexInfo), nil
+			}
+			return []byte("mock output"), nil
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	for i := 0; i < 2; i++ {
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"api_key": "test-api-key", "verbosity": verbosityDebug},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v, _ := resp.Outputs["toolchain"].(ToolchainVersions); v.OTP != "27.0" {
+			t.Errorf("toolchain = %+v", resp.Outputs["toolchain"])
+		}
+	}
+
+	detections := 0
+	for _, call := range mock.Calls {
+		if call.Args[0] == "hex.info" {
+			detections++
+		}
+	}
+	if detections != 1 {
+		t.Errorf("expected one detection across runs, got %d", detections)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// How a command's stdin was handled, recorded in transcripts.
+const (
+	// stdinNone means the command had no input, so any prompt failed or was
+	// skipped by --yes.
+	stdinNone = "none"
+	// stdinTerminal means the terminal was attached and the user answered
+	// the command's prompts.
+	stdinTerminal = "terminal"
+)
+
+// transcriptFile is the name of the transcript index in each run directory.
+const transcriptFile = "transcript.json"
+
+// TranscriptEntry is the archived record of one command: what ran, how its
+// stdin was handled, its timing, and the file holding its full output.
+type TranscriptEntry struct {
+	CommandRecord
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Stdin       string    `json:"stdin"`
+	OutputFile  string    `json:"output_file"`
+	OutputBytes int64     `json:"output_bytes"`
+
+	// output is the masked output, holding the spool marker when the full
+	// output was spooled.
+	output []byte
+}
+
+// Transcript is the index of an archived run, written to transcript.json.
+type Transcript struct {
+	Plugin         string            `json:"plugin"`
+	PluginVersion  string            `json:"plugin_version"`
+	Hook           string            `json:"hook"`
+	DryRun         bool              `json:"dry_run"`
+	Package        string            `json:"package,omitempty"`
+	ReleaseVersion string            `json:"release_version,omitempty"`
+	StartedAt      time.Time         `json:"started_at"`
+	FinishedAt     time.Time         `json:"finished_at"`
+	Success        bool              `json:"success"`
+	Errors         []string          `json:"errors"`
+	Commands       []TranscriptEntry `json:"commands"`
+}
+
+// recordTranscript keeps the output of the command just recorded when
+// transcripts are archived. Output is masked again when the transcript is
+// written, as secrets such as a provisioned key become known after the
+// command that printed them.
+func (s *RunSummary) recordTranscript(output []byte, stdin string, start time.Time) {
+	if s == nil || !s.transcripts || len(s.Commands) == 0 {
+		return
+	}
+	s.transcript = append(s.transcript, TranscriptEntry{
+		CommandRecord: s.Commands[len(s.Commands)-1],
+		StartedAt:     start.UTC(),
+		FinishedAt:    time.Now().UTC(),
+		Stdin:         stdin,
+		output:        output,
+	})
+}
+
+// transcriptName makes a value safe to use as a path element.
+func transcriptName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '+', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+	if strings.Trim(s, ".") == "" {
+		return "unversioned"
+	}
+	return s
+}
+
+// writeTranscript archives the finished run under dir, in a directory per
+// release version and run: the full output of each command in its own log
+// file, and their records in transcript.json. It returns the run directory.
+func (s *RunSummary) writeTranscript(dir string) (string, error) {
+	runDir := filepath.Join(dir, transcriptName(s.ReleaseVersion), fmt.Sprintf("%s-%s", transcriptName(s.Hook), s.StartedAt.Format("20060102T150405.000Z")))
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+
+	entries := make([]TranscriptEntry, len(s.transcript))
+	for i, entry := range s.transcript {
+		entry.OutputFile = fmt.Sprintf("%02d-%s.log", i+1, transcriptName(filepath.Base(entry.Argv[0])))
+		n, err := writeTranscriptOutput(filepath.Join(runDir, entry.OutputFile), entry.output, s.masker)
+		if err != nil {
+			return "", fmt.Errorf("failed to write transcript of %s: %w", entry.Command, err)
+		}
+		entry.OutputBytes = n
+		entries[i] = entry
+	}
+
+	data, err := json.MarshalIndent(Transcript{
+		Plugin:         s.Plugin,
+		PluginVersion:  s.PluginVersion,
+		Hook:           s.Hook,
+		DryRun:         s.DryRun,
+		Package:        s.Package,
+		ReleaseVersion: s.ReleaseVersion,
+		StartedAt:      s.StartedAt,
+		FinishedAt:     s.FinishedAt,
+		Success:        s.Success,
+		Errors:         s.Errors,
+		Commands:       entries,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transcript: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, transcriptFile), s.masker.maskJSON(data), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return runDir, nil
+}
+
+// writeTranscriptOutput writes a command's full output to path, copying it
+// from the spool file when it was spooled, and masks the secrets known by
+// the end of the run.
+func writeTranscriptOutput(path string, output []byte, masker *secretMasker) (int64, error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	if spooled := spooledOutputPath(output); spooled != "" {
+		in, err := os.Open(spooled)
+		if err != nil {
+			return 0, err
+		}
+		defer in.Close()
+
+		var n int64
+		r := bufio.NewReader(in)
+		w := bufio.NewWriter(out)
+		for {
+			line, rerr := r.ReadString('\n')
+			written, werr := w.WriteString(masker.mask(line))
+			n += int64(written)
+			if werr != nil {
+				return n, werr
+			}
+			if errors.Is(rerr, io.EOF) {
+				break
+			}
+			if rerr != nil {
+				return n, rerr
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return n, err
+		}
+		return n, out.Close()
+	}
+	n, err := out.Write([]byte(masker.mask(string(output))))
+	if err != nil {
+		return int64(n), err
+	}
+	return int64(n), out.Close()
+}
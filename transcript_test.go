@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestTranscriptName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "1.2.0-rc.1+build.5", want: "1.2.0-rc.1+build.5"},
+		{in: "../1.0.0", want: ".._1.0.0"},
+		{in: "", want: "unversioned"},
+		{in: "..", want: "unversioned"},
+		{in: "post publish", want: "post_publish"},
+	}
+	for _, tt := range tests {
+		if got := transcriptName(tt.in); got != tt.want {
+			t.Errorf("transcriptName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteArchivesTranscript(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   int
+		interactive bool
+		wantStdin   string
+	}{
+		{name: "in memory", wantStdin: stdinNone},
+		{name: "spooled", threshold: 16, wantStdin: stdinNone},
+		{name: "interactive", interactive: true, wantStdin: stdinTerminal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			output := "Building my_lib 1.0.0\nusing key test-api-key\nPackage published\n"
+			config := map[string]any{
+				"api_key":                "test-api-key",
+				"transcript_dir":         "audit",
+				"output_spool_threshold": tt.threshold,
+			}
+			if tt.interactive {
+				config["yes"] = false
+			}
+
+			var mock CommandExecutor = &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					w := &spoolWriter{threshold: spoolThresholdFrom(ctx)}
+					_, _ = w.Write([]byte(output))
+					return w.Bytes(), nil
+				},
+			}
+			if tt.interactive {
+				mock = &mockInteractiveExecutor{MockCommandExecutor: mock.(*MockCommandExecutor), output: output}
+			}
+			p := &HexPlugin{executor: mock, terminal: func() bool { return true }}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "v1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			runDir, ok := resp.Outputs["transcript_archive"].(string)
+			if !ok {
+				t.Fatalf("transcript_archive = %v, error %v", resp.Outputs["transcript_archive"], resp.Outputs["transcript_archive_error"])
+			}
+			if got := filepath.Dir(runDir); got != filepath.Join("audit", "1.0.0") {
+				t.Errorf("run directory %s is not under audit/1.0.0", runDir)
+			}
+
+			data, err := os.ReadFile(filepath.Join(runDir, transcriptFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var transcript Transcript
+			if err := json.Unmarshal(data, &transcript); err != nil {
+				t.Fatalf("transcript is not valid JSON: %v", err)
+			}
+			if transcript.Hook != string(plugin.HookPostPublish) || !transcript.Success || len(transcript.Commands) != 1 {
+				t.Fatalf("unexpected transcript: %+v", transcript)
+			}
+			entry := transcript.Commands[0]
+			if entry.Stdin != tt.wantStdin {
+				t.Errorf("stdin = %s, want %s", entry.Stdin, tt.wantStdin)
+			}
+			if entry.Argv[0] != "mix" || entry.StartedAt.IsZero() || entry.FinishedAt.Before(entry.StartedAt) {
+				t.Errorf("unexpected entry: %+v", entry)
+			}
+
+			log, err := os.ReadFile(filepath.Join(runDir, entry.OutputFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.ReplaceAll(output, "test-api-key", maskReplacement)
+			if string(log) != want {
+				t.Errorf("log = %q, want the full redacted output %q", log, want)
+			}
+			if entry.OutputBytes != int64(len(want)) {
+				t.Errorf("output_bytes = %d, want %d", entry.OutputBytes, len(want))
+			}
+		})
+	}
+}
+
+// mockInteractiveExecutor answers RunInteractive with fixed output.
+type mockInteractiveExecutor struct {
+	*MockCommandExecutor
+	output string
+}
+
+func (m *mockInteractiveExecutor) RunInteractive(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+	m.Calls = append(m.Calls, MockCall{Name: name, Args: args, Env: env, Dir: dir})
+	return []byte(m.output), nil
+}
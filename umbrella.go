@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// appNamePattern matches OTP application names.
+var appNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// resolveAppDir returns the directory of an umbrella app from the apps_path
+// declared in the root mix.exs.
+func resolveAppDir(root, app string) (string, error) {
+	if !appNamePattern.MatchString(app) {
+		return "", fmt.Errorf("%q is not a valid application name", app)
+	}
+
+	content, err := readMixExs(root)
+	if err != nil {
+		return "", err
+	}
+	appsPath, ok := parseMixAppsPath(content)
+	if !ok {
+		return "", fmt.Errorf("%s is not an umbrella project: no apps_path in %s", root, mixExsFile)
+	}
+	if err := validatePath(appsPath); err != nil {
+		return "", fmt.Errorf("apps_path %q: %v", appsPath, err)
+	}
+
+	dir := filepath.Join(root, appsPath, app)
+	if _, err := os.Stat(filepath.Join(dir, mixExsFile)); err != nil {
+		return "", fmt.Errorf("umbrella app %s not found in %s", app, filepath.Join(root, appsPath))
+	}
+	return dir, nil
+}
+
+// withUmbrellaApp points work_dir at the umbrella app named by app. The
+// configured work_dir is the umbrella root.
+func withUmbrellaApp(config map[string]any) (map[string]any, *fieldError) {
+	parser := helpers.NewConfigParser(config)
+	app := parser.GetString("app", "", "")
+	if app == "" {
+		return config, nil
+	}
+
+	// An invalid work_dir is reported by its own validation
+	root := parser.GetString("work_dir", "", ".")
+	if validatePath(root) != nil {
+		return config, nil
+	}
+
+	dir, err := resolveAppDir(root, app)
+	if err != nil {
+		return nil, &fieldError{Field: "app", Err: err}
+	}
+
+	resolved := make(map[string]any, len(config))
+	for k, v := range config {
+		resolved[k] = v
+	}
+	resolved["work_dir"] = dir
+	return resolved, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// writeUmbrella creates an umbrella project with the given apps in dir.
+func writeUmbrella(t *testing.T, dir, appsPath string, apps ...string) {
+	t.Helper()
+	root := "defmodule Umbrella.MixProject do\n  use Mix.Project\n\n  def project do\n    [apps_path: \"" + appsPath + "\", version: \"0.1.0\"]\n  end\nend\n"
+	if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte(root), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, app := range apps {
+		appDir := filepath.Join(dir, appsPath, app)
+		if err := os.MkdirAll(appDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(appDir, mixExsFile), []byte("[app: :"+app+"]"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseMixAppsPath(t *testing.T) {
+	if got, ok := parseMixAppsPath(`[apps_path: "apps", version: "0.1.0"]`); !ok || got != "apps" {
+		t.Errorf("parseMixAppsPath() = %q, %v", got, ok)
+	}
+	if _, ok := parseMixAppsPath(`[app: :my_lib]`); ok {
+		t.Error("expected a regular project not to be an umbrella")
+	}
+}
+
+func TestResolveAppDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		appsPath string
+		umbrella bool
+		app      string
+		want     string
+		wantErr  string
+	}{
+		{name: "default apps path", appsPath: "apps", umbrella: true, app: "my_lib", want: "apps/my_lib"},
+		{name: "custom apps path", appsPath: "components", umbrella: true, app: "my_lib", want: "components/my_lib"},
+		{name: "unknown app", appsPath: "apps", umbrella: true, app: "other", wantErr: "umbrella app other not found"},
+		{name: "not an umbrella", app: "my_lib", wantErr: "not an umbrella project"},
+		{name: "invalid name", appsPath: "apps", umbrella: true, app: "../etc", wantErr: "not a valid application name"},
+		{name: "escaping apps path", appsPath: "../elsewhere", umbrella: true, app: "my_lib", wantErr: "path traversal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t)
+			if tt.umbrella {
+				writeUmbrella(t, dir, tt.appsPath, "my_lib")
+			} else if err := os.WriteFile(filepath.Join(dir, mixExsFile), []byte("[app: :my_lib]"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := resolveAppDir(".", tt.app)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveAppDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteUmbrellaApp(t *testing.T) {
+	dir := chdirTemp(t)
+	writeUmbrella(t, dir, "apps", "my_lib", "my_web")
+	if err := os.WriteFile(filepath.Join(dir, "apps", "my_lib", defaultProjectConfigFile), []byte("organization: acme\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "app": "my_lib"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+	if len(mock.Calls) != 1 || mock.Calls[0].Dir != filepath.Join("apps", "my_lib") {
+		t.Fatalf("expected mix to run in apps/my_lib, got %+v", mock.Calls)
+	}
+	if !contains(mock.Calls[0].Args, "acme") {
+		t.Errorf("expected the app's project config to apply, got %v", mock.Calls[0].Args)
+	}
+
+	resp, _ = p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "app": "missing"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if resp.Success || !strings.Contains(resp.Error, "invalid app: umbrella app missing not found") {
+		t.Errorf("expected an unknown app to fail, got %+v", resp)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Verbosity levels selected by verbosity.
+const (
+	verbosityQuiet  = "quiet"
+	verbosityNormal = "normal"
+	verbosityDebug  = "debug"
+)
+
+// validateVerbosity checks that verbosity names a known level.
+func validateVerbosity(level string) error {
+	switch level {
+	case "", verbosityQuiet, verbosityNormal, verbosityDebug:
+		return nil
+	default:
+		return fmt.Errorf("must be %s, %s or %s", verbosityQuiet, verbosityNormal, verbosityDebug)
+	}
+}
+
+// debugf records a decision taken during the run, such as which gates ran or
+// why the publish was skipped. Decisions are only kept at debug verbosity,
+// and logged as they are made on hosts with structured logging.
+func (s *RunSummary) debugf(format string, args ...any) {
+	if s == nil || s.verbosity != verbosityDebug {
+		return
+	}
+	decision := fmt.Sprintf(format, args...)
+	s.Decisions = append(s.Decisions, decision)
+	if s.logger != nil {
+		s.logger.Debug(s.masker.mask(decision))
+	}
+}
+
+// envSummary describes the toolchain and environment mix runs with. Secret
+// values are redacted.
+func (c *Config) envSummary() map[string]any {
+	name, _, env := c.mixCommand(nil, c.hexEnv())
+	summary := map[string]any{
+		"mix":      name,
+		"work_dir": c.WorkDir,
+		"env":      redactEnv(env),
+	}
+	if len(c.CommandPrefix) > 0 {
+		summary["command_prefix"] = c.CommandPrefix
+	}
+	if len(c.PathPrepend) > 0 {
+		summary["path_prepend"] = c.PathPrepend
+	}
+	return summary
+}
+
+// applyVerbosity trims or extends the response outputs for the configured
+// verbosity. Quiet drops captured command output; debug adds the decision log
+// and an environment summary.
+func applyVerbosity(resp *plugin.ExecuteResponse, cfg *Config, summary *RunSummary) {
+	switch cfg.Verbosity {
+	case verbosityQuiet:
+		delete(resp.Outputs, "output")
+		delete(resp.Outputs, "env")
+		delete(resp.Outputs, "transcript")
+		for _, key := range []string{"gates", "verify_matrix"} {
+			if results, ok := resp.Outputs[key].([]GateResult); ok {
+				resp.Outputs[key] = withoutGateOutput(results)
+			}
+		}
+		if i := strings.Index(resp.Error, "\nOutput: "); i >= 0 {
+			resp.Error = resp.Error[:i]
+		}
+	case verbosityDebug:
+		resp.Outputs["decisions"] = summary.Decisions
+		resp.Outputs["env_summary"] = cfg.envSummary()
+	}
+}
+
+// withoutGateOutput returns copies of the results without captured output.
+func withoutGateOutput(results []GateResult) []GateResult {
+	trimmed := make([]GateResult, len(results))
+	for i, r := range results {
+		r.Output = ""
+		trimmed[i] = r
+	}
+	return trimmed
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestValidateVerbosity(t *testing.T) {
+	tests := []struct {
+		level   string
+		wantErr bool
+	}{
+		{"", false},
+		{"quiet", false},
+		{"normal", false},
+		{"debug", false},
+		{"verbose", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			err := validateVerbosity(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateVerbosity(%q) error = %v, wantErr %v", tt.level, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecuteVerbosity(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity string
+		present   []string
+		absent    []string
+	}{
+		{"normal", "normal", []string{"output", "env", "transcript"}, []string{"decisions", "env_summary"}},
+		{"quiet", "quiet", nil, []string{"output", "env", "transcript", "decisions", "env_summary"}},
+		{"debug", "debug", []string{"output", "env", "transcript", "decisions", "env_summary"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &HexPlugin{executor: &MockCommandExecutor{}}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"api_key":   "test-api-key",
+					"verbosity": tt.verbosity,
+					"gates":     []any{"test"},
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			for _, key := range tt.present {
+				if _, ok := resp.Outputs[key]; !ok {
+					t.Errorf("expected output %q", key)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := resp.Outputs[key]; ok {
+					t.Errorf("unexpected output %q", key)
+				}
+			}
+
+			gates, _ := resp.Outputs["gates"].([]GateResult)
+			if len(gates) != 1 {
+				t.Fatalf("expected 1 gate result, got %v", resp.Outputs["gates"])
+			}
+			if hasOutput := gates[0].Output != ""; hasOutput != (tt.verbosity != verbosityQuiet) {
+				t.Errorf("unexpected gate output %q at %s verbosity", gates[0].Output, tt.verbosity)
+			}
+		})
+	}
+}
+
+func TestExecuteDebugDecisions(t *testing.T) {
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("1 test, 1 failure"), &ReplayedError{Message: "exit status 1", Code: 1}
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"api_key":   "test-api-key",
+			"verbosity": "debug",
+			"gates":     []any{"test"},
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Success {
+		t.Fatal("expected the failing gate to block the publish")
+	}
+
+	decisions, _ := resp.Outputs["decisions"].([]string)
+	log := strings.Join(decisions, "\n")
+	for _, want := range []string{"running gates: test", "skipping publish: blocking gates failed: test"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("expected decision %q, got:\n%s", want, log)
+		}
+	}
+
+	envSummary, _ := resp.Outputs["env_summary"].(map[string]any)
+	env, _ := envSummary["env"].([]string)
+	if !contains(env, "HEX_API_KEY=<redacted>") {
+		t.Errorf("expected a redacted api key in the env summary, got %v", env)
+	}
+}
+
+func TestExecuteQuietTrimsError(t *testing.T) {
+	mock := &MockCommandExecutor{
+		RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+			return []byte("** (Mix) long mix output"), &ReplayedError{Message: "exit status 1", Code: 1}
+		},
+	}
+	p := &HexPlugin{executor: mock}
+
+	resp, _ := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key", "verbosity": "quiet"},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if resp.Success {
+		t.Fatal("expected failure")
+	}
+	if strings.Contains(resp.Error, "long mix output") {
+		t.Errorf("expected quiet verbosity to drop mix output from the error, got %q", resp.Error)
+	}
+}
+
+func TestValidateVerbosityConfig(t *testing.T) {
+	resp, err := (&HexPlugin{}).Validate(context.Background(), map[string]any{"verbosity": "loud"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Valid {
+		t.Error("expected unknown verbosity to be rejected")
+	}
+}
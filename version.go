@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// semVersion is a parsed semantic version.
+type semVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Build      string
+}
+
+// parseSemVersion splits a version such as "v1.2.3-rc.1+build.5" into its
+// numeric core, prerelease and build metadata.
+func parseSemVersion(version string) (semVersion, bool) {
+	var v semVersion
+	version = strings.TrimPrefix(version, "v")
+	version, v.Build, _ = strings.Cut(version, "+")
+	_, v.Prerelease, _ = strings.Cut(version, "-")
+	core, ok := parseVersionCore(version)
+	if !ok {
+		return v, false
+	}
+	v.Major, v.Minor, v.Patch = core[0], core[1], core[2]
+	return v, true
+}
+
+// requirement returns the version requirement dependents would typically
+// use, e.g. "~> 1.2".
+func (v semVersion) requirement() string {
+	return fmt.Sprintf("~> %d.%d", v.Major, v.Minor)
+}
+
+// addVersionOutputs adds the components of version to outputs so templates
+// need not parse it themselves. Unparseable versions add nothing.
+func addVersionOutputs(outputs map[string]any, version string) {
+	v, ok := parseSemVersion(version)
+	if !ok {
+		return
+	}
+	outputs["major"] = v.Major
+	outputs["minor"] = v.Minor
+	outputs["patch"] = v.Patch
+	outputs["prerelease"] = v.Prerelease
+	outputs["is_prerelease"] = v.Prerelease != ""
+	outputs["requirement"] = v.requirement()
+}
+
+// stripBuildMetadata removes a "+build" suffix from version, returning the
+// version and the metadata removed.
+func stripBuildMetadata(version string) (string, string) {
+	version, build, _ := strings.Cut(version, "+")
+	return version, build
+}
+
+// validateHexVersion checks that version is a semantic version Hex accepts,
+// naming the rule it breaks so the failure is clearer than the registry's
+// 422 response.
+func validateHexVersion(version string) error {
+	version = strings.TrimPrefix(version, "v")
+	if version == "" {
+		return fmt.Errorf("version is empty")
+	}
+	if _, build, ok := strings.Cut(version, "+"); ok {
+		return fmt.Errorf("build metadata +%s is not accepted by Hex", build)
+	}
+
+	core, prerelease, hasPrerelease := strings.Cut(version, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%s must have the form MAJOR.MINOR.PATCH", core)
+	}
+	for i, part := range parts {
+		name := [3]string{"major", "minor", "patch"}[i]
+		if err := validateNumericIdentifier(part); err != nil {
+			return fmt.Errorf("%s version %q %v", name, part, err)
+		}
+	}
+
+	if !hasPrerelease {
+		return nil
+	}
+	for _, ident := range strings.Split(prerelease, ".") {
+		if ident == "" {
+			return fmt.Errorf("prerelease %q has an empty identifier", prerelease)
+		}
+		if strings.Trim(ident, "0123456789") == "" {
+			if err := validateNumericIdentifier(ident); err != nil {
+				return fmt.Errorf("prerelease identifier %q %v", ident, err)
+			}
+			continue
+		}
+		if strings.Trim(ident, "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-") != "" {
+			return fmt.Errorf("prerelease identifier %q may only contain ASCII letters, digits and hyphens", ident)
+		}
+	}
+	return nil
+}
+
+// validateNumericIdentifier checks a version number: digits only, without
+// leading zeros.
+func validateNumericIdentifier(s string) error {
+	switch {
+	case s == "":
+		return fmt.Errorf("is empty")
+	case strings.Trim(s, "0123456789") != "":
+		return fmt.Errorf("must be a number")
+	case len(s) > 1 && s[0] == '0':
+		return fmt.Errorf("must not have leading zeros")
+	}
+	return nil
+}
+
+// checkVersion validates the release version at PrePublish, failing the
+// release before any plugin publishes a version Hex would reject. Contexts
+// without a version pass, as the publish uses whatever mix.exs declares.
+func (p *HexPlugin) checkVersion(releaseCtx plugin.ReleaseContext) *plugin.ExecuteResponse {
+	version := strings.TrimPrefix(releaseCtx.Version, "v")
+	if version == "" {
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "No release version to check; the version in mix.exs will be published",
+		}
+	}
+	if err := validateHexVersion(version); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid version for Hex: %v", err),
+		}
+	}
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: fmt.Sprintf("Version %s is valid for Hex", version),
+		Outputs: map[string]any{"version": version},
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseSemVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    semVersion
+		ok      bool
+	}{
+		{version: "1.2.3", want: semVersion{Major: 1, Minor: 2, Patch: 3}, ok: true},
+		{version: "v0.4.0", want: semVersion{Minor: 4}, ok: true},
+		{version: "2.0.0-rc.1", want: semVersion{Major: 2, Prerelease: "rc.1"}, ok: true},
+		{version: "1.0.0-beta+exp.sha.5114f85", want: semVersion{Major: 1, Prerelease: "beta", Build: "exp.sha.5114f85"}, ok: true},
+		{version: "1.0.0+build-7", want: semVersion{Major: 1, Build: "build-7"}, ok: true},
+		{version: "1.2", ok: false},
+		{version: "latest", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, ok := parseSemVersion(tt.version)
+			if ok != tt.ok {
+				t.Fatalf("parseSemVersion(%q) ok = %v, want %v", tt.version, ok, tt.ok)
+			}
+			if ok && got != tt.want {
+				t.Errorf("parseSemVersion(%q) = %+v, want %+v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteVersionOutputs(t *testing.T) {
+	p := &HexPlugin{executor: &MockCommandExecutor{}}
+
+	tests := []struct {
+		name    string
+		version string
+		dryRun  bool
+		want    map[string]any
+	}{
+		{
+			name:    "stable",
+			version: "1.4.2",
+			want:    map[string]any{"major": 1, "minor": 4, "patch": 2, "prerelease": "", "is_prerelease": false, "requirement": "~> 1.4"},
+		},
+		{
+			name:    "prerelease dry run",
+			version: "v2.0.0-rc.1",
+			dryRun:  true,
+			want:    map[string]any{"major": 2, "minor": 0, "patch": 0, "prerelease": "rc.1", "is_prerelease": true, "requirement": "~> 2.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key"},
+				Context: plugin.ReleaseContext{Version: tt.version},
+				DryRun:  tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			for key, want := range tt.want {
+				if got := resp.Outputs[key]; got != want {
+					t.Errorf("output %s = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateHexVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr string
+	}{
+		{version: "1.2.3"},
+		{version: "v0.1.0"},
+		{version: "1.0.0-rc.1"},
+		{version: "1.0.0-alpha-2.0"},
+		{version: "", wantErr: "version is empty"},
+		{version: "1.0.0+build.5", wantErr: "build metadata +build.5 is not accepted by Hex"},
+		{version: "1.0", wantErr: "must have the form MAJOR.MINOR.PATCH"},
+		{version: "1.02.0", wantErr: `minor version "02" must not have leading zeros`},
+		{version: "1.x.0", wantErr: `minor version "x" must be a number`},
+		{version: "1.0.0-rc..1", wantErr: "has an empty identifier"},
+		{version: "1.0.0-rc.01", wantErr: `prerelease identifier "01" must not have leading zeros`},
+		{version: "1.0.0-rc_1", wantErr: "may only contain ASCII letters, digits and hyphens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			err := validateHexVersion(tt.version)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExecutePrePublishVersionCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		wantErr string
+	}{
+		{name: "valid", version: "v1.2.3-rc.1"},
+		{name: "no version", version: ""},
+		{name: "build metadata", version: "1.2.3+ci.42", wantErr: "invalid version for Hex: build metadata +ci.42 is not accepted by Hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPrePublish,
+				Config:  map[string]any{},
+				Context: plugin.ReleaseContext{Version: tt.version},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(mock.Calls) != 0 {
+				t.Errorf("expected no commands at PrePublish, got %+v", mock.Calls)
+			}
+			if tt.wantErr == "" {
+				if !resp.Success {
+					t.Errorf("expected success, got error: %s", resp.Error)
+				}
+				return
+			}
+			if resp.Success || resp.Error != tt.wantErr {
+				t.Errorf("expected error %q, got %+v", tt.wantErr, resp)
+			}
+		})
+	}
+}
+
+func TestExecutePublishRejectsInvalidVersion(t *testing.T) {
+	mock := &MockCommandExecutor{}
+	p := &HexPlugin{executor: mock}
+	resp, _ := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  map[string]any{"api_key": "test-api-key"},
+		Context: plugin.ReleaseContext{Version: "1.0"},
+	})
+	if resp.Success || !strings.Contains(resp.Error, "must have the form MAJOR.MINOR.PATCH") {
+		t.Errorf("expected an invalid version to fail, got %+v", resp)
+	}
+	if len(mock.Calls) != 0 {
+		t.Errorf("expected nothing to run, got %+v", mock.Calls)
+	}
+}
+
+func TestExecuteStripBuildMetadata(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		strip     bool
+		wantErr   string
+		wantBuild string
+	}{
+		{name: "stripped", version: "1.2.3+ci.42", strip: true, wantBuild: "ci.42"},
+		{name: "no metadata", version: "1.2.3", strip: true},
+		{name: "not stripped", version: "1.2.3+ci.42", wantErr: "build metadata +ci.42 is not accepted by Hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &HexPlugin{executor: &MockCommandExecutor{}}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "strip_build_metadata": tt.strip},
+				Context: plugin.ReleaseContext{Version: tt.version},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" {
+				if resp.Success || !strings.Contains(resp.Error, tt.wantErr) {
+					t.Errorf("expected error containing %q, got %+v", tt.wantErr, resp)
+				}
+				return
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got error: %s", resp.Error)
+			}
+			if resp.Outputs["version"] != "1.2.3" {
+				t.Errorf("version = %v, want 1.2.3", resp.Outputs["version"])
+			}
+			if tt.wantBuild == "" {
+				if _, ok := resp.Outputs["source_version"]; ok {
+					t.Errorf("expected no source_version, got %v", resp.Outputs["source_version"])
+				}
+				return
+			}
+			if resp.Outputs["source_version"] != tt.version || resp.Outputs["build_metadata"] != tt.wantBuild {
+				t.Errorf("mapping = %v -> %v, want %s -> %s", resp.Outputs["source_version"], resp.Outputs["build_metadata"], tt.version, tt.wantBuild)
+			}
+		})
+	}
+}
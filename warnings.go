@@ -0,0 +1,59 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// publishWarningPattern matches the "WARNING! ..." lines printed by mix hex.publish.
+	publishWarningPattern = regexp.MustCompile(`^(\s*)WARNING!\s*(.*)$`)
+	// ansiPattern matches ANSI color escapes Hex adds when colors are enabled.
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// parsePublishWarnings extracts the warnings printed while building a
+// package, such as missing metadata fields or excluded dependencies. A
+// warning ending in ":" absorbs the more indented lines that follow it.
+func parsePublishWarnings(output string) []string {
+	lines := strings.Split(ansiPattern.ReplaceAllString(output, ""), "\n")
+
+	var warnings []string
+	for i := 0; i < len(lines); i++ {
+		m := publishWarningPattern.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+		if m == nil {
+			continue
+		}
+		warning := strings.TrimSpace(m[2])
+
+		if strings.HasSuffix(warning, ":") {
+			var items []string
+			for i+1 < len(lines) {
+				next := strings.TrimRight(lines[i+1], "\r")
+				item := strings.TrimSpace(next)
+				if item == "" || len(next)-len(strings.TrimLeft(next, " \t")) <= len(m[1]) {
+					break
+				}
+				items = append(items, item)
+				i++
+			}
+			if len(items) > 0 {
+				warning += " " + strings.Join(items, ", ")
+			}
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// publishDryRunArgs returns the hex.publish arguments for a local build that
+// reports warnings without uploading anything.
+func publishDryRunArgs(args []string) []string {
+	dryRun := make([]string, 0, len(args)+1)
+	for _, arg := range args {
+		if arg != "--yes" && arg != "--replace" {
+			dryRun = append(dryRun, arg)
+		}
+	}
+	return append(dryRun, "--dry-run")
+}
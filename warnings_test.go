@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParsePublishWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "no warnings",
+			output: "Building my_lib 1.0.0\n  App: my_lib\n  Version: 1.0.0\n",
+			want:   nil,
+		},
+		{
+			name:   "missing metadata",
+			output: "Building my_lib 1.0.0\n  WARNING! Missing metadata fields: description, links\n  Version: 1.0.0\n",
+			want:   []string{"Missing metadata fields: description, links"},
+		},
+		{
+			name: "excluded dependencies list",
+			output: "Building my_lib 1.0.0\n" +
+				"  WARNING! Excluded dependencies (not part of the Hex package):\n" +
+				"    local_dep\n" +
+				"    git_dep\n" +
+				"  App: my_lib\n",
+			want: []string{"Excluded dependencies (not part of the Hex package): local_dep, git_dep"},
+		},
+		{
+			name:   "ansi colors",
+			output: "\x1b[33m  WARNING! Missing files: CHANGELOG.md\x1b[0m\r\n",
+			want:   []string{"Missing files: CHANGELOG.md"},
+		},
+		{
+			name:   "several warnings",
+			output: "WARNING! Missing metadata fields: links\nWARNING! Missing files: README.md\n",
+			want:   []string{"Missing metadata fields: links", "Missing files: README.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePublishWarnings(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePublishWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishDryRunArgs(t *testing.T) {
+	got := publishDryRunArgs([]string{"hex.publish", "--organization", "acme", "--replace", "--yes"})
+	want := []string{"hex.publish", "--organization", "acme", "--dry-run"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("publishDryRunArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestExecutePublishWarnings(t *testing.T) {
+	const warningOutput = "Building my_lib 1.0.0\n  WARNING! Missing metadata fields: links\n"
+
+	tests := []struct {
+		name            string
+		config          map[string]any
+		expectedSuccess bool
+		expectedCalls   int
+	}{
+		{
+			name:            "warnings reported after publish",
+			config:          map[string]any{"api_key": "test-api-key"},
+			expectedSuccess: true,
+			expectedCalls:   1,
+		},
+		{
+			name:            "warnings fail before upload",
+			config:          map[string]any{"api_key": "test-api-key", "warnings_as_errors_publish": true},
+			expectedSuccess: false,
+			expectedCalls:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					return []byte(warningOutput), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  tt.config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if len(mock.Calls) != tt.expectedCalls {
+				t.Fatalf("expected %d calls, got %d", tt.expectedCalls, len(mock.Calls))
+			}
+			warnings, _ := resp.Outputs["warnings"].([]string)
+			if !reflect.DeepEqual(warnings, []string{"Missing metadata fields: links"}) {
+				t.Errorf("unexpected warnings output: %v", resp.Outputs["warnings"])
+			}
+			if !tt.expectedSuccess {
+				if !contains(mock.Calls[0].Args, "--dry-run") {
+					t.Errorf("expected dry-run build, got %v", mock.Calls[0].Args)
+				}
+				if !strings.Contains(resp.Error, "Missing metadata fields") {
+					t.Errorf("expected error to list warnings, got %q", resp.Error)
+				}
+			}
+		})
+	}
+}
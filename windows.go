@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actions taken outside every publish window, selected by publish_window_action.
+const (
+	windowActionReject = "reject"
+	windowActionWait   = "wait"
+)
+
+// cronTZPrefix selects the timezone of a string schedule, as in crontab.
+const cronTZPrefix = "CRON_TZ="
+
+// windowSearchLimit bounds the search for the next opening of a window.
+const windowSearchLimit = 8 * 24 * time.Hour
+
+// PublishWindow is a cron-like schedule of minutes in which publishing is allowed.
+type PublishWindow struct {
+	Schedule string `json:"schedule"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// cronField names, bounds and aliases of the five schedule fields.
+var cronFields = []struct {
+	name     string
+	min, max int
+	names    []string
+}{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// cronSchedule is a parsed schedule: the allowed values of each field.
+type cronSchedule struct {
+	fields   [5]map[int]bool
+	location *time.Location
+	// anyDay is set when day of month or day of week starts with "*"; the
+	// two day fields are combined with AND then, and with OR otherwise.
+	anyDay bool
+}
+
+// parsePublishWindow accepts "CRON_TZ=<zone> <schedule>" strings.
+func parsePublishWindow(s string) PublishWindow {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, cronTZPrefix) {
+		zone, rest, _ := strings.Cut(strings.TrimPrefix(s, cronTZPrefix), " ")
+		return PublishWindow{Schedule: strings.TrimSpace(rest), Timezone: zone}
+	}
+	return PublishWindow{Schedule: s}
+}
+
+// parsePublishWindows decodes publish_windows, accepting strings or objects.
+func parsePublishWindows(raw map[string]any) ([]PublishWindow, *fieldError) {
+	return decodeObjectList(raw, "publish_windows", parsePublishWindow)
+}
+
+// validatePublishWindows checks every schedule and timezone.
+func validatePublishWindows(raw map[string]any) []*fieldError {
+	windows, err := parsePublishWindows(raw)
+	if err != nil {
+		return []*fieldError{err}
+	}
+	var errs []*fieldError
+	for i, w := range windows {
+		if _, err := w.compile(); err != nil {
+			errs = append(errs, &fieldError{Field: fmt.Sprintf("publish_windows[%d]", i), Err: err})
+		}
+	}
+	return errs
+}
+
+// validateWindowAction checks that publish_window_action names a known action.
+func validateWindowAction(action string) error {
+	switch action {
+	case "", windowActionReject, windowActionWait:
+		return nil
+	default:
+		return fmt.Errorf("must be %s or %s", windowActionReject, windowActionWait)
+	}
+}
+
+// compile parses the schedule and loads its timezone (UTC by default).
+func (w PublishWindow) compile() (*cronSchedule, error) {
+	parts := strings.Fields(w.Schedule)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("schedule %q must have 5 fields: minute hour day-of-month month day-of-week", w.Schedule)
+	}
+
+	sched := &cronSchedule{location: time.UTC, anyDay: strings.HasPrefix(parts[2], "*") || strings.HasPrefix(parts[4], "*")}
+	if w.Timezone != "" {
+		loc, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", w.Timezone)
+		}
+		sched.location = loc
+	}
+
+	for i, part := range parts {
+		values, err := parseCronField(part, i)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", cronFields[i].name, part, err)
+		}
+		sched.fields[i] = values
+	}
+	// Sunday is both 0 and 7
+	if sched.fields[4][7] {
+		sched.fields[4][0] = true
+	}
+	return sched, nil
+}
+
+// parseCronField expands one field of lists, ranges, steps and names.
+func parseCronField(field string, index int) (map[int]bool, error) {
+	spec := cronFields[index]
+	values := map[int]bool{}
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := spec.min, spec.max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(from, index); err != nil {
+				return nil, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(to, index); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				hi = spec.max
+			}
+			if lo > hi {
+				return nil, fmt.Errorf("range %q is reversed", rangePart)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// cronValue parses a number or a month/weekday name within the field bounds.
+func cronValue(s string, index int) (int, error) {
+	spec := cronFields[index]
+	for i, name := range spec.names {
+		if strings.EqualFold(s, name) {
+			return i + spec.min, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if n < spec.min || n > spec.max {
+		return 0, fmt.Errorf("%d is outside %d-%d", n, spec.min, spec.max)
+	}
+	return n, nil
+}
+
+// matches reports whether the minute containing t lies in the schedule. As
+// in cron, a day matches either day field when both are restricted.
+func (c *cronSchedule) matches(t time.Time) bool {
+	t = t.In(c.location)
+	day := c.fields[2][t.Day()] && c.fields[4][int(t.Weekday())]
+	if !c.anyDay {
+		day = c.fields[2][t.Day()] || c.fields[4][int(t.Weekday())]
+	}
+	return c.fields[0][t.Minute()] &&
+		c.fields[1][t.Hour()] &&
+		c.fields[3][int(t.Month())] &&
+		day
+}
+
+// next returns the start of the first minute after t in the schedule.
+func (c *cronSchedule) next(t time.Time) (time.Time, bool) {
+	start := t.Truncate(time.Minute).Add(time.Minute)
+	for m := start; m.Sub(start) < windowSearchLimit; m = m.Add(time.Minute) {
+		if c.matches(m) {
+			return m, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// windowStatus reports whether now lies in any window and, if not, when the
+// earliest window opens.
+func windowStatus(windows []PublishWindow, now time.Time) (bool, time.Time, error) {
+	var opens time.Time
+	for _, w := range windows {
+		sched, err := w.compile()
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		if sched.matches(now) {
+			return true, time.Time{}, nil
+		}
+		if next, ok := sched.next(now); ok && (opens.IsZero() || next.Before(opens)) {
+			opens = next
+		}
+	}
+	return false, opens, nil
+}
+
+// checkPublishWindows fails when now is outside every publish window, or
+// waits for the next window when wait is set. It returns how long it waited.
+func checkPublishWindows(ctx context.Context, cfg *Config, now time.Time, wait bool) (time.Duration, error) {
+	if len(cfg.PublishWindows) == 0 {
+		return 0, nil
+	}
+
+	open, opens, err := windowStatus(cfg.PublishWindows, now)
+	if err != nil {
+		return 0, err
+	}
+	if open {
+		return 0, nil
+	}
+
+	when := "no window opens in the next 8 days"
+	if !opens.IsZero() {
+		when = "next window opens at " + opens.In(now.Location()).Format(time.RFC3339)
+	}
+	if !wait || opens.IsZero() {
+		return 0, fmt.Errorf("publishing is not allowed at %s: %s", now.Format(time.RFC3339), when)
+	}
+
+	delay := opens.Sub(now)
+	select {
+	case <-ctx.Done():
+		return 0, fmt.Errorf("cancelled while waiting for the publish window: %s", when)
+	case <-time.After(delay):
+		return delay, nil
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParsePublishWindow(t *testing.T) {
+	got := parsePublishWindow("CRON_TZ=Europe/Berlin * 9-16 * * mon-thu")
+	if got.Timezone != "Europe/Berlin" || got.Schedule != "* 9-16 * * mon-thu" {
+		t.Errorf("parsePublishWindow() = %+v", got)
+	}
+	if got := parsePublishWindow("* * * * *"); got.Timezone != "" || got.Schedule != "* * * * *" {
+		t.Errorf("parsePublishWindow() = %+v", got)
+	}
+}
+
+func TestPublishWindowCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  PublishWindow
+		wantErr string
+	}{
+		{name: "every minute", window: PublishWindow{Schedule: "* * * * *"}},
+		{name: "names and steps", window: PublishWindow{Schedule: "*/15 9-17 1-28 jan-nov mon,wed,fri"}},
+		{name: "sunday as 7", window: PublishWindow{Schedule: "* * * * 7"}},
+		{name: "timezone", window: PublishWindow{Schedule: "* * * * *", Timezone: "UTC"}},
+		{name: "too few fields", window: PublishWindow{Schedule: "* * *"}, wantErr: "5 fields"},
+		{name: "out of range", window: PublishWindow{Schedule: "* 24 * * *"}, wantErr: "invalid hour"},
+		{name: "reversed range", window: PublishWindow{Schedule: "* * * * fri-mon"}, wantErr: "reversed"},
+		{name: "bad step", window: PublishWindow{Schedule: "*/0 * * * *"}, wantErr: "invalid step"},
+		{name: "unknown timezone", window: PublishWindow{Schedule: "* * * * *", Timezone: "Mars/Olympus"}, wantErr: "unknown timezone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.window.compile()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWindowStatus(t *testing.T) {
+	// Monday to Thursday, 09:00-16:59 UTC
+	windows := []PublishWindow{{Schedule: "* 9-16 * * mon-thu"}}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantOpen  bool
+		wantOpens time.Time
+	}{
+		{
+			name:     "inside",
+			now:      time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC), // Wednesday
+			wantOpen: true,
+		},
+		{
+			name:      "friday evening",
+			now:       time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC),
+			wantOpens: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "end of day",
+			now:       time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC),
+			wantOpens: time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, opens, err := windowStatus(windows, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if open != tt.wantOpen || !opens.Equal(tt.wantOpens) {
+				t.Errorf("windowStatus() = %v, %v; want %v, %v", open, opens, tt.wantOpen, tt.wantOpens)
+			}
+		})
+	}
+}
+
+func TestCronScheduleDayFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		day      int
+		want     bool
+	}{
+		// October 2026: the 1st is a Thursday, the 5th a Monday
+		{name: "both restricted, day of month", schedule: "0 9 1 * mon", day: 1, want: true},
+		{name: "both restricted, day of week", schedule: "0 9 1 * mon", day: 5, want: true},
+		{name: "both restricted, neither", schedule: "0 9 1 * mon", day: 6},
+		{name: "any day of week", schedule: "0 9 1 * *", day: 5},
+		{name: "any day of month", schedule: "0 9 * * mon", day: 1},
+		{name: "starred day of month step", schedule: "0 9 */2 * mon", day: 1},
+		{name: "starred day of month step and weekday", schedule: "0 9 */2 * thu", day: 1, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := PublishWindow{Schedule: tt.schedule}.compile()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := sched.matches(time.Date(2026, 10, tt.day, 9, 0, 0, 0, time.UTC)); got != tt.want {
+				t.Errorf("matches(October %d) = %v, want %v", tt.day, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowStatusTimezone(t *testing.T) {
+	windows := []PublishWindow{{Schedule: "* 9-16 * * *", Timezone: "Asia/Tokyo"}}
+	if _, err := windows[0].compile(); err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// 01:00 UTC is 10:00 in Tokyo
+	open, _, err := windowStatus(windows, time.Date(2026, 10, 14, 1, 0, 0, 0, time.UTC))
+	if err != nil || !open {
+		t.Errorf("expected the Tokyo window to be open, got %v (%v)", open, err)
+	}
+}
+
+func TestExecutePublishWindows(t *testing.T) {
+	tests := []struct {
+		name            string
+		now             time.Time
+		action          string
+		dryRun          bool
+		expectedSuccess bool
+		expectedError   string
+		expectedCalls   int
+		expectedWindow  string
+	}{
+		{
+			name:            "inside window",
+			now:             time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+			expectedSuccess: true,
+			expectedCalls:   1,
+		},
+		{
+			name:          "rejected outside window",
+			now:           time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC),
+			expectedError: "next window opens at 2026-10-19T09:00:00Z",
+		},
+		{
+			name:            "waits for the window",
+			now:             time.Date(2026, 10, 14, 8, 59, 59, 990_000_000, time.UTC),
+			action:          "wait",
+			expectedSuccess: true,
+			expectedCalls:   1,
+		},
+		{
+			name:            "dry run reports a closed window",
+			now:             time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC),
+			action:          "wait",
+			dryRun:          true,
+			expectedSuccess: true,
+			expectedWindow:  "publishing is not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{}
+			p := &HexPlugin{executor: mock, clock: func() time.Time { return tt.now }}
+
+			config := map[string]any{
+				"api_key":         "test-api-key",
+				"publish_windows": []any{"* 9-16 * * mon-thu"},
+			}
+			if tt.action != "" {
+				config["publish_window_action"] = tt.action
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+				DryRun:  tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if tt.expectedError != "" && !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tt.expectedError, resp.Error)
+			}
+			if len(mock.Calls) != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, len(mock.Calls))
+			}
+			if tt.expectedWindow != "" {
+				if got, _ := resp.Outputs["publish_window"].(string); !strings.Contains(got, tt.expectedWindow) {
+					t.Errorf("expected publish_window containing %q, got %q", tt.expectedWindow, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePublishWindows(t *testing.T) {
+	p := &HexPlugin{}
+
+	resp, err := p.Validate(context.Background(), map[string]any{
+		"publish_windows":       []any{"* 25 * * *"},
+		"publish_window_action": "defer",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Valid || len(resp.Errors) != 2 {
+		t.Errorf("expected schedule and action errors, got %+v", resp.Errors)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Dirty worktree modes selected by dirty_worktree.
+const (
+	dirtyWorktreeIgnore = "ignore"
+	dirtyWorktreeWarn   = "warn"
+	dirtyWorktreeFail   = "fail"
+)
+
+// validateDirtyWorktree checks that dirty_worktree names a known mode.
+func validateDirtyWorktree(mode string) error {
+	switch mode {
+	case "", dirtyWorktreeIgnore, dirtyWorktreeWarn, dirtyWorktreeFail:
+		return nil
+	default:
+		return fmt.Errorf("must be %s, %s or %s", dirtyWorktreeIgnore, dirtyWorktreeWarn, dirtyWorktreeFail)
+	}
+}
+
+// parsePorcelainStatus returns the paths listed by git status --porcelain.
+func parsePorcelainStatus(output string) []string {
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(line) < 4 {
+			continue
+		}
+		files = append(files, line[3:])
+	}
+	return files
+}
+
+// dirtyFiles lists uncommitted changes under work_dir, so the published
+// package is known to match the tagged commit.
+func (p *HexPlugin) dirtyFiles(ctx context.Context, cfg *Config, summary *RunSummary) ([]string, error) {
+	output, err := p.runCommand(ctx, summary, "git", []string{"status", "--porcelain", "--", "."}, nil, cfg.WorkDir)
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %v\nOutput: %s", err, string(output))
+	}
+	return parsePorcelainStatus(string(output)), nil
+}
+
+// shaMatches reports whether two commit SHAs name the same commit, allowing
+// either to be abbreviated.
+func shaMatches(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if len(a) < 7 || len(b) < 7 {
+		return a == b
+	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+// verifyCheckout checks that HEAD in work_dir is the release commit and that
+// the release tag exists locally and points at it, catching pipelines that
+// publish from a stale or wrong checkout.
+func (p *HexPlugin) verifyCheckout(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, summary *RunSummary) error {
+	if releaseCtx.CommitSHA != "" {
+		output, err := p.runCommand(ctx, summary, "git", []string{"rev-parse", "HEAD"}, nil, cfg.WorkDir)
+		if err != nil {
+			return fmt.Errorf("git rev-parse HEAD failed: %v\nOutput: %s", err, string(output))
+		}
+		if head := strings.TrimSpace(string(output)); !shaMatches(head, releaseCtx.CommitSHA) {
+			return fmt.Errorf("HEAD is %s but the release commit is %s", head, releaseCtx.CommitSHA)
+		}
+	}
+
+	if releaseCtx.TagName != "" {
+		output, err := p.runCommand(ctx, summary, "git", []string{"rev-parse", "--verify", "--quiet", "refs/tags/" + releaseCtx.TagName + "^{commit}"}, nil, cfg.WorkDir)
+		if err != nil {
+			return fmt.Errorf("tag %s does not exist locally", releaseCtx.TagName)
+		}
+		if tagged := strings.TrimSpace(string(output)); releaseCtx.CommitSHA != "" && !shaMatches(tagged, releaseCtx.CommitSHA) {
+			return fmt.Errorf("tag %s points at %s but the release commit is %s", releaseCtx.TagName, tagged, releaseCtx.CommitSHA)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParsePorcelainStatus(t *testing.T) {
+	output := " M lib/my_lib.ex\n?? notes.txt\nR  old.ex -> new.ex\n\n"
+	want := []string{"lib/my_lib.ex", "notes.txt", "old.ex -> new.ex"}
+	if got := parsePorcelainStatus(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePorcelainStatus() = %v, want %v", got, want)
+	}
+	if got := parsePorcelainStatus(""); got != nil {
+		t.Errorf("expected no files for a clean worktree, got %v", got)
+	}
+}
+
+func TestExecuteDirtyWorktree(t *testing.T) {
+	tests := []struct {
+		name            string
+		mode            string
+		status          string
+		expectedSuccess bool
+		expectedCalls   int
+		expectedDirty   []string
+	}{
+		{name: "ignored by default", status: " M lib/my_lib.ex\n", expectedSuccess: true, expectedCalls: 1},
+		{name: "clean worktree", mode: "fail", status: "", expectedSuccess: true, expectedCalls: 2},
+		{name: "dirty worktree fails", mode: "fail", status: " M lib/my_lib.ex\n", expectedSuccess: false, expectedCalls: 1, expectedDirty: []string{"lib/my_lib.ex"}},
+		{name: "dirty worktree warns", mode: "warn", status: " M lib/my_lib.ex\n", expectedSuccess: true, expectedCalls: 2, expectedDirty: []string{"lib/my_lib.ex"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					if name == "git" {
+						return []byte(tt.status), nil
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			config := map[string]any{"api_key": "test-api-key"}
+			if tt.mode != "" {
+				config["dirty_worktree"] = tt.mode
+			}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Success != tt.expectedSuccess {
+				t.Fatalf("expected success=%v, got %v (error: %s)", tt.expectedSuccess, resp.Success, resp.Error)
+			}
+			if len(mock.Calls) != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, len(mock.Calls))
+			}
+			if tt.mode != "" && !reflect.DeepEqual(mock.Calls[0].Args, []string{"status", "--porcelain", "--", "."}) {
+				t.Errorf("unexpected git status args: %v", mock.Calls[0].Args)
+			}
+			dirty, _ := resp.Outputs["dirty_files"].([]string)
+			if !reflect.DeepEqual(dirty, tt.expectedDirty) {
+				t.Errorf("dirty_files = %v, want %v", dirty, tt.expectedDirty)
+			}
+			if !tt.expectedSuccess && !strings.Contains(resp.Error, "uncommitted changes") {
+				t.Errorf("unexpected error: %s", resp.Error)
+			}
+		})
+	}
+}
+
+func TestShaMatches(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"abc123def4567890", "abc123def4567890", true},
+		{"abc123def4567890", "abc123d", true},
+		{"ABC123DEF", "abc123def", true},
+		{"abc123def4567890", "abc123e", false},
+		{"abc", "abc123def", false},
+	}
+
+	for _, tt := range tests {
+		if got := shaMatches(tt.a, tt.b); got != tt.want {
+			t.Errorf("shaMatches(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteVerifyCheckout(t *testing.T) {
+	const releaseSHA = "abc123def4567890abc123def4567890abc123de"
+
+	tests := []struct {
+		name          string
+		head          string
+		tag           string
+		tagMissing    bool
+		expectedError string
+	}{
+		{name: "aligned checkout", head: releaseSHA, tag: releaseSHA},
+		{name: "stale checkout", head: "0123456789abcdef0123456789abcdef01234567", tag: releaseSHA, expectedError: "HEAD is 0123456789abcdef"},
+		{name: "missing tag", head: releaseSHA, tagMissing: true, expectedError: "tag v1.0.0 does not exist locally"},
+		{name: "tag on another commit", head: releaseSHA, tag: "0123456789abcdef0123456789abcdef01234567", expectedError: "tag v1.0.0 points at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandExecutor{
+				RunFunc: func(ctx context.Context, name string, args []string, env []string, dir string) ([]byte, error) {
+					switch {
+					case name == "git" && args[1] == "HEAD":
+						return []byte(tt.head + "\n"), nil
+					case name == "git" && args[1] == "--verify":
+						if tt.tagMissing {
+							return nil, &ReplayedError{Message: "exit status 1", Code: 1}
+						}
+						return []byte(tt.tag + "\n"), nil
+					}
+					return []byte("Building my_lib 1.0.0"), nil
+				},
+			}
+			p := &HexPlugin{executor: mock}
+
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPostPublish,
+				Config:  map[string]any{"api_key": "test-api-key", "verify_checkout": true},
+				Context: plugin.ReleaseContext{Version: "1.0.0", TagName: "v1.0.0", CommitSHA: releaseSHA[:12]},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectedError == "" {
+				if !resp.Success {
+					t.Fatalf("expected success, got error: %s", resp.Error)
+				}
+				if len(mock.Calls) != 3 {
+					t.Errorf("expected 3 calls, got %d", len(mock.Calls))
+				}
+				return
+			}
+			if resp.Success || !strings.Contains(resp.Error, tt.expectedError) {
+				t.Errorf("expected error containing %q, got success=%v error=%q", tt.expectedError, resp.Success, resp.Error)
+			}
+			for _, call := range mock.Calls {
+				if call.Name == "mix" {
+					t.Error("expected publish not to run")
+				}
+			}
+		})
+	}
+}